@@ -0,0 +1,89 @@
+package assert
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/goccy/go-yaml"
+	"github.com/zoncoen/query-go"
+
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
+)
+
+// All returns an assertion to ensure all elements of a value satisfy the assertion.
+// If the value is a map, it checks all values of the map.
+// The errors of the elements have the paths to them such as [0] and .key.
+func All(assertion Assertion) Assertion {
+	return optional(&allAssertion{
+		assertion: assertion,
+	})
+}
+
+// Each is an alias of All.
+func Each(assertion Assertion) Assertion {
+	return All(assertion)
+}
+
+type allAssertion struct {
+	assertion Assertion
+	failFast  bool
+}
+
+// Assert implements Assertion interface.
+func (a *allAssertion) Assert(v interface{}) error {
+	var errs []error
+	check := func(q *query.Query, elem interface{}) bool {
+		if err := a.assertion.Assert(elem); err != nil {
+			errs = append(errs, errors.WithQuery(err, q))
+			return !a.failFast
+		}
+		return true
+	}
+	if m, ok := v.(yaml.MapSlice); ok {
+		for _, item := range m {
+			if !check(newQuery().Key(fmt.Sprint(item.Key)), item.Value) {
+				break
+			}
+		}
+		return errors.Errors(errs...)
+	}
+	vv := reflectutil.Elem(reflect.ValueOf(v))
+	switch vv.Kind() {
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < vv.Len(); i++ {
+			if !check(newQuery().Index(i), vv.Index(i).Interface()) {
+				break
+			}
+		}
+	case reflect.Map:
+		keys := vv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			if !check(newQuery().Key(fmt.Sprint(k.Interface())), vv.MapIndex(k).Interface()) {
+				break
+			}
+		}
+	default:
+		return errors.Errorf("expected an array or a map but got %T", v)
+	}
+	return errors.Errors(errs...)
+}
+
+// AssertsList implements ListAssertion interface.
+func (a *allAssertion) AssertsList() {}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *allAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	assertion := a.assertion
+	if oa, ok := assertion.(optionalAssertion); ok {
+		assertion = oa.withBuildOpt(opt)
+	}
+	return &allAssertion{
+		assertion: assertion,
+		failFast:  a.failFast || opt.failFast,
+	}
+}
@@ -0,0 +1,86 @@
+package assert
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestAll(t *testing.T) {
+	tests := map[string]struct {
+		in          interface{}
+		assertion   Assertion
+		expectError string
+	}{
+		"empty": {
+			in:        []int{},
+			assertion: Greater(0),
+		},
+		"slice": {
+			in:        []int{1, 2, 3},
+			assertion: Greater(0),
+		},
+		"array": {
+			in:        [2]int{1, 2},
+			assertion: Greater(0),
+		},
+		"map": {
+			in:        map[string]int{"a": 1, "b": 2},
+			assertion: Greater(0),
+		},
+		"yaml.MapSlice": {
+			in:        yaml.MapSlice{{Key: "a", Value: 1}},
+			assertion: Greater(0),
+		},
+		"slice with errors": {
+			in:          []int{1, -1, 2, -2},
+			assertion:   GreaterOrEqual(0),
+			expectError: "2 errors occurred: [1]: must be equal or greater than 0\n[3]: must be equal or greater than 0",
+		},
+		"map with errors": {
+			in:          map[string]int{"b": -2, "a": -1, "c": 0},
+			assertion:   GreaterOrEqual(0),
+			expectError: "2 errors occurred: .a: must be equal or greater than 0\n.b: must be equal or greater than 0",
+		},
+		"yaml.MapSlice with errors": {
+			in:          yaml.MapSlice{{Key: "a", Value: 1}, {Key: "b", Value: -1}},
+			assertion:   GreaterOrEqual(0),
+			expectError: "1 error occurred: .b: must be equal or greater than 0",
+		},
+		"not array or map": {
+			in:          1,
+			assertion:   GreaterOrEqual(0),
+			expectError: "expected an array or a map but got int",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			err := All(test.assertion).Assert(test.in)
+			if test.expectError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error but got no error")
+			}
+			if got := err.Error(); got != test.expectError {
+				t.Fatalf("expect %q but got %q", test.expectError, got)
+			}
+		})
+	}
+}
+
+func TestAll_FailFast(t *testing.T) {
+	assertion := MustBuild(context.Background(), All(GreaterOrEqual(0)), WithFailFast())
+	err := assertion.Assert([]int{-1, -2})
+	if err == nil {
+		t.Fatal("expected error but got no error")
+	}
+	if got, expect := err.Error(), "1 error occurred: [0]: must be equal or greater than 0"; got != expect {
+		t.Fatalf("expect %q but got %q", expect, got)
+	}
+}
@@ -0,0 +1,50 @@
+package assert
+
+import (
+	"math"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// ApproxEqual returns an assertion to ensure a value equals the expected value within the absolute tolerance.
+// It passes if |actual - expected| <= tolerance.
+func ApproxEqual(expected, tolerance float64) Assertion {
+	return AssertionFunc(func(v interface{}) error {
+		f, err := toFloat64(v)
+		if err != nil {
+			return err
+		}
+		if delta := math.Abs(f - expected); !(delta <= tolerance) {
+			return errors.Errorf("expected %v ± %v but got %v (delta %v)", expected, tolerance, f, delta)
+		}
+		return nil
+	})
+}
+
+// ApproxEqualRelative returns an assertion to ensure a value equals the expected value within the relative tolerance.
+// It passes if |actual - expected| <= tolerance * |expected|. It is useful to compare values of large magnitude.
+func ApproxEqualRelative(expected, tolerance float64) Assertion {
+	return AssertionFunc(func(v interface{}) error {
+		f, err := toFloat64(v)
+		if err != nil {
+			return err
+		}
+		limit := tolerance * math.Abs(expected)
+		if delta := math.Abs(f - expected); !(delta <= limit) {
+			return errors.Errorf("expected %v ± %v (relative tolerance %v) but got %v (delta %v)", expected, limit, tolerance, f, delta)
+		}
+		return nil
+	})
+}
+
+// toFloat64 converts a number into float64.
+func toFloat64(v interface{}) (float64, error) {
+	if v == nil {
+		return 0, errors.New("failed to convert <nil> to number")
+	}
+	n, err := toNumber(v)
+	if err != nil {
+		return 0, err
+	}
+	return convertToFloat64(n)
+}
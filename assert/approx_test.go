@@ -0,0 +1,80 @@
+package assert
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApproxEqual(t *testing.T) {
+	tests := map[string]struct {
+		expected  float64
+		tolerance float64
+		ok        []interface{}
+		ng        []interface{}
+	}{
+		"float": {
+			expected:  0.3,
+			tolerance: 1e-9,
+			ok:        []interface{}{0.1 + 0.2, json.Number("0.3")},
+			ng:        []interface{}{0.31, float32(0.3), json.Number("0.299")},
+		},
+		"integer": {
+			expected:  100,
+			tolerance: 1,
+			ok:        []interface{}{99, 100, uint8(101), json.Number("100")},
+			ng:        []interface{}{98, int64(102)},
+		},
+		"not number": {
+			expected:  1,
+			tolerance: 1,
+			ng:        []interface{}{"1", nil, true},
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assertion := ApproxEqual(tc.expected, tc.tolerance)
+			for _, v := range tc.ok {
+				if err := assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range tc.ng {
+				if err := assertion.Assert(v); err == nil {
+					t.Errorf("%v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := ApproxEqual(1.5, 0.25).Assert(2)
+		if err == nil {
+			t.Fatal("expected error but no error")
+		}
+		if got, expect := err.Error(), "expected 1.5 ± 0.25 but got 2 (delta 0.5)"; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+}
+
+func TestApproxEqualRelative(t *testing.T) {
+	assertion := ApproxEqualRelative(1e10, 0.01)
+	for _, v := range []interface{}{1e10, 1.009e10, int64(9_900_000_000)} {
+		if err := assertion.Assert(v); err != nil {
+			t.Errorf("%v: unexpected error: %s", v, err)
+		}
+	}
+	for _, v := range []interface{}{1.02e10, 9.8e9, "1e10"} {
+		if err := assertion.Assert(v); err == nil {
+			t.Errorf("%v: expected error but no error", v)
+		}
+	}
+	err := ApproxEqualRelative(100, 0.1).Assert(120)
+	if err == nil {
+		t.Fatal("expected error but no error")
+	}
+	if got, expect := err.Error(), "expected 100 ± 10 (relative tolerance 0.1) but got 120 (delta 20)"; got != expect {
+		t.Errorf("expect %q but got %q", expect, got)
+	}
+}
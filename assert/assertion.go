@@ -4,7 +4,9 @@ package assert
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sync"
+	"time"
 
 	"github.com/goccy/go-yaml"
 	"github.com/zoncoen/query-go"
@@ -27,15 +29,97 @@ func (f AssertionFunc) Assert(v interface{}) error {
 	return f(v)
 }
 
+// ListAssertion is implemented by the assertions which assert a list as a whole such as Length, All, SetEqual, Empty, and NotEmpty.
+// Build also returns a ListAssertion if the expected value is a single ListAssertion,
+// so callers can tell whether the built assertion checks a list of values or each value of it.
+type ListAssertion interface {
+	Assertion
+	// AssertsList is a marker method which does nothing.
+	AssertsList()
+}
+
+// listAssertionFunc is an adaptor to allow the use of ordinary functions as list assertions.
+type listAssertionFunc func(v interface{}) error
+
+// Assert implements Assertion interface.
+func (f listAssertionFunc) Assert(v interface{}) error {
+	return f(v)
+}
+
+// AssertsList implements ListAssertion interface.
+func (f listAssertionFunc) AssertsList() {}
+
+// optionalAssertion is implemented by assertions that depend on build options.
+type optionalAssertion interface {
+	Assertion
+	withBuildOpt(opt *buildOpt) Assertion
+}
+
+// optionalAssertionFunc is an adaptor to allow the use of ordinary functions as optional assertions.
+// Assertions should be functions because the template package passes only functions as they are to left arrow functions.
+type optionalAssertionFunc func(opt *buildOpt) Assertion
+
+// Assert implements Assertion interface.
+func (f optionalAssertionFunc) Assert(v interface{}) error {
+	return f(nil).Assert(v)
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (f optionalAssertionFunc) withBuildOpt(opt *buildOpt) Assertion {
+	return f(opt)
+}
+
+// optional converts a into optionalAssertionFunc.
+func optional(a optionalAssertion) Assertion {
+	return optionalAssertionFunc(func(opt *buildOpt) Assertion {
+		if opt == nil {
+			return a
+		}
+		return a.withBuildOpt(opt)
+	})
+}
+
+// invalidAssertion is an assertion that has failed to be initialized.
+// Build returns the error immediately instead of deferring it to Assert.
+type invalidAssertion struct {
+	err error
+}
+
+// Assert implements Assertion interface.
+func (a *invalidAssertion) Assert(_ interface{}) error {
+	return a.err
+}
+
 type buildOpt struct {
-	tmplData any
-	eqs      []Equaler
+	ctx        context.Context
+	fs         []BuildOpt // to build the arguments of the matchers with the same options
+	tmplData   any
+	eqs        []Equaler
+	typeEqs    map[reflect.Type]Equaler
+	failFast   bool
+	timeLayout string
+
+	numericTolerance float64
+	errorContext     int
+	maxErrors        int
+	parallel         int
+	caseInsensitive  bool
+	envAllowlist     []string
+	ignorePaths      []pathPattern
+	ignorePathsErr   error
+	snapshotDir      string
+	updateSnapshots  bool
+	clock            template.Clock
+	nanEqual         bool
+	coercions        []func(any) (any, bool)
+	missingAsNil     bool
 }
 
 // BuildOpt represents an option for Build().
 type BuildOpt func(*buildOpt)
 
 // FromTemplate is a build option that executes templates before building assertions.
+// The data can be any value such as nested maps, structs, and slices, and templates refer to it by paths like {{vars.user.id}} or {{vars.ids[0]}}.
 func FromTemplate(data any) BuildOpt {
 	return func(opt *buildOpt) {
 		opt.tmplData = data
@@ -49,32 +133,155 @@ func WithEqualers(eqs ...Equaler) BuildOpt {
 	}
 }
 
+// WithTypeEqualer is a build option that enables a custom equaler for the specific type.
+// The equaler is used only if both values are of type t, and it takes precedence over the equalers passed by WithEqualers.
+func WithTypeEqualer(t reflect.Type, eq Equaler) BuildOpt {
+	return func(opt *buildOpt) {
+		if opt.typeEqs == nil {
+			opt.typeEqs = map[reflect.Type]Equaler{}
+		}
+		opt.typeEqs[t] = eq
+	}
+}
+
+// WithFailFast is a build option that stops the assertion at the first error instead of collecting all errors.
+// It also applies to And assertions in the expected value.
+func WithFailFast() BuildOpt {
+	return func(opt *buildOpt) {
+		opt.failFast = true
+	}
+}
+
+// WithTimeLayout is a build option that specifies the layout to parse time strings for time assertions such as Before.
+// The default layout is time.RFC3339.
+func WithTimeLayout(layout string) BuildOpt {
+	return func(opt *buildOpt) {
+		opt.timeLayout = layout
+	}
+}
+
+// WithNumericTolerance is a build option that treats two numbers as equal if the difference is within the tolerance.
+// It affects numeric comparisons such as Equal, Greater, and Less but only if either value is a float.
+// Integer comparisons remain exact.
+func WithNumericTolerance(tolerance float64) BuildOpt {
+	return func(opt *buildOpt) {
+		opt.numericTolerance = tolerance
+	}
+}
+
+// WithErrorContext is a build option that appends the parent value of the invalid value to each error message.
+// The parent value is rendered as YAML up to the given number of lines.
+func WithErrorContext(lines int) BuildOpt {
+	return func(opt *buildOpt) {
+		opt.errorContext = lines
+	}
+}
+
+// WithMaxErrors is a build option that limits the number of errors reported by each Assert call to n.
+// The nested errors such as the ones of And are counted individually, and the omitted errors are reported as "+ N more errors".
+func WithMaxErrors(n int) BuildOpt {
+	return func(opt *buildOpt) {
+		opt.maxErrors = n
+	}
+}
+
+// WithParallel is a build option that evaluates the assertions of the paths by up to n goroutines.
+// The errors are reported in the same order as the serial evaluation.
+// It is disabled by WithFailFast, and the custom equalers must be safe for concurrent use.
+func WithParallel(n int) BuildOpt {
+	return func(opt *buildOpt) {
+		opt.parallel = n
+	}
+}
+
+// WithClock is a build option that supplies the current time of the time functions such as now() in templates.
+// The default is the clock of the template data if it implements template.Clock, or the real clock.
+func WithClock(clock template.Clock) BuildOpt {
+	return func(opt *buildOpt) {
+		opt.clock = clock
+	}
+}
+
+// WithNaNEqual is a build option that makes Equal treat NaN as equal to NaN.
+// NaN doesn't equal NaN by default as IEEE 754.
+func WithNaNEqual() BuildOpt {
+	return func(opt *buildOpt) {
+		opt.nanEqual = true
+	}
+}
+
+// WithMissingAsNil is a build option that asserts nil instead of failing if the path of an expected value such as $.a.b.c doesn't exist in the actual value.
+// It makes the assertions such as Empty and Equal(nil) pass for the absent optional fields.
+// Missing paths are errors by default to avoid masking real bugs.
+func WithMissingAsNil() BuildOpt {
+	return func(opt *buildOpt) {
+		opt.missingAsNil = true
+	}
+}
+
+// WithCaseInsensitive is a build option that compares strings case-insensitively under Unicode case-folding.
+// It affects Equal, Contains, NotContains, HasPrefix, and HasSuffix but only if both values are strings.
+func WithCaseInsensitive() BuildOpt {
+	return func(opt *buildOpt) {
+		opt.caseInsensitive = true
+	}
+}
+
+// WithEnvAllowlist is a build option that restricts the environment variables templates can read by env.
+// Reading other variables results in an error.
+func WithEnvAllowlist(names []string) BuildOpt {
+	return func(opt *buildOpt) {
+		opt.envAllowlist = append([]string{}, names...)
+	}
+}
+
 // Build builds an assertion from Go value.
 // If the Assert method of built assertion isn't called, the context value should be canceled to avoid a goroutine leak.
+// The built assertion also observes the context. When the context is done, Assert aborts the outstanding comparisons and returns an error wrapping the context error.
+// Only the custom equalers which implement ContextEqualer receive the context and stop the comparisons early,
+// including the ones passed to Equal in the expected value.
+// The other equalers keep running in a goroutine after Assert is aborted until they return, so it leaks while such an equaler blocks.
 func Build(ctx context.Context, expect any, fs ...BuildOpt) (Assertion, error) {
-	var opt buildOpt
+	opt := buildOpt{ctx: ctx, fs: fs}
 	for _, f := range fs {
 		f(&opt)
 	}
+	if opt.clock != nil {
+		opt.tmplData = template.WithClock(opt.tmplData, opt.clock)
+	}
+	if opt.ignorePathsErr != nil {
+		return nil, fmt.Errorf("failed to build assertion: %w", opt.ignorePathsErr)
+	}
+	opt.eqs = withContext(ctx, opt.eqs)
+	for t, eq := range opt.typeEqs {
+		opt.typeEqs[t] = withContext(ctx, []Equaler{eq})[0]
+	}
 	var assertions []Assertion
 	if expect != nil {
 		var err error
-		assertions, err = build(ctx, query.New(
-			query.ExtractByStructTag("yaml", "json"),
-			query.CustomExtractFunc(yamlextractor.MapSliceExtractFunc(false)),
-		), expect, &opt)
+		assertions, err = build(ctx, newQuery(), expect, &opt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to build assertion: %w", err)
 		}
 	}
-	return AssertionFunc(func(v interface{}) error {
-		errs := []error{}
-		for _, assertion := range assertions {
-			assertion := assertion
-			if err := assertion.Assert(v); err != nil {
-				errs = append(errs, err)
+	assert := func(v interface{}) error {
+		var errs []error
+		if opt.parallel > 1 && !opt.failFast && len(assertions) > 1 {
+			errs = assertParallel(assertions, v, opt.parallel)
+		} else {
+			for _, assertion := range assertions {
+				assertion := assertion
+				if err := assertion.Assert(v); err != nil {
+					errs = append(errs, err)
+					if opt.failFast {
+						break
+					}
+				}
 			}
 		}
+		if opt.maxErrors > 0 {
+			errs = limitErrors(errs, opt.maxErrors)
+		}
 		if len(errs) > 0 {
 			if len(errs) == 1 {
 				return errs[0]
@@ -82,9 +289,117 @@ func Build(ctx context.Context, expect any, fs ...BuildOpt) (Assertion, error) {
 			return errors.Errors(errs...)
 		}
 		return nil
+	}
+	wrap := func(f func(interface{}) error) Assertion { return AssertionFunc(f) }
+	if len(assertions) == 1 {
+		if _, ok := assertions[0].(ListAssertion); ok {
+			wrap = func(f func(interface{}) error) Assertion { return listAssertionFunc(f) }
+		}
+	}
+	if ctx.Done() == nil {
+		return wrap(assert), nil
+	}
+	return wrap(func(v interface{}) error {
+		if err := ctx.Err(); err != nil {
+			return &AbortedError{err: err}
+		}
+		done := make(chan error, 1)
+		go func() {
+			// a panic in the goroutine can't be recovered by the caller
+			defer func() {
+				if err := recover(); err != nil {
+					done <- fmt.Errorf("assertion panicked: %v", err)
+				}
+			}()
+			done <- assert(v)
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return &AbortedError{err: ctx.Err()}
+		}
 	}), nil
 }
 
+// AbortedError is the error returned by Assert when the context passed to Build is done.
+// It is distinct from the assertion failures, and it wraps the context error such as context.DeadlineExceeded.
+// The outstanding comparisons of the equalers which don't implement ContextEqualer may still be running when it is returned.
+type AbortedError struct {
+	err error
+}
+
+// Error implements error interface.
+func (e *AbortedError) Error() string {
+	return fmt.Sprintf("assertion aborted: %s", e.err)
+}
+
+// Unwrap returns the context error.
+func (e *AbortedError) Unwrap() error {
+	return e.err
+}
+
+// assertParallel evaluates the assertions by up to n goroutines and returns the errors in the order of the assertions.
+// The assertions are divided into contiguous chunks to reduce the overhead of the goroutines.
+func assertParallel(assertions []Assertion, v interface{}, n int) []error {
+	if n > len(assertions) {
+		n = len(assertions)
+	}
+	if n < 1 {
+		return nil
+	}
+	results := make([]error, len(assertions))
+	size := (len(assertions) + n - 1) / n
+	var wg sync.WaitGroup
+	for start := 0; start < len(assertions); start += size {
+		end := start + size
+		if end > len(assertions) {
+			end = len(assertions)
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				results[i] = assertions[i].Assert(v)
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	var errs []error
+	for _, err := range results {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// limitErrors returns the first n errors of errs and the error which reports the number of the omitted errors.
+// It returns errs as it is if the number of errors doesn't exceed n.
+func limitErrors(errs []error, n int) []error {
+	flattened := flattenErrors(errs)
+	if len(flattened) <= n {
+		return errs
+	}
+	return append(flattened[:n:n], errors.Errorf("+ %d more errors", len(flattened)-n))
+}
+
+// flattenErrors expands the multiple errors in errs.
+func flattenErrors(errs []error) []error {
+	var flattened []error
+	for _, err := range errs {
+		switch e := err.(type) { //nolint:errorlint
+		case *errors.MultiPathError:
+			flattened = append(flattened, flattenErrors(e.Errs)...)
+		case *Error:
+			flattened = append(flattened, flattenErrors(e.Errors)...)
+		default:
+			flattened = append(flattened, err)
+		}
+	}
+	return flattened
+}
+
 // MustBuild builds an assertion from Go value.
 // If the Assert method of built assertion isn't called, the context value should be canceled to avoid a goroutine leak.
 // If it fails to build, creates an assertion function that returns the build error.
@@ -98,10 +413,27 @@ func MustBuild(ctx context.Context, expect any, fs ...BuildOpt) Assertion {
 	return assertion
 }
 
+func newQuery() *query.Query {
+	return query.New(
+		query.ExtractByStructTag("yaml", "json"),
+		query.CustomExtractFunc(yamlextractor.MapSliceExtractFunc(false)),
+	)
+}
+
 func build(ctx context.Context, q *query.Query, expect any, opt *buildOpt) ([]Assertion, error) {
+	if opt.ignored(q) {
+		return nil, nil
+	}
 	var assertions []Assertion
 	switch v := expect.(type) {
 	case yaml.MapSlice:
+		if key, ok := leftArrowFuncKey(v); ok {
+			// the map is a left arrow function call such as {{f <-}}: {...}
+			if name, ok := findUnknownMatcher(key, opt.tmplData); ok {
+				return nil, errors.WithQuery(errors.Errorf("unknown matcher %q", name), q)
+			}
+			return buildAssertion(ctx, q, v, opt)
+		}
 		for _, item := range v {
 			item := item
 			k, err := template.Execute(item.Key, opt.tmplData)
@@ -125,24 +457,42 @@ func build(ctx context.Context, q *query.Query, expect any, opt *buildOpt) ([]As
 			assertions = append(assertions, as...)
 		}
 	default:
+		if a, ok := expect.(optionalAssertion); ok {
+			expect = a.withBuildOpt(opt)
+		}
 		switch v := expect.(type) {
 		case string:
 			return buildAssertion(ctx, q, v, opt)
+		case *invalidAssertion:
+			return nil, errors.WithQuery(v.err, q)
 		case Assertion:
-			assertions = append(assertions, AssertionFunc(func(val interface{}) error {
+			f := func(val interface{}) error {
 				got, err := q.Extract(val)
 				if err != nil {
-					return err
+					if !opt.missingAsNil {
+						return err
+					}
+					got = nil
 				}
-				if err := v.Assert(got); err != nil {
-					return errors.WithQuery(err, q)
+				if err := v.Assert(opt.coerce(got)); err != nil {
+					err = errors.WithQuery(err, q)
+					if opt.errorContext > 0 {
+						err = withErrorContext(err, q, val, opt.errorContext)
+					}
+					return err
 				}
 				return nil
-			}))
+			}
+			if _, ok := v.(ListAssertion); ok && q.String() == "" {
+				// keep the marker to tell the value is a list assertion
+				assertions = append(assertions, listAssertionFunc(f))
+			} else {
+				assertions = append(assertions, AssertionFunc(f))
+			}
 		case func(*query.Query) Assertion:
 			assertions = append(assertions, v(q))
 		default:
-			as, err := build(ctx, q, Equal(v, opt.eqs...), opt)
+			as, err := build(ctx, q, Equal(v), opt)
 			if err != nil {
 				return nil, err
 			}
@@ -153,7 +503,12 @@ func build(ctx context.Context, q *query.Query, expect any, opt *buildOpt) ([]As
 }
 
 func buildAssertion(ctx context.Context, q *query.Query, expect any, opt *buildOpt) ([]Assertion, error) {
-	wc, done := executeTemplate(ctx, expect, opt.tmplData)
+	if s, ok := expect.(string); ok {
+		if name, ok := findUnknownMatcher(s, opt.tmplData); ok {
+			return nil, errors.WithQuery(errors.Errorf("unknown matcher %q", name), q)
+		}
+	}
+	wc, done := executeTemplate(ctx, expect, opt)
 
 	select {
 	case result := <-done:
@@ -161,27 +516,31 @@ func buildAssertion(ctx context.Context, q *query.Query, expect any, opt *buildO
 			return nil, result.err
 		}
 		if s, ok := result.v.(string); ok {
-			result.v = Equal(s, opt.eqs...)
+			result.v = Equal(s)
 		}
 		return build(ctx, q, result.v, opt)
 	case <-wc.blocked():
 		// Delay template evaluation because the actual value is required.
+		// Each call of Assert binds the value to $ by its own execution, so the assertion can be reused concurrently.
 		var once sync.Once
 		a := AssertionFunc(func(val interface{}) error {
-			var c *waitContext
+			var (
+				c *waitContext
+				d chan templateResult
+			)
 			once.Do(func() {
 				// already executing
-				c = wc
+				c, d = wc, done
 			})
 			if c == nil {
 				// re-execution is required from the second time onwards
-				c, done = executeTemplate(ctx, expect, opt.tmplData)
+				c, d = executeTemplate(ctx, expect, opt)
 			}
 
 			if err := c.set(val); err != nil {
 				return err
 			}
-			result := <-done
+			result := <-d
 			if result.err != nil {
 				return result.err
 			}
@@ -197,8 +556,12 @@ func buildAssertion(ctx context.Context, q *query.Query, expect any, opt *buildO
 	}
 }
 
-func executeTemplate(ctx context.Context, tmpl any, data any) (*waitContext, chan templateResult) {
-	wc := newWaitContext(ctx, data)
+func executeTemplate(ctx context.Context, tmpl any, opt *buildOpt) (*waitContext, chan templateResult) {
+	wc := newWaitContext(ctx, opt.tmplData)
+	wc.fs = opt.fs
+	if opt.envAllowlist != nil {
+		wc.env = template.NewEnv(opt.envAllowlist)
+	}
 	done := make(chan templateResult)
 	go func() {
 		v, err := template.Execute(tmpl, wc)
@@ -221,6 +584,9 @@ type waitContext struct {
 	ready              chan any
 	blocked            func() <-chan struct{}
 	setOnce            sync.Once
+	env                *template.Env // overrides env of the base data if not nil
+	ctx                context.Context
+	fs                 []BuildOpt // binds the matchers
 }
 
 func newWaitContext(ctx context.Context, base any) *waitContext {
@@ -240,6 +606,7 @@ func newWaitContext(ctx context.Context, base any) *waitContext {
 		}),
 		ready:   ready,
 		blocked: block.Done, //nolint:contextcheck
+		ctx:     ctx,
 	}
 }
 
@@ -255,18 +622,26 @@ func (c *waitContext) set(v any) error {
 	return errors.New("set an actual value twice")
 }
 
+// Now implements template.Clock interface to pass the clock of the base data to the time functions.
+func (c *waitContext) Now() time.Time {
+	if clock, ok := c.any.(template.Clock); ok {
+		return clock.Now()
+	}
+	return time.Now()
+}
+
 // ExtractByKey implements query.KeyExtractor interface.
 func (c *waitContext) ExtractByKey(key string) (any, bool) {
 	if key == "$" {
 		return c.extractActualValue()
 	}
-	k := query.New(
-		query.ExtractByStructTag("yaml", "json"),
-		query.CustomExtractFunc(yamlextractor.MapSliceExtractFunc(false)),
-	).Key(key)
+	if key == "env" && c.env != nil {
+		return c.env, true
+	}
+	k := newQuery().Key(key)
 	res, err := k.Extract(c.any)
 	if err != nil {
-		return nil, false
+		return LookupMatcher(c.ctx, key, c.fs...)
 	}
 	return res, true
 }
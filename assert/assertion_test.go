@@ -2,14 +2,19 @@ package assert
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
 	"github.com/zoncoen/query-go"
 	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/template"
 )
 
 func TestBuild(t *testing.T) {
@@ -158,6 +163,38 @@ deps:
 			t.Errorf("unexpected error: %s", err)
 		}
 	})
+	t.Run("nested template data", func(t *testing.T) {
+		type user struct {
+			ID   int      `yaml:"id"`
+			Tags []string `yaml:"tags"`
+		}
+		data := map[string]any{
+			"vars": map[string]any{
+				"user": user{
+					ID:   1,
+					Tags: []string{"admin"},
+				},
+				"ids": []any{1, 2},
+			},
+		}
+		expect := yaml.MapSlice{
+			{Key: "id", Value: "{{vars.user.id}}"},
+			{Key: "tag", Value: "{{vars.user.tags[0]}}"},
+			{Key: "next", Value: "{{$ == vars.ids[1]}}"},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		assertion, err := Build(ctx, expect, FromTemplate(data))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if err := assertion.Assert(map[string]any{"id": 1, "tag": "admin", "next": 2}); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if err := assertion.Assert(map[string]any{"id": 2, "tag": "admin", "next": 2}); err == nil {
+			t.Error("no error")
+		}
+	})
 	t.Run("use $", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -175,6 +212,30 @@ deps:
 			t.Errorf("expect %q but got %q", expect, got)
 		}
 	})
+	t.Run("use $ concurrently", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		assertion, err := Build(ctx, `{{$ % 2 == 0}}`)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := assertion.Assert(i)
+				if i%2 == 0 && err != nil {
+					t.Errorf("%d: unexpected error: %s", i, err)
+				}
+				if i%2 != 0 && err == nil {
+					t.Errorf("%d: no error", i)
+				}
+			}()
+		}
+		wg.Wait()
+	})
 	t.Run("use $ twice", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -186,6 +247,28 @@ deps:
 			t.Errorf("unexpected error: %s", err)
 		}
 	})
+	t.Run("use $ with fromJSON", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		expect := yaml.MapSlice{
+			{Key: "payload", Value: `{{fromJSON($).user.id == 1}}`},
+		}
+		assertion, err := Build(ctx, expect)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if err := assertion.Assert(map[string]any{"payload": `{"user": {"id": 1}}`}); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if err := assertion.Assert(map[string]any{"payload": `{"user": {"id": 2}}`}); err == nil {
+			t.Error("no error")
+		}
+		if err := assertion.Assert(map[string]any{"payload": `{"user":`}); err == nil {
+			t.Error("no error")
+		} else if got, expect := err.Error(), "fromJSON: failed to decode"; !strings.Contains(got, expect) {
+			t.Errorf("expect %q to contain %q", got, expect)
+		}
+	})
 	t.Run("assertion result is not boolean", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -254,3 +337,795 @@ func extract(t *testing.T, s string, target any) any {
 	}
 	return v
 }
+
+func TestWithNumericTolerance(t *testing.T) {
+	tests := map[string]struct {
+		expect any
+		ok     []any
+		ng     []any
+	}{
+		"equal (float)": {
+			expect: 0.3,
+			ok:     []any{0.1 + 0.2, 0.3 + 1e-10, json.Number("0.3000000001")},
+			ng:     []any{0.31},
+		},
+		"equal (int)": {
+			expect: 1,
+			ok:     []any{1, 1.0000000001},
+			ng:     []any{2},
+		},
+		"greater": {
+			expect: Greater(1.0),
+			ok:     []any{1.1},
+			ng:     []any{1 + 1e-10, 1},
+		},
+		"greater or equal": {
+			expect: GreaterOrEqual(1.0),
+			ok:     []any{1 - 1e-10, 1},
+			ng:     []any{0.9},
+		},
+		"less": {
+			expect: Less(1.0),
+			ok:     []any{0.9},
+			ng:     []any{1 - 1e-10},
+		},
+		"between": {
+			expect: Between(0.0, 1.0),
+			ok:     []any{1 + 1e-10, -1e-10},
+			ng:     []any{1.1},
+		},
+		"nested": {
+			expect: yaml.MapSlice{
+				{Key: "values", Value: []any{0.3, And(LessOrEqual(1.0))}},
+			},
+			ok: []any{map[string]any{"values": []any{0.1 + 0.2, 1 + 1e-10}}},
+			ng: []any{map[string]any{"values": []any{0.3, 1.1}}},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := Build(context.Background(), test.expect, WithNumericTolerance(1e-9))
+			if err != nil {
+				t.Fatalf("failed to build: %s", err)
+			}
+			for _, v := range test.ok {
+				if err := assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range test.ng {
+				if err := assertion.Assert(v); err == nil {
+					t.Errorf("%v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("integers remain exact", func(t *testing.T) {
+		assertion, err := Build(context.Background(), 100, WithNumericTolerance(10))
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		if err := assertion.Assert(101); err == nil {
+			t.Error("expected error but no error")
+		}
+		if err := assertion.Assert(100.5); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+}
+
+func TestWithEnvAllowlist(t *testing.T) {
+	t.Setenv("SCENARIGO_TEST_ALLOWED", "foo")
+	t.Setenv("SCENARIGO_TEST_DENIED", "bar")
+	tests := map[string]struct {
+		expect      any
+		opts        []BuildOpt
+		expectError string
+	}{
+		"no allowlist": {
+			expect: `{{env("SCENARIGO_TEST_DENIED")}}`,
+		},
+		"allowed": {
+			expect: `{{env("SCENARIGO_TEST_ALLOWED")}}`,
+			opts:   []BuildOpt{WithEnvAllowlist([]string{"SCENARIGO_TEST_ALLOWED"})},
+		},
+		"denied": {
+			expect:      `{{env("SCENARIGO_TEST_DENIED")}}`,
+			opts:        []BuildOpt{WithEnvAllowlist([]string{"SCENARIGO_TEST_ALLOWED"})},
+			expectError: `env: environment variable "SCENARIGO_TEST_DENIED" is not allowed to read`,
+		},
+		"denied (key)": {
+			expect:      `{{env.SCENARIGO_TEST_DENIED}}`,
+			opts:        []BuildOpt{WithEnvAllowlist([]string{"SCENARIGO_TEST_ALLOWED"})},
+			expectError: `".env.SCENARIGO_TEST_DENIED" not found`,
+		},
+		"overrides env of the template data": {
+			expect: `{{env("SCENARIGO_TEST_DENIED")}}`,
+			opts: []BuildOpt{
+				FromTemplate(map[string]any{"env": template.NewEnv(nil)}),
+				WithEnvAllowlist([]string{"SCENARIGO_TEST_ALLOWED"}),
+			},
+			expectError: `env: environment variable "SCENARIGO_TEST_DENIED" is not allowed to read`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			_, err := Build(context.Background(), test.expect, test.opts...)
+			if test.expectError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if got := err.Error(); !strings.Contains(got, test.expectError) {
+				t.Errorf("expected %q to contain %q", got, test.expectError)
+			}
+		})
+	}
+}
+
+func TestWithMissingAsNil(t *testing.T) {
+	actual := map[string]any{
+		"a": map[string]any{
+			"b": nil,
+		},
+		"items": []any{},
+	}
+	tests := map[string]struct {
+		expect      any
+		opts        []BuildOpt
+		expectError string
+	}{
+		"strict by default": {
+			expect: yaml.MapSlice{
+				{Key: "a", Value: yaml.MapSlice{
+					{Key: "b", Value: yaml.MapSlice{{Key: "c", Value: nil}}},
+				}},
+			},
+			expectError: `".a.b.c" not found`,
+		},
+		"missing intermediate": {
+			expect: yaml.MapSlice{
+				{Key: "a", Value: yaml.MapSlice{
+					{Key: "b", Value: yaml.MapSlice{{Key: "c", Value: Empty()}}},
+				}},
+				{Key: "x", Value: yaml.MapSlice{{Key: "y", Value: nil}}},
+			},
+			opts: []BuildOpt{WithMissingAsNil()},
+		},
+		"missing index": {
+			expect: yaml.MapSlice{
+				{Key: "items", Value: []any{`{{default("none", $) == "none"}}`}},
+			},
+			opts: []BuildOpt{WithMissingAsNil()},
+		},
+		"missing value is nil": {
+			expect: yaml.MapSlice{
+				{Key: "x", Value: yaml.MapSlice{{Key: "y", Value: NotEmpty()}}},
+			},
+			opts:        []BuildOpt{WithMissingAsNil()},
+			expectError: ".x.y: expected not empty but got <nil>",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := Build(context.Background(), test.expect, test.opts...)
+			if err != nil {
+				t.Fatalf("failed to build: %s", err)
+			}
+			err = assertion.Assert(actual)
+			if test.expectError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if got := err.Error(); !strings.Contains(got, test.expectError) {
+				t.Errorf("expected %q to contain %q", got, test.expectError)
+			}
+		})
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	clock := template.ClockFunc(func() time.Time {
+		return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	})
+	tests := map[string]struct {
+		expect any
+		opts   []BuildOpt
+		ok     any
+		ng     any
+	}{
+		"now": {
+			expect: "{{now()}}",
+			opts:   []BuildOpt{WithClock(clock)},
+			ok:     "2024-01-02T03:04:05Z",
+			ng:     "2024-01-02T03:04:06Z",
+		},
+		"now with $": {
+			expect: `{{$ == addDuration(now(), "1h")}}`,
+			opts:   []BuildOpt{WithClock(clock)},
+			ok:     "2024-01-02T04:04:05Z",
+			ng:     "2024-01-02T03:04:05Z",
+		},
+		"with template data": {
+			expect: yaml.MapSlice{{Key: "at", Value: "{{now()}}"}, {Key: "name", Value: "{{vars.name}}"}},
+			opts: []BuildOpt{
+				FromTemplate(map[string]any{"vars": map[string]any{"name": "foo"}}),
+				WithClock(clock),
+			},
+			ok: map[string]any{"at": "2024-01-02T03:04:05Z", "name": "foo"},
+			ng: map[string]any{"at": "2024-01-02T03:04:05Z", "name": "bar"},
+		},
+		"clock of template data": {
+			expect: `{{$ == now()}}`,
+			opts:   []BuildOpt{FromTemplate(template.WithClock(nil, clock))},
+			ok:     "2024-01-02T03:04:05Z",
+			ng:     "2024-01-02T03:04:06Z",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := Build(context.Background(), test.expect, test.opts...)
+			if err != nil {
+				t.Fatalf("failed to build: %s", err)
+			}
+			if err := assertion.Assert(test.ok); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if err := assertion.Assert(test.ng); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestWithCaseInsensitive(t *testing.T) {
+	type myString string
+	tests := map[string]struct {
+		expect any
+		ok     []any
+		ng     []any
+	}{
+		"equal": {
+			expect: "Straße",
+			ok:     []any{"straße", "STRAßE", myString("sTRAßE")},
+			ng:     []any{"strasse", "street", []byte("straße")},
+		},
+		"equal (unicode)": {
+			expect: "ΣΑΣ",
+			ok:     []any{"σας", "ΣΑς"},
+			ng:     []any{"sas"},
+		},
+		"equal (number)": {
+			expect: 1,
+			ok:     []any{1},
+			ng:     []any{"1", 2},
+		},
+		"equal (struct)": {
+			expect: struct{ S string }{S: "A"},
+			ok:     []any{struct{ S string }{S: "A"}},
+			ng:     []any{struct{ S string }{S: "a"}},
+		},
+		"contains (substring)": {
+			expect: Contains("WORLD"),
+			ok:     []any{"hello, world", "Hello, World!"},
+			ng:     []any{"hello"},
+		},
+		"contains (element)": {
+			expect: Contains("B"),
+			ok:     []any{[]any{"a", "b"}, map[string]any{"b": 1}},
+			ng:     []any{[]any{"a", "c"}},
+		},
+		"not contains": {
+			expect: NotContains("B"),
+			ok:     []any{[]any{"a", "c"}, "ac"},
+			ng:     []any{[]any{"a", "b"}, "abc"},
+		},
+		"has prefix": {
+			expect: HasPrefix("HTTP"),
+			ok:     []any{"http://example.com", "Https"},
+			ng:     []any{"ftp://example.com", "htt"},
+		},
+		"has suffix": {
+			expect: HasSuffix(".JSON"),
+			ok:     []any{"a.json", "b.Json"},
+			ng:     []any{"a.yaml", "json"},
+		},
+		"nested": {
+			expect: yaml.MapSlice{
+				{Key: "name", Value: "Alice"},
+				{Key: "tags", Value: Not(Contains("ADMIN"))},
+			},
+			ok: []any{map[string]any{"name": "ALICE", "tags": []any{"user"}}},
+			ng: []any{map[string]any{"name": "ALICE", "tags": []any{"admin"}}},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := Build(context.Background(), test.expect, WithCaseInsensitive())
+			if err != nil {
+				t.Fatalf("failed to build: %s", err)
+			}
+			for _, v := range test.ok {
+				if err := assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range test.ng {
+				if err := assertion.Assert(v); err == nil {
+					t.Errorf("%v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("case-sensitive by default", func(t *testing.T) {
+		assertion, err := Build(context.Background(), Contains("WORLD"))
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		if err := assertion.Assert("hello, world"); err == nil {
+			t.Error("expected error but no error")
+		}
+	})
+}
+
+func TestWithErrorContext(t *testing.T) {
+	expect := yaml.MapSlice{
+		{Key: "user", Value: yaml.MapSlice{
+			{Key: "name", Value: "foo"},
+		}},
+	}
+	v := map[string]any{
+		"user": yaml.MapSlice{
+			{Key: "id", Value: 1},
+			{Key: "name", Value: "bar"},
+			{Key: "email", Value: "bar@example.com"},
+		},
+	}
+	tests := map[string]struct {
+		opts   []BuildOpt
+		expect string
+	}{
+		"default": {
+			expect: ".user.name: expected foo but got bar",
+		},
+		"with context": {
+			opts: []BuildOpt{WithErrorContext(5)},
+			expect: `.user.name: expected foo but got bar
+    parent (.user):
+      id: 1
+      name: bar
+      email: bar@example.com
+`,
+		},
+		"truncated": {
+			opts: []BuildOpt{WithErrorContext(2)},
+			expect: `.user.name: expected foo but got bar
+    parent (.user):
+      id: 1
+      name: bar
+      ...
+`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := Build(context.Background(), expect, test.opts...)
+			if err != nil {
+				t.Fatalf("failed to build: %s", err)
+			}
+			err = assertion.Assert(v)
+			if err == nil {
+				t.Fatal("expected error but no error")
+			}
+			if got := err.Error(); got != test.expect {
+				t.Errorf("expect %q but got %q", test.expect, got)
+			}
+			var perr *errors.PathError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected errors.PathError: %s", err)
+			}
+			if got, expect := perr.Path, ".user.name"; got != expect {
+				t.Errorf("expect %q but got %q", expect, got)
+			}
+		})
+	}
+}
+
+func TestWithMaxErrors(t *testing.T) {
+	expect := yaml.MapSlice{
+		{Key: "a", Value: 1},
+		{Key: "b", Value: 2},
+		{Key: "items", Value: All(Greater(0))},
+	}
+	v := map[string]any{
+		"a":     0,
+		"b":     0,
+		"items": []int{0, 0, 0},
+	}
+	tests := map[string]struct {
+		opts   []BuildOpt
+		expect string
+	}{
+		"default": {
+			expect: `3 errors occurred: .a: expected 1 but got 0
+.b: expected 2 but got 0
+3 errors occurred: .items[0]: must be greater than 0
+.items[1]: must be greater than 0
+.items[2]: must be greater than 0`,
+		},
+		"limited": {
+			opts: []BuildOpt{WithMaxErrors(3)},
+			expect: `4 errors occurred: .a: expected 1 but got 0
+.b: expected 2 but got 0
+.items[0]: must be greater than 0
++ 2 more errors`,
+		},
+		"not exceeded": {
+			opts: []BuildOpt{WithMaxErrors(5)},
+			expect: `3 errors occurred: .a: expected 1 but got 0
+.b: expected 2 but got 0
+3 errors occurred: .items[0]: must be greater than 0
+.items[1]: must be greater than 0
+.items[2]: must be greater than 0`,
+		},
+		"one": {
+			opts: []BuildOpt{WithMaxErrors(1)},
+			expect: `2 errors occurred: .a: expected 1 but got 0
++ 4 more errors`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := Build(context.Background(), expect, test.opts...)
+			if err != nil {
+				t.Fatalf("failed to build: %s", err)
+			}
+			// the limit applies to each call
+			for i := 0; i < 2; i++ {
+				err = assertion.Assert(v)
+				if err == nil {
+					t.Fatal("expected error but no error")
+				}
+				if got := err.Error(); got != test.expect {
+					t.Errorf("expect %q but got %q", test.expect, got)
+				}
+			}
+		})
+	}
+}
+
+// nestedDocument returns the expected value and the actual value of a nested document which has width^depth leaves.
+// The leaves whose index is a multiple of 7 don't match.
+func nestedDocument(width, depth int) (yaml.MapSlice, map[string]any) {
+	var n int
+	var gen func(depth int) (yaml.MapSlice, map[string]any)
+	gen = func(depth int) (yaml.MapSlice, map[string]any) {
+		expect := yaml.MapSlice{}
+		v := map[string]any{}
+		for i := 0; i < width; i++ {
+			key := fmt.Sprintf("key%d", i)
+			if depth == 1 {
+				expect = append(expect, yaml.MapItem{Key: key, Value: n})
+				if n%7 == 0 {
+					v[key] = -1
+				} else {
+					v[key] = n
+				}
+				n++
+				continue
+			}
+			e, child := gen(depth - 1)
+			expect = append(expect, yaml.MapItem{Key: key, Value: e})
+			v[key] = child
+		}
+		return expect, v
+	}
+	return gen(depth)
+}
+
+func TestWithParallel(t *testing.T) {
+	expect, v := nestedDocument(5, 3)
+	serial, err := Build(context.Background(), expect)
+	if err != nil {
+		t.Fatalf("failed to build: %s", err)
+	}
+	serialErr := serial.Assert(v)
+	if serialErr == nil {
+		t.Fatal("expected error but no error")
+	}
+	for _, n := range []int{2, 4, 1000} {
+		n := n
+		t.Run(fmt.Sprintf("parallel %d", n), func(t *testing.T) {
+			parallel, err := Build(context.Background(), expect, WithParallel(n))
+			if err != nil {
+				t.Fatalf("failed to build: %s", err)
+			}
+			err = parallel.Assert(v)
+			if err == nil {
+				t.Fatal("expected error but no error")
+			}
+			if diff := cmp.Diff(serialErr.Error(), err.Error()); diff != "" {
+				t.Errorf("differs from the serial evaluation (-want +got):\n%s", diff)
+			}
+		})
+	}
+	t.Run("no assertions", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		for name, test := range map[string]struct {
+			expect interface{}
+			v      interface{}
+		}{
+			"nil":       {expect: nil, v: 1},
+			"empty map": {expect: map[string]interface{}{}, v: map[string]interface{}{}},
+			"one value": {expect: 1, v: 1},
+		} {
+			assertion, err := Build(ctx, test.expect, WithParallel(4))
+			if err != nil {
+				t.Fatalf("%s: failed to build: %s", name, err)
+			}
+			if err := assertion.Assert(test.v); err != nil {
+				t.Errorf("%s: unexpected error: %s", name, err)
+			}
+		}
+	})
+	t.Run("fail fast", func(t *testing.T) {
+		assertion, err := Build(context.Background(), expect, WithParallel(4), WithFailFast())
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		if got, expect := assertion.Assert(v).Error(), ".key0.key0.key0: expected 0 but got -1"; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+}
+
+func BenchmarkAssert(b *testing.B) {
+	expect, v := nestedDocument(10, 4)
+	for name, opts := range map[string][]BuildOpt{
+		"serial":   nil,
+		"parallel": {WithParallel(runtime.NumCPU())},
+	} {
+		opts := opts
+		b.Run(name, func(b *testing.B) {
+			assertion, err := Build(context.Background(), expect, opts...)
+			if err != nil {
+				b.Fatalf("failed to build: %s", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = assertion.Assert(v)
+			}
+		})
+	}
+}
+
+type contextEqualer struct {
+	ctx chan context.Context
+}
+
+func (eq *contextEqualer) Equal(expected, got interface{}) (bool, error) {
+	return false, errors.New("Equal must not be called")
+}
+
+func (eq *contextEqualer) EqualContext(ctx context.Context, expected, got interface{}) (bool, error) {
+	eq.ctx <- ctx
+	<-ctx.Done() // a long comparison which stops by the context
+	return false, ctx.Err()
+}
+
+func TestBuild_Context(t *testing.T) {
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		unblock := make(chan struct{})
+		defer close(unblock)
+		blocking := EqualerFunc(func(expected, got interface{}) (bool, error) {
+			<-unblock // doesn't observe the context
+			return true, nil
+		})
+		assertion, err := Build(ctx, map[string]any{"foo": 1}, WithEqualers(blocking))
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		start := time.Now()
+		err = assertion.Assert(map[string]any{"foo": 2})
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("too slow: %s", elapsed)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expect context.DeadlineExceeded but got %s", err)
+		}
+		var aerr *AbortedError
+		if !errors.As(err, &aerr) {
+			t.Errorf("expect AbortedError but got %T", err)
+		}
+		if expect := "assertion aborted: context deadline exceeded"; err.Error() != expect {
+			t.Errorf("expect %q but got %q", expect, err.Error())
+		}
+	})
+	t.Run("already canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		assertion, err := Build(ctx, 1)
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		cancel()
+		if err := assertion.Assert(1); !errors.Is(err, context.Canceled) {
+			t.Errorf("expect context.Canceled but got %v", err)
+		}
+	})
+	t.Run("assertion failure is not aborted error", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		assertion, err := Build(ctx, 1)
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		err = assertion.Assert(2)
+		if err == nil {
+			t.Fatal("no error")
+		}
+		var aerr *AbortedError
+		if errors.As(err, &aerr) {
+			t.Errorf("unexpected AbortedError: %s", err)
+		}
+	})
+	t.Run("context equaler", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		eq := &contextEqualer{ctx: make(chan context.Context, 1)}
+		assertion, err := Build(ctx, map[string]any{"foo": 1}, WithEqualers(eq))
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		if err := assertion.Assert(map[string]any{"foo": 2}); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expect context.DeadlineExceeded but got %v", err)
+		}
+		if got := <-eq.ctx; got != ctx {
+			t.Error("the equaler didn't receive the context")
+		}
+	})
+	t.Run("context equaler passed to Equal", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		eq := &contextEqualer{ctx: make(chan context.Context, 1)}
+		assertion, err := Build(ctx, yaml.MapSlice{{Key: "foo", Value: Equal(1, eq)}})
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		if err := assertion.Assert(map[string]any{"foo": 2}); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expect context.DeadlineExceeded but got %v", err)
+		}
+		if got := <-eq.ctx; got != ctx {
+			t.Error("the equaler didn't receive the context")
+		}
+	})
+	t.Run("panic", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		panicking := EqualerFunc(func(expected, got interface{}) (bool, error) {
+			panic("boom")
+		})
+		assertion, err := Build(ctx, map[string]any{"foo": 1}, WithEqualers(panicking))
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		err = assertion.Assert(map[string]any{"foo": 2})
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if expect := "assertion panicked: boom"; err.Error() != expect {
+			t.Errorf("expect %q but got %q", expect, err.Error())
+		}
+	})
+}
+
+func TestBuild_ListAssertion(t *testing.T) {
+	tests := map[string]struct {
+		expect any
+		list   bool
+	}{
+		"length": {
+			expect: "{{ length(2) }}",
+			list:   true,
+		},
+		"all": {
+			expect: All(Equal(1)),
+			list:   true,
+		},
+		"set equal": {
+			expect: SetEqual([]any{1, 2}),
+			list:   true,
+		},
+		"empty": {
+			expect: Empty(),
+			list:   true,
+		},
+		"not empty": {
+			expect: NotEmpty(),
+			list:   true,
+		},
+		"equal": {
+			expect: "{{ 1 }}",
+		},
+		"list": {
+			expect: []any{Length(1)},
+		},
+		"map": {
+			expect: map[string]any{"foo": Length(1)},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			for _, ctx := range []context.Context{context.Background(), ctx} {
+				assertion, err := Build(ctx, test.expect, FromTemplate(nil))
+				if err != nil {
+					t.Fatalf("failed to build: %s", err)
+				}
+				if _, ok := assertion.(ListAssertion); ok != test.list {
+					t.Errorf("expect %t but got %t", test.list, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestBuild_Diffs(t *testing.T) {
+	expect := yaml.MapSlice{
+		{Key: "id", Value: 1},
+		{Key: "count", Value: Greater(0)},
+		{Key: "tags", Value: []any{"a"}},
+	}
+	assertion, err := Build(context.Background(), expect)
+	if err != nil {
+		t.Fatalf("failed to build: %s", err)
+	}
+	err = assertion.Assert(map[string]any{
+		"id":    2,
+		"count": 0,
+		"tags":  []any{"b"},
+	})
+	var merr *errors.MultiPathError
+	if !errors.As(err, &merr) {
+		t.Fatalf("expected errors.MultiPathError: %s", err)
+	}
+	diffs := merr.Diffs()
+	expectDiffs := []errors.PathDiff{
+		{Path: ".id", Expected: 1, Actual: 2, Matcher: "Equal"},
+		{Path: ".count", Expected: 0, Actual: 0, Matcher: "Greater"},
+		{Path: ".tags[0]", Expected: "a", Actual: "b", Matcher: "Equal"},
+	}
+	if len(diffs) != len(expectDiffs) {
+		t.Fatalf("expect %d diffs but got %d", len(expectDiffs), len(diffs))
+	}
+	for i, d := range diffs {
+		if d != expectDiffs[i] {
+			t.Errorf("expect %#v but got %#v", expectDiffs[i], d)
+		}
+	}
+}
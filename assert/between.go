@@ -0,0 +1,60 @@
+package assert
+
+import (
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// Between returns an assertion to ensure a value is within the range [low, high].
+func Between(low, high interface{}) Assertion {
+	return optional(&betweenAssertion{
+		low:  low,
+		high: high,
+	})
+}
+
+// BetweenExclusive returns an assertion to ensure a value is within the range (low, high).
+func BetweenExclusive(low, high interface{}) Assertion {
+	return optional(&betweenAssertion{
+		low:       low,
+		high:      high,
+		exclusive: true,
+	})
+}
+
+type betweenAssertion struct {
+	low, high interface{}
+	exclusive bool
+	tolerance float64
+}
+
+// Assert implements Assertion interface.
+func (a *betweenAssertion) Assert(actual interface{}) error {
+	l, _, err := cmpNumber(actual, a.low, a.tolerance)
+	if err != nil {
+		return err
+	}
+	h, _, err := cmpNumber(actual, a.high, a.tolerance)
+	if err != nil {
+		return err
+	}
+	if a.exclusive {
+		if l > 0 && h < 0 {
+			return nil
+		}
+		return errors.NewDiffError("BetweenExclusive", []interface{}{a.low, a.high}, actual, errors.Errorf("expected %v to be in the range (%v, %v)", actual, a.low, a.high))
+	}
+	if l >= 0 && h <= 0 {
+		return nil
+	}
+	return errors.NewDiffError("Between", []interface{}{a.low, a.high}, actual, errors.Errorf("expected %v to be in the range [%v, %v]", actual, a.low, a.high))
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *betweenAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	return &betweenAssertion{
+		low:       a.low,
+		high:      a.high,
+		exclusive: a.exclusive,
+		tolerance: opt.numericTolerance,
+	}
+}
@@ -0,0 +1,79 @@
+package assert
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := map[string]struct {
+		low, high interface{}
+		ok        []interface{}
+		ng        []interface{}
+	}{
+		"int": {
+			low:  1,
+			high: 3,
+			ok:   []interface{}{1, 2, 3, int8(2), uint64(3), 2.5, json.Number("3")},
+			ng:   []interface{}{0, 4, 0.9, 3.1, json.Number("4")},
+		},
+		"float": {
+			low:  0.5,
+			high: 1.5,
+			ok:   []interface{}{0.5, 1, 1.5, json.Number("1.2")},
+			ng:   []interface{}{0, 2, 0.49, json.Number("1.6")},
+		},
+		"not number": {
+			low:  1,
+			high: 3,
+			ng:   []interface{}{"2", nil, true},
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assertion := Between(tc.low, tc.high)
+			for _, v := range tc.ok {
+				if err := assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range tc.ng {
+				if err := assertion.Assert(v); err == nil {
+					t.Errorf("%v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := Between(1, 3).Assert(4)
+		if err == nil {
+			t.Fatal("expected error but no error")
+		}
+		if got, expect := err.Error(), "expected 4 to be in the range [1, 3]"; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+}
+
+func TestBetweenExclusive(t *testing.T) {
+	assertion := BetweenExclusive(1, 3)
+	for _, v := range []interface{}{2, 1.1, json.Number("2.9")} {
+		if err := assertion.Assert(v); err != nil {
+			t.Errorf("%v: unexpected error: %s", v, err)
+		}
+	}
+	for _, v := range []interface{}{1, 3, 0, json.Number("3")} {
+		if err := assertion.Assert(v); err == nil {
+			t.Errorf("%v: expected error but no error", v)
+		}
+	}
+	err := assertion.Assert(3)
+	if err == nil {
+		t.Fatal("expected error but no error")
+	}
+	if got, expect := err.Error(), "expected 3 to be in the range (1, 3)"; got != expect {
+		t.Errorf("expect %q but got %q", expect, got)
+	}
+}
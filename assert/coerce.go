@@ -0,0 +1,23 @@
+package assert
+
+// WithCoercion is a build option that normalizes the actual values before the assertions.
+// The function is applied to the value of each leaf of the expected value, and the result is used only if it returns true.
+// If the option is given multiple times, the first function which returns true is used.
+func WithCoercion(fn func(any) (any, bool)) BuildOpt {
+	return func(opt *buildOpt) {
+		opt.coercions = append(opt.coercions, fn)
+	}
+}
+
+// coerce returns the value converted by the functions of WithCoercion.
+func (opt *buildOpt) coerce(v any) any {
+	if opt == nil {
+		return v
+	}
+	for _, fn := range opt.coercions {
+		if converted, ok := fn(v); ok {
+			return converted
+		}
+	}
+	return v
+}
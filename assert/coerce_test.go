@@ -0,0 +1,99 @@
+package assert
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestWithCoercion(t *testing.T) {
+	atoi := func(v any) (any, bool) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, false
+		}
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		return i, true
+	}
+	actual := map[string]interface{}{
+		"id":    "9007199254740993",
+		"count": "3",
+		"name":  "foo",
+		"items": []interface{}{"1", "2"},
+	}
+	tests := map[string]struct {
+		expect    interface{}
+		coercions []func(any) (any, bool)
+		err       string
+	}{
+		"numeric strings": {
+			expect: yaml.MapSlice{
+				{Key: "id", Value: int64(9007199254740993)},
+				{Key: "count", Value: Greater(2)},
+				{Key: "name", Value: "foo"},
+				{Key: "items", Value: []interface{}{1, LessOrEqual(2)}},
+			},
+			coercions: []func(any) (any, bool){atoi},
+		},
+		"deep equal": {
+			expect: DeepEqual(yaml.MapSlice{
+				{Key: "id", Value: int64(9007199254740993)},
+				{Key: "count", Value: 3},
+				{Key: "name", Value: "foo"},
+				{Key: "items", Value: []interface{}{1, 2}},
+			}),
+			coercions: []func(any) (any, bool){atoi},
+		},
+		"first hook which returns true": {
+			expect: yaml.MapSlice{
+				{Key: "count", Value: 3},
+				{Key: "name", Value: "FOO"},
+			},
+			coercions: []func(any) (any, bool){
+				atoi,
+				func(v any) (any, bool) {
+					s, ok := v.(string)
+					return strings.ToUpper(s), ok
+				},
+			},
+		},
+		"no coercion": {
+			expect: yaml.MapSlice{
+				{Key: "count", Value: Greater(2)},
+			},
+			err: ".count: failed to convert string to number",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			var opts []BuildOpt
+			for _, c := range test.coercions {
+				opts = append(opts, WithCoercion(c))
+			}
+			assertion, err := Build(context.Background(), test.expect, opts...)
+			if err != nil {
+				t.Fatalf("failed to build: %s", err)
+			}
+			err = assertion.Assert(actual)
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%q not found in %q", test.err, err)
+			}
+		})
+	}
+}
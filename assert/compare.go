@@ -2,6 +2,7 @@ package assert
 
 import (
 	"encoding/json"
+	"math"
 	"math/big"
 	"reflect"
 
@@ -17,44 +18,102 @@ const (
 	compareLessOrEqual
 )
 
-// compareNumber compares expected with actual based on compareType.
-// If the comparison fails, an error will be returned.
-func compareNumber(expected, actual interface{}, typ compareType) error {
-	if !reflect.ValueOf(expected).IsValid() {
-		return errors.Errorf("expected value %v is invalid", expected)
-	}
-	if !reflect.ValueOf(actual).IsValid() {
-		return errors.Errorf("actual value %v is invalid", actual)
+func (t compareType) String() string {
+	switch t {
+	case compareGreater:
+		return "Greater"
+	case compareGreaterOrEqual:
+		return "GreaterOrEqual"
+	case compareLess:
+		return "Less"
+	case compareLessOrEqual:
+		return "LessOrEqual"
+	default:
+		return "unknown"
 	}
+}
+
+// errUnordered is returned by cmpNumber if either number is NaN because NaN is unordered by IEEE 754.
+var errUnordered = errors.New("NaN is unordered")
 
-	n1, err := toNumber(expected)
+type compareAssertion struct {
+	expected  interface{}
+	typ       compareType
+	tolerance float64
+}
+
+// Assert implements Assertion interface.
+func (a *compareAssertion) Assert(actual interface{}) error {
+	result, expValue, err := cmpNumber(actual, a.expected, a.tolerance)
 	if err != nil {
+		if errors.Is(err, errUnordered) {
+			return errors.NewDiffError(a.typ.String(), a.expected, actual, errors.Errorf("%v can't be compared with %v: %s", actual, a.expected, err))
+		}
 		return err
 	}
-	n2, err := toNumber(actual)
+	if err := compareByType(result, expValue, a.typ); err != nil {
+		return errors.NewDiffError(a.typ.String(), a.expected, actual, err)
+	}
+	return nil
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *compareAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	return &compareAssertion{
+		expected:  a.expected,
+		typ:       a.typ,
+		tolerance: opt.numericTolerance,
+	}
+}
+
+// cmpNumber compares two numbers and returns the result like (*big.Int).Cmp with the string representation of y.
+// If either number is a float and the difference is within the tolerance, they are treated as equal.
+// The infinities are ordered as IEEE 754, and it returns errUnordered if either number is NaN.
+func cmpNumber(x, y interface{}, tolerance float64) (int, string, error) {
+	if !reflect.ValueOf(x).IsValid() {
+		return 0, "", errors.Errorf("expected value %v is invalid", x)
+	}
+	if !reflect.ValueOf(y).IsValid() {
+		return 0, "", errors.Errorf("actual value %v is invalid", y)
+	}
+
+	n1, err := toNumber(x)
 	if err != nil {
-		return err
+		return 0, "", err
+	}
+	n2, err := toNumber(y)
+	if err != nil {
+		return 0, "", err
+	}
+	if isNaN(n1) || isNaN(n2) {
+		return 0, "", errUnordered
 	}
 	if isKindOfInt(n1) && isKindOfInt(n2) {
 		i1, err := convertToBigInt(n1)
 		if err != nil {
-			return err
+			return 0, "", err
 		}
 		i2, err := convertToBigInt(n2)
 		if err != nil {
-			return err
+			return 0, "", err
 		}
-		return compareByType(i1.Cmp(i2), i2.String(), typ)
+		return i1.Cmp(i2), i2.String(), nil
 	}
 	f1, err := convertToBigFloat(n1)
 	if err != nil {
-		return err
+		return 0, "", err
 	}
 	f2, err := convertToBigFloat(n2)
 	if err != nil {
-		return err
+		return 0, "", err
+	}
+	if tolerance > 0 && !f1.IsInf() && !f2.IsInf() {
+		diff := new(big.Float).Sub(f1, f2)
+		if diff.Abs(diff).Cmp(big.NewFloat(tolerance)) <= 0 {
+			return 0, f2.String(), nil
+		}
 	}
-	return compareByType(f1.Cmp(f2), f2.String(), typ)
+	return f1.Cmp(f2), f2.String(), nil
 }
 
 func toNumber(v interface{}) (interface{}, error) {
@@ -73,6 +132,22 @@ func toNumber(v interface{}) (interface{}, error) {
 	return v, nil
 }
 
+// isFloatComparison reports whether x and y are numbers and at least one of them is a float.
+func isFloatComparison(x, y interface{}) bool {
+	if x == nil || y == nil {
+		return false
+	}
+	n1, err := toNumber(x)
+	if err != nil {
+		return false
+	}
+	n2, err := toNumber(y)
+	if err != nil {
+		return false
+	}
+	return isKindOfFloat(n1) || isKindOfFloat(n2)
+}
+
 func isKindOfInt(v interface{}) bool {
 	switch reflect.TypeOf(v).Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
@@ -93,6 +168,18 @@ func isKindOfFloat(v interface{}) bool {
 	}
 }
 
+// isNaN reports whether v is a NaN of float types or json.Number.
+func isNaN(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	n, err := toNumber(v)
+	if err != nil || !isKindOfFloat(n) {
+		return false
+	}
+	return math.IsNaN(reflect.ValueOf(n).Float())
+}
+
 func isKindOfNumber(v interface{}) bool {
 	return isKindOfInt(v) || isKindOfFloat(v)
 }
@@ -140,7 +227,7 @@ func convert[T any](v interface{}, t T) (T, error) {
 
 func convertToType(v interface{}, t reflect.Type) (interface{}, error) {
 	rv := reflect.ValueOf(v)
-	if !rv.IsValid() {
+	if !rv.IsValid() || t == nil {
 		return nil, errors.Errorf("value is invalid")
 	}
 	if rv.Type().ConvertibleTo(t) {
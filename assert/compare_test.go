@@ -0,0 +1,99 @@
+package assert
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNaNAndInf(t *testing.T) {
+	nan := math.NaN()
+	inf := math.Inf(1)
+	tests := map[string]struct {
+		assertion Assertion
+		opts      []BuildOpt
+		ok        []interface{}
+		ng        []interface{}
+	}{
+		"Equal NaN": {
+			assertion: Equal(nan),
+			ng:        []interface{}{nan, float32(nan), json.Number("NaN"), 0, inf},
+		},
+		"Equal NaN with WithNaNEqual": {
+			assertion: Equal(nan),
+			opts:      []BuildOpt{WithNaNEqual()},
+			ok:        []interface{}{nan, float32(nan), json.Number("NaN")},
+			ng:        []interface{}{0, inf, "NaN"},
+		},
+		"Equal NaN with tolerance": {
+			assertion: Equal(nan),
+			opts:      []BuildOpt{WithNumericTolerance(1)},
+			ng:        []interface{}{nan, 0},
+		},
+		"Equal Inf": {
+			assertion: Equal(inf),
+			opts:      []BuildOpt{WithNumericTolerance(1)},
+			ok:        []interface{}{inf, float32(inf), json.Number("+Inf")},
+			ng:        []interface{}{math.Inf(-1), math.MaxFloat64, nan},
+		},
+		"Greater": {
+			assertion: Greater(0),
+			ok:        []interface{}{inf},
+			ng:        []interface{}{math.Inf(-1), nan, json.Number("NaN")},
+		},
+		"Greater Inf": {
+			assertion: Greater(inf),
+			ng:        []interface{}{inf, math.MaxFloat64, nan},
+		},
+		"GreaterOrEqual Inf": {
+			assertion: GreaterOrEqual(inf),
+			ok:        []interface{}{inf},
+			ng:        []interface{}{math.MaxFloat64, nan},
+		},
+		"Less": {
+			assertion: Less(0),
+			ok:        []interface{}{math.Inf(-1)},
+			ng:        []interface{}{inf, nan},
+		},
+		"LessOrEqual NaN": {
+			assertion: LessOrEqual(nan),
+			ng:        []interface{}{nan, 0, inf},
+		},
+		"Between": {
+			assertion: Between(math.Inf(-1), inf),
+			ok:        []interface{}{0, inf, math.Inf(-1)},
+			ng:        []interface{}{nan},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := Build(context.Background(), test.assertion, test.opts...)
+			if err != nil {
+				t.Fatalf("failed to build: %s", err)
+			}
+			for _, v := range test.ok {
+				if err := assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range test.ng {
+				if err := assertion.Assert(v); err == nil {
+					t.Errorf("%v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := Greater(0).Assert(nan)
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if expect := "NaN can't be compared with 0: NaN is unordered"; !strings.Contains(err.Error(), expect) {
+			t.Errorf("%q not found in %q", expect, err)
+		}
+	})
+}
@@ -1,60 +1,258 @@
 package assert
 
 import (
+	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
 
 	"github.com/zoncoen/scenarigo/errors"
 	"github.com/zoncoen/scenarigo/internal/reflectutil"
 )
 
-// Contains returns an assertion to ensure a value contains the value.
-func Contains(assertion Assertion) Assertion {
-	return AssertionFunc(func(v interface{}) error {
-		vv, err := arrayOrSlice(v)
-		if err != nil {
-			return err
-		}
-		if err := contains(assertion, vv); err != nil {
-			return errors.Wrap(err, "doesn't contain expected value")
-		}
-		return nil
+// Contains returns an assertion to ensure a value contains the expected value.
+// If the value is a string, it checks the string contains the expected substring.
+// If the value is an array or a slice, it checks at least one element satisfies the expected value.
+// If the value is a map, it checks at least one key satisfies the expected value.
+func Contains(expected interface{}, customEqs ...Equaler) Assertion {
+	return optional(&containsAssertion{
+		expected: expected,
+		eqs:      customEqs,
 	})
 }
 
-// NotContains returns an assertion to ensure a value doesn't contain the value.
-func NotContains(assertion Assertion) Assertion {
-	return AssertionFunc(func(v interface{}) error {
-		vv, err := arrayOrSlice(v)
-		if err != nil {
-			return err
-		}
-		if err := contains(assertion, vv); err == nil {
-			return errors.Wrap(err, "contains the value")
-		}
-		return nil
+// NotContains returns an assertion to ensure a value doesn't contain the expected value.
+// It accepts the same kinds of values as Contains.
+func NotContains(expected interface{}, customEqs ...Equaler) Assertion {
+	return optional(&containsAssertion{
+		expected: expected,
+		eqs:      customEqs,
+		not:      true,
 	})
 }
 
-func arrayOrSlice(v interface{}) (reflect.Value, error) {
+type containsAssertion struct {
+	expected interface{}
+	eqs      []Equaler
+	not      bool
+	opt      *buildOpt
+}
+
+// Assert implements Assertion interface.
+func (a *containsAssertion) Assert(v interface{}) error {
+	pos, err := a.find(v)
+	if err != nil {
+		var nf *notFoundError
+		if errors.As(err, &nf) {
+			if a.not {
+				return nil
+			}
+			return nf.err
+		}
+		return err
+	}
+	if a.not {
+		return errors.Errorf("unexpectedly contains the value at %s", pos)
+	}
+	return nil
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *containsAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	return &containsAssertion{
+		expected: a.expected,
+		eqs:      a.eqs,
+		not:      a.not,
+		opt:      opt,
+	}
+}
+
+// elemAssertion returns the assertion to find the expected element or key.
+func (a *containsAssertion) elemAssertion() Assertion {
+	return elemAssertion(a.expected, a.eqs, a.opt)
+}
+
+// notFoundError represents the expected value is not found.
+type notFoundError struct {
+	err error
+}
+
+func (e *notFoundError) Error() string {
+	return e.err.Error()
+}
+
+// find finds the expected value from v and returns the position where the value is found.
+// It returns *notFoundError if v is a valid container but the value is not found.
+func (a *containsAssertion) find(v interface{}) (string, error) {
+	if m, ok := v.(yaml.MapSlice); ok {
+		pos, err := findMapSliceKey(a.elemAssertion(), m)
+		if err != nil {
+			return "", &notFoundError{errors.Wrap(err, "doesn't contain expected key")}
+		}
+		return pos, nil
+	}
 	vv := reflectutil.Elem(reflect.ValueOf(v))
+	if vv.Kind() == reflect.Slice && vv.Type().Elem().Kind() == reflect.Uint8 {
+		return a.findSubstring(string(vv.Bytes()))
+	}
 	switch vv.Kind() {
+	case reflect.String:
+		return a.findSubstring(vv.String())
 	case reflect.Array, reflect.Slice:
+		find := findElem
+		if _, ok := a.expected.(Assertion); ok {
+			find = findElemByAssertion
+		}
+		pos, err := find(a.elemAssertion(), vv)
+		if err != nil {
+			return "", &notFoundError{errors.Wrap(err, "doesn't contain expected value")}
+		}
+		return pos, nil
+	case reflect.Map:
+		pos, err := findKey(a.elemAssertion(), vv)
+		if err != nil {
+			return "", &notFoundError{errors.Wrap(err, "doesn't contain expected key")}
+		}
+		return pos, nil
+	default:
+		return "", errors.Errorf("expected a string, an array, or a map but got %T", v)
+	}
+}
+
+// findSubstring finds the expected string from s.
+// The expected value can be a string or a byte slice.
+func (a *containsAssertion) findSubstring(s string) (string, error) {
+	var sub string
+	switch e := a.expected.(type) {
+	case string:
+		sub = e
+	case []byte:
+		sub = string(e)
 	default:
-		return reflect.Value{}, errors.New("expected an array")
+		return "", errors.Errorf("expected value must be a string to check a substring but got %T", a.expected)
 	}
-	return vv, nil
+	i := indexString(s, sub, a.opt != nil && a.opt.caseInsensitive)
+	if i < 0 {
+		return "", &notFoundError{errors.Errorf("%q doesn't contain %q", s, sub)}
+	}
+	return fmt.Sprintf("index %d", i), nil
 }
 
-func contains(assertion Assertion, v reflect.Value) error {
+// elemAssertion returns the assertion to compare an element with the expected value by the build options, the same as the expected value of Build.
+// If the expected value is an assertion, the build options are applied to it.
+func elemAssertion(expected interface{}, customEqs []Equaler, opt *buildOpt) Assertion {
+	a, ok := expected.(Assertion)
+	if !ok {
+		a = &equalAssertion{
+			expected: expected,
+			eqs:      customEqs,
+		}
+	}
+	if oa, ok := a.(optionalAssertion); ok && opt != nil {
+		return oa.withBuildOpt(opt)
+	}
+	return a
+}
+
+func findElem(assertion Assertion, v reflect.Value) (string, error) {
 	if v.Len() == 0 {
-		return errors.New("empty")
+		return "", errors.New("empty")
 	}
 	var err error
 	for i := 0; i < v.Len(); i++ {
 		e := v.Index(i).Interface()
 		if err = assertion.Assert(e); err == nil {
-			return nil
+			return fmt.Sprintf("index %d", i), nil
+		}
+	}
+	return "", errors.Wrap(err, "last error")
+}
+
+// maxNearestMisses is the maximum number of the nearest misses reported by findElemByAssertion.
+const maxNearestMisses = 3
+
+// findElemByAssertion finds the element which satisfies the assertion like findElem.
+// If no element of multiple elements satisfies it, the error reports the number of the checked elements and the nearest misses, which are the elements with the fewest errors.
+func findElemByAssertion(assertion Assertion, v reflect.Value) (string, error) {
+	if v.Len() < 2 {
+		return findElem(assertion, v)
+	}
+	type miss struct {
+		index int
+		err   error
+		n     int
+	}
+	misses := make([]miss, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		err := assertion.Assert(v.Index(i).Interface())
+		if err == nil {
+			return fmt.Sprintf("index %d", i), nil
+		}
+		misses = append(misses, miss{index: i, err: err, n: numErrors(err)})
+	}
+	sort.SliceStable(misses, func(i, j int) bool {
+		return misses[i].n < misses[j].n
+	})
+	if len(misses) > maxNearestMisses {
+		misses = misses[:maxNearestMisses]
+	}
+	msgs := make([]string, len(misses))
+	for i, m := range misses {
+		msgs[i] = fmt.Sprintf("index %d: %s", m.index, m.err)
+	}
+	return "", errors.Errorf("checked %d elements but none satisfies the assertion, the nearest misses:\n%s", v.Len(), strings.Join(msgs, "\n"))
+}
+
+// numErrors returns the number of the errors which err consists of.
+func numErrors(err error) int {
+	var errs []error
+	var multiErr *errors.MultiPathError
+	var assertErr *Error
+	switch {
+	case errors.As(err, &multiErr):
+		errs = multiErr.Errs
+	case errors.As(err, &assertErr):
+		errs = assertErr.Errors
+	default:
+		return 1
+	}
+	n := 0
+	for _, err := range errs {
+		n += numErrors(err)
+	}
+	return n
+}
+
+func findKey(assertion Assertion, v reflect.Value) (string, error) {
+	if v.Len() == 0 {
+		return "", errors.New("empty")
+	}
+	// sort the keys to make the found key and the last error deterministic
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	var err error
+	for _, key := range keys {
+		k := key.Interface()
+		if err = assertion.Assert(k); err == nil {
+			return fmt.Sprintf("key %#v", k), nil
+		}
+	}
+	return "", errors.Wrap(err, "last error")
+}
+
+func findMapSliceKey(assertion Assertion, m yaml.MapSlice) (string, error) {
+	if len(m) == 0 {
+		return "", errors.New("empty")
+	}
+	var err error
+	for _, item := range m {
+		if err = assertion.Assert(item.Key); err == nil {
+			return fmt.Sprintf("key %#v", item.Key), nil
 		}
 	}
-	return errors.Wrap(err, "last error")
+	return "", errors.Wrap(err, "last error")
 }
@@ -1,13 +1,18 @@
 package assert
 
 import (
+	"context"
+	"strings"
 	"testing"
+
+	"github.com/goccy/go-yaml"
 )
 
 func TestContains(t *testing.T) {
 	tests := map[string]struct {
 		in          interface{}
-		contains    int
+		contains    interface{}
+		customEqs   []Equaler
 		expectError bool
 	}{
 		"not array or slice": {
@@ -29,11 +34,74 @@ func TestContains(t *testing.T) {
 			contains:    2,
 			expectError: true,
 		},
+		"contains (assertion)": {
+			in:       []int{0, 1},
+			contains: Greater(0),
+		},
+		"contains (custom equalers)": {
+			in:       []string{"a", "b"},
+			contains: "B",
+			customEqs: []Equaler{
+				EqualerFunc(func(expected, got interface{}) (bool, error) {
+					return true, Equal(strings.ToLower(expected.(string))).Assert(got)
+				}),
+			},
+		},
+		"string contains substring": {
+			in:       "hello world",
+			contains: "o w",
+		},
+		"string doesn't contain substring": {
+			in:          "hello world",
+			contains:    "foo",
+			expectError: true,
+		},
+		"bytes contain substring": {
+			in:       []byte("hello world"),
+			contains: "o w",
+		},
+		"bytes contain bytes": {
+			in:       []byte("hello world"),
+			contains: []byte("o w"),
+		},
+		"bytes don't contain substring": {
+			in:          []byte("hello world"),
+			contains:    "foo",
+			expectError: true,
+		},
+		"string with non-string expected value": {
+			in:          "1",
+			contains:    1,
+			expectError: true,
+		},
+		"map contains key": {
+			in:       map[string]int{"a": 0, "b": 1},
+			contains: "b",
+		},
+		"map doesn't contain key": {
+			in:          map[string]int{"a": 0, "b": 1},
+			contains:    "c",
+			expectError: true,
+		},
+		"empty map": {
+			in:          map[string]int{},
+			contains:    "a",
+			expectError: true,
+		},
+		"yaml.MapSlice contains key": {
+			in:       yaml.MapSlice{{Key: "a", Value: 0}, {Key: "b", Value: 1}},
+			contains: "b",
+		},
+		"yaml.MapSlice doesn't contain key": {
+			in:          yaml.MapSlice{{Key: "a", Value: 0}, {Key: "b", Value: 1}},
+			contains:    "c",
+			expectError: true,
+		},
 	}
 	for name, test := range tests {
 		test := test
 		t.Run(name, func(t *testing.T) {
-			assertion := Contains(Equal(test.contains))
+			assertion := Contains(test.contains, test.customEqs...)
 			err := assertion.Assert(test.in)
 			if !test.expectError && err != nil {
 				t.Fatalf("unexpected error: %s", err)
@@ -48,13 +116,13 @@ func TestContains(t *testing.T) {
 func TestNotContains(t *testing.T) {
 	tests := map[string]struct {
 		in          interface{}
-		notContains int
-		expectError bool
+		notContains interface{}
+		expectError string
 	}{
 		"not array or slice": {
 			in:          0,
 			notContains: 0,
-			expectError: true,
+			expectError: "expected a string, an array, or a map but got int",
 		},
 		"empty": {
 			in:          []int{},
@@ -63,24 +131,171 @@ func TestNotContains(t *testing.T) {
 		"contains": {
 			in:          []int{0, 1},
 			notContains: 1,
-			expectError: true,
+			expectError: "unexpectedly contains the value at index 1",
 		},
 		"not contains": {
 			in:          []int{0, 1},
 			notContains: 2,
 		},
+		"string contains substring": {
+			in:          "panic: runtime error",
+			notContains: "panic",
+			expectError: "unexpectedly contains the value at index 0",
+		},
+		"string doesn't contain substring": {
+			in:          "internal server error",
+			notContains: "panic",
+		},
+		"string with non-string expected value": {
+			in:          "1",
+			notContains: 1,
+			expectError: "expected value must be a string to check a substring but got int",
+		},
+		"map contains key": {
+			in:          map[string]int{"a": 0},
+			notContains: "a",
+			expectError: `unexpectedly contains the value at key "a"`,
+		},
+		"map doesn't contain key": {
+			in:          map[string]int{"a": 0},
+			notContains: "b",
+		},
+		"yaml.MapSlice contains key": {
+			in:          yaml.MapSlice{{Key: "a", Value: 0}},
+			notContains: "a",
+			expectError: `unexpectedly contains the value at key "a"`,
+		},
 	}
 	for name, test := range tests {
 		test := test
 		t.Run(name, func(t *testing.T) {
-			assertion := NotContains(Equal(test.notContains))
+			assertion := NotContains(test.notContains)
 			err := assertion.Assert(test.in)
-			if !test.expectError && err != nil {
+			if test.expectError == "" && err != nil {
 				t.Fatalf("unexpected error: %s", err)
 			}
-			if test.expectError && err == nil {
+			if test.expectError != "" {
+				if err == nil {
+					t.Fatal("expected error but got no error")
+				}
+				if got, expect := err.Error(), test.expectError; got != expect {
+					t.Fatalf("expect %q but got %q", expect, got)
+				}
+			}
+		})
+	}
+}
+
+func TestContains_Assertion(t *testing.T) {
+	items := []interface{}{
+		map[string]interface{}{"name": "basic", "price": 10},
+		map[string]interface{}{"name": "standard", "price": 120},
+		map[string]interface{}{"name": "pro", "price": 100},
+		map[string]interface{}{"name": "pro max", "price": 200},
+	}
+	tests := map[string]struct {
+		in          interface{}
+		assertion   Assertion
+		expectError string
+	}{
+		"found": {
+			in: items,
+			assertion: MustBuild(context.Background(), yaml.MapSlice{
+				{Key: "name", Value: Contains("pro")},
+				{Key: "price", Value: Greater(100)},
+			}),
+		},
+		"not found": {
+			in: items,
+			assertion: MustBuild(context.Background(), yaml.MapSlice{
+				{Key: "name", Value: Contains("pro")},
+				{Key: "price", Value: Greater(200)},
+			}),
+			expectError: `doesn't contain expected value: checked 4 elements but none satisfies the assertion, the nearest misses:
+index 2: .price: must be greater than 200
+index 3: .price: must be greater than 200
+index 0: 2 errors occurred: .name: "basic" doesn't contain "pro"
+.price: must be greater than 200`,
+		},
+		"single element": {
+			in:          items[:1],
+			assertion:   Greater(0),
+			expectError: "doesn't contain expected value: last error: ",
+		},
+		"map keys are checked in sorted order": {
+			in:          map[string]int{"c": 0, "a": 1, "b": 2},
+			assertion:   Equal("x"),
+			expectError: "doesn't contain expected key: last error: expected x but got c",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			err := Contains(test.assertion).Assert(test.in)
+			if test.expectError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
 				t.Fatal("expected error but got no error")
 			}
+			if got := err.Error(); !strings.HasPrefix(got, test.expectError) {
+				t.Fatalf("expect %q but got %q", test.expectError, got)
+			}
+		})
+	}
+}
+
+func TestContains_BuildOpt(t *testing.T) {
+	trimEq := EqualerFunc(func(expected, got interface{}) (bool, error) {
+		e, ok1 := expected.(string)
+		g, ok2 := got.(string)
+		if !ok1 || !ok2 {
+			return false, nil
+		}
+		return strings.TrimSpace(e) == strings.TrimSpace(g), nil
+	})
+	tests := map[string]struct {
+		expect interface{}
+		in     interface{}
+		opts   []BuildOpt
+	}{
+		"equalers (slice)": {
+			expect: Contains(" x "),
+			in:     []string{"a", "x"},
+			opts:   []BuildOpt{WithEqualers(trimEq)},
+		},
+		"equalers (map)": {
+			expect: Contains(" x "),
+			in:     map[string]int{"x": 1},
+			opts:   []BuildOpt{WithEqualers(trimEq)},
+		},
+		"case-insensitive nested assertion": {
+			expect: Contains(Equal("X")),
+			in:     []string{"x"},
+			opts:   []BuildOpt{WithCaseInsensitive()},
+		},
+		"numeric tolerance": {
+			expect: Contains(1.0),
+			in:     []float64{1.05},
+			opts:   []BuildOpt{WithNumericTolerance(0.1)},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := Build(context.Background(), test.expect, test.opts...)
+			if err != nil {
+				t.Fatalf("failed to build: %s", err)
+			}
+			if err := assertion.Assert(test.in); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if err := test.expect.(Assertion).Assert(test.in); err == nil {
+				t.Error("expected error without the build options but no error")
+			}
 		})
 	}
 }
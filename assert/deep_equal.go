@@ -0,0 +1,267 @@
+package assert
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/zoncoen/query-go"
+
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
+)
+
+// DeepEqual returns an assertion to ensure a value deeply equals the expected value.
+// Unlike the assertion built from a map or an array, the value must not have extra keys or elements.
+// The leaves are compared by Equal, or asserted if they are assertions, with the build options such as WithEqualers.
+// On mismatch, it returns MultiPathError which has the errors of the paths, and its message is a diff (-expected +actual) of the whole values.
+func DeepEqual(expected interface{}) Assertion {
+	return optional(&deepEqualAssertion{
+		expected: expected,
+	})
+}
+
+type deepEqualAssertion struct {
+	expected interface{}
+	opt      *buildOpt
+}
+
+// Assert implements Assertion interface.
+func (a *deepEqualAssertion) Assert(v interface{}) error {
+	d := &differ{opt: a.opt}
+	d.compare(newQuery(), 0, "", a.expected, v)
+	if len(d.errs) == 0 {
+		return nil
+	}
+	return &errors.MultiPathError{
+		Errs: d.errs,
+		Diff: strings.Join(d.lines, "\n"),
+	}
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *deepEqualAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	return &deepEqualAssertion{
+		expected: a.expected,
+		opt:      opt,
+	}
+}
+
+// differ compares two values recursively and records the errors and the lines of the diff.
+type differ struct {
+	opt   *buildOpt
+	errs  []error
+	lines []string
+}
+
+const (
+	diffSame     = "  "
+	diffRemoved  = "- "
+	diffAdded    = "+ "
+	diffIndentBy = 2
+)
+
+func (d *differ) compare(q *query.Query, indent int, head string, expected, actual interface{}) {
+	if d.opt.ignored(q) {
+		d.line(diffSame, indent, head, "<ignored>")
+		return
+	}
+	if ek, ev, ok := mapEntries(expected); ok {
+		if ak, av, ok := mapEntries(actual); ok {
+			d.compareMaps(q, indent, head, ek, ev, ak, av)
+			return
+		}
+	}
+	if es, ok := listElems(expected); ok {
+		if as, ok := listElems(actual); ok {
+			d.compareLists(q, indent, head, es, as)
+			return
+		}
+	}
+	actual = d.opt.coerce(actual)
+	err := d.leaf(expected).Assert(actual)
+	if err == nil {
+		d.render(diffSame, indent, head, actual)
+		return
+	}
+	d.errs = append(d.errs, errors.WithQuery(err, q))
+	if _, ok := expected.(Assertion); ok {
+		d.line(diffRemoved, indent, head, fmt.Sprintf("<%s>", err))
+	} else {
+		d.render(diffRemoved, indent, head, expected)
+	}
+	d.render(diffAdded, indent, head, actual)
+}
+
+func (d *differ) compareMaps(q *query.Query, indent int, head string, ek []string, ev map[string]interface{}, ak []string, av map[string]interface{}) {
+	indent = d.open(indent, head)
+	for _, k := range ek {
+		if actual, ok := av[k]; ok {
+			d.compare(q.Key(k), indent, k+":", ev[k], actual)
+			continue
+		}
+		if d.opt.ignored(q.Key(k)) {
+			d.line(diffSame, indent, k+":", "<ignored>")
+			continue
+		}
+		d.errs = append(d.errs, errors.ErrorQueryf(q.Key(k), "key not found"))
+		d.render(diffRemoved, indent, k+":", ev[k])
+	}
+	for _, k := range ak {
+		if _, ok := ev[k]; ok {
+			continue
+		}
+		if d.opt.ignored(q.Key(k)) {
+			d.line(diffSame, indent, k+":", "<ignored>")
+			continue
+		}
+		d.errs = append(d.errs, errors.ErrorQueryf(q.Key(k), "unexpected key"))
+		d.render(diffAdded, indent, k+":", av[k])
+	}
+}
+
+func (d *differ) compareLists(q *query.Query, indent int, head string, expected, actual []interface{}) {
+	indent = d.open(indent, head)
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		switch {
+		case d.opt.ignored(q.Index(i)):
+			d.line(diffSame, indent, "-", "<ignored>")
+		case i >= len(actual):
+			d.errs = append(d.errs, errors.ErrorQueryf(q.Index(i), "element not found"))
+			d.render(diffRemoved, indent, "-", expected[i])
+		case i >= len(expected):
+			d.errs = append(d.errs, errors.ErrorQueryf(q.Index(i), "unexpected element"))
+			d.render(diffAdded, indent, "-", actual[i])
+		default:
+			d.compare(q.Index(i), indent, "-", expected[i], actual[i])
+		}
+	}
+}
+
+// open writes the line of the head of a map or a list and returns the indent of the children.
+func (d *differ) open(indent int, head string) int {
+	if head == "" {
+		return indent
+	}
+	d.line(diffSame, indent, head, "")
+	return indent + diffIndentBy
+}
+
+// render writes the lines of v with the mark.
+func (d *differ) render(mark string, indent int, head string, v interface{}) {
+	if keys, m, ok := mapEntries(v); ok && len(keys) > 0 {
+		if head != "" {
+			d.line(mark, indent, head, "")
+			indent += diffIndentBy
+		}
+		for _, k := range keys {
+			d.render(mark, indent, k+":", m[k])
+		}
+		return
+	}
+	if elems, ok := listElems(v); ok && len(elems) > 0 {
+		if head != "" {
+			d.line(mark, indent, head, "")
+			indent += diffIndentBy
+		}
+		for _, elem := range elems {
+			d.render(mark, indent, "-", elem)
+		}
+		return
+	}
+	d.line(mark, indent, head, formatDiffValue(v))
+}
+
+func (d *differ) line(mark string, indent int, head, value string) {
+	s := head
+	if value != "" {
+		if s != "" {
+			s += " "
+		}
+		s += value
+	}
+	d.lines = append(d.lines, mark+strings.Repeat(" ", indent)+s)
+}
+
+// leaf returns the assertion to compare the leaf values with the build options.
+func (d *differ) leaf(expected interface{}) Assertion {
+	a, ok := expected.(Assertion)
+	if !ok {
+		a = Equal(expected)
+	}
+	if oa, ok := a.(optionalAssertion); ok && d.opt != nil {
+		a = oa.withBuildOpt(d.opt)
+	}
+	return a
+}
+
+// mapEntries returns the keys and the values of v if v is a map.
+// The keys of yaml.MapSlice keep the order, and the keys of a map are sorted.
+func mapEntries(v interface{}) ([]string, map[string]interface{}, bool) {
+	if ms, ok := v.(yaml.MapSlice); ok {
+		keys := make([]string, 0, len(ms))
+		m := make(map[string]interface{}, len(ms))
+		for _, item := range ms {
+			k := fmt.Sprint(item.Key)
+			keys = append(keys, k)
+			m[k] = item.Value
+		}
+		return keys, m, true
+	}
+	if _, ok := v.(Assertion); ok {
+		return nil, nil, false
+	}
+	vv := reflectutil.Elem(reflect.ValueOf(v))
+	if vv.Kind() != reflect.Map {
+		return nil, nil, false
+	}
+	keys := make([]string, 0, vv.Len())
+	m := make(map[string]interface{}, vv.Len())
+	iter := vv.MapRange()
+	for iter.Next() {
+		k := fmt.Sprint(iter.Key().Interface())
+		keys = append(keys, k)
+		m[k] = iter.Value().Interface()
+	}
+	sort.Strings(keys)
+	return keys, m, true
+}
+
+// listElems returns the elements of v if v is an array or a slice.
+func listElems(v interface{}) ([]interface{}, bool) {
+	if _, ok := v.(Assertion); ok {
+		return nil, false
+	}
+	vv := reflectutil.Elem(reflect.ValueOf(v))
+	switch vv.Kind() {
+	case reflect.Array, reflect.Slice:
+	default:
+		return nil, false
+	}
+	if _, ok := v.([]byte); ok {
+		return nil, false
+	}
+	elems := make([]interface{}, vv.Len())
+	for i := range elems {
+		elems[i] = vv.Index(i).Interface()
+	}
+	return elems, true
+}
+
+func formatDiffValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	if keys, _, ok := mapEntries(v); ok && len(keys) == 0 {
+		return "{}"
+	}
+	if elems, ok := listElems(v); ok && len(elems) == 0 {
+		return "[]"
+	}
+	return fmt.Sprintf("%v", v)
+}
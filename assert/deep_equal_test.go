@@ -0,0 +1,153 @@
+package assert
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+func TestDeepEqual(t *testing.T) {
+	tests := map[string]struct {
+		expected interface{}
+		ok       []interface{}
+		ng       []interface{}
+	}{
+		"scalar": {
+			expected: 1,
+			ok:       []interface{}{1, uint8(1)},
+			ng:       []interface{}{2, "1", nil},
+		},
+		"map": {
+			expected: yaml.MapSlice{
+				{Key: "id", Value: 1},
+				{Key: "tags", Value: []interface{}{"a", "b"}},
+			},
+			ok: []interface{}{
+				map[string]interface{}{"id": 1, "tags": []string{"a", "b"}},
+				yaml.MapSlice{{Key: "tags", Value: []interface{}{"a", "b"}}, {Key: "id", Value: 1}},
+			},
+			ng: []interface{}{
+				map[string]interface{}{"id": 1},
+				map[string]interface{}{"id": 1, "tags": []string{"a", "b"}, "extra": true},
+				map[string]interface{}{"id": 1, "tags": []string{"a"}},
+				map[string]interface{}{"id": 1, "tags": []string{"a", "b", "c"}},
+				[]interface{}{1},
+			},
+		},
+		"assertion": {
+			expected: map[string]interface{}{"id": Greater(0)},
+			ok:       []interface{}{map[string]interface{}{"id": 1}},
+			ng:       []interface{}{map[string]interface{}{"id": 0}},
+		},
+		"empty": {
+			expected: map[string]interface{}{},
+			ok:       []interface{}{map[string]interface{}{}, yaml.MapSlice{}},
+			ng:       []interface{}{map[string]interface{}{"id": 1}, []interface{}{}},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion := DeepEqual(test.expected)
+			for _, v := range test.ok {
+				if err := assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range test.ng {
+				if err := assertion.Assert(v); err == nil {
+					t.Errorf("%v: expected error but no error", v)
+				}
+			}
+		})
+	}
+}
+
+func TestDeepEqual_Diff(t *testing.T) {
+	assertion, err := Build(context.Background(), DeepEqual(yaml.MapSlice{
+		{Key: "id", Value: 1},
+		{Key: "name", Value: "alice"},
+		{Key: "age", Value: Greater(20)},
+		{Key: "tags", Value: []interface{}{"a", "b"}},
+		{Key: "profile", Value: yaml.MapSlice{
+			{Key: "email", Value: "alice@example.com"},
+		}},
+	}))
+	if err != nil {
+		t.Fatalf("failed to build: %s", err)
+	}
+	err = assertion.Assert(map[string]interface{}{
+		"id":   1,
+		"name": "bob",
+		"age":  20,
+		"tags": []interface{}{"a"},
+		"role": "admin",
+	})
+	if err == nil {
+		t.Fatal("no error")
+	}
+	expect := strings.Join([]string{
+		`values differ (-expected +actual):`,
+		`  id: 1`,
+		`- name: "alice"`,
+		`+ name: "bob"`,
+		`- age: <must be greater than 20>`,
+		`+ age: 20`,
+		`  tags:`,
+		`    - "a"`,
+		`-   - "b"`,
+		`- profile:`,
+		`-   email: "alice@example.com"`,
+		`+ role: "admin"`,
+	}, "\n")
+	if diff := cmp.Diff(expect, err.Error()); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+
+	var merr *errors.MultiPathError
+	if !errors.As(err, &merr) {
+		t.Fatalf("expected errors.MultiPathError: %T", err)
+	}
+	var msgs []string
+	for _, err := range merr.Errs {
+		msgs = append(msgs, err.Error())
+	}
+	expectMsgs := []string{
+		".name: expected alice but got bob",
+		".age: must be greater than 20",
+		".tags[1]: element not found",
+		".profile: key not found",
+		".role: unexpected key",
+	}
+	if diff := cmp.Diff(expectMsgs, msgs); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+	if got := len(merr.Diffs()); got != 2 {
+		t.Errorf("expect 2 diffs but got %d", got)
+	}
+}
+
+func TestDeepEqual_Equalers(t *testing.T) {
+	eq := EqualerFunc(func(expected, got interface{}) (bool, error) {
+		s, ok := expected.(string)
+		return ok && s == "any", nil
+	})
+	assertion, err := Build(context.Background(), DeepEqual(map[string]interface{}{
+		"id":   "any",
+		"name": "foo",
+	}), WithEqualers(eq))
+	if err != nil {
+		t.Fatalf("failed to build: %s", err)
+	}
+	if err := assertion.Assert(map[string]interface{}{"id": 1, "name": "foo"}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := assertion.Assert(map[string]interface{}{"id": 1, "name": "bar"}); err == nil {
+		t.Error("no error")
+	}
+}
@@ -0,0 +1,100 @@
+package assert
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// DurationLess returns an assertion to ensure a duration value is less than d.
+// The value can be a time.Duration, a duration string such as "1h30m", or a number of seconds.
+func DurationLess(d time.Duration) Assertion {
+	return durationAssertion(d, compareLess)
+}
+
+// DurationLessOrEqual returns an assertion to ensure a duration value is equal or less than d.
+// The value can be a time.Duration, a duration string such as "1h30m", or a number of seconds.
+func DurationLessOrEqual(d time.Duration) Assertion {
+	return durationAssertion(d, compareLessOrEqual)
+}
+
+// DurationGreater returns an assertion to ensure a duration value is greater than d.
+// The value can be a time.Duration, a duration string such as "1h30m", or a number of seconds.
+func DurationGreater(d time.Duration) Assertion {
+	return durationAssertion(d, compareGreater)
+}
+
+// DurationGreaterOrEqual returns an assertion to ensure a duration value is equal or greater than d.
+// The value can be a time.Duration, a duration string such as "1h30m", or a number of seconds.
+func DurationGreaterOrEqual(d time.Duration) Assertion {
+	return durationAssertion(d, compareGreaterOrEqual)
+}
+
+func durationAssertion(expected time.Duration, typ compareType) Assertion {
+	return AssertionFunc(func(v interface{}) error {
+		got, err := parseDuration(v)
+		if err != nil {
+			return err
+		}
+		var result int
+		switch {
+		case got < expected:
+			result = -1
+		case got > expected:
+			result = 1
+		}
+		if err := compareByType(result, expected.String(), typ); err != nil {
+			return errors.NewDiffError("Duration"+typ.String(), expected, got, errors.Errorf("%s: got %s", err, got))
+		}
+		return nil
+	})
+}
+
+// parseDuration converts v into time.Duration.
+// A string is parsed by time.ParseDuration, and a number is the seconds.
+func parseDuration(v interface{}) (time.Duration, error) {
+	switch v := v.(type) {
+	case time.Duration:
+		return v, nil
+	case *time.Duration:
+		if v != nil {
+			return *v, nil
+		}
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, errors.Errorf("failed to parse %q as seconds: %s", v, err)
+		}
+		return secondsToDuration(f)
+	}
+	if isNumber(v) {
+		f, err := toFloat64(v)
+		if err != nil {
+			return 0, err
+		}
+		return secondsToDuration(f)
+	}
+	s, err := toString(v)
+	if err != nil {
+		return 0, errors.Errorf("expected duration but got %T", v)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.Errorf("failed to parse %q as duration: %s", s, err)
+	}
+	return d, nil
+}
+
+func isNumber(v interface{}) bool {
+	return v != nil && isKindOfNumber(v)
+}
+
+func secondsToDuration(f float64) (time.Duration, error) {
+	d := f * float64(time.Second)
+	if math.IsNaN(d) || d > math.MaxInt64 || d < math.MinInt64 {
+		return 0, errors.Errorf("%v seconds is out of range of duration", f)
+	}
+	return time.Duration(d), nil
+}
@@ -0,0 +1,88 @@
+package assert
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	tests := map[string]struct {
+		assertion Assertion
+		ok        []interface{}
+		ng        []interface{}
+	}{
+		"DurationLess": {
+			assertion: DurationLess(time.Second),
+			ok:        []interface{}{500 * time.Millisecond, "999ms", 0.5, 0, json.Number("0.25"), []byte("1ms")},
+			ng:        []interface{}{time.Second, "1h30m", 1, json.Number("2"), "1", "invalid", nil, true},
+		},
+		"DurationLessOrEqual": {
+			assertion: DurationLessOrEqual(time.Second),
+			ok:        []interface{}{time.Second, "1s", 1, uint8(1)},
+			ng:        []interface{}{"1.001s", 1.5},
+		},
+		"DurationGreater": {
+			assertion: DurationGreater(time.Hour),
+			ok:        []interface{}{"1h30m", 3601, float32(3700)},
+			ng:        []interface{}{time.Hour, "59m", 3600},
+		},
+		"DurationGreaterOrEqual": {
+			assertion: DurationGreaterOrEqual(time.Hour),
+			ok:        []interface{}{time.Hour, "60m", 3600, json.Number("3600")},
+			ng:        []interface{}{"59m59s", 1e300},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			for _, v := range test.ok {
+				if err := test.assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range test.ng {
+				if err := test.assertion.Assert(v); err == nil {
+					t.Errorf("%v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		tests := map[string]struct {
+			assertion Assertion
+			v         interface{}
+			expect    string
+		}{
+			"string": {
+				assertion: DurationLess(time.Second),
+				v:         "1m30s",
+				expect:    "must be less than 1s: got 1m30s",
+			},
+			"seconds": {
+				assertion: DurationGreaterOrEqual(time.Minute),
+				v:         1.5,
+				expect:    "must be equal or greater than 1m0s: got 1.5s",
+			},
+			"invalid": {
+				assertion: DurationLess(time.Second),
+				v:         "1 hour",
+				expect:    `failed to parse "1 hour" as duration`,
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				err := test.assertion.Assert(test.v)
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if !strings.Contains(err.Error(), test.expect) {
+					t.Errorf("%q not found in %q", test.expect, err)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package assert
+
+import (
+	"reflect"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// Empty returns an assertion to ensure a value is empty.
+// Zero-length strings, arrays, slices, and maps, nil pointers, and nil interfaces are empty.
+func Empty() Assertion {
+	return listAssertionFunc(func(v interface{}) error {
+		if isEmpty(v) {
+			return nil
+		}
+		return errors.Errorf("expected empty but got %#v", v)
+	})
+}
+
+// NotEmpty returns an assertion to ensure a value is not empty.
+func NotEmpty() Assertion {
+	return listAssertionFunc(func(v interface{}) error {
+		if !isEmpty(v) {
+			return nil
+		}
+		return errors.Errorf("expected not empty but got %#v", v)
+	})
+}
+
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return true
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.String, reflect.Array, reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,102 @@
+package assert
+
+import (
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestEmpty(t *testing.T) {
+	var (
+		nilPtr *string
+		s      = ""
+		str    = "test"
+	)
+	tests := map[string]struct {
+		v     interface{}
+		empty bool
+	}{
+		"nil": {
+			v:     nil,
+			empty: true,
+		},
+		"nil pointer": {
+			v:     nilPtr,
+			empty: true,
+		},
+		"empty string": {
+			v:     "",
+			empty: true,
+		},
+		"pointer to empty string": {
+			v:     &s,
+			empty: true,
+		},
+		"empty slice": {
+			v:     []int{},
+			empty: true,
+		},
+		"nil slice": {
+			v:     []int(nil),
+			empty: true,
+		},
+		"empty map": {
+			v:     map[string]int{},
+			empty: true,
+		},
+		"empty yaml.MapSlice": {
+			v:     yaml.MapSlice{},
+			empty: true,
+		},
+		"string": {
+			v: "a",
+		},
+		"pointer to string": {
+			v: &str,
+		},
+		"slice": {
+			v: []int{0},
+		},
+		"map": {
+			v: map[string]int{"a": 0},
+		},
+		"zero integer": {
+			v: 0,
+		},
+		"false": {
+			v: false,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			emptyErr := Empty().Assert(test.v)
+			notEmptyErr := NotEmpty().Assert(test.v)
+			if test.empty {
+				if emptyErr != nil {
+					t.Errorf("Empty: unexpected error: %s", emptyErr)
+				}
+				if notEmptyErr == nil {
+					t.Error("NotEmpty: expected error but no error")
+				}
+			} else {
+				if emptyErr == nil {
+					t.Error("Empty: expected error but no error")
+				}
+				if notEmptyErr != nil {
+					t.Errorf("NotEmpty: unexpected error: %s", notEmptyErr)
+				}
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := Empty().Assert([]interface{}{"failed"})
+		if err == nil {
+			t.Fatal("expected error but no error")
+		}
+		if got, expect := err.Error(), `expected empty but got []interface {}{"failed"}`; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+}
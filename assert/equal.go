@@ -1,8 +1,10 @@
 package assert
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/zoncoen/scenarigo/errors"
@@ -14,12 +16,48 @@ var (
 )
 
 // Equaler is the interface for custom equaler.
+// Assert returns when the context passed to Build is done, but a running Equal isn't stopped.
+// Equalers which may take a long time should implement ContextEqualer to check for cancellation.
 type Equaler interface {
 	// Equal checks two values are equal or not.
 	// If the ok is true, the err should be used as result.
 	Equal(expected, got interface{}) (ok bool, err error)
 }
 
+// ContextEqualer is the interface for custom equaler which receives the context of the assertion.
+// The context is the one passed to Build, and EqualContext is used instead of Equal if the assertion is built by Build.
+type ContextEqualer interface {
+	Equaler
+	// EqualContext checks two values are equal or not like Equal.
+	// It should return as soon as possible when ctx is done.
+	EqualContext(ctx context.Context, expected, got interface{}) (ok bool, err error)
+}
+
+// equal compares two values by eq with ctx if eq implements ContextEqualer.
+func equal(ctx context.Context, eq Equaler, expected, got interface{}) (bool, error) {
+	if ceq, ok := eq.(ContextEqualer); ok && ctx != nil {
+		return ceq.EqualContext(ctx, expected, got)
+	}
+	return eq.Equal(expected, got)
+}
+
+// withContext returns the equalers which call EqualContext with ctx if they implement ContextEqualer.
+func withContext(ctx context.Context, eqs []Equaler) []Equaler {
+	bound := make([]Equaler, len(eqs))
+	if ctx == nil {
+		return append(bound[:0], eqs...)
+	}
+	for i, eq := range eqs {
+		bound[i] = eq
+		if ceq, ok := eq.(ContextEqualer); ok {
+			bound[i] = EqualerFunc(func(expected, got interface{}) (bool, error) {
+				return ceq.EqualContext(ctx, expected, got)
+			})
+		}
+	}
+	return bound
+}
+
 // RegisterCustomEqualer appends eq as a custom equaler.
 // Registered equaler will be used when all default equalers judge two values are not equal.
 func RegisterCustomEqualer(eq Equaler) {
@@ -41,60 +79,118 @@ func (eq equaler) Equal(expected, got interface{}) (bool, error) {
 }
 
 // Equal returns an assertion to ensure a value equals the expected value.
+// NaN doesn't equal any value including NaN by IEEE 754 unless WithNaNEqual is enabled.
 func Equal(expected interface{}, customEqs ...Equaler) Assertion {
-	return AssertionFunc(func(v interface{}) error {
-		if n, ok := v.(json.Number); ok {
-			switch expected.(type) {
-			case int, int8, int16, int32, int64,
-				uint, uint8, uint16, uint32, uint64:
-				i, err := n.Int64()
-				if err == nil {
-					v = i
-				}
-			case float32, float64:
-				f, err := n.Float64()
-				if err == nil {
-					v = f
-				}
+	return optional(&equalAssertion{
+		expected: expected,
+		eqs:      customEqs,
+	})
+}
+
+type equalAssertion struct {
+	ctx       context.Context
+	expected  interface{}
+	eqs       []Equaler
+	typeEqs   map[reflect.Type]Equaler
+	tolerance float64
+	nanEqual  bool
+
+	caseInsensitive bool
+}
+
+// Assert implements Assertion interface.
+func (a *equalAssertion) Assert(v interface{}) error {
+	expected := a.expected
+	if n, ok := v.(json.Number); ok {
+		switch expected.(type) {
+		case int, int8, int16, int32, int64,
+			uint, uint8, uint16, uint32, uint64:
+			i, err := n.Int64()
+			if err == nil {
+				v = i
+			}
+		case float32, float64:
+			f, err := n.Float64()
+			if err == nil {
+				v = f
 			}
 		}
+	}
+
+	if reflect.DeepEqual(v, expected) {
+		return nil
+	}
 
-		if reflect.DeepEqual(v, expected) {
+	if a.nanEqual && isNaN(v) && isNaN(expected) {
+		return nil
+	}
+
+	if isNil(v) && isNil(expected) {
+		return nil
+	}
+
+	if a.caseInsensitive {
+		if x, y, ok := bothStrings(expected, v); ok && strings.EqualFold(x, y) {
 			return nil
 		}
+	}
 
-		if isNil(v) && isNil(expected) {
+	if a.tolerance > 0 && isFloatComparison(v, expected) {
+		if result, _, err := cmpNumber(v, expected, a.tolerance); err == nil && result == 0 {
 			return nil
 		}
+	}
 
-		for _, eq := range customEqs {
+	if t := reflect.TypeOf(expected); t != nil && t == reflect.TypeOf(v) {
+		if eq, found := a.typeEqs[t]; found {
 			ok, err := eq.Equal(expected, v)
 			if ok {
 				return err
 			}
 		}
+	}
 
-		m.RLock()
-		defer m.RUnlock()
-		for _, eq := range equalers {
-			ok, err := eq.Equal(expected, v)
-			if ok {
-				return err
-			}
+	for _, eq := range a.eqs {
+		ok, err := eq.Equal(expected, v)
+		if ok {
+			return err
 		}
+	}
 
-		if t := reflect.TypeOf(v); t != reflect.TypeOf(expected) {
-			// try type conversion
-			converted, err := convertToType(expected, t)
-			if err == nil {
-				if reflect.DeepEqual(v, converted) {
-					return nil
-				}
+	m.RLock()
+	defer m.RUnlock()
+	for _, eq := range equalers {
+		ok, err := equal(a.ctx, eq, expected, v)
+		if ok {
+			return err
+		}
+	}
+
+	if t := reflect.TypeOf(v); t != reflect.TypeOf(expected) {
+		// try type conversion
+		converted, err := convertToType(expected, t)
+		if err == nil {
+			if reflect.DeepEqual(v, converted) {
+				return nil
 			}
-			return errors.Errorf("expected %T (%+v) but got %T (%+v)", expected, expected, v, v)
 		}
-		return errors.Errorf("expected %+v but got %+v", expected, v)
-	})
+		return errors.NewDiffError("Equal", expected, v, errors.Errorf("expected %T (%+v) but got %T (%+v)", expected, expected, v, v))
+	}
+	return errors.NewDiffError("Equal", expected, v, errors.Errorf("expected %+v but got %+v", expected, v))
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *equalAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	return &equalAssertion{
+		ctx:       opt.ctx,
+		expected:  a.expected,
+		eqs:       append(withContext(opt.ctx, a.eqs), opt.eqs...),
+		typeEqs:   opt.typeEqs,
+		tolerance: opt.numericTolerance,
+		nanEqual:  opt.nanEqual,
+
+		caseInsensitive: opt.caseInsensitive,
+	}
 }
 
 func isNil(i interface{}) bool {
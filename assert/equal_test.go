@@ -1,9 +1,15 @@
 package assert
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
 
 	"github.com/zoncoen/scenarigo/errors"
 )
@@ -37,6 +43,11 @@ func TestEqual(t *testing.T) {
 			ok:       1,
 			ng:       2,
 		},
+		"integer (got nil)": {
+			expected: 1,
+			ok:       1,
+			ng:       nil,
+		},
 		"integer (type conversion)": {
 			expected: 1,
 			ok:       uint64(1),
@@ -266,3 +277,56 @@ func TestIsNil(t *testing.T) {
 		})
 	}
 }
+
+func TestWithTypeEqualer(t *testing.T) {
+	truncated := EqualerFunc(func(expected, got any) (bool, error) {
+		e, ok := expected.(time.Time)
+		if !ok {
+			return false, nil
+		}
+		g, ok := got.(time.Time)
+		if !ok {
+			return false, nil
+		}
+		if e.Truncate(time.Second).Equal(g.Truncate(time.Second)) {
+			return true, nil
+		}
+		return true, fmt.Errorf("expected %s but got %s", e, g)
+	})
+	alwaysFail := EqualerFunc(func(expected, got any) (bool, error) {
+		return true, errors.New("generic equaler")
+	})
+	now := time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)
+	expect := yaml.MapSlice{
+		{Key: "time", Value: now},
+		{Key: "name", Value: "foo"},
+	}
+	assertion, err := Build(context.Background(), expect,
+		WithEqualers(alwaysFail),
+		WithTypeEqualer(reflect.TypeOf(time.Time{}), truncated),
+	)
+	if err != nil {
+		t.Fatalf("failed to build: %s", err)
+	}
+	if err := assertion.Assert(map[string]any{
+		"time": now.Add(500 * time.Millisecond),
+		"name": "foo",
+	}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := assertion.Assert(map[string]any{
+		"time": now.Add(time.Second),
+		"name": "foo",
+	}); err == nil {
+		t.Error("expected error but no error")
+	}
+	// the generic equaler is used for other types
+	if err := assertion.Assert(map[string]any{
+		"time": now,
+		"name": "bar",
+	}); err == nil {
+		t.Error("expected error but no error")
+	} else if !strings.Contains(err.Error(), "generic equaler") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
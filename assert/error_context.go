@@ -0,0 +1,59 @@
+package assert
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/zoncoen/query-go"
+)
+
+// contextError represents an error with the rendered parent value of the invalid value.
+type contextError struct {
+	err     error
+	context string
+}
+
+func (e *contextError) Error() string {
+	return fmt.Sprintf("%s\n%s", e.err.Error(), e.context)
+}
+
+func (e *contextError) Unwrap() error {
+	return e.err
+}
+
+// withErrorContext appends the parent value of the value extracted by q from v to err.
+func withErrorContext(err error, q *query.Query, v any, lines int) error {
+	extractors := q.Extractors()
+	if len(extractors) == 0 {
+		return err
+	}
+	parent := newQuery().Append(extractors[:len(extractors)-1]...)
+	pv, perr := parent.Extract(v)
+	if perr != nil {
+		return err
+	}
+	b, merr := yaml.Marshal(pv)
+	if merr != nil {
+		return err
+	}
+	ls := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	if len(ls) > lines {
+		ls = append(ls[:lines], "...")
+	}
+	path := parent.String()
+	if path == "" {
+		path = "$"
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("    parent (%s):\n", path))
+	for _, l := range ls {
+		sb.WriteString("      ")
+		sb.WriteString(l)
+		sb.WriteString("\n")
+	}
+	return &contextError{
+		err:     err,
+		context: sb.String(),
+	}
+}
@@ -1,15 +1,18 @@
 package assert
 
 // Greater returns an assertion to ensure a value greater than the expected value.
+// The infinities are ordered by IEEE 754, and the comparisons with NaN always fail because NaN is unordered.
 func Greater(expected interface{}) Assertion {
-	return AssertionFunc(func(actual interface{}) error {
-		return compareNumber(actual, expected, compareGreater)
+	return optional(&compareAssertion{
+		expected: expected,
+		typ:      compareGreater,
 	})
 }
 
 // GreaterOrEqual returns an assertion to ensure a value equal or greater than the expected value.
 func GreaterOrEqual(expected interface{}) Assertion {
-	return AssertionFunc(func(actual interface{}) error {
-		return compareNumber(actual, expected, compareGreaterOrEqual)
+	return optional(&compareAssertion{
+		expected: expected,
+		typ:      compareGreaterOrEqual,
 	})
 }
@@ -0,0 +1,124 @@
+package assert
+
+import (
+	"strings"
+
+	"github.com/zoncoen/query-go"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// WithIgnorePaths is a build option that skips the assertions of the values at the paths.
+// The paths use the query syntax such as $.items[0].id, and the wildcard segments [*] and .* match any index and key.
+// The paths of DeepEqual are relative to the value which DeepEqual asserts, and the ignored values are shown as <ignored> in its diff.
+func WithIgnorePaths(paths ...string) BuildOpt {
+	return func(opt *buildOpt) {
+		for _, p := range paths {
+			pattern, err := parsePathPattern(p)
+			if err != nil {
+				opt.ignorePathsErr = err
+				return
+			}
+			opt.ignorePaths = append(opt.ignorePaths, pattern)
+		}
+	}
+}
+
+// ignored reports whether the value at q is ignored by WithIgnorePaths.
+func (opt *buildOpt) ignored(q *query.Query) bool {
+	if opt == nil || len(opt.ignorePaths) == 0 {
+		return false
+	}
+	segments, err := pathSegments(q.String())
+	if err != nil {
+		return false
+	}
+	for _, p := range opt.ignorePaths {
+		if p.match(segments) {
+			return true
+		}
+	}
+	return false
+}
+
+const wildcardSegment = "*"
+
+// pathPattern is the segments of a path. The wildcard segment matches any segment.
+type pathPattern []string
+
+func parsePathPattern(s string) (pathPattern, error) {
+	segments, err := pathSegments(strings.TrimPrefix(s, "$"))
+	if err != nil {
+		return nil, errors.Errorf("invalid ignore path %q: %s", s, err)
+	}
+	return segments, nil
+}
+
+func (p pathPattern) match(segments []string) bool {
+	if len(p) != len(segments) {
+		return false
+	}
+	for i, s := range p {
+		if s != wildcardSegment && s != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// pathSegments splits the path such as .items[0]['a.b'] into the keys and the indexes.
+func pathSegments(s string) ([]string, error) {
+	var segments []string
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, "['"):
+			var (
+				b       strings.Builder
+				escaped bool
+				end     = -1
+			)
+			for i, ch := range s[2:] {
+				if escaped {
+					b.WriteRune(ch)
+					escaped = false
+					continue
+				}
+				if ch == '\\' {
+					escaped = true
+					continue
+				}
+				if ch == '\'' {
+					end = i + 2
+					break
+				}
+				b.WriteRune(ch)
+			}
+			if end < 0 || !strings.HasPrefix(s[end:], "']") {
+				return nil, errors.New("unterminated quoted key")
+			}
+			segments = append(segments, b.String())
+			s = s[end+2:]
+		case strings.HasPrefix(s, "["):
+			end := strings.Index(s, "]")
+			if end < 0 {
+				return nil, errors.New("unterminated index")
+			}
+			segments = append(segments, s[1:end])
+			s = s[end+1:]
+		case strings.HasPrefix(s, "."):
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, errors.New("empty key")
+			}
+			segments = append(segments, s[:end])
+			s = s[end:]
+		default:
+			return nil, errors.Errorf("unexpected %q", s)
+		}
+	}
+	return segments, nil
+}
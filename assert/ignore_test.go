@@ -0,0 +1,140 @@
+package assert
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithIgnorePaths(t *testing.T) {
+	actual := map[string]interface{}{
+		"id":        "0f8fad5b",
+		"createdAt": "2024-01-01T00:00:00Z",
+		"items": []interface{}{
+			map[string]interface{}{"id": 1, "name": "a"},
+			map[string]interface{}{"id": 2, "name": "b"},
+		},
+	}
+	tests := map[string]struct {
+		expect interface{}
+		paths  []string
+		err    string
+	}{
+		"skip subtree assertions": {
+			expect: yaml.MapSlice{
+				{Key: "id", Value: "xxx"},
+				{Key: "items", Value: []interface{}{
+					yaml.MapSlice{{Key: "id", Value: 0}, {Key: "name", Value: "a"}},
+				}},
+			},
+			paths: []string{"$.id", "$.items[*].id"},
+		},
+		"deep equal": {
+			expect: DeepEqual(yaml.MapSlice{
+				{Key: "items", Value: []interface{}{
+					yaml.MapSlice{{Key: "name", Value: "a"}},
+					yaml.MapSlice{{Key: "name", Value: "b"}},
+				}},
+			}),
+			paths: []string{"$.id", "$.createdAt", "$.items[*].id"},
+		},
+		"ignore whole element": {
+			expect: DeepEqual(yaml.MapSlice{
+				{Key: "id", Value: "0f8fad5b"},
+				{Key: "createdAt", Value: "2024-01-01T00:00:00Z"},
+				{Key: "items", Value: []interface{}{
+					yaml.MapSlice{{Key: "id", Value: 1}, {Key: "name", Value: "a"}},
+				}},
+			}),
+			paths: []string{".items[1]"},
+		},
+		"not ignored": {
+			expect: DeepEqual(yaml.MapSlice{
+				{Key: "items", Value: []interface{}{
+					yaml.MapSlice{{Key: "name", Value: "a"}},
+					yaml.MapSlice{{Key: "name", Value: "b"}},
+				}},
+			}),
+			paths: []string{"$.id", "$.items[0].id"},
+			err:   "values differ",
+		},
+		"invalid path": {
+			expect: 1,
+			paths:  []string{"$.items[0"},
+			err:    `failed to build assertion: invalid ignore path "$.items[0": unterminated index`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := Build(context.Background(), test.expect, WithIgnorePaths(test.paths...))
+			if err == nil {
+				err = assertion.Assert(actual)
+			}
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%q not found in %q", test.err, err)
+			}
+		})
+	}
+}
+
+func TestWithIgnorePaths_Diff(t *testing.T) {
+	assertion, err := Build(context.Background(), DeepEqual(yaml.MapSlice{
+		{Key: "id", Value: "xxx"},
+		{Key: "name", Value: "alice"},
+	}), WithIgnorePaths("$.id", "$.requestId"))
+	if err != nil {
+		t.Fatalf("failed to build: %s", err)
+	}
+	err = assertion.Assert(map[string]interface{}{
+		"id":        "0f8fad5b",
+		"name":      "bob",
+		"requestId": "r-1",
+	})
+	if err == nil {
+		t.Fatal("no error")
+	}
+	expect := strings.Join([]string{
+		`values differ (-expected +actual):`,
+		`  id: <ignored>`,
+		`- name: "alice"`,
+		`+ name: "bob"`,
+		`  requestId: <ignored>`,
+	}, "\n")
+	if diff := cmp.Diff(expect, err.Error()); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+}
+
+func TestPathSegments(t *testing.T) {
+	tests := map[string][]string{
+		"":                  nil,
+		".items[0].id":      {"items", "0", "id"},
+		".items[*].id":      {"items", "*", "id"},
+		"['a.b']['it\\'s']": {"a.b", "it's"},
+	}
+	for path, expect := range tests {
+		path, expect := path, expect
+		t.Run(path, func(t *testing.T) {
+			got, err := pathSegments(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(expect, got); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package assert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// JSONSchema returns an assertion to ensure a value is valid against the JSON Schema document.
+// The schema is compiled only once, and Build fails if the schema is invalid.
+// Each schema violation is reported as an error with the path of the invalid value.
+func JSONSchema(schema string) Assertion {
+	const url = "schema.json"
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, strings.NewReader(schema)); err != nil {
+		return &invalidAssertion{
+			err: fmt.Errorf("invalid JSON Schema: %w", err),
+		}
+	}
+	sch, err := compiler.Compile(url)
+	if err != nil {
+		return &invalidAssertion{
+			err: fmt.Errorf("invalid JSON Schema: %w", err),
+		}
+	}
+	return AssertionFunc(func(v interface{}) error {
+		doc, err := toJSONValue(v)
+		if err != nil {
+			return err
+		}
+		err = sch.Validate(doc)
+		if err == nil {
+			return nil
+		}
+		var verr *jsonschema.ValidationError
+		if !errors.As(err, &verr) {
+			return err
+		}
+		leaves := leafValidationErrors(verr)
+		sort.SliceStable(leaves, func(i, j int) bool {
+			return lessJSONPointer(leaves[i].InstanceLocation, leaves[j].InstanceLocation)
+		})
+		var errs []error
+		for _, e := range leaves {
+			path := jsonPointerToPath(doc, e.InstanceLocation)
+			if path == "" {
+				errs = append(errs, errors.New(e.Message))
+				continue
+			}
+			errs = append(errs, errors.ErrorPath(path, e.Message))
+		}
+		return errors.Errors(errs...)
+	})
+}
+
+// toJSONValue converts v into a value decoded from JSON to validate by JSON Schema.
+func toJSONValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(mapSliceToMap(v))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %T into JSON: %w", v, err)
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var doc interface{}
+	if err := d.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to convert %T into JSON: %w", v, err)
+	}
+	return doc, nil
+}
+
+// mapSliceToMap converts yaml.MapSlice values into maps recursively to encode them as JSON objects.
+func mapSliceToMap(v interface{}) interface{} {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		m := make(map[string]interface{}, len(v))
+		for _, item := range v {
+			m[fmt.Sprint(item.Key)] = mapSliceToMap(item.Value)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, e := range v {
+			s[i] = mapSliceToMap(e)
+		}
+		return s
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = mapSliceToMap(e)
+		}
+		return m
+	default:
+		return v
+	}
+}
+
+func leafValidationErrors(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var errs []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		errs = append(errs, leafValidationErrors(cause)...)
+	}
+	return errs
+}
+
+// lessJSONPointer reports whether the JSON Pointer a sorts before b.
+// The tokens are compared numerically if both are array indexes, so "/items/2" sorts before "/items/10".
+func lessJSONPointer(a, b string) bool {
+	ta, tb := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(ta) && i < len(tb); i++ {
+		if ta[i] == tb[i] {
+			continue
+		}
+		na, errA := strconv.Atoi(ta[i])
+		nb, errB := strconv.Atoi(tb[i])
+		if errA == nil && errB == nil {
+			return na < nb
+		}
+		return ta[i] < tb[i]
+	}
+	return len(ta) < len(tb)
+}
+
+// jsonPointerToPath converts a JSON Pointer of doc such as "/items/0/name" into a query path such as "items[0].name".
+// It walks doc along the pointer to tell the array indexes from the object keys such as "/responses/200".
+func jsonPointerToPath(doc interface{}, ptr string) string {
+	if ptr == "" || ptr == "/" {
+		return ""
+	}
+	var b strings.Builder
+	for _, token := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		if arr, ok := doc.([]interface{}); ok {
+			if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(arr) {
+				b.WriteString(fmt.Sprintf("[%s]", token))
+				doc = arr[i]
+				continue
+			}
+		}
+		if obj, ok := doc.(map[string]interface{}); ok {
+			doc = obj[token]
+		} else {
+			doc = nil
+		}
+		if b.Len() > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(token)
+	}
+	return b.String()
+}
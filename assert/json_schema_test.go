@@ -0,0 +1,135 @@
+package assert
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema := `{
+  "type": "object",
+  "required": ["id", "items"],
+  "properties": {
+    "id": {"type": "integer", "minimum": 1},
+    "responses": {
+      "type": "object",
+      "additionalProperties": {
+        "type": "object",
+        "properties": {
+          "description": {"type": "string"}
+        }
+      }
+    },
+    "items": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "name": {"type": "string"}
+        }
+      }
+    }
+  }
+}`
+	tests := map[string]struct {
+		v      interface{}
+		expect string
+	}{
+		"valid": {
+			v: map[string]interface{}{
+				"id": 1,
+				"items": []interface{}{
+					map[string]interface{}{"name": "foo"},
+				},
+			},
+		},
+		"valid (yaml.MapSlice)": {
+			v: yaml.MapSlice{
+				{Key: "id", Value: uint64(1)},
+				{Key: "items", Value: []interface{}{
+					yaml.MapSlice{{Key: "name", Value: "foo"}},
+				}},
+			},
+		},
+		"valid (struct)": {
+			v: struct {
+				ID    int        `json:"id"`
+				Items []struct{} `json:"items"`
+			}{ID: 1, Items: []struct{}{}},
+		},
+		"missing property": {
+			v: map[string]interface{}{
+				"items": []interface{}{},
+			},
+			expect: "1 error occurred: missing properties: 'id'",
+		},
+		"invalid values": {
+			v: map[string]interface{}{
+				"id": 0,
+				"items": []interface{}{
+					map[string]interface{}{"name": 1},
+				},
+			},
+			expect: `2 errors occurred: .id: must be >= 1 but found 0
+.items[0].name: expected string, but got number`,
+		},
+		"sorted by index": {
+			v: map[string]interface{}{
+				"id": 1,
+				"items": func() []interface{} {
+					items := make([]interface{}, 11)
+					for i := range items {
+						items[i] = map[string]interface{}{"name": "foo"}
+					}
+					items[2] = map[string]interface{}{"name": 1}
+					items[10] = map[string]interface{}{"name": 1}
+					return items
+				}(),
+			},
+			expect: `2 errors occurred: .items[2].name: expected string, but got number
+.items[10].name: expected string, but got number`,
+		},
+		"numeric object key": {
+			v: map[string]interface{}{
+				"id":    1,
+				"items": []interface{}{},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{"description": 1},
+				},
+			},
+			expect: "1 error occurred: .responses.200.description: expected string, but got number",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			err := JSONSchema(schema).Assert(test.v)
+			if test.expect == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error but no error")
+			}
+			if got := err.Error(); got != test.expect {
+				t.Errorf("expect %q but got %q", test.expect, got)
+			}
+		})
+	}
+
+	t.Run("Build fails with invalid schema", func(t *testing.T) {
+		_, err := Build(context.Background(), JSONSchema(`{"type": 1}`))
+		if err == nil {
+			t.Fatal("expected error but no error")
+		}
+		if got, expect := err.Error(), "failed to build assertion: invalid JSON Schema"; !strings.HasPrefix(got, expect) {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package assert
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
+)
+
+// KeysEqual returns an assertion to ensure a map has exactly the expected keys regardless of the values.
+// The missing keys and the unexpected keys are reported separately.
+func KeysEqual(keys ...string) Assertion {
+	return &keysAssertion{
+		expected: keys,
+	}
+}
+
+// KeysSubset returns an assertion to ensure a map has all the expected keys regardless of the values.
+// The extra keys are ignored in the same way as Subset.
+func KeysSubset(keys ...string) Assertion {
+	return &keysAssertion{
+		expected: keys,
+		subset:   true,
+	}
+}
+
+type keysAssertion struct {
+	expected []string
+	subset   bool
+}
+
+// Assert implements Assertion interface.
+func (a *keysAssertion) Assert(v interface{}) error {
+	actual, err := mapKeys(v)
+	if err != nil {
+		return err
+	}
+	expected := make(map[string]struct{}, len(a.expected))
+	var missing []string
+	for _, k := range a.expected {
+		expected[k] = struct{}{}
+		if _, ok := actual[k]; !ok {
+			missing = append(missing, fmt.Sprintf("%q", k))
+		}
+	}
+	var unexpected []string
+	if !a.subset {
+		for k := range actual {
+			if _, ok := expected[k]; !ok {
+				unexpected = append(unexpected, fmt.Sprintf("%q", k))
+			}
+		}
+		sort.Strings(unexpected)
+	}
+	if len(missing) == 0 && len(unexpected) == 0 {
+		return nil
+	}
+	var msgs []string
+	if len(missing) > 0 {
+		msgs = append(msgs, fmt.Sprintf("missing keys [%s]", strings.Join(missing, ", ")))
+	}
+	if len(unexpected) > 0 {
+		msgs = append(msgs, fmt.Sprintf("unexpected keys [%s]", strings.Join(unexpected, ", ")))
+	}
+	return errors.Errorf("keys don't match: %s", strings.Join(msgs, ", "))
+}
+
+// mapKeys returns the set of the keys of a map as strings.
+func mapKeys(v interface{}) (map[string]struct{}, error) {
+	if m, ok := v.(yaml.MapSlice); ok {
+		keys := make(map[string]struct{}, len(m))
+		for _, item := range m {
+			keys[fmt.Sprint(item.Key)] = struct{}{}
+		}
+		return keys, nil
+	}
+	vv := reflectutil.Elem(reflect.ValueOf(v))
+	if vv.Kind() != reflect.Map {
+		return nil, errors.Errorf("expected a map but got %T", v)
+	}
+	keys := make(map[string]struct{}, vv.Len())
+	for _, k := range vv.MapKeys() {
+		keys[fmt.Sprint(k.Interface())] = struct{}{}
+	}
+	return keys, nil
+}
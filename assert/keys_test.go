@@ -0,0 +1,85 @@
+package assert
+
+import (
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestKeysEqual(t *testing.T) {
+	tests := map[string]struct {
+		keys []string
+		ok   []interface{}
+		ng   []interface{}
+	}{
+		"empty": {
+			keys: []string{},
+			ok:   []interface{}{map[string]interface{}{}, yaml.MapSlice{}},
+			ng:   []interface{}{map[string]int{"a": 1}, "not map", []string{}},
+		},
+		"keys": {
+			keys: []string{"id", "name"},
+			ok: []interface{}{
+				map[string]interface{}{"id": 1, "name": "foo"},
+				map[string]interface{}{"name": nil, "id": nil},
+				yaml.MapSlice{{Key: "name", Value: "foo"}, {Key: "id", Value: 1}},
+			},
+			ng: []interface{}{
+				map[string]interface{}{"id": 1},
+				map[string]interface{}{"id": 1, "name": "foo", "password": "xxx"},
+				yaml.MapSlice{{Key: "id", Value: 1}},
+			},
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assertion := KeysEqual(tc.keys...)
+			for _, v := range tc.ok {
+				if err := assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range tc.ng {
+				if err := assertion.Assert(v); err == nil {
+					t.Errorf("%v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := KeysEqual("id", "name", "email").Assert(map[string]interface{}{
+			"id":       1,
+			"password": "xxx",
+			"name":     "foo",
+			"internal": true,
+		})
+		if err == nil {
+			t.Fatal("expected error but no error")
+		}
+		if got, expect := err.Error(), `keys don't match: missing keys ["email"], unexpected keys ["internal", "password"]`; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+}
+
+func TestKeysSubset(t *testing.T) {
+	assertion := KeysSubset("id", "name")
+	for _, v := range []interface{}{
+		map[string]interface{}{"id": 1, "name": "foo"},
+		map[string]interface{}{"id": 1, "name": "foo", "email": "foo@example.com"},
+		yaml.MapSlice{{Key: "name", Value: "foo"}, {Key: "id", Value: 1}},
+	} {
+		if err := assertion.Assert(v); err != nil {
+			t.Errorf("%v: unexpected error: %s", v, err)
+		}
+	}
+	err := assertion.Assert(map[string]interface{}{"id": 1, "email": "foo@example.com"})
+	if err == nil {
+		t.Fatal("expected error but no error")
+	}
+	if got, expect := err.Error(), `keys don't match: missing keys ["name"]`; got != expect {
+		t.Errorf("expect %q but got %q", expect, got)
+	}
+}
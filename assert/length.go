@@ -5,34 +5,35 @@ import (
 	"reflect"
 
 	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
 )
 
 // Length returns an assertion to ensure a value length is the expected value.
+// The expected value must be an integer or an assertion for the length, such as GreaterOrEqual(1).
 func Length(expected interface{}) Assertion {
 	var assertion Assertion
 	if a, ok := expected.(Assertion); ok {
 		assertion = a
 	} else {
-		if !isKindOfInt(expected) {
-			return AssertionFunc(func(v interface{}) error {
-				return fmt.Errorf("invalid expected length %#v", expected)
-			})
+		if expected == nil || !isKindOfInt(expected) {
+			return &invalidAssertion{
+				err: fmt.Errorf("invalid expected length %#v", expected),
+			}
 		}
 		assertion = Equal(expected)
 	}
-	return AssertionFunc(func(v interface{}) error {
-		if s, ok := v.(string); ok {
-			v = []rune(s)
-		}
-		vv := reflect.ValueOf(v)
+	return listAssertionFunc(func(v interface{}) error {
+		vv := reflectutil.Elem(reflect.ValueOf(v))
 		switch vv.Kind() {
+		case reflect.String:
+			vv = reflect.ValueOf([]rune(vv.String()))
 		case reflect.Array, reflect.Slice, reflect.Map:
-			if err := assertion.Assert(vv.Len()); err != nil {
-				return errors.Wrap(err, "length")
-			}
-			return nil
 		default:
 			return fmt.Errorf("can't get the length of %T", v)
 		}
+		if err := assertion.Assert(vv.Len()); err != nil {
+			return errors.Wrapf(err, "unexpected length %d", vv.Len())
+		}
+		return nil
 	})
 }
@@ -1,6 +1,7 @@
 package assert
 
 import (
+	"context"
 	"testing"
 )
 
@@ -37,6 +38,11 @@ func TestLength(t *testing.T) {
 			ok:     []int{1},
 			ng:     []int{},
 		},
+		"pointer": {
+			expect: 1,
+			ok:     &[]int{1},
+			ng:     &[]int{},
+		},
 	}
 	for name, test := range tests {
 		test := test
@@ -64,6 +70,22 @@ func TestLength_Error(t *testing.T) {
 			t.Errorf("expected %q but got %q", expect, err)
 		}
 	})
+	t.Run("failed to build", func(t *testing.T) {
+		_, err := Build(context.Background(), Length(nil))
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if got, expect := err.Error(), "failed to build assertion: invalid expected length <nil>"; got != expect {
+			t.Errorf("expected %q but got %q", expect, got)
+		}
+	})
+	t.Run("report the actual length", func(t *testing.T) {
+		if err := Length(GreaterOrEqual(1)).Assert([]int{}); err == nil {
+			t.Error("no error")
+		} else if got, expect := err.Error(), "unexpected length 0: must be equal or greater than 1"; got != expect {
+			t.Errorf("expected %q but got %q", expect, err)
+		}
+	})
 	t.Run("failed to get length", func(t *testing.T) {
 		if err := Length(0).Assert(0); err == nil {
 			t.Error("no error")
@@ -1,15 +1,18 @@
 package assert
 
 // Less returns an assertion to ensure a value less than the expected value.
+// The infinities are ordered by IEEE 754, and the comparisons with NaN always fail because NaN is unordered.
 func Less(expected interface{}) Assertion {
-	return AssertionFunc(func(actual interface{}) error {
-		return compareNumber(actual, expected, compareLess)
+	return optional(&compareAssertion{
+		expected: expected,
+		typ:      compareLess,
 	})
 }
 
 // LessOrEqual returns an assertion to ensure a value equal or less than the expected value.
 func LessOrEqual(expected interface{}) Assertion {
-	return AssertionFunc(func(actual interface{}) error {
-		return compareNumber(actual, expected, compareLessOrEqual)
+	return optional(&compareAssertion{
+		expected: expected,
+		typ:      compareLessOrEqual,
 	})
 }
@@ -0,0 +1,319 @@
+package assert
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/template"
+	"github.com/zoncoen/scenarigo/template/ast"
+	"github.com/zoncoen/scenarigo/template/parser"
+)
+
+var (
+	matchersMu sync.RWMutex
+	matchers   = map[string]any{
+		"equal":                  Equal,
+		"greaterThan":            Greater,
+		"greaterThanOrEqual":     GreaterOrEqual,
+		"lessThan":               Less,
+		"lessThanOrEqual":        LessOrEqual,
+		"between":                Between,
+		"betweenExclusive":       BetweenExclusive,
+		"approxEqual":            ApproxEqual,
+		"approxEqualRelative":    ApproxEqualRelative,
+		"regexp":                 Regexp,
+		"hasPrefix":              HasPrefix,
+		"hasSuffix":              HasSuffix,
+		"length":                 Length,
+		"oneOf":                  OneOf,
+		"setEqual":               SetEqual,
+		"subset":                 Subset,
+		"keysEqual":              KeysEqual,
+		"keysSubset":             KeysSubset,
+		"deepEqual":              DeepEqual,
+		"validJSON":              ValidJSON,
+		"validYAML":              ValidYAML,
+		"durationLess":           DurationLess,
+		"durationLessOrEqual":    DurationLessOrEqual,
+		"durationGreater":        DurationGreater,
+		"durationGreaterOrEqual": DurationGreaterOrEqual,
+		"jsonSchema":             JSONSchema,
+		"before":                 timeMatcher(Before),
+		"after":                  timeMatcher(After),
+		"empty":                  Empty(),
+		"notEmpty":               NotEmpty(),
+		"zero":                   Zero(),
+		"notZero":                NotZero(),
+		"isType":                 IsType,
+		"isInteger":              IsInteger,
+		"statusClass":            StatusClass,
+		"status1xx":              Status1xx(),
+		"status2xx":              Status2xx(),
+		"status3xx":              Status3xx(),
+		"status4xx":              Status4xx(),
+		"status5xx":              Status5xx(),
+	}
+	builtinMatchers = map[string]struct{}{}
+)
+
+func init() {
+	// the matchers which build the arguments are registered here to avoid an initialization cycle
+	for name, m := range map[string]boundMatcher{
+		"contains":    containsMatcher(Contains),
+		"notContains": containsMatcher(NotContains),
+		"snapshot":    snapshotMatcher,
+		"and":         listMatcher(And),
+		"or":          listMatcher(Or),
+		"not":         unaryMatcher(Not),
+		"all":         unaryMatcher(All),
+		"each":        unaryMatcher(All),
+	} {
+		matchers[name] = m
+	}
+	for name := range matchers {
+		builtinMatchers[name] = struct{}{}
+	}
+}
+
+// RegisterMatcher registers f as a matcher that can be called by the name in templates such as {{contains "foo"}}.
+// The f must be an Assertion or a function that returns an Assertion.
+// Template data passed by FromTemplate takes precedence over registered matchers.
+func RegisterMatcher(name string, f any) {
+	matchersMu.Lock()
+	defer matchersMu.Unlock()
+	matchers[name] = f
+}
+
+// LookupMatcher returns the matcher registered by the name.
+// The matchers which build their arguments such as and and contains build them with ctx and fs.
+func LookupMatcher(ctx context.Context, name string, fs ...BuildOpt) (any, bool) {
+	f, ok := lookupMatcher(name)
+	if !ok {
+		return nil, false
+	}
+	if bind, ok := f.(boundMatcher); ok {
+		return bind(ctx, fs), true
+	}
+	return f, true
+}
+
+// IsBuiltinMatcher reports whether the name is a matcher provided by this package such as contains.
+func IsBuiltinMatcher(name string) bool {
+	_, ok := builtinMatchers[name]
+	return ok
+}
+
+func lookupMatcher(name string) (any, bool) {
+	matchersMu.RLock()
+	defer matchersMu.RUnlock()
+	f, ok := matchers[name]
+	return f, ok
+}
+
+// boundMatcher returns the matcher which depends on the context and the build options of the assertion.
+type boundMatcher func(ctx context.Context, fs []BuildOpt) any
+
+// buildMatcherArg builds the argument of a matcher unless it is already an assertion.
+func buildMatcherArg(ctx context.Context, fs []BuildOpt, arg any) Assertion {
+	if assertion, ok := arg.(Assertion); ok {
+		return assertion
+	}
+	return MustBuild(ctx, arg, fs...)
+}
+
+// containsMatcher returns the matcher of f which builds the argument unless it is a string to allow checking a substring.
+func containsMatcher(f func(any, ...Equaler) Assertion) boundMatcher {
+	return func(ctx context.Context, fs []BuildOpt) any {
+		return &leftArrowFunc{
+			ctx: ctx,
+			fs:  fs,
+			f: func(arg any) Assertion {
+				if s, ok := arg.(string); ok {
+					return f(s)
+				}
+				return f(buildMatcherArg(ctx, fs, arg))
+			},
+		}
+	}
+}
+
+// unaryMatcher returns the matcher of f which takes an assertion.
+func unaryMatcher(f func(Assertion) Assertion) boundMatcher {
+	return func(ctx context.Context, fs []BuildOpt) any {
+		return &leftArrowFunc{
+			ctx: ctx,
+			fs:  fs,
+			f: func(arg any) Assertion {
+				return f(buildMatcherArg(ctx, fs, arg))
+			},
+		}
+	}
+}
+
+// listMatcher returns the matcher of f which takes assertions.
+func listMatcher(f func(...Assertion) Assertion) boundMatcher {
+	return func(ctx context.Context, fs []BuildOpt) any {
+		return listArgsLeftArrowFunc(func(args ...any) Assertion {
+			assertions := make([]Assertion, 0, len(args))
+			for _, arg := range args {
+				assertions = append(assertions, buildMatcherArg(ctx, fs, arg))
+			}
+			return f(assertions...)
+		})
+	}
+}
+
+func snapshotMatcher(ctx context.Context, fs []BuildOpt) any {
+	return func(name string) Assertion {
+		return MustBuild(ctx, Snapshot(name), fs...)
+	}
+}
+
+// leftArrowFunc is the matcher which can also be called as a left arrow function such as {{contains <-}}: {...}.
+type leftArrowFunc struct {
+	ctx context.Context
+	fs  []BuildOpt
+	f   func(any) Assertion
+}
+
+// Call calls the matcher such as {{contains("foo")}}.
+func (f *leftArrowFunc) Call(v any) Assertion {
+	return f.f(v)
+}
+
+// Exec implements template.Func interface.
+func (f *leftArrowFunc) Exec(arg any) (any, error) {
+	assertion, ok := arg.(Assertion)
+	if !ok {
+		return nil, errors.New("argument must be a assert.Assertion")
+	}
+	return f.f(assertion), nil
+}
+
+// UnmarshalArg implements template.Func interface.
+func (f *leftArrowFunc) UnmarshalArg(unmarshal func(any) error) (any, error) {
+	var i any
+	if err := unmarshal(&i); err != nil {
+		return nil, err
+	}
+	return Build(f.ctx, i, f.fs...)
+}
+
+// listArgsLeftArrowFunc is the matcher which takes a list of assertions such as {{and <-}}: [...].
+type listArgsLeftArrowFunc func(args ...any) Assertion
+
+// Exec implements template.Func interface.
+func (f listArgsLeftArrowFunc) Exec(arg any) (any, error) {
+	assertions, ok := arg.([]any)
+	if !ok {
+		return nil, errors.New("argument must be a slice of interface{}")
+	}
+	return f(assertions...), nil
+}
+
+// UnmarshalArg implements template.Func interface.
+func (listArgsLeftArrowFunc) UnmarshalArg(unmarshal func(any) error) (any, error) {
+	var args []any
+	if err := unmarshal(&args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// findUnknownMatcher returns the name of the first function called in the template that is neither defined in the data nor registered as a matcher.
+func findUnknownMatcher(tmpl string, data any) (string, bool) {
+	if !strings.Contains(tmpl, "{{") {
+		return "", false
+	}
+	node, err := parser.NewParser(strings.NewReader(tmpl)).Parse()
+	if err != nil {
+		return "", false
+	}
+	var name string
+	walkFuncIdents(node, func(id *ast.Ident) bool {
+		if template.IsBuiltinFunc(id.Name) {
+			return true
+		}
+		if _, ok := lookupMatcher(id.Name); ok {
+			return true
+		}
+		if data != nil {
+			if _, err := newQuery().Key(id.Name).Extract(data); err == nil {
+				return true
+			}
+		}
+		name = id.Name
+		return false
+	})
+	return name, name != ""
+}
+
+// leftArrowFuncKey returns the key if m is a left arrow function call such as {{f <-}}: {...}.
+func leftArrowFuncKey(m yaml.MapSlice) (string, bool) {
+	if len(m) != 1 {
+		return "", false
+	}
+	key, ok := m[0].Key.(string)
+	if !ok || !strings.Contains(key, "<-") {
+		return "", false
+	}
+	node, err := parser.NewParser(strings.NewReader(key)).Parse()
+	if err != nil {
+		return "", false
+	}
+	param, ok := node.(*ast.ParameterExpr)
+	if !ok {
+		return "", false
+	}
+	if _, ok := param.X.(*ast.LeftArrowExpr); !ok {
+		return "", false
+	}
+	return key, true
+}
+
+// walkFuncIdents calls f for each identifier in node that is called as a function until f returns false.
+//
+//nolint:cyclop
+func walkFuncIdents(node ast.Node, f func(*ast.Ident) bool) bool {
+	switch n := node.(type) {
+	case *ast.UnaryExpr:
+		return walkFuncIdents(n.X, f)
+	case *ast.BinaryExpr:
+		return walkFuncIdents(n.X, f) && walkFuncIdents(n.Y, f)
+	case *ast.ParameterExpr:
+		return walkFuncIdents(n.X, f)
+	case *ast.ParenExpr:
+		return walkFuncIdents(n.X, f)
+	case *ast.ConditionalExpr:
+		return walkFuncIdents(n.Condition, f) && walkFuncIdents(n.X, f) && walkFuncIdents(n.Y, f)
+	case *ast.SelectorExpr:
+		return walkFuncIdents(n.X, f)
+	case *ast.IndexExpr:
+		return walkFuncIdents(n.X, f) && walkFuncIdents(n.Index, f)
+	case *ast.CallExpr:
+		if id, ok := n.Fun.(*ast.Ident); ok {
+			if !f(id) {
+				return false
+			}
+		} else if !walkFuncIdents(n.Fun, f) {
+			return false
+		}
+		for _, arg := range n.Args {
+			if !walkFuncIdents(arg, f) {
+				return false
+			}
+		}
+	case *ast.LeftArrowExpr:
+		if id, ok := n.Fun.(*ast.Ident); ok {
+			return f(id)
+		}
+		return walkFuncIdents(n.Fun, f)
+	case *ast.DefinedExpr:
+		return walkFuncIdents(n.Arg, f)
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package assert
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestBuild_Matchers(t *testing.T) {
+	tests := map[string]struct {
+		yaml string
+		data any
+		ok   []any
+		ng   []any
+	}{
+		"contains": {
+			yaml: `name: '{{contains("scenari")}}'`,
+			ok:   []any{map[string]any{"name": "scenarigo"}},
+			ng:   []any{map[string]any{"name": "example"}},
+		},
+		"contains a map": {
+			yaml: `users: '{{contains(vars.user)}}'`,
+			data: map[string]any{"vars": map[string]any{"user": yaml.MapSlice{{Key: "name", Value: "Alice"}}}},
+			ok:   []any{map[string]any{"users": []any{map[string]any{"id": 1, "name": "Alice"}}}},
+			ng:   []any{map[string]any{"users": []any{map[string]any{"id": 2, "name": "Bob"}}}},
+		},
+		"without arguments": {
+			yaml: `count: '{{notZero}}'`,
+			ok:   []any{map[string]any{"count": 1}},
+			ng:   []any{map[string]any{"count": 0}},
+		},
+		"greaterThan": {
+			yaml: `count: '{{greaterThan(0)}}'`,
+			ok:   []any{map[string]any{"count": 1}},
+			ng:   []any{map[string]any{"count": 0}},
+		},
+		"regexp": {
+			yaml: `id: '{{regexp("^[0-9]+$")}}'`,
+			ok:   []any{map[string]any{"id": "123"}},
+			ng:   []any{map[string]any{"id": "abc"}},
+		},
+		"before": {
+			yaml: `at: '{{before("2024-01-01T00:00:00Z")}}'`,
+			ok:   []any{map[string]any{"at": "2023-12-31T23:59:59Z"}},
+			ng:   []any{map[string]any{"at": "2024-01-01T00:00:00Z"}},
+		},
+		"nested": {
+			yaml: `count: '{{and(greaterThan(0), lessThan(10))}}'`,
+			ok:   []any{map[string]any{"count": 5}},
+			ng:   []any{map[string]any{"count": 10}},
+		},
+		"with data": {
+			yaml: `count: '{{greaterThan(vars.min)}}'`,
+			data: map[string]any{"vars": map[string]any{"min": 5}},
+			ok:   []any{map[string]any{"count": 6}},
+			ng:   []any{map[string]any{"count": 5}},
+		},
+		"data takes precedence": {
+			yaml: `count: '{{greaterThan(1)}}'`,
+			data: map[string]any{"greaterThan": Less},
+			ok:   []any{map[string]any{"count": 0}},
+			ng:   []any{map[string]any{"count": 2}},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			var expect any
+			if err := yaml.UnmarshalWithOptions([]byte(test.yaml), &expect, yaml.UseOrderedMap()); err != nil {
+				t.Fatalf("failed to unmarshal: %s", err)
+			}
+			assertion, err := Build(context.Background(), expect, FromTemplate(test.data))
+			if err != nil {
+				t.Fatalf("failed to build: %s", err)
+			}
+			for _, v := range test.ok {
+				if err := assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range test.ng {
+				if err := assertion.Assert(v); err == nil {
+					t.Errorf("%v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("unknown matcher", func(t *testing.T) {
+		expect := yaml.MapSlice{{Key: "count", Value: "{{and(greatr(0), lessThan(10))}}"}}
+		_, err := Build(context.Background(), expect)
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if got, expected := err.Error(), `.count: unknown matcher "greatr"`; !strings.Contains(got, expected) {
+			t.Errorf("expected %q to contain %q", got, expected)
+		}
+	})
+
+	t.Run("registered matcher", func(t *testing.T) {
+		RegisterMatcher("isEven", func() Assertion {
+			return AssertionFunc(func(v any) error {
+				if n, ok := v.(int); ok && n%2 == 0 {
+					return nil
+				}
+				return Equal("even number").Assert(v)
+			})
+		})
+		assertion, err := Build(context.Background(), yaml.MapSlice{{Key: "n", Value: "{{isEven()}}"}})
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		if err := assertion.Assert(map[string]any{"n": 2}); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if err := assertion.Assert(map[string]any{"n": 3}); err == nil {
+			t.Error("expected error but no error")
+		}
+	})
+
+	t.Run("left arrow function", func(t *testing.T) {
+		type user struct {
+			Name    string `yaml:"name"`
+			Address struct {
+				City string `yaml:"city"`
+			} `yaml:"address"`
+		}
+		RegisterMatcher("user", func(u user) Assertion {
+			return Subset(map[string]any{
+				"name": u.Name,
+				"address": map[string]any{
+					"city": u.Address.City,
+				},
+			})
+		})
+		var expect any
+		if err := yaml.UnmarshalWithOptions([]byte(strings.Trim(`
+owner:
+  '{{user <-}}':
+    name: '{{vars.name}}'
+    address:
+      city: Tokyo
+`, "\n")), &expect, yaml.UseOrderedMap()); err != nil {
+			t.Fatalf("failed to unmarshal: %s", err)
+		}
+		assertion, err := Build(context.Background(), expect, FromTemplate(map[string]any{
+			"vars": map[string]any{"name": "Alice"},
+		}))
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		ok := map[string]any{
+			"owner": map[string]any{
+				"id":   1,
+				"name": "Alice",
+				"address": map[string]any{
+					"city": "Tokyo",
+					"zip":  "100-0001",
+				},
+			},
+		}
+		if err := assertion.Assert(ok); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		ng := map[string]any{
+			"owner": map[string]any{
+				"name": "Alice",
+				"address": map[string]any{
+					"city": "Osaka",
+				},
+			},
+		}
+		if err := assertion.Assert(ng); err == nil {
+			t.Error("expected error but no error")
+		}
+	})
+
+	t.Run("left arrow function (unknown)", func(t *testing.T) {
+		expect := yaml.MapSlice{
+			{Key: "owner", Value: yaml.MapSlice{
+				{Key: "{{usr <-}}", Value: yaml.MapSlice{{Key: "name", Value: "Alice"}}},
+			}},
+		}
+		_, err := Build(context.Background(), expect)
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if got, expected := err.Error(), `.owner: unknown matcher "usr"`; !strings.Contains(got, expected) {
+			t.Errorf("expected %q to contain %q", got, expected)
+		}
+	})
+}
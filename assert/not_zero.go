@@ -3,28 +3,70 @@ package assert
 import (
 	"encoding/json"
 	"reflect"
+	"time"
 
 	"github.com/zoncoen/scenarigo/errors"
 )
 
+// Zero returns an assertion to ensure a value is zero value.
+func Zero() Assertion {
+	return optional(&zeroAssertion{})
+}
+
 // NotZero returns an assertion to ensure a value is not zero value.
 func NotZero() Assertion {
-	return AssertionFunc(func(v interface{}) error {
-		if n, ok := v.(json.Number); ok {
-			if i, err := n.Int64(); err == nil {
-				if i == 0 {
-					return errors.New("expected not zero value")
-				}
-			}
-			if f, err := n.Float64(); err == nil {
-				if f == 0.0 {
-					return errors.New("expected not zero value")
-				}
-			}
-		}
-		if v == nil || reflect.DeepEqual(v, reflect.Zero(reflect.TypeOf(v)).Interface()) {
-			return errors.New("expected not zero value")
+	return optional(&zeroAssertion{not: true})
+}
+
+type zeroAssertion struct {
+	not bool
+	eqs []Equaler
+}
+
+// Assert implements Assertion interface.
+func (a *zeroAssertion) Assert(v interface{}) error {
+	zero := isZero(v, a.eqs)
+	if a.not {
+		if zero {
+			return errors.Errorf("expected not zero value but got %#v", v)
 		}
 		return nil
-	})
+	}
+	if !zero {
+		return errors.Errorf("expected zero value but got %#v", v)
+	}
+	return nil
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *zeroAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	return &zeroAssertion{
+		not: a.not,
+		eqs: opt.eqs,
+	}
+}
+
+// isZero reports whether v is the zero value of its type.
+// The custom equalers and the registered equalers are used to compare v with the zero value.
+func isZero(v interface{}, eqs []Equaler) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i == 0
+		}
+		if f, err := v.Float64(); err == nil {
+			return f == 0.0
+		}
+	case time.Time:
+		if v.IsZero() {
+			return true
+		}
+	}
+	eq := &equalAssertion{
+		expected: reflect.Zero(reflect.TypeOf(v)).Interface(),
+		eqs:      eqs,
+	}
+	return eq.Assert(v) == nil
 }
@@ -1,9 +1,11 @@
 package assert
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestNotZero(t *testing.T) {
@@ -35,6 +37,18 @@ func TestNotZero(t *testing.T) {
 			ok: &myStruct{},
 			ng: nil,
 		},
+		{
+			ok: &myStruct{},
+			ng: (*myStruct)(nil),
+		},
+		{
+			ok: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			ng: time.Time{},
+		},
+		{
+			ok: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			ng: time.Time{}.In(time.FixedZone("JST", 9*60*60)),
+		},
 		{
 			ok: json.Number("1"),
 			ng: json.Number("0"),
@@ -57,3 +71,98 @@ func TestNotZero(t *testing.T) {
 		})
 	}
 }
+
+func TestZero(t *testing.T) {
+	type myStruct struct {
+		name string
+	}
+	tests := []struct {
+		ok interface{}
+		ng interface{}
+	}{
+		{
+			ok: 0,
+			ng: 1,
+		},
+		{
+			ok: "",
+			ng: "test",
+		},
+		{
+			ok: myStruct{},
+			ng: myStruct{name: "test"},
+		},
+		{
+			ok: (*myStruct)(nil),
+			ng: &myStruct{},
+		},
+		{
+			ok: nil,
+			ng: true,
+		},
+		{
+			ok: json.Number("0.0"),
+			ng: json.Number("0.1"),
+		},
+		{
+			ok: time.Time{},
+			ng: time.Unix(0, 0),
+		},
+	}
+	for i, test := range tests {
+		test := test
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			assertion := Zero()
+			if err := assertion.Assert(test.ok); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if err := assertion.Assert(test.ng); err == nil {
+				t.Errorf("expected error but no error")
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		if err := Zero().Assert("test"); err == nil {
+			t.Error("no error")
+		} else if got, expect := err.Error(), `expected zero value but got "test"`; got != expect {
+			t.Errorf("expected %q but got %q", expect, got)
+		}
+		if err := NotZero().Assert(0); err == nil {
+			t.Error("no error")
+		} else if got, expect := err.Error(), `expected not zero value but got 0`; got != expect {
+			t.Errorf("expected %q but got %q", expect, got)
+		}
+	})
+
+	t.Run("with equalers", func(t *testing.T) {
+		// treat "N/A" as the zero value of strings
+		eq := EqualerFunc(func(expected, got interface{}) (bool, error) {
+			return expected == "" && got == "N/A", nil
+		})
+		for name, test := range map[string]struct {
+			assertion Assertion
+			ok        bool
+		}{
+			"zero":     {assertion: Zero(), ok: true},
+			"not zero": {assertion: NotZero(), ok: false},
+			"and":      {assertion: And(Zero(), Not(NotZero())), ok: true},
+			"or":       {assertion: Or(NotZero(), Equal("-")), ok: false},
+		} {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				assertion, err := Build(context.Background(), test.assertion, WithEqualers(eq))
+				if err != nil {
+					t.Fatalf("failed to build: %s", err)
+				}
+				err = assertion.Assert("N/A")
+				if test.ok && err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				if !test.ok && err == nil {
+					t.Error("expected error but no error")
+				}
+			})
+		}
+	})
+}
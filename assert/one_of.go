@@ -0,0 +1,50 @@
+package assert
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// OneOf returns an assertion to ensure a value equals one of the expected values.
+// The build options such as WithEqualers are applied to compare values when the assertion is built by Build, the same as the expected value of Build.
+func OneOf(values ...interface{}) Assertion {
+	return optional(&oneOfAssertion{
+		values: values,
+	})
+}
+
+type oneOfAssertion struct {
+	values []interface{}
+	opt    *buildOpt
+}
+
+// Assert implements Assertion interface.
+func (a *oneOfAssertion) Assert(v interface{}) error {
+	if len(a.values) == 0 {
+		return errors.New("empty value list")
+	}
+	for _, value := range a.values {
+		eq := &equalAssertion{expected: value}
+		if a.opt != nil {
+			eq = eq.withBuildOpt(a.opt).(*equalAssertion)
+		}
+		if err := eq.Assert(v); err == nil {
+			return nil
+		}
+	}
+	values := make([]string, len(a.values))
+	for i, value := range a.values {
+		values[i] = fmt.Sprintf("%#v", value)
+	}
+	return errors.Errorf("expected one of [%s] but got %#v", strings.Join(values, ", "), v)
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *oneOfAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	return &oneOfAssertion{
+		values: a.values,
+		opt:    opt,
+	}
+}
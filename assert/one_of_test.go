@@ -0,0 +1,126 @@
+package assert
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestOneOf(t *testing.T) {
+	if err := OneOf().Assert(""); err == nil {
+		t.Fatal("empty value list should be an error")
+	}
+
+	tests := map[string]struct {
+		values []interface{}
+		ok     []interface{}
+		ng     []interface{}
+	}{
+		"string": {
+			values: []interface{}{"active", "inactive"},
+			ok:     []interface{}{"active", "inactive"},
+			ng:     []interface{}{"deleted", 1},
+		},
+		"number": {
+			values: []interface{}{200, 204},
+			ok:     []interface{}{200, uint64(204), json.Number("204")},
+			ng:     []interface{}{201, "200"},
+		},
+		"bool": {
+			values: []interface{}{true},
+			ok:     []interface{}{true},
+			ng:     []interface{}{false, "true"},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion := OneOf(test.values...)
+			for _, v := range test.ok {
+				if err := assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range test.ng {
+				if err := assertion.Assert(v); err == nil {
+					t.Errorf("%v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := OneOf("a", 1).Assert("b")
+		if err == nil {
+			t.Fatal("expected error but no error")
+		}
+		if got, expect := err.Error(), `expected one of ["a", 1] but got "b"`; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+
+	t.Run("with equalers", func(t *testing.T) {
+		eq := EqualerFunc(func(expected, got interface{}) (bool, error) {
+			e, ok := expected.(string)
+			if !ok {
+				return false, nil
+			}
+			g, ok := got.(string)
+			if !ok {
+				return false, nil
+			}
+			return true, Equal(strings.ToLower(e)).Assert(strings.ToLower(g))
+		})
+		assertion, err := Build(context.Background(), And(OneOf("A", "B")), WithEqualers(eq))
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		if err := assertion.Assert("b"); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if err := assertion.Assert("c"); err == nil {
+			t.Error("expected error but no error")
+		}
+	})
+
+	t.Run("with build options", func(t *testing.T) {
+		tests := map[string]struct {
+			values []interface{}
+			v      interface{}
+			opt    BuildOpt
+		}{
+			"case-insensitive": {
+				values: []interface{}{"A", "B"},
+				v:      "b",
+				opt:    WithCaseInsensitive(),
+			},
+			"numeric tolerance": {
+				values: []interface{}{1.0, 2.0},
+				v:      2.05,
+				opt:    WithNumericTolerance(0.1),
+			},
+			"NaN": {
+				values: []interface{}{1.0, math.NaN()},
+				v:      math.NaN(),
+				opt:    WithNaNEqual(),
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				assertion, err := Build(context.Background(), OneOf(test.values...), test.opt)
+				if err != nil {
+					t.Fatalf("failed to build: %s", err)
+				}
+				if err := assertion.Assert(test.v); err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				if err := OneOf(test.values...).Assert(test.v); err == nil {
+					t.Error("expected error without the build option but no error")
+				}
+			})
+		}
+	})
+}
@@ -7,41 +7,121 @@ import (
 // And returns a new assertion to ensure that value passes all assertions.
 // If the assertions are empty, it returns an error.
 func And(assertions ...Assertion) Assertion {
-	return AssertionFunc(func(v interface{}) error {
-		if len(assertions) == 0 {
-			return errors.New("empty assertion list")
-		}
-		errs := []error{}
-		for _, assertion := range assertions {
-			assertion := assertion
-			err := assertion.Assert(v)
-			if err != nil {
-				errs = append(errs, err)
+	return optional(&andAssertion{
+		assertions: assertions,
+	})
+}
+
+type andAssertion struct {
+	assertions []Assertion
+	failFast   bool
+}
+
+// Assert implements Assertion interface.
+func (a *andAssertion) Assert(v interface{}) error {
+	if len(a.assertions) == 0 {
+		return errors.New("empty assertion list")
+	}
+	errs := []error{}
+	for _, assertion := range a.assertions {
+		assertion := assertion
+		err := assertion.Assert(v)
+		if err != nil {
+			errs = append(errs, err)
+			if a.failFast {
+				break
 			}
 		}
-		if len(errs) == 0 {
-			return nil
-		}
-		return errors.Errors(errs...)
-	})
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.Errors(errs...)
+}
+
+// withBuildOpt implements optionalAssertion interface.
+// If the fail fast option is enabled, it returns a copy of a that stops at the first error.
+func (a *andAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	return &andAssertion{
+		assertions: withBuildOpt(a.assertions, opt),
+		failFast:   a.failFast || opt.failFast,
+	}
 }
 
 // Or returns new assertion to ensure that value passes at least one of assertions.
 // If the assertions are empty, it returns an error.
 func Or(assertions ...Assertion) Assertion {
-	return AssertionFunc(func(v interface{}) error {
-		if len(assertions) == 0 {
-			return errors.New("empty assertion list")
-		}
-		errs := []error{}
-		for _, assertion := range assertions {
-			assertion := assertion
-			err := assertion.Assert(v)
-			if err == nil {
-				return nil
-			}
-			errs = append(errs, err)
+	return optional(&orAssertion{
+		assertions: assertions,
+	})
+}
+
+type orAssertion struct {
+	assertions []Assertion
+}
+
+// Assert implements Assertion interface.
+func (a *orAssertion) Assert(v interface{}) error {
+	if len(a.assertions) == 0 {
+		return errors.New("empty assertion list")
+	}
+	errs := []error{}
+	for i, assertion := range a.assertions {
+		assertion := assertion
+		err := assertion.Assert(v)
+		if err == nil {
+			return nil
 		}
-		return errors.Wrap(errors.Errors(errs...), "all assertions failed")
+		errs = append(errs, errors.Wrapf(err, "alternative %d", i+1))
+	}
+	return errors.Wrap(errors.Errors(errs...), "all assertions failed")
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *orAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	return &orAssertion{
+		assertions: withBuildOpt(a.assertions, opt),
+	}
+}
+
+// Not returns a new assertion to ensure that value doesn't pass the assertion.
+func Not(assertion Assertion) Assertion {
+	return optional(&notAssertion{
+		assertion: assertion,
 	})
 }
+
+type notAssertion struct {
+	assertion Assertion
+}
+
+// Assert implements Assertion interface.
+func (a *notAssertion) Assert(v interface{}) error {
+	if err := a.assertion.Assert(v); err != nil {
+		return nil //nolint:nilerr
+	}
+	return errors.Errorf("expected the assertion to fail but it passed with %#v", v)
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *notAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	assertion := a.assertion
+	if oa, ok := assertion.(optionalAssertion); ok {
+		assertion = oa.withBuildOpt(opt)
+	}
+	return &notAssertion{
+		assertion: assertion,
+	}
+}
+
+// withBuildOpt applies the build options to the assertions.
+func withBuildOpt(assertions []Assertion, opt *buildOpt) []Assertion {
+	as := make([]Assertion, len(assertions))
+	for i, assertion := range assertions {
+		if oa, ok := assertion.(optionalAssertion); ok {
+			assertion = oa.withBuildOpt(opt)
+		}
+		as[i] = assertion
+	}
+	return as
+}
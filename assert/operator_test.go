@@ -1,7 +1,11 @@
 package assert
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
+
+	"github.com/zoncoen/scenarigo/errors"
 )
 
 func TestAnd(t *testing.T) {
@@ -42,6 +46,66 @@ func TestAnd(t *testing.T) {
 	}
 }
 
+func TestAnd_FailFast(t *testing.T) {
+	expect := And(
+		Greater(0),
+		Less(100),
+		And(Less(10), Equal(5)),
+	)
+	tests := map[string]struct {
+		opts     []BuildOpt
+		v        interface{}
+		errCount int
+	}{
+		"collect all errors": {
+			v:        json.Number("200"),
+			errCount: 3,
+		},
+		"collect all errors (nested)": {
+			v:        50,
+			errCount: 2,
+		},
+		"fail fast": {
+			opts:     []BuildOpt{WithFailFast()},
+			v:        json.Number("200"),
+			errCount: 1,
+		},
+		"fail fast (nested)": {
+			opts:     []BuildOpt{WithFailFast()},
+			v:        50,
+			errCount: 1,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := Build(context.Background(), expect, test.opts...)
+			if err != nil {
+				t.Fatalf("failed to build: %s", err)
+			}
+			err = assertion.Assert(test.v)
+			if err == nil {
+				t.Fatal("expect error but no error")
+			}
+			if got := countErrors(err); got != test.errCount {
+				t.Errorf("expect %d errors but got %d: %s", test.errCount, got, err)
+			}
+		})
+	}
+}
+
+func countErrors(err error) int {
+	var merr *errors.MultiPathError
+	if !errors.As(err, &merr) {
+		return 1
+	}
+	var n int
+	for _, err := range merr.Errs {
+		n += countErrors(err)
+	}
+	return n
+}
+
 func TestOr(t *testing.T) {
 	if err := Or().Assert(""); err == nil {
 		t.Fatal("empty assertion list should be an error")
@@ -79,3 +143,86 @@ func TestOr(t *testing.T) {
 		}
 	}
 }
+
+func TestOr_ErrorMessage(t *testing.T) {
+	err := Or(And(Greater(0), Less(2)), Equal(200), Equal(204)).Assert(500)
+	if err == nil {
+		t.Fatal("expect error but no error")
+	}
+	expect := `3 errors occurred: 1 error occurred: all assertions failed: alternative 1: must be less than 2
+all assertions failed: alternative 2: expected 200 but got 500
+all assertions failed: alternative 3: expected 204 but got 500`
+	if got := err.Error(); got != expect {
+		t.Errorf("unexpected error message:\n%s", got)
+	}
+	if err := Or(And(Greater(0), Less(2)), Equal(200)).Assert(1); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestOr_BuildOpt(t *testing.T) {
+	// treat "N/A" as "-"
+	eq := EqualerFunc(func(expected, got interface{}) (bool, error) {
+		return expected == "-" && got == "N/A", nil
+	})
+	or := Or(Equal(0), Equal("-"))
+	assertion, err := Build(context.Background(), or, WithEqualers(eq))
+	if err != nil {
+		t.Fatalf("failed to build: %s", err)
+	}
+	if err := assertion.Assert("N/A"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := assertion.Assert("unknown"); err == nil {
+		t.Error("expect error but no error")
+	}
+	if err := or.Assert("N/A"); err == nil {
+		t.Error("expect error without the build options but no error")
+	}
+}
+
+func TestNot(t *testing.T) {
+	tests := map[string]struct {
+		assertion Assertion
+		ok        interface{}
+		ng        interface{}
+	}{
+		"equal": {
+			assertion: Equal(500),
+			ok:        200,
+			ng:        500,
+		},
+		"contains": {
+			assertion: Contains("panic"),
+			ok:        "internal server error",
+			ng:        "panic: runtime error",
+		},
+		"nested": {
+			assertion: Not(Regexp("^[0-9]+$")),
+			ok:        "123",
+			ng:        "abc",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			not := Not(test.assertion)
+			if err := not.Assert(test.ok); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if err := not.Assert(test.ng); err == nil {
+				t.Error("expect error but no error")
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := Not(Equal(500)).Assert(500)
+		if err == nil {
+			t.Fatal("expect error but no error")
+		}
+		if got, expect := err.Error(), "expected the assertion to fail but it passed with 500"; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+}
@@ -1,35 +1,27 @@
 package assert
 
 import (
-	"errors"
 	"fmt"
 	"regexp"
 )
 
 // Regexp returns an assertion to ensure a value matches the regular expression pattern.
+// The pattern is compiled only once, and Build fails if the pattern is invalid.
 func Regexp(expr string) Assertion {
 	pattern, err := regexp.Compile(expr)
 	if err != nil {
-		return AssertionFunc(func(v interface{}) error {
-			return err
-		})
+		return &invalidAssertion{
+			err: fmt.Errorf("failed to compile the regular expression %q: %w", expr, err),
+		}
 	}
 	return AssertionFunc(func(v interface{}) error {
-		if s, ok := v.(string); ok {
-			if pattern.MatchString(s) {
-				return nil
-			}
-			return fmt.Errorf(`does not match the pattern "%s"`, expr)
-		}
-
-		s, err := convert(v, "")
+		s, err := toString(v)
 		if err != nil {
-			return errors.New("expect string")
+			return err
 		}
-
 		if pattern.MatchString(s) {
 			return nil
 		}
-		return fmt.Errorf(`does not match the pattern "%s"`, expr)
+		return fmt.Errorf(`%q does not match the pattern "%s"`, s, expr)
 	})
 }
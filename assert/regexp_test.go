@@ -1,7 +1,11 @@
 package assert
 
 import (
+	"context"
+	"strings"
 	"testing"
+
+	"github.com/goccy/go-yaml"
 )
 
 func TestRegexp(t *testing.T) {
@@ -57,4 +61,24 @@ func TestRegexp(t *testing.T) {
 			t.Errorf("expected error but no error")
 		}
 	})
+	t.Run("Build fails with invalid pattern", func(t *testing.T) {
+		_, err := Build(context.Background(), yaml.MapSlice{
+			{Key: "id", Value: Regexp("(?a)")},
+		})
+		if err == nil {
+			t.Fatal("expected error but no error")
+		}
+		if got, expect := err.Error(), "failed to build assertion: .id: failed to compile the regular expression \"(?a)\""; !strings.HasPrefix(got, expect) {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+	t.Run("type mismatch", func(t *testing.T) {
+		err := Regexp("true").Assert(true)
+		if err == nil {
+			t.Fatal("expected error but no error")
+		}
+		if got, expect := err.Error(), "expected string but got bool"; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
 }
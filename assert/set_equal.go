@@ -0,0 +1,118 @@
+package assert
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
+)
+
+// SetEqual returns an assertion to ensure a value has the same elements as the expected values regardless of order.
+// Duplicate elements are counted, so [1, 1, 2] doesn't equal [1, 2, 2].
+// The build options such as WithEqualers are applied to compare elements when the assertion is built by Build.
+func SetEqual(expected []interface{}) Assertion {
+	return optional(&setEqualAssertion{
+		expected: expected,
+	})
+}
+
+type setEqualAssertion struct {
+	expected []interface{}
+	opt      *buildOpt
+}
+
+// Assert implements Assertion interface.
+func (a *setEqualAssertion) Assert(v interface{}) error {
+	vv, err := arrayOrSlice(v)
+	if err != nil {
+		return err
+	}
+	actual := make([]interface{}, vv.Len())
+	for i := range actual {
+		actual[i] = vv.Index(i).Interface()
+	}
+	assertions := make([]Assertion, len(a.expected))
+	for i, e := range a.expected {
+		assertions[i] = elemAssertion(e, nil, a.opt)
+	}
+
+	missingIdx, unexpectedIdx := matchElements(assertions, actual)
+	missing := make([]string, len(missingIdx))
+	for i, idx := range missingIdx {
+		missing[i] = fmt.Sprintf("%#v", a.expected[idx])
+	}
+	unexpected := make([]string, len(unexpectedIdx))
+	for i, idx := range unexpectedIdx {
+		unexpected[i] = fmt.Sprintf("%#v", actual[idx])
+	}
+	if len(missing) == 0 && len(unexpected) == 0 {
+		return nil
+	}
+	var msgs []string
+	if len(missing) > 0 {
+		msgs = append(msgs, fmt.Sprintf("missing elements [%s]", strings.Join(missing, ", ")))
+	}
+	if len(unexpected) > 0 {
+		msgs = append(msgs, fmt.Sprintf("unexpected elements [%s]", strings.Join(unexpected, ", ")))
+	}
+	return errors.Errorf("elements don't match: %s", strings.Join(msgs, ", "))
+}
+
+// AssertsList implements ListAssertion interface.
+func (a *setEqualAssertion) AssertsList() {}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *setEqualAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	return &setEqualAssertion{
+		expected: a.expected,
+		opt:      opt,
+	}
+}
+
+// matchElements finds the maximum matching between assertions and actual elements.
+// It returns the indexes of assertions that don't match any element and the indexes of unmatched elements.
+func matchElements(assertions []Assertion, actual []interface{}) ([]int, []int) {
+	matched := make([]int, len(actual)) // index of the assertion matched with the element
+	for i := range matched {
+		matched[i] = -1
+	}
+	var match func(a int, visited []bool) bool
+	match = func(a int, visited []bool) bool {
+		for i, elem := range actual {
+			if visited[i] || assertions[a].Assert(elem) != nil {
+				continue
+			}
+			visited[i] = true
+			if matched[i] < 0 || match(matched[i], visited) {
+				matched[i] = a
+				return true
+			}
+		}
+		return false
+	}
+	var missing []int
+	for a := range assertions {
+		if !match(a, make([]bool, len(actual))) {
+			missing = append(missing, a)
+		}
+	}
+	var unexpected []int
+	for i, a := range matched {
+		if a < 0 {
+			unexpected = append(unexpected, i)
+		}
+	}
+	return missing, unexpected
+}
+
+func arrayOrSlice(v interface{}) (reflect.Value, error) {
+	vv := reflectutil.Elem(reflect.ValueOf(v))
+	switch vv.Kind() {
+	case reflect.Array, reflect.Slice:
+	default:
+		return reflect.Value{}, errors.Errorf("expected an array but got %T", v)
+	}
+	return vv, nil
+}
@@ -0,0 +1,94 @@
+package assert
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSetEqual(t *testing.T) {
+	tests := map[string]struct {
+		expected []interface{}
+		ok       []interface{}
+		ng       []interface{}
+	}{
+		"empty": {
+			expected: []interface{}{},
+			ok:       []interface{}{[]int{}, []string(nil)},
+			ng:       []interface{}{[]int{1}, "not array"},
+		},
+		"ignore order": {
+			expected: []interface{}{1, 2, 3},
+			ok:       []interface{}{[]int{1, 2, 3}, []int{3, 1, 2}, []interface{}{json.Number("2"), 3, uint8(1)}},
+			ng:       []interface{}{[]int{1, 2}, []int{1, 2, 3, 4}, []int{1, 2, 4}},
+		},
+		"duplicates": {
+			expected: []interface{}{1, 1, 2},
+			ok:       []interface{}{[]int{1, 2, 1}},
+			ng:       []interface{}{[]int{1, 2, 2}, []int{1, 2}, []int{1, 1, 1, 2}},
+		},
+		"assertions": {
+			expected: []interface{}{Greater(1), Equal(2)},
+			ok:       []interface{}{[]int{2, 3}, []int{3, 2}},
+			ng:       []interface{}{[]int{3, 3}, []int{1, 2}},
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assertion := SetEqual(tc.expected)
+			for _, v := range tc.ok {
+				if err := assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range tc.ng {
+				if err := assertion.Assert(v); err == nil {
+					t.Errorf("%v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := SetEqual([]interface{}{"a", "b", "b"}).Assert([]string{"b", "c", "a"})
+		if err == nil {
+			t.Fatal("expected error but no error")
+		}
+		if got, expect := err.Error(), `elements don't match: missing elements ["b"], unexpected elements ["c"]`; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+
+	t.Run("with equalers", func(t *testing.T) {
+		eq := EqualerFunc(func(expected, got interface{}) (bool, error) {
+			e, ok := expected.(string)
+			if !ok {
+				return false, nil
+			}
+			g, ok := got.(string)
+			if !ok {
+				return false, nil
+			}
+			return true, Equal(strings.ToLower(e)).Assert(strings.ToLower(g))
+		})
+		assertion, err := Build(context.Background(), SetEqual([]interface{}{"A", "B"}), WithEqualers(eq))
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		if err := assertion.Assert([]string{"b", "a"}); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("with build options", func(t *testing.T) {
+		assertion, err := Build(context.Background(), SetEqual([]interface{}{"A", Equal("B")}), WithCaseInsensitive())
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		if err := assertion.Assert([]string{"b", "a"}); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+}
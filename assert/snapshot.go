@@ -0,0 +1,138 @@
+package assert
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
+)
+
+// snapshotLocks serializes the reads and writes of each snapshot file in the process.
+var snapshotLocks sync.Map
+
+// WithSnapshotDir is a build option that sets the directory of the snapshot files of Snapshot.
+func WithSnapshotDir(dir string) BuildOpt {
+	return func(opt *buildOpt) {
+		opt.snapshotDir = dir
+	}
+}
+
+// WithUpdateSnapshots is a build option that enables Snapshot to rewrite the snapshot files by the actual values instead of comparing them.
+func WithUpdateSnapshots(enabled bool) BuildOpt {
+	return func(opt *buildOpt) {
+		opt.updateSnapshots = enabled
+	}
+}
+
+// Snapshot returns an assertion to ensure a value equals the snapshot stored as <name>.yaml in the directory set by WithSnapshotDir.
+// The value is serialized as YAML with sorted map keys, and the mismatch is reported as the diff of DeepEqual.
+// If WithUpdateSnapshots is enabled, it writes the value to the snapshot file and passes.
+func Snapshot(name string) Assertion {
+	return optional(&snapshotAssertion{
+		name: name,
+	})
+}
+
+type snapshotAssertion struct {
+	name   string
+	dir    string
+	update bool
+}
+
+// Assert implements Assertion interface.
+func (a *snapshotAssertion) Assert(v interface{}) error {
+	if a.name == "" || a.name != filepath.Base(a.name) || a.name == ".." {
+		return errors.Errorf("invalid snapshot name %q", a.name)
+	}
+	if a.dir == "" {
+		return errors.Errorf("snapshot directory of %q is not set", a.name)
+	}
+	b, err := yaml.Marshal(sortKeys(v))
+	if err != nil {
+		return errors.Errorf("failed to serialize snapshot %q: %s", a.name, err)
+	}
+	path := filepath.Join(a.dir, a.name+".yaml")
+	mu, _ := snapshotLocks.LoadOrStore(path, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+	if a.update {
+		return writeSnapshot(path, b)
+	}
+	stored, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Errorf("failed to read snapshot %q (update snapshots to create it): %s", a.name, err)
+	}
+	var expected, actual interface{}
+	if err := yaml.UnmarshalWithOptions(stored, &expected, yaml.UseOrderedMap()); err != nil {
+		return errors.Errorf("failed to decode snapshot %q: %s", a.name, err)
+	}
+	if err := yaml.UnmarshalWithOptions(b, &actual, yaml.UseOrderedMap()); err != nil {
+		return errors.Errorf("failed to decode snapshot %q: %s", a.name, err)
+	}
+	return DeepEqual(expected).Assert(actual)
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *snapshotAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	return &snapshotAssertion{
+		name:   a.name,
+		dir:    opt.snapshotDir,
+		update: opt.updateSnapshots,
+	}
+}
+
+// writeSnapshot writes the snapshot file via a temporary file to avoid leaving a partially written file.
+func writeSnapshot(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Errorf("failed to update snapshot: %s", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), fmt.Sprintf(".%s.*", filepath.Base(path)))
+	if err != nil {
+		return errors.Errorf("failed to update snapshot: %s", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return errors.Errorf("failed to update snapshot: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Errorf("failed to update snapshot: %s", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return errors.Errorf("failed to update snapshot: %s", err)
+	}
+	return nil
+}
+
+// sortKeys converts the maps in v into yaml.MapSlice with sorted keys to serialize v deterministically.
+func sortKeys(v interface{}) interface{} {
+	if keys, m, ok := mapEntries(v); ok {
+		ms := make(yaml.MapSlice, 0, len(keys))
+		if _, ordered := v.(yaml.MapSlice); ordered {
+			sort.Strings(keys)
+		}
+		for _, k := range keys {
+			ms = append(ms, yaml.MapItem{Key: k, Value: sortKeys(m[k])})
+		}
+		return ms
+	}
+	vv := reflectutil.Elem(reflect.ValueOf(v))
+	if vv.Kind() == reflect.Slice || vv.Kind() == reflect.Array {
+		if _, ok := v.([]byte); ok {
+			return v
+		}
+		elems := make([]interface{}, vv.Len())
+		for i := range elems {
+			elems[i] = sortKeys(vv.Index(i).Interface())
+		}
+		return elems
+	}
+	return v
+}
@@ -0,0 +1,135 @@
+package assert
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "__snapshots__")
+	build := func(t *testing.T, name string, update bool) Assertion {
+		t.Helper()
+		assertion, err := Build(context.Background(), Snapshot(name), WithSnapshotDir(dir), WithUpdateSnapshots(update))
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		return assertion
+	}
+	user := map[string]interface{}{
+		"name": "alice",
+		"id":   1,
+		"tags": []interface{}{
+			yaml.MapSlice{{Key: "z", Value: 1}, {Key: "a", Value: 2}},
+		},
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		err := build(t, "user", false).Assert(user)
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if expect := `failed to read snapshot "user" (update snapshots to create it)`; !strings.Contains(err.Error(), expect) {
+			t.Errorf("%q not found in %q", expect, err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		if err := build(t, "user", true).Assert(user); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		b, err := os.ReadFile(filepath.Join(dir, "user.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := "id: 1\nname: alice\ntags:\n- a: 2\n  z: 1\n"
+		if diff := cmp.Diff(expect, string(b)); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("match", func(t *testing.T) {
+		if err := build(t, "user", false).Assert(user); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		err := build(t, "user", false).Assert(map[string]interface{}{
+			"name": "bob",
+			"id":   1,
+			"tags": []interface{}{map[string]interface{}{"a": 2, "z": 1}},
+		})
+		if err == nil {
+			t.Fatal("no error")
+		}
+		expect := strings.Join([]string{
+			`values differ (-expected +actual):`,
+			`  id: 1`,
+			`- name: "alice"`,
+			`+ name: "bob"`,
+			`  tags:`,
+			`    -`,
+			`      a: 2`,
+			`      z: 1`,
+		}, "\n")
+		if diff := cmp.Diff(expect, err.Error()); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, name := range []string{"", "../user", "a/b"} {
+			if err := build(t, name, true).Assert(1); err == nil {
+				t.Errorf("%q: no error", name)
+			}
+		}
+		if err := Snapshot("user").Assert(1); err == nil {
+			t.Error("no error without snapshot directory")
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			i := i
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				if err := build(t, "concurrent", true).Assert(map[string]interface{}{"i": i}); err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				_ = build(t, "concurrent", false).Assert(map[string]interface{}{"i": i})
+			}()
+		}
+		wg.Wait()
+		b, err := os.ReadFile(filepath.Join(dir, "concurrent.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v map[string]int
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			t.Fatalf("broken snapshot: %s", err)
+		}
+		if expect := fmt.Sprintf("i: %d\n", v["i"]); string(b) != expect {
+			t.Errorf("expect %q but got %q", expect, b)
+		}
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 2 {
+			t.Errorf("temporary files are left: %v", files)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package assert
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// The classes of the status codes for StatusClass, which are the hundreds digits of the codes.
+const (
+	StatusClassInformational = 1
+	StatusClassSuccessful    = 2
+	StatusClassRedirection   = 3
+	StatusClassClientError   = 4
+	StatusClassServerError   = 5
+)
+
+// StatusClass returns an assertion to ensure a status code belongs to the class such as 2 for 2xx.
+// The code can be an integer or a numeric string such as "200".
+func StatusClass(class int) Assertion {
+	if class < StatusClassInformational || class > StatusClassServerError {
+		return &invalidAssertion{
+			err: errors.Errorf("invalid status class %d: class must be between %d and %d", class, StatusClassInformational, StatusClassServerError),
+		}
+	}
+	return AssertionFunc(func(v interface{}) error {
+		code, err := statusCode(v)
+		if err != nil {
+			return err
+		}
+		if code/100 != int64(class) {
+			return errors.Errorf("expected %dxx status code but got %d", class, code)
+		}
+		return nil
+	})
+}
+
+// Status1xx returns an assertion to ensure a status code is informational (1xx).
+func Status1xx() Assertion {
+	return StatusClass(StatusClassInformational)
+}
+
+// Status2xx returns an assertion to ensure a status code is successful (2xx).
+func Status2xx() Assertion {
+	return StatusClass(StatusClassSuccessful)
+}
+
+// Status3xx returns an assertion to ensure a status code is redirection (3xx).
+func Status3xx() Assertion {
+	return StatusClass(StatusClassRedirection)
+}
+
+// Status4xx returns an assertion to ensure a status code is client error (4xx).
+func Status4xx() Assertion {
+	return StatusClass(StatusClassClientError)
+}
+
+// Status5xx returns an assertion to ensure a status code is server error (5xx).
+func Status5xx() Assertion {
+	return StatusClass(StatusClassServerError)
+}
+
+func statusCode(v interface{}) (int64, error) {
+	var (
+		code int64
+		err  error
+	)
+	switch n := v.(type) {
+	case string:
+		code, err = strconv.ParseInt(n, 10, 64)
+	case json.Number:
+		code, err = n.Int64()
+	default:
+		if !isNumber(v) || !isKindOfInt(v) {
+			return 0, errors.Errorf("expected status code but got %s (%T)", typeKind(v), v)
+		}
+		var f float64
+		f, err = toFloat64(v)
+		code = int64(f)
+	}
+	if err != nil || code < 100 || code > 999 {
+		return 0, errors.Errorf("expected status code but got %v", v)
+	}
+	return code, nil
+}
@@ -0,0 +1,100 @@
+package assert
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStatusClass(t *testing.T) {
+	tests := map[string]struct {
+		assertion Assertion
+		ok        []interface{}
+		ng        []interface{}
+	}{
+		"1xx": {
+			assertion: Status1xx(),
+			ok:        []interface{}{100, "101"},
+			ng:        []interface{}{200, "99"},
+		},
+		"2xx": {
+			assertion: Status2xx(),
+			ok:        []interface{}{200, 299, "204", int64(201), uint16(202), json.Number("200")},
+			ng:        []interface{}{199, 300, "404", "OK", 200.5, json.Number("2e2x"), nil, true},
+		},
+		"3xx": {
+			assertion: Status3xx(),
+			ok:        []interface{}{301, "304"},
+			ng:        []interface{}{200, "400"},
+		},
+		"4xx": {
+			assertion: StatusClass(StatusClassClientError),
+			ok:        []interface{}{400, "404", 499},
+			ng:        []interface{}{500, "302"},
+		},
+		"5xx": {
+			assertion: Status5xx(),
+			ok:        []interface{}{500, "503"},
+			ng:        []interface{}{404, "5000"},
+		},
+		"invalid class": {
+			assertion: StatusClass(6),
+			ng:        []interface{}{600, "600"},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			for _, v := range test.ok {
+				if err := test.assertion.Assert(v); err != nil {
+					t.Errorf("%#v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range test.ng {
+				if err := test.assertion.Assert(v); err == nil {
+					t.Errorf("%#v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		tests := map[string]struct {
+			assertion Assertion
+			v         interface{}
+			expect    string
+		}{
+			"wrong class": {
+				assertion: Status2xx(),
+				v:         "404",
+				expect:    "expected 2xx status code but got 404",
+			},
+			"not status code": {
+				assertion: Status2xx(),
+				v:         true,
+				expect:    "expected status code but got bool (bool)",
+			},
+			"invalid status code": {
+				assertion: Status2xx(),
+				v:         "OK",
+				expect:    "expected status code but got OK",
+			},
+			"invalid class": {
+				assertion: StatusClass(0),
+				v:         200,
+				expect:    "invalid status class 0: class must be between 1 and 5",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				err := test.assertion.Assert(test.v)
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if got := err.Error(); got != test.expect {
+					t.Errorf("expected %q but got %q", test.expect, got)
+				}
+			})
+		}
+	})
+}
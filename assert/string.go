@@ -0,0 +1,133 @@
+package assert
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
+)
+
+// HasPrefix returns an assertion to ensure a string value begins with the prefix.
+func HasPrefix(prefix string) Assertion {
+	return optional(&affixAssertion{affix: prefix})
+}
+
+// HasSuffix returns an assertion to ensure a string value ends with the suffix.
+func HasSuffix(suffix string) Assertion {
+	return optional(&affixAssertion{affix: suffix, suffix: true})
+}
+
+type affixAssertion struct {
+	affix           string
+	suffix          bool
+	caseInsensitive bool
+}
+
+// Assert implements Assertion interface.
+func (a *affixAssertion) Assert(v interface{}) error {
+	s, err := toString(v)
+	if err != nil {
+		return err
+	}
+	if a.suffix {
+		if hasSuffix(s, a.affix, a.caseInsensitive) {
+			return nil
+		}
+		return fmt.Errorf("expected %q to have the suffix %q", s, a.affix)
+	}
+	if hasPrefix(s, a.affix, a.caseInsensitive) {
+		return nil
+	}
+	return fmt.Errorf("expected %q to have the prefix %q", s, a.affix)
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *affixAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	return &affixAssertion{
+		affix:           a.affix,
+		suffix:          a.suffix,
+		caseInsensitive: opt.caseInsensitive,
+	}
+}
+
+func hasPrefix(s, prefix string, fold bool) bool {
+	if !fold {
+		return strings.HasPrefix(s, prefix)
+	}
+	for prefix != "" {
+		if s == "" {
+			return false
+		}
+		r1, n1 := utf8.DecodeRuneInString(s)
+		r2, n2 := utf8.DecodeRuneInString(prefix)
+		if !equalFoldRune(r1, r2) {
+			return false
+		}
+		s, prefix = s[n1:], prefix[n2:]
+	}
+	return true
+}
+
+func hasSuffix(s, suffix string, fold bool) bool {
+	if !fold {
+		return strings.HasSuffix(s, suffix)
+	}
+	for suffix != "" {
+		if s == "" {
+			return false
+		}
+		r1, n1 := utf8.DecodeLastRuneInString(s)
+		r2, n2 := utf8.DecodeLastRuneInString(suffix)
+		if !equalFoldRune(r1, r2) {
+			return false
+		}
+		s, suffix = s[:len(s)-n1], suffix[:len(suffix)-n2]
+	}
+	return true
+}
+
+// indexString returns the byte index of the first instance of substr in s, or -1 if substr is not present in s.
+func indexString(s, substr string, fold bool) int {
+	if !fold {
+		return strings.Index(s, substr)
+	}
+	for i := range s {
+		if hasPrefix(s[i:], substr, true) {
+			return i
+		}
+	}
+	if substr == "" {
+		return len(s)
+	}
+	return -1
+}
+
+func equalFoldRune(r1, r2 rune) bool {
+	return strings.EqualFold(string(r1), string(r2))
+}
+
+// bothStrings returns the string values if both x and y are strings.
+func bothStrings(x, y interface{}) (string, string, bool) {
+	xv, yv := reflect.ValueOf(x), reflect.ValueOf(y)
+	if xv.Kind() != reflect.String || yv.Kind() != reflect.String {
+		return "", "", false
+	}
+	return xv.String(), yv.String(), true
+}
+
+// toString converts v into a string if it is a string or a byte slice.
+func toString(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	rv := reflectutil.Elem(reflect.ValueOf(v))
+	switch {
+	case rv.Kind() == reflect.String:
+		return rv.String(), nil
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		return string(rv.Bytes()), nil
+	}
+	return "", fmt.Errorf("expected string but got %T", v)
+}
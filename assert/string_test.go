@@ -0,0 +1,119 @@
+package assert
+
+import (
+	"testing"
+)
+
+func TestHasPrefix(t *testing.T) {
+	type myString string
+	tests := map[string]struct {
+		prefix string
+		ok     interface{}
+		ng     interface{}
+	}{
+		"simple": {
+			prefix: "https://",
+			ok:     "https://example.com",
+			ng:     "http://example.com",
+		},
+		"empty prefix": {
+			prefix: "",
+			ok:     "",
+			ng:     0,
+		},
+		"[]byte": {
+			prefix: "a",
+			ok:     []byte("abc"),
+			ng:     []byte("cba"),
+		},
+		"string (type conversion)": {
+			prefix: "a",
+			ok:     myString("abc"),
+			ng:     myString("cba"),
+		},
+		"must be a string": {
+			prefix: "true",
+			ok:     "true",
+			ng:     true,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assertion := HasPrefix(tc.prefix)
+			if err := assertion.Assert(tc.ok); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if err := assertion.Assert(tc.ng); err == nil {
+				t.Errorf("expected error but no error")
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := HasPrefix("https://").Assert("http://example.com")
+		if err == nil {
+			t.Fatal("expected error but no error")
+		}
+		if got, expect := err.Error(), `expected "http://example.com" to have the prefix "https://"`; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+}
+
+func TestHasSuffix(t *testing.T) {
+	type myString string
+	tests := map[string]struct {
+		suffix string
+		ok     interface{}
+		ng     interface{}
+	}{
+		"simple": {
+			suffix: ".json",
+			ok:     "data.json",
+			ng:     "data.yaml",
+		},
+		"empty suffix": {
+			suffix: "",
+			ok:     "",
+			ng:     0,
+		},
+		"[]byte": {
+			suffix: "c",
+			ok:     []byte("abc"),
+			ng:     []byte("cba"),
+		},
+		"string (type conversion)": {
+			suffix: "c",
+			ok:     myString("abc"),
+			ng:     myString("cba"),
+		},
+		"must be a string": {
+			suffix: "true",
+			ok:     "true",
+			ng:     true,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assertion := HasSuffix(tc.suffix)
+			if err := assertion.Assert(tc.ok); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if err := assertion.Assert(tc.ng); err == nil {
+				t.Errorf("expected error but no error")
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := HasSuffix(".json").Assert("data.yaml")
+		if err == nil {
+			t.Fatal("expected error but no error")
+		}
+		if got, expect := err.Error(), `expected "data.yaml" to have the suffix ".json"`; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package assert
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
+)
+
+// Subset returns an assertion to ensure a value includes the expected value partially.
+// If the expected value is a map, the value must have all keys of the map with the matching values, and extra keys are ignored.
+// If the expected value is an array or a slice, each expected element must match a distinct element of the value regardless of order.
+// Nested maps and slices are also compared as subsets.
+// The equalers passed by WithEqualers are used to compare values when the assertion is built by Build.
+func Subset(expected interface{}) Assertion {
+	return optional(&subsetAssertion{
+		expected: expected,
+	})
+}
+
+type subsetAssertion struct {
+	expected interface{}
+	eqs      []Equaler
+}
+
+// Assert implements Assertion interface.
+func (a *subsetAssertion) Assert(v interface{}) error {
+	return subset(a.expected, v, a.eqs)
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *subsetAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	return &subsetAssertion{
+		expected: a.expected,
+		eqs:      append(append([]Equaler{}, a.eqs...), opt.eqs...),
+	}
+}
+
+func subset(expected, v interface{}, eqs []Equaler) error {
+	switch e := expected.(type) {
+	case Assertion:
+		return e.Assert(v)
+	case yaml.MapSlice:
+		items := make([]mapItem, len(e))
+		for i, item := range e {
+			items[i] = mapItem{key: fmt.Sprint(item.Key), value: item.Value}
+		}
+		return subsetMap(items, v, eqs)
+	case []byte:
+		return Equal(expected, eqs...).Assert(v)
+	}
+
+	ev := reflectutil.Elem(reflect.ValueOf(expected))
+	switch ev.Kind() {
+	case reflect.Map:
+		items := make([]mapItem, 0, ev.Len())
+		iter := ev.MapRange()
+		for iter.Next() {
+			items = append(items, mapItem{key: fmt.Sprint(iter.Key().Interface()), value: iter.Value().Interface()})
+		}
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].key < items[j].key
+		})
+		return subsetMap(items, v, eqs)
+	case reflect.Array, reflect.Slice:
+		vv, err := arrayOrSlice(v)
+		if err != nil {
+			return err
+		}
+		assertions := make([]Assertion, ev.Len())
+		for i := range assertions {
+			assertions[i] = &subsetAssertion{
+				expected: ev.Index(i).Interface(),
+				eqs:      eqs,
+			}
+		}
+		actual := make([]interface{}, vv.Len())
+		for i := range actual {
+			actual[i] = vv.Index(i).Interface()
+		}
+		missing, _ := matchElements(assertions, actual)
+		if len(missing) == 0 {
+			return nil
+		}
+		errs := make([]error, len(missing))
+		for i, idx := range missing {
+			errs[i] = errors.Errorf("no element matches the expected element [%d] %#v", idx, ev.Index(idx).Interface())
+		}
+		return errors.Errors(errs...)
+	default:
+		return Equal(expected, eqs...).Assert(v)
+	}
+}
+
+type mapItem struct {
+	key   string
+	value interface{}
+}
+
+func subsetMap(items []mapItem, v interface{}, eqs []Equaler) error {
+	var errs []error
+	for _, item := range items {
+		got, err := newQuery().Key(item.key).Extract(v)
+		if err != nil {
+			errs = append(errs, errors.ErrorPath(item.key, "missing key"))
+			continue
+		}
+		if err := subset(item.value, got, eqs); err != nil {
+			errs = append(errs, errors.WithPath(err, item.key))
+		}
+	}
+	return errors.Errors(errs...)
+}
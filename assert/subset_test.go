@@ -0,0 +1,128 @@
+package assert
+
+import (
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestSubset(t *testing.T) {
+	tests := map[string]struct {
+		expected interface{}
+		ok       []interface{}
+		ng       []interface{}
+	}{
+		"map": {
+			expected: map[string]interface{}{
+				"id": 1,
+			},
+			ok: []interface{}{
+				map[string]interface{}{"id": 1},
+				map[string]interface{}{"id": 1, "name": "foo"},
+				yaml.MapSlice{{Key: "name", Value: "foo"}, {Key: "id", Value: 1}},
+				struct {
+					ID   int    `json:"id"`
+					Name string `json:"name"`
+				}{ID: 1, Name: "foo"},
+			},
+			ng: []interface{}{
+				map[string]interface{}{"id": 2, "name": "foo"},
+				map[string]interface{}{"name": "foo"},
+				"id",
+			},
+		},
+		"nested map": {
+			expected: yaml.MapSlice{
+				{Key: "user", Value: yaml.MapSlice{
+					{Key: "name", Value: "foo"},
+				}},
+			},
+			ok: []interface{}{
+				map[string]interface{}{
+					"user": map[string]interface{}{"id": 1, "name": "foo"},
+				},
+			},
+			ng: []interface{}{
+				map[string]interface{}{
+					"user": map[string]interface{}{"id": 1, "name": "bar"},
+				},
+			},
+		},
+		"slice": {
+			expected: []interface{}{2, 1},
+			ok: []interface{}{
+				[]int{1, 2},
+				[]int{3, 2, 1},
+			},
+			ng: []interface{}{
+				[]int{1},
+				[]int{1, 3},
+				1,
+			},
+		},
+		"slice of maps": {
+			expected: []interface{}{
+				map[string]interface{}{"name": "bar"},
+			},
+			ok: []interface{}{
+				[]interface{}{
+					map[string]interface{}{"id": 1, "name": "foo"},
+					map[string]interface{}{"id": 2, "name": "bar"},
+				},
+			},
+			ng: []interface{}{
+				[]interface{}{
+					map[string]interface{}{"id": 1, "name": "foo"},
+				},
+			},
+		},
+		"assertion": {
+			expected: map[string]interface{}{
+				"id": Greater(0),
+			},
+			ok: []interface{}{
+				map[string]interface{}{"id": 1},
+			},
+			ng: []interface{}{
+				map[string]interface{}{"id": 0},
+			},
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assertion := Subset(tc.expected)
+			for _, v := range tc.ok {
+				if err := assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range tc.ng {
+				if err := assertion.Assert(v); err == nil {
+					t.Errorf("%v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := Subset(map[string]interface{}{
+			"id": 1,
+			"user": map[string]interface{}{
+				"name": "foo",
+			},
+		}).Assert(map[string]interface{}{
+			"user": map[string]interface{}{
+				"name": "bar",
+			},
+		})
+		if err == nil {
+			t.Fatal("expected error but no error")
+		}
+		expect := `2 errors occurred: .id: missing key
+1 error occurred: .user.name: expected foo but got bar`
+		if got := err.Error(); got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package assert
+
+import (
+	"time"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// Before returns an assertion to ensure a time value is before the expected time.
+// If the value is a string, it is parsed as RFC3339 unless another layout is specified by WithTimeLayout.
+func Before(t time.Time) Assertion {
+	return optional(&timeAssertion{
+		expected: t,
+		before:   true,
+	})
+}
+
+// After returns an assertion to ensure a time value is after the expected time.
+// If the value is a string, it is parsed as RFC3339 unless another layout is specified by WithTimeLayout.
+func After(t time.Time) Assertion {
+	return optional(&timeAssertion{
+		expected: t,
+	})
+}
+
+// timeMatcher returns the matcher of f which also accepts an RFC3339 string such as the result of now() in templates.
+func timeMatcher(f func(time.Time) Assertion) func(interface{}) Assertion {
+	return func(v interface{}) Assertion {
+		t, err := parseTime(v, "")
+		if err != nil {
+			return &invalidAssertion{err: err}
+		}
+		return f(t)
+	}
+}
+
+type timeAssertion struct {
+	expected time.Time
+	before   bool
+	layout   string
+}
+
+// Assert implements Assertion interface.
+func (a *timeAssertion) Assert(v interface{}) error {
+	got, err := parseTime(v, a.layout)
+	if err != nil {
+		return err
+	}
+	if a.before {
+		if got.Before(a.expected) {
+			return nil
+		}
+		return errors.Errorf("expected %s to be before %s", got.Format(time.RFC3339Nano), a.expected.Format(time.RFC3339Nano))
+	}
+	if got.After(a.expected) {
+		return nil
+	}
+	return errors.Errorf("expected %s to be after %s", got.Format(time.RFC3339Nano), a.expected.Format(time.RFC3339Nano))
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *timeAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	if opt.timeLayout == "" {
+		return a
+	}
+	return &timeAssertion{
+		expected: a.expected,
+		before:   a.before,
+		layout:   opt.timeLayout,
+	}
+}
+
+// parseTime converts v into time.Time.
+// If v is a string, it is parsed with the layout. The default layout is time.RFC3339.
+func parseTime(v interface{}, layout string) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v != nil {
+			return *v, nil
+		}
+	}
+	s, err := toString(v)
+	if err != nil {
+		return time.Time{}, errors.Errorf("expected time but got %T", v)
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, errors.Errorf("failed to parse %q as time: %s", s, err)
+	}
+	return t, nil
+}
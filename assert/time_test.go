@@ -0,0 +1,92 @@
+package assert
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBefore(t *testing.T) {
+	base := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		ok []interface{}
+		ng []interface{}
+	}{
+		"string": {
+			ok: []interface{}{"2023-11-01T11:59:59Z", "2023-11-01T20:59:59+09:00", "2023-11-01T11:59:59.999999999Z"},
+			ng: []interface{}{"2023-11-01T12:00:00Z", "2023-11-01T12:00:01Z"},
+		},
+		"time.Time": {
+			ok: []interface{}{base.Add(-time.Second)},
+			ng: []interface{}{base.Add(time.Second), &base},
+		},
+		"invalid": {
+			ng: []interface{}{"2023-11-01", 1, nil},
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assertion := Before(base)
+			for _, v := range tc.ok {
+				if err := assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range tc.ng {
+				if err := assertion.Assert(v); err == nil {
+					t.Errorf("%v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := Before(base).Assert("2023-11-01T12:00:01Z")
+		if err == nil {
+			t.Fatal("expected error but no error")
+		}
+		if got, expect := err.Error(), "expected 2023-11-01T12:00:01Z to be before 2023-11-01T12:00:00Z"; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+}
+
+func TestAfter(t *testing.T) {
+	base := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	assertion := After(base)
+	for _, v := range []interface{}{"2023-11-01T12:00:01Z", base.Add(time.Nanosecond)} {
+		if err := assertion.Assert(v); err != nil {
+			t.Errorf("%v: unexpected error: %s", v, err)
+		}
+	}
+	for _, v := range []interface{}{"2023-11-01T12:00:00Z", "2023-11-01T11:00:00Z", "invalid"} {
+		if err := assertion.Assert(v); err == nil {
+			t.Errorf("%v: expected error but no error", v)
+		}
+	}
+	err := assertion.Assert("invalid")
+	if err == nil {
+		t.Fatal("expected error but no error")
+	}
+	if got, expect := err.Error(), `failed to parse "invalid" as time: parsing time "invalid" as "2006-01-02T15:04:05Z07:00": cannot parse "invalid" as "2006"`; got != expect {
+		t.Errorf("expect %q but got %q", expect, got)
+	}
+}
+
+func TestWithTimeLayout(t *testing.T) {
+	base := time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)
+	assertion, err := Build(context.Background(), And(After(base), Before(base.AddDate(0, 0, 2))), WithTimeLayout(time.DateOnly))
+	if err != nil {
+		t.Fatalf("failed to build: %s", err)
+	}
+	if err := assertion.Assert("2023-11-02"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := assertion.Assert("2023-11-03"); err == nil {
+		t.Error("expected error but no error")
+	}
+	if err := assertion.Assert("2023-11-02T00:00:00Z"); err == nil {
+		t.Error("expected error but no error")
+	}
+}
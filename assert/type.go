@@ -0,0 +1,114 @@
+package assert
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// typeKinds are the type names that IsType accepts.
+var typeKinds = []string{"string", "number", "bool", "array", "object", "null"}
+
+// IsType returns an assertion to ensure a value is of the JSON type kind.
+// The kind must be one of "string", "number", "bool", "array", "object", and "null".
+func IsType(kind string) Assertion {
+	valid := false
+	for _, k := range typeKinds {
+		if k == kind {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return &invalidAssertion{
+			err: fmt.Errorf("unknown type %q: type must be one of %q", kind, typeKinds),
+		}
+	}
+	return AssertionFunc(func(v interface{}) error {
+		if got := typeKind(v); got != kind {
+			return errors.Errorf("expected %s but got %s (%T)", kind, got, v)
+		}
+		return nil
+	})
+}
+
+// typeKind returns the JSON type name of v.
+func typeKind(v interface{}) string {
+	switch v.(type) {
+	case json.Number:
+		return "number"
+	case yaml.MapSlice:
+		return "object"
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "null"
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return "null"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Array, reflect.Slice:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return "null"
+		}
+		return "array"
+	case reflect.Map:
+		if rv.IsNil() {
+			return "null"
+		}
+		return "object"
+	case reflect.Struct:
+		return "object"
+	default:
+		return rv.Kind().String()
+	}
+}
+
+// integerEpsilon is the tolerance of IsInteger for the errors of floating-point numbers.
+const integerEpsilon = 1e-9
+
+// IsInteger returns an assertion to ensure a number has no fractional part.
+// Floats such as 1.0 pass, and the fractional part within a tiny epsilon is ignored.
+func IsInteger() Assertion {
+	return AssertionFunc(func(v interface{}) error {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			v = rv.Elem().Interface()
+		}
+		if n, ok := v.(json.Number); ok {
+			if _, err := n.Int64(); err == nil {
+				return nil
+			}
+		} else {
+			if !isNumber(v) {
+				return errors.Errorf("expected number but got %s (%T)", typeKind(v), v)
+			}
+			if isKindOfInt(v) {
+				return nil
+			}
+		}
+		f, err := toFloat64(v)
+		if err != nil {
+			return err
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) || math.Abs(f-math.Round(f)) > integerEpsilon {
+			return errors.Errorf("expected integer but got %v", v)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,127 @@
+package assert
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestIsType(t *testing.T) {
+	var nilPtr *int
+	n := 1
+	tests := map[string]struct {
+		kind string
+		ok   []interface{}
+		ng   []interface{}
+	}{
+		"string": {
+			kind: "string",
+			ok:   []interface{}{"", "test"},
+			ng:   []interface{}{1, nil, []string{"test"}},
+		},
+		"number": {
+			kind: "number",
+			ok:   []interface{}{0, int64(1), uint8(1), 1.5, json.Number("1"), &n},
+			ng:   []interface{}{"1", true, nilPtr},
+		},
+		"bool": {
+			kind: "bool",
+			ok:   []interface{}{true, false},
+			ng:   []interface{}{"true", 1},
+		},
+		"array": {
+			kind: "array",
+			ok:   []interface{}{[]interface{}{}, []int{1}, [1]string{"a"}},
+			ng:   []interface{}{[]int(nil), map[string]int{}},
+		},
+		"object": {
+			kind: "object",
+			ok:   []interface{}{map[string]interface{}{}, yaml.MapSlice{}, struct{}{}},
+			ng:   []interface{}{[]interface{}{}, map[string]int(nil)},
+		},
+		"null": {
+			kind: "null",
+			ok:   []interface{}{nil, nilPtr, []int(nil)},
+			ng:   []interface{}{0, ""},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion := IsType(test.kind)
+			for _, v := range test.ok {
+				if err := assertion.Assert(v); err != nil {
+					t.Errorf("%#v: unexpected error: %s", v, err)
+				}
+			}
+			for _, v := range test.ng {
+				if err := assertion.Assert(v); err == nil {
+					t.Errorf("%#v: expected error but no error", v)
+				}
+			}
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := IsType("number").Assert("1")
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if got, expect := err.Error(), "expected number but got string (string)"; got != expect {
+			t.Errorf("expected %q but got %q", expect, got)
+		}
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		if _, err := Build(context.Background(), IsType("integer")); err == nil {
+			t.Error("no error")
+		}
+	})
+}
+
+func TestIsInteger(t *testing.T) {
+	n := 1.5
+	i := 1
+	assertion := IsInteger()
+	for _, v := range []interface{}{0, int64(-1), uint8(1), 1.0, -2.0, float32(3), 1 + 1e-12, json.Number("1"), json.Number("1e3"), json.Number("12345678901234567890"), &i} {
+		if err := assertion.Assert(v); err != nil {
+			t.Errorf("%#v: unexpected error: %s", v, err)
+		}
+	}
+	for _, v := range []interface{}{1.5, -0.1, 1 + 1e-6, math.NaN(), math.Inf(1), json.Number("1.5"), &n, "1", nil, true} {
+		if err := assertion.Assert(v); err == nil {
+			t.Errorf("%#v: expected error but no error", v)
+		}
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		tests := map[string]struct {
+			v      interface{}
+			expect string
+		}{
+			"fraction": {
+				v:      json.Number("12.5"),
+				expect: "expected integer but got 12.5",
+			},
+			"not number": {
+				v:      "12",
+				expect: "expected number but got string (string)",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				err := assertion.Assert(test.v)
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if got := err.Error(); got != test.expect {
+					t.Errorf("expected %q but got %q", test.expect, got)
+				}
+			})
+		}
+	})
+}
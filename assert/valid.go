@@ -0,0 +1,131 @@
+package assert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// ValidJSON returns an assertion to ensure a string value is a valid JSON document.
+// If the assertions are given, they assert the parsed value.
+func ValidJSON(assertions ...Assertion) Assertion {
+	return optional(&validAssertion{
+		format:     "JSON",
+		parse:      parseJSON,
+		assertions: assertions,
+	})
+}
+
+// ValidYAML returns an assertion to ensure a string value is a valid YAML document.
+// If the assertions are given, they assert the parsed value.
+func ValidYAML(assertions ...Assertion) Assertion {
+	return optional(&validAssertion{
+		format:     "YAML",
+		parse:      parseYAML,
+		assertions: assertions,
+	})
+}
+
+type validAssertion struct {
+	format     string
+	parse      func([]byte) (interface{}, error)
+	assertions []Assertion
+}
+
+// Assert implements Assertion interface.
+func (a *validAssertion) Assert(v interface{}) error {
+	s, err := toString(v)
+	if err != nil {
+		return err
+	}
+	parsed, err := a.parse([]byte(s))
+	if err != nil {
+		return errors.Errorf("invalid %s: %s", a.format, err)
+	}
+	var errs []error
+	for _, assertion := range a.assertions {
+		if err := assertion.Assert(parsed); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errors.Errors(errs...)
+}
+
+// withBuildOpt implements optionalAssertion interface.
+func (a *validAssertion) withBuildOpt(opt *buildOpt) Assertion {
+	assertions := make([]Assertion, len(a.assertions))
+	for i, assertion := range a.assertions {
+		if oa, ok := assertion.(optionalAssertion); ok {
+			assertion = oa.withBuildOpt(opt)
+		}
+		assertions[i] = assertion
+	}
+	return &validAssertion{
+		format:     a.format,
+		parse:      a.parse,
+		assertions: assertions,
+	}
+}
+
+func parseJSON(b []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		var serr *json.SyntaxError
+		if errors.As(err, &serr) {
+			line, col := position(b, serr.Offset-1)
+			return nil, fmt.Errorf("line %d, column %d: %w", line, col, err)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("empty document")
+		}
+		return nil, err
+	}
+	offset := d.InputOffset()
+	rest := b[offset:]
+	if trimmed := bytes.TrimLeft(rest, " \t\r\n"); len(trimmed) > 0 {
+		line, col := position(b, offset+int64(len(rest)-len(trimmed)))
+		return nil, fmt.Errorf("line %d, column %d: unexpected data after top-level value", line, col)
+	}
+	return v, nil
+}
+
+func parseYAML(b []byte) (interface{}, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, errors.New(yaml.FormatError(err, false, true))
+	}
+	return v, nil
+}
+
+// position returns the line and column numbers of the byte at the offset in b.
+func position(b []byte, offset int64) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > int64(len(b)) {
+		offset = int64(len(b))
+	}
+	line, col := 1, 1
+	for _, c := range b[:offset] {
+		if c == '\n' {
+			line++
+			col = 1
+			continue
+		}
+		col++
+	}
+	return line, col
+}
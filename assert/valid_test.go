@@ -0,0 +1,71 @@
+package assert
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidJSON(t *testing.T) {
+	tests := map[string]struct {
+		assertion Assertion
+		ok        []interface{}
+		ng        map[interface{}]string
+	}{
+		"valid": {
+			assertion: ValidJSON(),
+			ok:        []interface{}{`{"a": 1}`, `[1, 2]`, `"s"`, " null \n", []byte(`{}`)},
+			ng: map[interface{}]string{
+				`{"a": 1`:           "invalid JSON: unexpected EOF",
+				"{\n  \"a\": 1,\n}": "invalid JSON: line 3, column 1: invalid character '}' looking for beginning of object key string",
+				`{"a": 1} {}`:       "invalid JSON: line 1, column 10: unexpected data after top-level value",
+				``:                  "invalid JSON: empty document",
+				1:                   "expected string but got int",
+			},
+		},
+		"nested assertion": {
+			assertion: ValidJSON(Subset([]interface{}{Equal(1)})),
+			ok:        []interface{}{`[1, 2]`},
+			ng: map[interface{}]string{
+				`[2, 3]`: "no element matches the expected element",
+				`[2, 3`:  "invalid JSON",
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			for _, v := range test.ok {
+				if err := test.assertion.Assert(v); err != nil {
+					t.Errorf("%v: unexpected error: %s", v, err)
+				}
+			}
+			for v, expect := range test.ng {
+				err := test.assertion.Assert(v)
+				if err == nil {
+					t.Errorf("%v: expected error but no error", v)
+					continue
+				}
+				if !strings.Contains(err.Error(), expect) {
+					t.Errorf("%v: %q not found in %q", v, expect, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidYAML(t *testing.T) {
+	assertion := ValidYAML(Equal(map[string]interface{}{"a": uint64(1)}))
+	if err := assertion.Assert("a: 1"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := assertion.Assert("a: 2"); err == nil {
+		t.Error("no error")
+	}
+	err := ValidYAML().Assert("{a: 1")
+	if err == nil {
+		t.Fatal("no error")
+	}
+	if expect := "invalid YAML: [1:1] unterminated flow mapping"; !strings.HasPrefix(err.Error(), expect) {
+		t.Errorf("expect %q but got %q", expect, err)
+	}
+}
@@ -2,8 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
-	"github.com/fatih/color"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/zoncoen/scenarigo"
@@ -15,10 +15,24 @@ import (
 // ErrTestFailed is the error returned when the test failed.
 var ErrTestFailed = errors.New("test failed")
 
-var verbose bool
+var (
+	verbose   int
+	tapReport string
+	parallel  int
+	dryRun    bool
+	ctxValues map[string]string
+	timeout   time.Duration
+	update    bool
+)
 
 func init() {
-	runCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "print verbose log")
+	runCmd.Flags().CountVarP(&verbose, "verbose", "v", "print verbose log (-vv also prints the expected and actual values of each assertion error)")
+	runCmd.Flags().StringVar(&tapReport, "tap", "", "write the test report to the file in TAP format")
+	runCmd.Flags().IntVar(&parallel, "parallel", 0, "run the scenario files in parallel up to the number (default value is the parallel of the configuration or 1)")
+	runCmd.Flags().BoolVar(&dryRun, "dry-run", false, "validate the scenarios without sending requests")
+	runCmd.Flags().DurationVar(&timeout, "timeout", 0, "set the default timeout of the steps which don't set the timeout (default value is the timeout of the configuration or no timeout)")
+	runCmd.Flags().BoolVar(&update, "update", false, "rewrite the golden files of the expected bodies (bodyFile) and the snapshots by the actual values")
+	runCmd.Flags().StringToStringVar(&ctxValues, "ctx", nil, "set the values available as {{ctx.<key>}} in templates (e.g. --ctx tenantID=foo,flag=on)")
 	rootCmd.AddCommand(runCmd)
 }
 
@@ -46,6 +60,33 @@ func run(cmd *cobra.Command, args []string) error {
 	if len(args) > 0 {
 		opts = append(opts, scenarigo.WithScenarios(args...))
 	}
+	if tapReport != "" {
+		opts = append(opts, scenarigo.WithTAPReport(tapReport))
+	}
+	if len(ctxValues) > 0 {
+		values := make(map[string]any, len(ctxValues))
+		for k, v := range ctxValues {
+			values[k] = v
+		}
+		opts = append(opts, scenarigo.WithContextValues(values))
+	}
+	if cmd.Flags().Changed("timeout") {
+		opts = append(opts, scenarigo.WithStepTimeout(timeout))
+	}
+	if update {
+		opts = append(opts, scenarigo.WithUpdateGolden(true))
+	}
+	if dryRun {
+		opts = append(opts, scenarigo.WithDryRun(true))
+	}
+	maxParallel := 1
+	if cfg != nil && cfg.Parallel > 0 {
+		maxParallel = cfg.Parallel
+	}
+	if parallel > 0 {
+		maxParallel = parallel
+		opts = append(opts, scenarigo.WithParallel(parallel))
+	}
 	r, err := scenarigo.NewRunner(opts...)
 	if err != nil {
 		return err
@@ -53,12 +94,18 @@ func run(cmd *cobra.Command, args []string) error {
 
 	reporterOpts := []reporter.Option{
 		reporter.WithWriter(cmd.OutOrStdout()),
+		reporter.WithMaxParallel(maxParallel),
+	}
+	verbosity := reporter.Verbosity(verbose)
+	if cfg != nil && cfg.Output.Verbose && verbosity < reporter.VerbosityVerbose {
+		verbosity = reporter.VerbosityVerbose
 	}
-	if (cfg != nil && cfg.Output.Verbose) || verbose {
-		reporterOpts = append(reporterOpts, reporter.WithVerboseLog())
+	if verbosity > reporter.VerbosityDebug {
+		verbosity = reporter.VerbosityDebug
 	}
+	reporterOpts = append(reporterOpts, reporter.WithVerbosity(verbosity))
 
-	noColor := color.NoColor
+	noColor := !reporter.ColorEnabled()
 	if cfg != nil && cfg.Output.Colored != nil {
 		noColor = !*cfg.Output.Colored
 	}
@@ -8,6 +8,7 @@ import (
 	"os/signal"
 
 	"github.com/zoncoen/scenarigo/cmd/scenarigo/cmd"
+	_ "github.com/zoncoen/scenarigo/protocol/db/drivers"
 )
 
 func main() {
@@ -2,115 +2,54 @@ package context
 
 import (
 	"context"
-
-	"github.com/pkg/errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
 
 	"github.com/zoncoen/scenarigo/assert"
 )
 
-type assertions struct {
-	ctx context.Context
-}
+var (
+	registerAssertionMu sync.Mutex
 
-// ExtractByKey implements query.KeyExtractor interface.
-func (a *assertions) ExtractByKey(key string) (interface{}, bool) {
-	switch key {
-	case "and":
-		return listArgsLeftArrowFunc(buildArgs(a.ctx, assert.And)), true
-	case "or":
-		return listArgsLeftArrowFunc(buildArgs(a.ctx, assert.Or)), true
-	case "contains":
-		return &leftArrowFunc{
-			ctx: a.ctx,
-			f:   buildArg(a.ctx, assert.Contains),
-		}, true
-	case "notContains":
-		return &leftArrowFunc{
-			ctx: a.ctx,
-			f:   buildArg(a.ctx, assert.NotContains),
-		}, true
-	case "notZero":
-		return assert.NotZero(), true
-	case "regexp":
-		return assert.Regexp, true
-	case "greaterThan":
-		return assert.Greater, true
-	case "greaterThanOrEqual":
-		return assert.GreaterOrEqual, true
-	case "lessThan":
-		return assert.Less, true
-	case "lessThanOrEqual":
-		return assert.LessOrEqual, true
-	case "length":
-		return assert.Length, true
-	}
-	return nil, false
-}
+	assertionNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	assertionType       = reflect.TypeOf((*assert.Assertion)(nil)).Elem()
+)
 
-func buildArg(ctx context.Context, base func(assert.Assertion) assert.Assertion) func(interface{}) assert.Assertion {
-	return func(arg interface{}) assert.Assertion {
-		assertion, ok := arg.(assert.Assertion)
-		if !ok {
-			assertion = assert.MustBuild(ctx, arg)
-		}
-		return base(assertion)
+// RegisterAssertion registers a custom assertion as a matcher of the assert package, which is available as <name> and assert.<name> in templates.
+// v must be an assert.Assertion or a function which returns an assert.Assertion as the first result.
+// It returns an error if name is already used by a built-in assertion or a registered matcher.
+func RegisterAssertion(name string, v interface{}) error {
+	if !assertionNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid assertion name %q", name)
 	}
-}
-
-type leftArrowFunc struct {
-	ctx context.Context
-	f   func(interface{}) assert.Assertion
-}
-
-func (laf *leftArrowFunc) Call(v any) assert.Assertion {
-	return laf.f(v)
-}
-
-func (laf *leftArrowFunc) Exec(arg interface{}) (interface{}, error) {
-	assertion, ok := arg.(assert.Assertion)
-	if !ok {
-		return nil, errors.New("argument must be a assert.Assertion")
+	if _, ok := v.(assert.Assertion); !ok {
+		t := reflect.TypeOf(v)
+		if t == nil || t.Kind() != reflect.Func || t.NumOut() == 0 || !t.Out(0).Implements(assertionType) {
+			return fmt.Errorf("assertion %q must be an assert.Assertion or a function which returns it but got %T", name, v)
+		}
 	}
-	return laf.f(assertion), nil
-}
-
-func (laf *leftArrowFunc) UnmarshalArg(unmarshal func(interface{}) error) (interface{}, error) {
-	var i interface{}
-	if err := unmarshal(&i); err != nil {
-		return nil, err
+	if assert.IsBuiltinMatcher(name) {
+		return fmt.Errorf("assertion %q is already defined as a built-in assertion", name)
 	}
-	return assert.Build(laf.ctx, i)
-}
-
-func buildArgs(ctx context.Context, base func(...assert.Assertion) assert.Assertion) func(...interface{}) assert.Assertion {
-	return func(args ...interface{}) assert.Assertion {
-		var assertions []assert.Assertion
-		for _, arg := range args {
-			arg := arg
-			assertion, ok := arg.(assert.Assertion)
-			if !ok {
-				assertion = assert.MustBuild(ctx, arg)
-			}
-			assertions = append(assertions, assertion)
-		}
-		return base(assertions...)
+	registerAssertionMu.Lock()
+	defer registerAssertionMu.Unlock()
+	if _, ok := assert.LookupMatcher(context.Background(), name); ok {
+		return fmt.Errorf("assertion %q is already registered", name)
 	}
+	assert.RegisterMatcher(name, v)
+	return nil
 }
 
-type listArgsLeftArrowFunc func(args ...interface{}) assert.Assertion
-
-func (f listArgsLeftArrowFunc) Exec(arg interface{}) (interface{}, error) {
-	assertions, ok := arg.([]interface{})
-	if !ok {
-		return nil, errors.New("argument must be a slice of interface{}")
-	}
-	return f(assertions...), nil
+type assertions struct {
+	ctx             context.Context
+	snapshotDir     string
+	updateSnapshots bool
 }
 
-func (listArgsLeftArrowFunc) UnmarshalArg(unmarshal func(interface{}) error) (interface{}, error) {
-	var args []interface{}
-	if err := unmarshal(&args); err != nil {
-		return nil, err
-	}
-	return args, nil
+// ExtractByKey implements query.KeyExtractor interface.
+// It looks up the matchers of the assert package, so the assertions in YAML and Build behave the same.
+func (a *assertions) ExtractByKey(key string) (interface{}, bool) {
+	return assert.LookupMatcher(a.ctx, key, assert.WithSnapshotDir(a.snapshotDir), assert.WithUpdateSnapshots(a.updateSnapshots))
 }
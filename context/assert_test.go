@@ -2,6 +2,9 @@ package context
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -18,7 +21,7 @@ func TestAssertions(t *testing.T) {
 		decode(&i)
 		return func(r testutil.Reporter, v interface{}) error {
 			return assert.MustBuild(context.Background(), i, assert.FromTemplate(map[string]interface{}{
-				"assert": &assertions{context.Background()},
+				"assert": &assertions{ctx: context.Background()},
 			})).Assert(v)
 		}
 	}
@@ -26,7 +29,13 @@ func TestAssertions(t *testing.T) {
 		t, executor,
 		"testdata/assertion/and.yaml",
 		"testdata/assertion/or.yaml",
+		"testdata/assertion/not.yaml",
+		"testdata/assertion/all.yaml",
+		"testdata/assertion/keys.yaml",
 		"testdata/assertion/contains.yaml",
+		"testdata/assertion/deep_equal.yaml",
+		"testdata/assertion/valid.yaml",
+		"testdata/assertion/time.yaml",
 	)
 }
 
@@ -66,11 +75,12 @@ func TestLeftArrowFunc(t *testing.T) {
 			if err := yaml.Unmarshal([]byte(tc.yaml), &i); err != nil {
 				t.Fatalf("failed to unmarshal: %s", err)
 			}
+			f, ok := (&assertions{ctx: context.Background()}).ExtractByKey("contains")
+			if !ok {
+				t.Fatal("contains not found")
+			}
 			v, err := template.Execute(i, map[string]interface{}{
-				"f": &leftArrowFunc{
-					ctx: context.Background(),
-					f:   buildArg(context.Background(), assert.Contains),
-				},
+				"f": f,
 			})
 			if err != nil {
 				t.Fatalf("failed to execute: %s", err)
@@ -85,3 +95,164 @@ func TestLeftArrowFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestRegisterAssertion(t *testing.T) {
+	isEven := assert.AssertionFunc(func(v interface{}) error {
+		if n, ok := v.(int); !ok || n%2 != 0 {
+			return fmt.Errorf("%v is not even", v)
+		}
+		return nil
+	})
+	assert.RegisterMatcher("testMatcher", isEven)
+	if err := RegisterAssertion("testIsEven", isEven); err != nil {
+		t.Fatalf("failed to register: %s", err)
+	}
+	if err := RegisterAssertion("testDivisibleBy", func(d int) assert.Assertion {
+		return assert.AssertionFunc(func(v interface{}) error {
+			if n, ok := v.(int); !ok || n%d != 0 {
+				return fmt.Errorf("%v is not divisible by %d", v, d)
+			}
+			return nil
+		})
+	}); err != nil {
+		t.Fatalf("failed to register: %s", err)
+	}
+
+	t.Run("assert", func(t *testing.T) {
+		tests := map[string]struct {
+			expect string
+			v      interface{}
+			err    string
+		}{
+			"value": {
+				expect: `'{{assert.testIsEven}}'`,
+				v:      2,
+			},
+			"value failed": {
+				expect: `n: '{{assert.testIsEven}}'`,
+				v:      map[string]interface{}{"n": 3},
+				err:    ".n: 3 is not even",
+			},
+			"function": {
+				expect: `'{{assert.testDivisibleBy(3)}}'`,
+				v:      9,
+			},
+			"function failed": {
+				expect: `'{{assert.testDivisibleBy(3)}}'`,
+				v:      10,
+				err:    "10 is not divisible by 3",
+			},
+			"value without assert": {
+				expect: `n: '{{testIsEven}}'`,
+				v:      map[string]interface{}{"n": 3},
+				err:    ".n: 3 is not even",
+			},
+			"function without assert": {
+				expect: `'{{testDivisibleBy(3)}}'`,
+				v:      10,
+				err:    "10 is not divisible by 3",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				var expect interface{}
+				if err := yaml.UnmarshalWithOptions([]byte(test.expect), &expect, yaml.UseOrderedMap()); err != nil {
+					t.Fatalf("failed to unmarshal: %s", err)
+				}
+				err := assert.MustBuild(context.Background(), expect, assert.FromTemplate(map[string]interface{}{
+					"assert": &assertions{ctx: context.Background()},
+				})).Assert(test.v)
+				if test.err == "" {
+					if err != nil {
+						t.Fatalf("unexpected error: %s", err)
+					}
+					return
+				}
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected %q but got %q", test.err, err)
+				}
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := map[string]struct {
+			name string
+			v    interface{}
+			err  string
+		}{
+			"built-in": {
+				name: "notZero",
+				v:    isEven,
+				err:  `assertion "notZero" is already defined as a built-in assertion`,
+			},
+			"built-in matcher": {
+				name: "equal",
+				v:    isEven,
+				err:  `assertion "equal" is already defined as a built-in assertion`,
+			},
+			"matcher": {
+				name: "testMatcher",
+				v:    isEven,
+				err:  `assertion "testMatcher" is already registered`,
+			},
+			"duplicated": {
+				name: "testIsEven",
+				v:    isEven,
+				err:  `assertion "testIsEven" is already registered`,
+			},
+			"invalid name": {
+				name: "is-even",
+				v:    isEven,
+				err:  `invalid assertion name "is-even"`,
+			},
+			"not assertion": {
+				name: "testInvalid",
+				v:    func() string { return "" },
+				err:  `assertion "testInvalid" must be an assert.Assertion or a function which returns it but got func() string`,
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				err := RegisterAssertion(test.name, test.v)
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if got, expect := err.Error(), test.err; got != expect {
+					t.Errorf("expected %q but got %q", expect, got)
+				}
+			})
+		}
+	})
+}
+
+func TestAssertions_Snapshot(t *testing.T) {
+	dir := t.TempDir()
+	ctx := FromT(t).WithScenarioFilepath(filepath.Join(dir, "users.yaml"))
+	expect := yaml.MapSlice{{Key: "user", Value: `{{assert.snapshot("get-user")}}`}}
+	v := map[string]interface{}{"user": map[string]interface{}{"id": 1}}
+
+	if err := assert.MustBuild(ctx.RequestContext(), expect, assert.FromTemplate(ctx.WithUpdateGolden(true))).Assert(v); err != nil {
+		t.Fatalf("failed to update snapshot: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "__snapshots__", "users", "get-user.yaml")); err != nil {
+		t.Fatalf("snapshot not found: %s", err)
+	}
+	if err := assert.MustBuild(ctx.RequestContext(), expect, assert.FromTemplate(ctx)).Assert(v); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	err := assert.MustBuild(ctx.RequestContext(), expect, assert.FromTemplate(ctx)).Assert(map[string]interface{}{
+		"user": map[string]interface{}{"id": 2},
+	})
+	if err == nil {
+		t.Fatal("no error")
+	}
+	if expect := "- id: 1\n+ id: 2"; !strings.Contains(err.Error(), expect) {
+		t.Errorf("%q not found in %q", expect, err)
+	}
+}
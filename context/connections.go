@@ -0,0 +1,70 @@
+package context
+
+import (
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// Connections represents the connections which persist across steps in a scenario such as WebSocket connections.
+type Connections struct {
+	mu    sync.Mutex
+	conns map[string]io.Closer
+}
+
+// NewConnections returns a *Connections.
+func NewConnections() *Connections {
+	return &Connections{
+		mu:    sync.Mutex{},
+		conns: map[string]io.Closer{},
+	}
+}
+
+// Add adds the connection with the key.
+// It returns an error if the connection with the same key already exists.
+func (c *Connections) Add(key string, conn io.Closer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.conns[key]; ok {
+		return errors.Errorf("connection %q already exists", key)
+	}
+	c.conns[key] = conn
+	return nil
+}
+
+// Get gets the connection by the key.
+func (c *Connections) Get(key string) io.Closer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conns[key]
+}
+
+// Remove removes the connection from c without closing it and returns it.
+func (c *Connections) Remove(key string) io.Closer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	conn := c.conns[key]
+	delete(c.conns, key)
+	return conn
+}
+
+// Close closes all connections.
+func (c *Connections) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]string, 0, len(c.conns))
+	for k := range c.conns {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var errs []error
+	for _, k := range keys {
+		if err := c.conns[k].Close(); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to close connection %q", k))
+		}
+	}
+	c.conns = map[string]io.Closer{}
+	return errors.Errors(errs...)
+}
@@ -3,23 +3,43 @@ package context
 
 import (
 	"context"
+	"net/http"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/goccy/go-yaml/ast"
 	"github.com/zoncoen/scenarigo/reporter"
+	"github.com/zoncoen/scenarigo/template"
 )
 
 type (
 	keyScenarioFilepath struct{}
+	keyIncludes         struct{}
 	keyPluginDir        struct{}
 	keyPlugins          struct{}
 	keyVars             struct{}
+	keyCase             struct{}
+	keyLoop             struct{}
 	keySteps            struct{}
 	keyRequest          struct{}
 	keyResponse         struct{}
+	keyInvokeResult     struct{}
+	keyResponseMetadata struct{}
+	keyElapsed          struct{}
+	keyStepTimeout      struct{}
+	keyUpdateGolden     struct{}
+	keyClock            struct{}
 	keyYAMLNode         struct{}
 	keyEnabledColor     struct{}
+	keyCookieJar        struct{}
+	keyAuthToken        struct{}
+	keyEnvAllowlist     struct{}
+	keyTemplateState    struct{}
+	keyProtocolConfig   struct{ name string }
+	keyConnections      struct{}
+	keyValues           struct{}
 )
 
 // Context represents a scenarigo context.
@@ -90,6 +110,36 @@ func (c *Context) ScenarioFilepath() string {
 	return ""
 }
 
+// SnapshotDir returns the directory of the snapshot files of the scenario executing in this context.
+// It is __snapshots__/<scenario file name without extension> in the directory of the scenario file, and empty if the scenario isn't read from a file.
+func (c *Context) SnapshotDir() string {
+	path := c.ScenarioFilepath()
+	if path == "" {
+		return ""
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return filepath.Join(filepath.Dir(path), "__snapshots__", name)
+}
+
+// WithIncludes returns a copy of c with the filepaths of the scenarios which include the current one.
+func (c *Context) WithIncludes(paths []string) *Context {
+	return newContext(
+		context.WithValue(c.ctx, keyIncludes{}, paths),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// Includes returns the filepaths of the scenarios which include the current one.
+// The first element is the outermost scenario.
+func (c *Context) Includes() []string {
+	paths, ok := c.ctx.Value(keyIncludes{}).([]string)
+	if ok {
+		return paths
+	}
+	return nil
+}
+
 // WithPluginDir returns a copy of c with plugin root directory.
 func (c *Context) WithPluginDir(path string) *Context {
 	abs, err := filepath.Abs(path)
@@ -158,6 +208,50 @@ func (c *Context) Vars() Vars {
 	return nil
 }
 
+// WithCase returns a copy of c with the parameters of the data-driven scenario.
+func (c *Context) WithCase(v interface{}) *Context {
+	if v == nil {
+		return c
+	}
+	return newContext(
+		context.WithValue(c.ctx, keyCase{}, v),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// Case returns the parameters of the data-driven scenario.
+func (c *Context) Case() interface{} {
+	return c.ctx.Value(keyCase{})
+}
+
+// Loop represents the current iteration of the foreach loop.
+type Loop struct {
+	Item  interface{} `yaml:"item"`
+	Index int         `yaml:"index"`
+}
+
+// WithLoop returns a copy of c with the current iteration of the foreach loop.
+func (c *Context) WithLoop(loop *Loop) *Context {
+	if loop == nil {
+		return c
+	}
+	return newContext(
+		context.WithValue(c.ctx, keyLoop{}, loop),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// Loop returns the current iteration of the foreach loop.
+func (c *Context) Loop() *Loop {
+	loop, ok := c.ctx.Value(keyLoop{}).(*Loop)
+	if ok {
+		return loop
+	}
+	return nil
+}
+
 // WithSteps returns a copy of c with steps.
 func (c *Context) WithSteps(steps *Steps) *Context {
 	if steps == nil {
@@ -213,6 +307,136 @@ func (c *Context) Response() interface{} {
 	return c.ctx.Value(keyResponse{})
 }
 
+// WithResponseMetadata returns a copy of c with the metadata of the response such as the header and trailer of gRPC.
+func (c *Context) WithResponseMetadata(md interface{}) *Context {
+	return newContext(
+		context.WithValue(c.ctx, keyResponseMetadata{}, md),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// ResponseMetadata returns the metadata of the response.
+func (c *Context) ResponseMetadata() interface{} {
+	return c.ctx.Value(keyResponseMetadata{})
+}
+
+// WithInvokeResult returns a copy of c with the whole response of the request such as the status, header, and body of HTTP.
+// It is the value checked by expect, unlike Response which returns the value available as {{response}} in templates.
+func (c *Context) WithInvokeResult(resp interface{}) *Context {
+	return newContext(
+		context.WithValue(c.ctx, keyInvokeResult{}, resp),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// InvokeResult returns the whole response of the request.
+func (c *Context) InvokeResult() interface{} {
+	return c.ctx.Value(keyInvokeResult{})
+}
+
+// WithElapsed returns a copy of c with the elapsed time of the request.
+func (c *Context) WithElapsed(d time.Duration) *Context {
+	return newContext(
+		context.WithValue(c.ctx, keyElapsed{}, d),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// Elapsed returns the elapsed time of the request.
+// The second return value reports whether the elapsed time is set.
+func (c *Context) Elapsed() (time.Duration, bool) {
+	d, ok := c.ctx.Value(keyElapsed{}).(time.Duration)
+	return d, ok
+}
+
+// WithStepTimeout returns a copy of c with the default timeout of the steps which don't set the timeout.
+func (c *Context) WithStepTimeout(d time.Duration) *Context {
+	return newContext(
+		context.WithValue(c.ctx, keyStepTimeout{}, d),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// StepTimeout returns the default timeout of the steps.
+// The zero value means no timeout.
+func (c *Context) StepTimeout() time.Duration {
+	d, _ := c.ctx.Value(keyStepTimeout{}).(time.Duration)
+	return d
+}
+
+// WithEnvAllowlist returns a copy of c which restricts the environment variables templates can read by env.
+// If names is nil, all variables can be read.
+func (c *Context) WithEnvAllowlist(names []string) *Context {
+	if names != nil {
+		names = append([]string{}, names...)
+	}
+	return newContext(
+		context.WithValue(c.ctx, keyEnvAllowlist{}, names),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// EnvAllowlist returns the environment variables templates can read by env.
+// It returns nil if all variables can be read.
+func (c *Context) EnvAllowlist() []string {
+	names, _ := c.ctx.Value(keyEnvAllowlist{}).([]string)
+	return names
+}
+
+// WithUpdateGolden returns a copy of c which reports whether the golden files and the snapshots are rewritten by the actual values instead of asserting them.
+func (c *Context) WithUpdateGolden(update bool) *Context {
+	return newContext(
+		context.WithValue(c.ctx, keyUpdateGolden{}, update),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// UpdateGolden reports whether the golden files and the snapshots are rewritten by the actual values.
+func (c *Context) UpdateGolden() bool {
+	update, _ := c.ctx.Value(keyUpdateGolden{}).(bool)
+	return update
+}
+
+// WithClock returns a copy of c with the clock which supplies the current time of the time functions such as now() in templates.
+func (c *Context) WithClock(clock template.Clock) *Context {
+	return newContext(
+		context.WithValue(c.ctx, keyClock{}, clock),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// Now implements template.Clock interface.
+// It returns the current time of the clock set by WithClock, or the real clock.
+func (c *Context) Now() time.Time {
+	if clock, ok := c.ctx.Value(keyClock{}).(template.Clock); ok {
+		return clock.Now()
+	}
+	return time.Now()
+}
+
+// WithTemplateState returns a copy of c with the state shared by the template executions such as the UUIDs generated by uuid().
+func (c *Context) WithTemplateState(st *template.State) *Context {
+	return newContext(
+		context.WithValue(c.ctx, keyTemplateState{}, st),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// TemplateState implements template.StateHolder interface.
+// It returns nil if the state isn't set by WithTemplateState.
+func (c *Context) TemplateState() *template.State {
+	st, _ := c.ctx.Value(keyTemplateState{}).(*template.State)
+	return st
+}
+
 // WithNode returns a copy of c with ast.Node.
 func (c *Context) WithNode(node ast.Node) *Context {
 	if node == nil {
@@ -252,6 +476,100 @@ func (c *Context) EnabledColor() bool {
 	return false
 }
 
+// WithCookieJar returns a copy of c with the cookie jar for HTTP requests.
+func (c *Context) WithCookieJar(jar http.CookieJar) *Context {
+	if jar == nil {
+		return c
+	}
+	return newContext(
+		context.WithValue(c.ctx, keyCookieJar{}, jar),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// CookieJar returns the cookie jar for HTTP requests.
+// It returns nil if the cookie jar is disabled.
+func (c *Context) CookieJar() http.CookieJar {
+	jar, ok := c.ctx.Value(keyCookieJar{}).(http.CookieJar)
+	if !ok {
+		return nil
+	}
+	return jar
+}
+
+// WithAuthToken returns a copy of c with the access token which is used by the request such as the OAuth2 bearer token.
+func (c *Context) WithAuthToken(token string) *Context {
+	return newContext(
+		context.WithValue(c.ctx, keyAuthToken{}, token),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// AuthToken returns the access token which is used by the request.
+func (c *Context) AuthToken() (string, bool) {
+	token, ok := c.ctx.Value(keyAuthToken{}).(string)
+	return token, ok
+}
+
+// WithProtocolConfig returns a copy of c with the configuration for the protocol.
+func (c *Context) WithProtocolConfig(name string, config interface{}) *Context {
+	if config == nil {
+		return c
+	}
+	return newContext(
+		context.WithValue(c.ctx, keyProtocolConfig{strings.ToLower(name)}, config),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// ProtocolConfig returns the configuration for the protocol.
+func (c *Context) ProtocolConfig(name string) interface{} {
+	return c.ctx.Value(keyProtocolConfig{strings.ToLower(name)})
+}
+
+// WithConnections returns a copy of c with the connections shared by steps in the scenario.
+func (c *Context) WithConnections(conns *Connections) *Context {
+	if conns == nil {
+		return c
+	}
+	return newContext(
+		context.WithValue(c.ctx, keyConnections{}, conns),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// Connections returns the connections shared by steps in the scenario.
+func (c *Context) Connections() *Connections {
+	conns, ok := c.ctx.Value(keyConnections{}).(*Connections)
+	if ok {
+		return conns
+	}
+	return nil
+}
+
+// WithValues returns a copy of c with the values which are available as {{ctx.<key>}} in templates.
+// The existing values can't be overwritten, so the values stay the same during the run.
+func (c *Context) WithValues(m map[string]interface{}) *Context {
+	if len(m) == 0 {
+		return c
+	}
+	return newContext(
+		context.WithValue(c.ctx, keyValues{}, c.Values().merge(m)),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// Values returns the values injected into the context.
+func (c *Context) Values() Values {
+	vs, _ := c.ctx.Value(keyValues{}).(Values)
+	return vs
+}
+
 // Run runs f as a subtest of c called name.
 func (c *Context) Run(name string, f func(*Context)) bool {
 	return c.Reporter().Run(name, func(r reporter.Reporter) { f(c.WithReporter(r)) })
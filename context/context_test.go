@@ -7,8 +7,10 @@ import (
 
 	"github.com/goccy/go-yaml/ast"
 	"github.com/goccy/go-yaml/token"
+	"github.com/zoncoen/scenarigo/assert"
 	"github.com/zoncoen/scenarigo/context"
 	"github.com/zoncoen/scenarigo/schema"
+	"github.com/zoncoen/scenarigo/template"
 )
 
 func TestContext(t *testing.T) {
@@ -34,6 +36,46 @@ func TestContext(t *testing.T) {
 			t.Fatal("failed to get enabledColor")
 		}
 	})
+	t.Run("values", func(t *testing.T) {
+		m := map[string]interface{}{"tenantID": "foo"}
+		ctx := context.FromT(t).WithValues(m)
+		m["tenantID"] = "changed"
+		ctx = ctx.WithValues(map[string]interface{}{"tenantID": "bar", "flag": true})
+		if v, ok := ctx.Values().Get("tenantID"); !ok || v != "foo" {
+			t.Errorf("expect foo but got %v", v)
+		}
+		if v, ok := ctx.Values().Get("flag"); !ok || v != true {
+			t.Errorf("expect true but got %v", v)
+		}
+		v, err := ctx.ExecuteTemplate("{{ctx.tenantID}}-{{ctx.flag ? \"on\" : \"off\"}}")
+		if err != nil {
+			t.Fatalf("failed to execute template: %s", err)
+		}
+		if got, expect := v, "foo-on"; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+	t.Run("clock", func(t *testing.T) {
+		now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		ctx := context.FromT(t).WithClock(template.ClockFunc(func() time.Time { return now }))
+		if got := ctx.Now(); !got.Equal(now) {
+			t.Errorf("expect %s but got %s", now, got)
+		}
+		v, err := ctx.WithVars(map[string]interface{}{"d": "1h"}).ExecuteTemplate("{{addDuration(now(), vars.d)}}")
+		if err != nil {
+			t.Fatalf("failed to execute template: %s", err)
+		}
+		if got, expect := v, "2024-01-02T04:04:05Z"; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+		assertion, err := assert.Build(ctx.RequestContext(), "{{$ == now()}}", assert.FromTemplate(ctx))
+		if err != nil {
+			t.Fatalf("failed to build: %s", err)
+		}
+		if err := assertion.Assert("2024-01-02T03:04:05Z"); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
 }
 
 func TestRunWithRetry(t *testing.T) {
@@ -0,0 +1,23 @@
+package context
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// cookies returns the function to get the cookies in jar which will be sent to the URL such as {{cookies("http://example.com").session}}.
+func cookies(jar http.CookieJar) func(string) (map[string]string, error) {
+	return func(s string) (map[string]string, error) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, errors.Errorf("cookies: invalid URL %q: %s", s, err)
+		}
+		m := map[string]string{}
+		for _, c := range jar.Cookies(u) {
+			m[c.Name] = c.Value
+		}
+		return m, nil
+	}
+}
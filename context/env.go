@@ -1,16 +1,6 @@
 package context
 
-import "os"
+import "github.com/zoncoen/scenarigo/template"
 
-var env = &envExtractor{}
-
-type envExtractor struct{}
-
-// ExtractByKey implements query.KeyExtractor interface.
-func (f *envExtractor) ExtractByKey(key string) (interface{}, bool) {
-	v, ok := os.LookupEnv(key)
-	if !ok {
-		return nil, false
-	}
-	return v, true
-}
+// env reads environment variables by the key such as {{env.PORT}} or the call such as {{env("PORT", "8080")}}.
+var env = template.NewEnv(nil)
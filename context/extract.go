@@ -1,14 +1,23 @@
 package context
 
+import "github.com/zoncoen/scenarigo/template"
+
 const (
-	nameContext  = "ctx"
-	namePlugins  = "plugins"
-	nameVars     = "vars"
-	nameSteps    = "steps"
-	nameRequest  = "request"
-	nameResponse = "response"
-	nameEnv      = "env"
-	nameAssert   = "assert"
+	nameContext          = "ctx"
+	namePlugins          = "plugins"
+	nameVars             = "vars"
+	nameCase             = "case"
+	nameLoop             = "loop"
+	nameSteps            = "steps"
+	nameRequest          = "request"
+	nameResponse         = "response"
+	nameResponseMetadata = "responseMetadata"
+	nameElapsed          = "elapsed"
+	nameEnv              = "env"
+	nameAssert           = "assert"
+	nameCookies          = "cookies"
+	nameAuthToken        = "authToken"
+	nameSecret           = "secret"
 )
 
 // ExtractByKey implements query.KeyExtractor interface.
@@ -26,6 +35,16 @@ func (c *Context) ExtractByKey(key string) (interface{}, bool) {
 		if v != nil {
 			return v, true
 		}
+	case nameCase:
+		v := c.Case()
+		if v != nil {
+			return v, true
+		}
+	case nameLoop:
+		v := c.Loop()
+		if v != nil {
+			return v, true
+		}
 	case nameSteps:
 		v := c.Steps()
 		if v != nil {
@@ -41,10 +60,37 @@ func (c *Context) ExtractByKey(key string) (interface{}, bool) {
 		if v != nil {
 			return v, true
 		}
+	case nameResponseMetadata:
+		v := c.ResponseMetadata()
+		if v != nil {
+			return v, true
+		}
+	case nameElapsed:
+		if d, ok := c.Elapsed(); ok {
+			return d, true
+		}
 	case nameEnv:
+		if names := c.EnvAllowlist(); names != nil {
+			return template.NewEnv(names), true
+		}
 		return env, true
 	case nameAssert:
-		return &assertions{c.RequestContext()}, true
+		return &assertions{
+			ctx:             c.RequestContext(),
+			snapshotDir:     c.SnapshotDir(),
+			updateSnapshots: c.UpdateGolden(),
+		}, true
+	case nameCookies:
+		if jar := c.CookieJar(); jar != nil {
+			return cookies(jar), true
+		}
+	case nameAuthToken:
+		if token, ok := c.AuthToken(); ok {
+			return token, true
+		}
+	case nameSecret:
+		return secret(c), true
 	}
-	return nil, false
+	// the values injected into the context such as {{ctx.tenantID}}
+	return c.Values().Get(key)
 }
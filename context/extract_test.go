@@ -2,6 +2,7 @@ package context
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/zoncoen/query-go"
@@ -35,6 +36,20 @@ func TestContext_ExtractKey(t *testing.T) {
 			query:  "vars.foo",
 			expect: "bar",
 		},
+		"case": {
+			ctx: func(ctx *Context) *Context {
+				return ctx.WithCase(vars)
+			},
+			query:  "case.foo",
+			expect: "bar",
+		},
+		"loop": {
+			ctx: func(ctx *Context) *Context {
+				return ctx.WithLoop(&Loop{Item: "bar", Index: 1})
+			},
+			query:  "loop.item",
+			expect: "bar",
+		},
 		"steps": {
 			ctx: func(ctx *Context) *Context {
 				steps := NewSteps()
@@ -60,10 +75,51 @@ func TestContext_ExtractKey(t *testing.T) {
 			query:  "response.foo",
 			expect: "bar",
 		},
+		"elapsed": {
+			ctx: func(ctx *Context) *Context {
+				return ctx.WithElapsed(time.Second)
+			},
+			query:  "elapsed",
+			expect: time.Second,
+		},
+		"steps elapsed": {
+			ctx: func(ctx *Context) *Context {
+				steps := NewSteps()
+				steps.Add("foo", &Step{
+					Elapsed: time.Second,
+				})
+				return ctx.WithSteps(steps)
+			},
+			query:  "steps.foo.elapsed",
+			expect: time.Second,
+		},
+		"responseMetadata": {
+			ctx: func(ctx *Context) *Context {
+				return ctx.WithResponseMetadata(map[string]string{
+					"key": "value",
+				})
+			},
+			query:  "responseMetadata.key",
+			expect: "value",
+		},
+		"authToken": {
+			ctx: func(ctx *Context) *Context {
+				return ctx.WithAuthToken("token")
+			},
+			query:  "authToken",
+			expect: "token",
+		},
 		"env": {
 			query:  "env.TEST_PORT",
 			expect: "5000",
 		},
+		"env with allowlist": {
+			ctx: func(ctx *Context) *Context {
+				return ctx.WithEnvAllowlist([]string{"TEST_PORT"})
+			},
+			query:  "env.TEST_PORT",
+			expect: "5000",
+		},
 	}
 	for name, test := range tests {
 		test := test
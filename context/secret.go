@@ -0,0 +1,18 @@
+package context
+
+import (
+	"fmt"
+
+	"github.com/zoncoen/scenarigo/reporter"
+)
+
+// secret returns the function which registers the argument as a secret value such as {{secret(env.API_TOKEN)}}.
+// The function returns the argument as it is, and the value is masked in the outputs.
+func secret(c *Context) func(interface{}) interface{} {
+	return func(v interface{}) interface{} {
+		if v != nil {
+			reporter.AddSecret(c.Reporter(), fmt.Sprint(v))
+		}
+		return v
+	}
+}
@@ -1,6 +1,9 @@
 package context
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 // Steps represents results of steps.
 type Steps struct {
@@ -10,8 +13,11 @@ type Steps struct {
 
 // Step represents a result of step.
 type Step struct {
-	Result string `yaml:"result,omitempty"`
-	Steps  *Steps `yaml:"steps,omitempty"` // child steps
+	Result   string        `yaml:"result,omitempty"`
+	Elapsed  time.Duration `yaml:"elapsed,omitempty"`  // elapsed time of the request
+	Request  interface{}   `yaml:"request,omitempty"`  // same as {{request}} of the step
+	Response interface{}   `yaml:"response,omitempty"` // the response checked by expect, e.g. the status, header, and body of HTTP
+	Steps    *Steps        `yaml:"steps,omitempty"`    // child steps
 }
 
 // NewStesp returns a *Steps.
@@ -23,6 +29,7 @@ func NewSteps() *Steps {
 }
 
 // Add adds a result of step.
+// The result is overwritten if the step with the same id runs again, e.g. in loops.
 func (s *Steps) Add(id string, step *Step) {
 	if id == "" {
 		return
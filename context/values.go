@@ -0,0 +1,31 @@
+package context
+
+// Values represents the values injected into the context at the start of the run, such as feature flags and tenant IDs.
+// They are read-only to prevent the steps from changing the configuration of the run.
+type Values struct {
+	m map[string]interface{}
+}
+
+// Get returns the value of key.
+func (v Values) Get(key string) (interface{}, bool) {
+	x, ok := v.m[key]
+	return x, ok
+}
+
+// Len returns the number of the values.
+func (v Values) Len() int {
+	return len(v.m)
+}
+
+// merge returns new values which have the values of v and m.
+// The existing values are not overwritten.
+func (v Values) merge(m map[string]interface{}) Values {
+	merged := make(map[string]interface{}, len(v.m)+len(m))
+	for k, x := range m {
+		merged[k] = x
+	}
+	for k, x := range v.m {
+		merged[k] = x
+	}
+	return Values{m: merged}
+}
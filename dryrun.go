@@ -0,0 +1,254 @@
+package scenarigo
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/plugin"
+	"github.com/zoncoen/scenarigo/protocol"
+	"github.com/zoncoen/scenarigo/reporter"
+	"github.com/zoncoen/scenarigo/schema"
+	"github.com/zoncoen/scenarigo/template"
+)
+
+// runtimeValues are the names of the template values which are available only while running the scenario.
+var runtimeValues = map[string]struct{}{
+	"ctx":              {},
+	"vars":             {},
+	"case":             {},
+	"loop":             {},
+	"steps":            {},
+	"request":          {},
+	"response":         {},
+	"responseMetadata": {},
+	"elapsed":          {},
+	"cookies":          {},
+}
+
+// DryRunScenario validates a test scenario s without sending requests.
+// It decodes the steps, executes the templates, and builds the assertions to report all errors of the steps.
+// The references to the values which are available only at runtime such as response and bound variables are not checked.
+// The plugins are opened, but their setup functions don't run.
+func DryRunScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
+	if s.Cases == nil {
+		return dryRunScenario(ctx, s)
+	}
+	cases, err := s.Cases.Load(filepath.Dir(s.Filepath()))
+	if err != nil {
+		ctx.Reporter().Fatalf("invalid cases: %s", err)
+	}
+	for i, c := range cases {
+		c := c
+		x, err := ctx.ExecuteTemplate(c)
+		if err != nil {
+			ctx.Reporter().Errorf("invalid cases[%d]: %s", i, err)
+			continue
+		}
+		ctx.Run(caseName(i, x), func(ctx *context.Context) {
+			_ = dryRunScenario(ctx.WithCase(x), s)
+		})
+	}
+	return ctx
+}
+
+func dryRunScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
+	ctx = ctx.WithScenarioFilepath(s.Filepath())
+	ctx = ctx.WithSteps(context.NewSteps())
+
+	if s.Plugins != nil {
+		plugs := map[string]interface{}{}
+		for name, path := range s.Plugins {
+			path := path
+			if root := ctx.PluginDir(); root != "" {
+				path = filepath.Join(root, path)
+			}
+			p, err := plugin.Open(path)
+			if err != nil {
+				ctx.Reporter().Fatalf("failed to open plugin: %s", err)
+			}
+			plugs[name] = p
+		}
+		ctx = ctx.WithPlugins(plugs)
+	}
+
+	// executing templates modifies the scenario in place
+	s, err := s.Clone()
+	if err != nil {
+		ctx.Reporter().Fatalf("failed to copy scenario: %s", err)
+	}
+
+	if s.Vars != nil {
+		vars, err := ctx.ExecuteTemplate(s.Vars)
+		if err != nil {
+			reportDryRunError(ctx, errors.Wrap(err, "invalid vars"), "vars")
+		} else {
+			ctx = ctx.WithVars(vars)
+		}
+	}
+	for i, secret := range s.Secrets {
+		if _, err := ctx.ExecuteTemplate(secret); err != nil {
+			reportDryRunError(ctx, errors.Wrap(err, "invalid secret"), fmt.Sprintf("secrets[%d]", i))
+		}
+	}
+
+	for _, list := range []struct {
+		name  string
+		steps []*schema.Step
+	}{
+		{name: "setup", steps: s.Setup},
+		{name: "steps", steps: s.Steps},
+		{name: "teardown", steps: s.Teardown},
+	} {
+		if len(list.steps) == 0 {
+			continue
+		}
+		if list.name == "steps" {
+			dryRunSteps(ctx, s, list.name, list.steps)
+			continue
+		}
+		ctx.Run(list.name, func(ctx *context.Context) {
+			dryRunSteps(ctx, s, list.name, list.steps)
+		})
+	}
+	return ctx
+}
+
+// dryRunSteps validates all steps even if some of them are invalid.
+func dryRunSteps(ctx *context.Context, s *schema.Scenario, section string, stepList []*schema.Step) {
+	for idx, step := range stepList {
+		idx, step := idx, step
+		ctx.Run(step.Title, func(ctx *context.Context) {
+			dryRunStep(ctx, s, step, section, idx)
+		})
+	}
+}
+
+func dryRunStep(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, section string, stepIdx int) {
+	if _, err := executeIf(ctx, s.If); err != nil {
+		reportDryRunError(ctx, err, fmt.Sprintf("%s[%d].if", section, stepIdx))
+	}
+	if s.Vars != nil {
+		vars, err := ctx.ExecuteTemplate(s.Vars)
+		if err != nil {
+			reportDryRunError(ctx, errors.Wrap(err, "invalid vars"), fmt.Sprintf("%s[%d].vars", section, stepIdx))
+		} else {
+			ctx = ctx.WithVars(vars)
+		}
+	}
+	defer func() {
+		if s.Bind.Vars != nil {
+			if _, err := ctx.ExecuteTemplate(s.Bind.Vars); err != nil {
+				reportDryRunError(ctx, errors.Wrap(err, "invalid bind"), fmt.Sprintf("%s[%d].bind.vars", section, stepIdx))
+			}
+		}
+	}()
+
+	switch {
+	case s.ForEach != nil:
+		if _, err := ctx.ExecuteTemplate(s.ForEach.Items); err != nil {
+			reportDryRunError(ctx, errors.Wrap(err, "invalid items"), fmt.Sprintf("%s[%d].foreach.items", section, stepIdx))
+		}
+		steps, err := s.ForEach.NewSteps()
+		if err != nil {
+			reportDryRunError(ctx, errors.Wrap(err, "invalid steps"), fmt.Sprintf("%s[%d].foreach.steps", section, stepIdx))
+			return
+		}
+		dryRunSteps(ctx, scenario, "steps", steps)
+	case s.Include != "":
+		baseDir := filepath.Dir(scenario.Filepath())
+		include := filepath.Join(baseDir, s.Include)
+		scenarios, err := schema.LoadScenarios(include)
+		if err != nil {
+			ctx.Reporter().Errorf(`failed to include "%s" as step: %s`, s.Include, err)
+			return
+		}
+		if len(scenarios) != 1 {
+			ctx.Reporter().Errorf(`failed to include "%s" as step: must be a scenario`, s.Include)
+			return
+		}
+		testName, err := filepath.Rel(baseDir, include)
+		if err != nil {
+			testName = include
+		}
+		includes, err := appendInclude(ctx, scenario, include)
+		if err != nil {
+			ctx.Reporter().Errorf(`failed to include "%s" as step: %s`, s.Include, err)
+			return
+		}
+		ctx.Reporter().Run(testName, func(rptr reporter.Reporter) {
+			DryRunScenario(ctx.WithReporter(rptr).WithNode(scenarios[0].Node).WithIncludes(includes), scenarios[0])
+		})
+	case s.Ref != nil:
+		x, err := ctx.ExecuteTemplate(s.Ref)
+		if err != nil {
+			reportDryRunError(ctx, errors.Wrapf(err, `failed to reference "%s" as step`, s.Ref), fmt.Sprintf("%s[%d].ref", section, stepIdx))
+			return
+		}
+		if _, ok := x.(plugin.Step); !ok {
+			reportDryRunError(ctx, errors.Errorf(`failed to reference "%s" as step: not implement plugin.Step interface`, s.Ref), fmt.Sprintf("%s[%d].ref", section, stepIdx))
+		}
+	default:
+		if err := s.ResolveProtocol(); err != nil {
+			reportDryRunError(ctx, err, fmt.Sprintf("%s[%d].protocol", section, stepIdx))
+			return
+		}
+		if p, ok := s.Request.(protocol.Preparer); ok {
+			if err := p.Prepare(ctx); err != nil {
+				reportDryRunError(ctx, err, fmt.Sprintf("%s[%d].request", section, stepIdx))
+			}
+		}
+		if s.Request != nil {
+			if _, err := ctx.ExecuteTemplate(s.Request); err != nil {
+				reportDryRunError(ctx, err, fmt.Sprintf("%s[%d].request", section, stepIdx))
+			}
+		}
+		if s.Until != nil {
+			if _, err := executeIf(ctx, s.Until.Condition); err != nil {
+				reportDryRunError(ctx, err, fmt.Sprintf("%s[%d].until.condition", section, stepIdx))
+			}
+		}
+		if s.Expect != nil {
+			if _, err := s.Expect.Build(ctx); err != nil {
+				reportDryRunError(ctx, err, fmt.Sprintf("%s[%d].expect", section, stepIdx))
+			}
+		}
+	}
+}
+
+// reportDryRunError reports err with path unless it is caused by referring to the runtime values.
+func reportDryRunError(ctx *context.Context, err error, path string) {
+	if referRuntimeValues(err) {
+		return
+	}
+	ctx.Reporter().Error(
+		errors.WithNodeAndColored(
+			errors.WithPath(err, path),
+			ctx.Node(),
+			ctx.EnabledColor(),
+		),
+	)
+}
+
+// referRuntimeValues reports whether all causes of err are the references to the runtime values.
+func referRuntimeValues(err error) bool {
+	if root, ok := template.UndefinedRoot(err); ok {
+		_, ok := runtimeValues[root]
+		return ok
+	}
+	var pathErr *errors.PathError
+	if errors.As(err, &pathErr) {
+		return referRuntimeValues(pathErr.Err)
+	}
+	var multiErr *errors.MultiPathError
+	if errors.As(err, &multiErr) {
+		for _, err := range multiErr.Errs {
+			if !referRuntimeValues(err) {
+				return false
+			}
+		}
+		return len(multiErr.Errs) > 0
+	}
+	return false
+}
@@ -235,9 +235,15 @@ func (e *PathError) Error() string {
 type MultiPathError struct {
 	Node ast.Node
 	Errs []error
+	// Diff is a human-readable diff of the whole values.
+	// If it isn't empty, Error returns the diff instead of the list of errors.
+	Diff string
 }
 
 func (e *MultiPathError) Error() string {
+	if e.Diff != "" {
+		return fmt.Sprintf("values differ (-expected +actual):\n%s", e.Diff)
+	}
 	var mulerr error
 	mulerr = &multierror.Error{
 		Errors: nil,
@@ -273,7 +279,7 @@ func (e *MultiPathError) appendPath(path string) {
 func (e *MultiPathError) wrapf(message string, args ...interface{}) {
 	for idx, err := range e.Errs {
 		var pathErr Error
-		if errors.As(err, &e) {
+		if errors.As(err, &pathErr) {
 			pathErr.wrapf(message, args...)
 		} else {
 			e.Errs[idx] = Wrapf(err, message, args...)
@@ -290,3 +296,77 @@ func (e *MultiPathError) setNodeAndColored(node ast.Node, colored bool) {
 		e.setNodeAndColored(node, colored)
 	}
 }
+
+// PathDiff represents the difference between the expected and actual values at the path.
+type PathDiff struct {
+	Path     string
+	Expected interface{}
+	Actual   interface{}
+	Matcher  string
+}
+
+// DiffError represents an assertion error with the expected and actual values.
+type DiffError struct {
+	Matcher  string
+	Expected interface{}
+	Actual   interface{}
+	Err      error
+}
+
+// NewDiffError creates DiffError instance.
+func NewDiffError(matcher string, expected, actual interface{}, err error) error {
+	return &DiffError{
+		Matcher:  matcher,
+		Expected: expected,
+		Actual:   actual,
+		Err:      err,
+	}
+}
+
+func (e *DiffError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *DiffError) Unwrap() error {
+	return e.Err
+}
+
+// Diffs returns the differences of errors which have the expected and actual values.
+// Errors without the values are ignored.
+func (e *MultiPathError) Diffs() []PathDiff {
+	var diffs []PathDiff
+	for _, err := range e.Errs {
+		diffs = append(diffs, Diffs(err)...)
+	}
+	return diffs
+}
+
+// Diffs returns the differences of err if err is DiffError or an error wrapping DiffError.
+func Diffs(err error) []PathDiff {
+	var merr *MultiPathError
+	if errors.As(err, &merr) {
+		return merr.Diffs()
+	}
+	var path string
+	for err != nil {
+		var diffErr *DiffError
+		if errors.As(err, &diffErr) {
+			return []PathDiff{
+				{
+					Path:     path,
+					Expected: diffErr.Expected,
+					Actual:   diffErr.Actual,
+					Matcher:  diffErr.Matcher,
+				},
+			}
+		}
+		var pathErr *PathError
+		if !errors.As(err, &pathErr) {
+			break
+		}
+		path += pathErr.Path
+		err = pathErr.Err
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/goccy/go-yaml/ast"
 	"github.com/goccy/go-yaml/parser"
 	"github.com/goccy/go-yaml/token"
+	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	"github.com/sergi/go-diff/diffmatchpatch"
 	"github.com/zoncoen/query-go"
@@ -117,6 +118,16 @@ func TestWrap(t *testing.T) {
 	})
 }
 
+func TestWrap_MultiPathError(t *testing.T) {
+	err := Wrap(Errors(Errors(New("a"), New("b")), New("c")), "message")
+	expect := `2 errors occurred: 2 errors occurred: message: a
+message: b
+message: c`
+	if got := err.Error(); got != expect {
+		t.Fatalf("unexpected error message: %s", got)
+	}
+}
+
 func TestWrapPath(t *testing.T) {
 	t.Run("wrap pkg/errors instance", func(t *testing.T) {
 		err := WrapPath(errors.New("message"), "path", "message2")
@@ -366,3 +377,30 @@ unexpected error: invalid b
 		}
 	})
 }
+
+func TestMultiPathError_Diffs(t *testing.T) {
+	err := Errors(
+		WithPath(NewDiffError("Equal", 1, 2, New("expected 1 but got 2")), "a"),
+		Wrap(WithPath(NewDiffError("Greater", 0, -1, New("must be greater than 0")), "b"), "message"),
+		Errors(WithPath(NewDiffError("Equal", "x", "y", New("expected x but got y")), "c[0]")),
+		ErrorPath("d", "no diff"),
+	)
+	var merr *MultiPathError
+	if !errors.As(err, &merr) {
+		t.Fatalf("expect *MultiPathError but %T", err)
+	}
+	expect := []PathDiff{
+		{Path: ".a", Expected: 1, Actual: 2, Matcher: "Equal"},
+		{Path: ".b", Expected: 0, Actual: -1, Matcher: "Greater"},
+		{Path: ".c[0]", Expected: "x", Actual: "y", Matcher: "Equal"},
+	}
+	if diff := cmp.Diff(expect, merr.Diffs()); diff != "" {
+		t.Errorf("diff (-want +got):\n%s", diff)
+	}
+	if got, expect := err.Error(), `4 errors occurred: .a: expected 1 but got 2
+.b: message: must be greater than 0
+1 error occurred: .c[0]: expected x but got y
+.d: no diff`; got != expect {
+		t.Errorf("unexpected error message: %s", got)
+	}
+}
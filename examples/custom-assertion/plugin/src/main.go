@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/plugin"
+)
+
+func init() {
+	plugin.RegisterAssertion("isValidIBAN", assert.AssertionFunc(isValidIBAN))
+	plugin.RegisterSetup(startServer)
+}
+
+// isValidIBAN validates the check digits of IBAN.
+func isValidIBAN(v interface{}) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("expected string but got %T", v)
+	}
+	iban := strings.ReplaceAll(strings.ToUpper(s), " ", "")
+	if len(iban) < 5 || len(iban) > 34 {
+		return fmt.Errorf("invalid IBAN %q: invalid length", s)
+	}
+	// move the first four characters to the end and convert the letters into numbers (A = 10, ..., Z = 35)
+	var b strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		switch {
+		case '0' <= r && r <= '9':
+			b.WriteRune(r)
+		case 'A' <= r && r <= 'Z':
+			fmt.Fprintf(&b, "%d", r-'A'+10)
+		default:
+			return fmt.Errorf("invalid IBAN %q: invalid character %q", s, r)
+		}
+	}
+	n, ok := new(big.Int).SetString(b.String(), 10)
+	if !ok || n.Mod(n, big.NewInt(97)).Int64() != 1 {
+		return fmt.Errorf("invalid IBAN %q: invalid check digits", s)
+	}
+	return nil
+}
+
+var ServerAddr string
+
+func startServer(ctx *plugin.Context) (*plugin.Context, func(*plugin.Context)) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		ctx.Reporter().Fatalf("failed to start server: %s", err)
+	}
+	ServerAddr = ln.Addr().String()
+
+	m := http.NewServeMux()
+	m.Handle("/account", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprint(w, `{"iban":"DE89 3704 0044 0532 0130 00"}`)
+	}))
+	s := http.Server{
+		Handler: m,
+	}
+	go func() {
+		if err := s.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ctx.Reporter().Errorf("failed to start server: %s", err)
+		}
+	}()
+
+	return ctx, func(ctx *plugin.Context) {
+		if err := s.Close(); err != nil {
+			ctx.Reporter().Errorf("failed to close server: %s", err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+module github.com/zoncoen/scenarigo/examples/custom-protocol/plugin
+
+go 1.20
+
+require (
+	github.com/goccy/go-yaml v1.11.2
+	github.com/zoncoen/scenarigo v0.15.1
+)
+
+require (
+	github.com/andybalholm/brotli v1.0.6 // indirect
+	github.com/cenkalti/backoff/v4 v4.2.1 // indirect
+	github.com/fatih/color v1.16.0 // indirect
+	github.com/hashicorp/errwrap v1.1.0 // indirect
+	github.com/hashicorp/go-multierror v1.1.1 // indirect
+	github.com/hashicorp/go-version v1.6.0 // indirect
+	github.com/k14s/starlark-go v0.0.0-20200720175618-3a5c849cc368 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-encoding v0.0.2 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1 // indirect
+	github.com/tetratelabs/wazero v1.5.0 // indirect
+	github.com/vmware-tanzu/carvel-ytt v0.45.4 // indirect
+	github.com/zoncoen/query-go v1.2.1 // indirect
+	github.com/zoncoen/query-go/extractor/yaml v0.1.1 // indirect
+	golang.org/x/mod v0.14.0 // indirect
+	golang.org/x/net v0.17.0 // indirect
+	golang.org/x/sys v0.14.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	golang.org/x/xerrors v0.0.0-20220907171357-04be3eba64a2 // indirect
+)
+
+replace github.com/zoncoen/scenarigo v0.15.1 => ../../../..
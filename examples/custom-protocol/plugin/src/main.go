@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/plugin"
+	"github.com/zoncoen/scenarigo/protocol"
+)
+
+func init() {
+	plugin.RegisterProtocol(&kv{})
+}
+
+// kv is a protocol to get and set values of an in-memory key-value store.
+type kv struct {
+	m      sync.Mutex
+	values map[string]interface{}
+}
+
+// Name implements protocol.Protocol interface.
+func (p *kv) Name() string {
+	return "kv"
+}
+
+// UnmarshalRequest implements protocol.Protocol interface.
+func (p *kv) UnmarshalRequest(b []byte) (protocol.Invoker, error) {
+	req := &request{kv: p}
+	if err := yaml.UnmarshalWithOptions(b, req, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// UnmarshalExpect implements protocol.Protocol interface.
+func (p *kv) UnmarshalExpect(b []byte) (protocol.AssertionBuilder, error) {
+	e := &expect{}
+	if err := yaml.UnmarshalWithOptions(b, e, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// request represents a request. It sets the value if it is not nil and gets the value of the key.
+type request struct {
+	Key   string      `yaml:"key"`
+	Value interface{} `yaml:"value,omitempty"`
+
+	kv *kv
+}
+
+// response represents a response.
+type response struct {
+	Found bool        `yaml:"found"`
+	Value interface{} `yaml:"value"`
+}
+
+// Invoke implements protocol.Invoker interface.
+func (r *request) Invoke(ctx *context.Context) (*context.Context, interface{}, error) {
+	key, err := ctx.ExecuteTemplate(r.Key)
+	if err != nil {
+		return ctx, nil, errors.WithPath(err, "key")
+	}
+	k, ok := key.(string)
+	if !ok {
+		return ctx, nil, errors.ErrorPathf("key", "key must be a string but got %T", key)
+	}
+	value, err := ctx.ExecuteTemplate(r.Value)
+	if err != nil {
+		return ctx, nil, errors.WithPath(err, "value")
+	}
+	ctx = ctx.WithRequest(map[string]interface{}{"key": k, "value": value})
+
+	r.kv.m.Lock()
+	defer r.kv.m.Unlock()
+	if r.kv.values == nil {
+		r.kv.values = map[string]interface{}{}
+	}
+	if value != nil {
+		r.kv.values[k] = value
+	}
+	v, found := r.kv.values[k]
+	resp := &response{Found: found, Value: v}
+	return ctx.WithResponse(resp), resp, nil
+}
+
+// expect represents expected response values.
+type expect struct {
+	Found *bool       `yaml:"found,omitempty"`
+	Value interface{} `yaml:"value,omitempty"`
+}
+
+// Build implements protocol.AssertionBuilder interface.
+func (e *expect) Build(ctx *context.Context) (assert.Assertion, error) {
+	value, err := assert.Build(ctx.RequestContext(), e.Value, assert.FromTemplate(ctx))
+	if err != nil {
+		return nil, errors.WithPath(err, "value")
+	}
+	return assert.AssertionFunc(func(v interface{}) error {
+		resp, ok := v.(*response)
+		if !ok {
+			return fmt.Errorf("expected *response but got %T", v)
+		}
+		if e.Found != nil && *e.Found != resp.Found {
+			return errors.ErrorPathf("found", "expected %t but got %t", *e.Found, resp.Found)
+		}
+		if e.Value != nil {
+			if err := value.Assert(resp.Value); err != nil {
+				return errors.WithPath(err, "value")
+			}
+		}
+		return nil
+	}), nil
+}
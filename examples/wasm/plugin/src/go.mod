@@ -0,0 +1,3 @@
+module github.com/zoncoen/scenarigo/examples/wasm/plugin
+
+go 1.24
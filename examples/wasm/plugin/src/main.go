@@ -0,0 +1,90 @@
+//go:build wasip1
+
+// This is a WebAssembly plugin example.
+// Build it by the following command.
+//
+//	GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared -o ../gen/plugin.wasm .
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"unsafe"
+)
+
+func main() {}
+
+// buffers keeps the allocated memory from the garbage collector until it is freed.
+var buffers = map[uint32][]byte{}
+
+//go:wasmexport scenarigo_malloc
+func malloc(size uint32) uint32 {
+	if size == 0 {
+		size = 1
+	}
+	b := make([]byte, size)
+	ptr := uint32(uintptr(unsafe.Pointer(unsafe.SliceData(b))))
+	buffers[ptr] = b
+	return ptr
+}
+
+//go:wasmexport scenarigo_free
+func free(ptr, _ uint32) {
+	delete(buffers, ptr)
+}
+
+func read(ptr, size uint32) []byte {
+	return buffers[ptr][:size]
+}
+
+func write(v any) uint64 {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	ptr := malloc(uint32(len(b)))
+	copy(buffers[ptr], b)
+	return uint64(ptr)<<32 | uint64(len(b))
+}
+
+// Greet is a template function.
+//
+//	{{plugins.wasm.Greet("scenarigo")}}
+//
+//go:wasmexport scenarigo_func_Greet
+func greet(ptr, size uint32) uint64 {
+	var args []string
+	if err := json.Unmarshal(read(ptr, size), &args); err != nil {
+		return write(map[string]string{"error": err.Error()})
+	}
+	if len(args) != 1 {
+		return write(map[string]string{"error": fmt.Sprintf("expected 1 argument but got %d", len(args))})
+	}
+	return write(map[string]string{"result": fmt.Sprintf("Hello, %s!", args[0])})
+}
+
+// Count is a step which counts the items of the vars.
+//
+//	ref: '{{plugins.wasm.Count}}'
+//	vars:
+//	  items: [a, b, c]
+//	bind:
+//	  vars:
+//	    count: '{{vars.count}}'
+//
+//go:wasmexport scenarigo_step_Count
+func count(ptr, size uint32) uint64 {
+	var in struct {
+		Title string `json:"title"`
+		Vars  struct {
+			Items []any `json:"items"`
+		} `json:"vars"`
+	}
+	if err := json.Unmarshal(read(ptr, size), &in); err != nil {
+		return write(map[string]string{"error": err.Error()})
+	}
+	return write(map[string]any{
+		"logs": []string{fmt.Sprintf("%s: %d items", in.Title, len(in.Vars.Items))},
+		"vars": map[string]any{"count": len(in.Vars.Items)},
+	})
+}
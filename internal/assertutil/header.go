@@ -36,7 +36,8 @@ func BuildHeaderAssertion(ctx *context.Context, in yaml.MapSlice) (assert.Assert
 		}
 
 		// Wrap with the "Contains" function to allow using not only an array but also just a string.
-		if reflect.ValueOf(elem.Value).Kind() != reflect.Slice {
+		// The assertions for lists assert all values of the header instead.
+		if _, ok := valAssertion.(assert.ListAssertion); !ok && reflect.ValueOf(elem.Value).Kind() != reflect.Slice {
 			valAssertion = assert.Contains(valAssertion)
 		}
 
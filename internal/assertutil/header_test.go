@@ -1,11 +1,14 @@
 package assertutil
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/assert"
 	"github.com/zoncoen/scenarigo/context"
 )
 
@@ -92,7 +95,142 @@ foo: '{{$ != ""}}'
 				},
 			},
 		},
+		"list matcher (length)": {
+			in: `
+foo: '{{assert.length(2)}}'
+`,
+			ok: map[string][]string{
+				"foo": {
+					"a",
+					"b",
+				},
+			},
+			ng: map[string][]string{
+				"foo": {
+					"ab",
+				},
+			},
+		},
+		"list matcher (all)": {
+			in: `
+foo: '{{assert.all(assert.hasPrefix("a="))}}'
+`,
+			ok: map[string][]string{
+				"foo": {
+					"a=1",
+					"a=2",
+				},
+			},
+			ng: map[string][]string{
+				"foo": {
+					"a=1",
+					"b=2",
+				},
+			},
+		},
+		"list matcher with spaces": {
+			in: `
+foo: '{{ assert.length(2) }}'
+`,
+			ok: map[string][]string{
+				"foo": {
+					"a",
+					"b",
+				},
+			},
+			ng: map[string][]string{
+				"foo": {
+					"ab",
+				},
+			},
+		},
+		"list matcher without assert": {
+			in: `
+foo: '{{length(2)}}'
+`,
+			ok: map[string][]string{
+				"foo": {
+					"a",
+					"b",
+				},
+			},
+			ng: map[string][]string{
+				"foo": {
+					"ab",
+				},
+			},
+		},
+		"list matcher (empty)": {
+			in: `
+foo: '{{assert.empty}}'
+`,
+			ok: map[string][]string{
+				"foo": {},
+			},
+			ng: map[string][]string{
+				"foo": {
+					"",
+				},
+			},
+		},
+		"list matcher (notEmpty)": {
+			in: `
+foo: '{{assert.notEmpty}}'
+`,
+			ok: map[string][]string{
+				"foo": {
+					"",
+				},
+			},
+			ng: map[string][]string{
+				"foo": {},
+			},
+		},
+		"custom assertion": {
+			in: `
+foo: '{{testIsShort}}'
+`,
+			ok: map[string][]string{
+				"foo": {
+					"a",
+					"long",
+				},
+			},
+			ng: map[string][]string{
+				"foo": {
+					"long",
+				},
+			},
+		},
+		"custom list assertion": {
+			in: `
+foo: '{{testIsPair}}'
+`,
+			ok: map[string][]string{
+				"foo": {
+					"a",
+					"b",
+				},
+			},
+			ng: map[string][]string{
+				"foo": {
+					"a",
+				},
+			},
+		},
 	}
+	assert.RegisterMatcher("testIsShort", assert.AssertionFunc(func(v interface{}) error {
+		if s, ok := v.(string); !ok || len(s) > 1 {
+			return fmt.Errorf("%v is not short", v)
+		}
+		return nil
+	}))
+	assert.RegisterMatcher("testIsPair", testListAssertion(func(v interface{}) error {
+		if reflect.ValueOf(v).Len() != 2 {
+			return fmt.Errorf("%v is not a pair", v)
+		}
+		return nil
+	}))
 	for name, test := range tests {
 		test := test
 		t.Run(name, func(t *testing.T) {
@@ -116,6 +254,14 @@ foo: '{{$ != ""}}'
 	}
 }
 
+type testListAssertion func(v interface{}) error
+
+func (f testListAssertion) Assert(v interface{}) error {
+	return f(v)
+}
+
+func (f testListAssertion) AssertsList() {}
+
 func Test_BuildHeaderAssertion_Error(t *testing.T) {
 	tests := map[string]struct {
 		expect yaml.MapSlice
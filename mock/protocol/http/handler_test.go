@@ -196,7 +196,7 @@ func TestHandler(t *testing.T) {
 							header: http.Header{
 								"Content-Type": []string{"text/plain; charset=utf-8"},
 							},
-							body: `assertion error: .body.message: expected not zero value`,
+							body: `assertion error: .body.message: expected not zero value but got ""`,
 						},
 					},
 				},
@@ -6,7 +6,11 @@ import (
 	"plugin"
 	"reflect"
 	"strconv"
+	"strings"
 	"sync"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/protocol"
 )
 
 var (
@@ -15,7 +19,7 @@ var (
 	newPlugin *openedPlugin
 )
 
-// Open opens a Go plugin.
+// Open opens a Go plugin or a WebAssembly plugin whose extension is ".wasm".
 // If a path has already been opened, then the existing *Plugin is returned.
 // It is safe for concurrent use by multiple goroutines.
 func Open(path string) (Plugin, error) {
@@ -32,6 +36,14 @@ func Open(path string) (Plugin, error) {
 	if p, ok := cache[path]; ok {
 		return p, nil
 	}
+	if strings.EqualFold(filepath.Ext(path), ".wasm") {
+		p, err := openWASM(path)
+		if err != nil {
+			return nil, err
+		}
+		cache[path] = p
+		return p, nil
+	}
 	newPlugin = &openedPlugin{} //nolint:exhaustruct
 	defer func() { newPlugin = nil }()
 	p, err := plugin.Open(path)
@@ -39,6 +51,17 @@ func Open(path string) (Plugin, error) {
 		return nil, err
 	}
 	newPlugin.Plugin = p
+	for _, a := range newPlugin.assertions {
+		if err := context.RegisterAssertion(a.name, a.v); err != nil {
+			return nil, fmt.Errorf("failed to register assertion: %w", err)
+		}
+	}
+	for _, p := range newPlugin.protocols {
+		if protocol.Get(p.Name()) != nil {
+			return nil, fmt.Errorf("failed to register protocol: protocol %q is already registered", p.Name())
+		}
+		protocol.Register(p)
+	}
 	cache[path] = newPlugin
 	return newPlugin, nil
 }
@@ -68,6 +91,31 @@ func RegisterSetup(setup SetupFunc) {
 	newPlugin.setups = append(newPlugin.setups, setup)
 }
 
+// RegisterAssertion registers a custom assertion which is available as <name> and assert.<name> in templates.
+// v must be an assert.Assertion or a function which returns an assert.Assertion.
+// Plugins must call this function in their init function if it registers the assertion.
+// Opening the plugin fails if name is already used by another assertion.
+func RegisterAssertion(name string, v interface{}) {
+	if newPlugin == nil {
+		panic("RegisterAssertion must be called in init()")
+	}
+	newPlugin.m.Lock()
+	defer newPlugin.m.Unlock()
+	newPlugin.assertions = append(newPlugin.assertions, namedAssertion{name: name, v: v})
+}
+
+// RegisterProtocol registers a protocol which is available in the protocol field of steps.
+// Plugins must call this function in their init function if it registers the protocol.
+// Opening the plugin fails if the protocol name is already registered.
+func RegisterProtocol(p protocol.Protocol) {
+	if newPlugin == nil {
+		panic("RegisterProtocol must be called in init()")
+	}
+	newPlugin.m.Lock()
+	defer newPlugin.m.Unlock()
+	newPlugin.protocols = append(newPlugin.protocols, p)
+}
+
 // RegisterSetupEachScenario registers a function to setup for plugin.
 // Plugins must call this function in their init function if it registers the setup process.
 // The registered function will be called before each scenario.
@@ -85,6 +133,13 @@ type openedPlugin struct {
 	m                  sync.Mutex
 	setups             []SetupFunc
 	setupsEachScenario []SetupFunc
+	assertions         []namedAssertion
+	protocols          []protocol.Protocol
+}
+
+type namedAssertion struct {
+	name string
+	v    interface{}
 }
 
 // GetSetup implements Plugin interface.
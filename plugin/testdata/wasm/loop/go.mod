@@ -0,0 +1,3 @@
+module github.com/zoncoen/scenarigo/plugin/testdata/wasm/loop
+
+go 1.24
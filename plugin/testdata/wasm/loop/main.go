@@ -0,0 +1,32 @@
+//go:build wasip1
+
+// This is a WebAssembly plugin which never returns to test the cancellation.
+package main
+
+import "unsafe"
+
+func main() {}
+
+var buffers = map[uint32][]byte{}
+
+//go:wasmexport scenarigo_malloc
+func malloc(size uint32) uint32 {
+	b := make([]byte, size)
+	ptr := uint32(uintptr(unsafe.Pointer(unsafe.SliceData(b))))
+	buffers[ptr] = b
+	return ptr
+}
+
+//go:wasmexport scenarigo_free
+func free(ptr, _ uint32) {
+	delete(buffers, ptr)
+}
+
+var n int
+
+//go:wasmexport scenarigo_step_Loop
+func loop(_, _ uint32) uint64 {
+	for {
+		n++
+	}
+}
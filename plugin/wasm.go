@@ -0,0 +1,272 @@
+package plugin
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+// A WebAssembly plugin is a WASI module which communicates with scenarigo through the following exports.
+// All values are passed as JSON in the linear memory exported as "memory".
+//
+//	scenarigo_malloc(size i32) i32
+//		Allocates size bytes and returns the pointer to write the JSON arguments.
+//	scenarigo_free(ptr i32, size i32) (optional)
+//		Frees the memory allocated by scenarigo_malloc or returned by the following functions.
+//	scenarigo_func_<Name>(ptr i32, len i32) i64
+//		Template function <Name>.
+//		It receives the arguments as a JSON array and returns {"result": <value>} or {"error": "<message>"}.
+//	scenarigo_step_<Name>(ptr i32, len i32) i64
+//		Step <Name>.
+//		It receives {"title": "<step title>", "vars": <step vars>} and returns {"logs": ["<log>"], "vars": <vars>, "error": "<message>"}.
+//		The returned vars are added to the variables of the following templates such as bind.
+//
+// The results are returned as the pointer in the upper 32 bits and the length in the lower 32 bits.
+// It calls "_initialize" after instantiation if the module exports it.
+const (
+	wasmMallocName = "scenarigo_malloc"
+	wasmFreeName   = "scenarigo_free"
+	wasmFuncPrefix = "scenarigo_func_"
+	wasmStepPrefix = "scenarigo_step_"
+)
+
+// wasmPlugin is a plugin which is loaded from a WebAssembly module.
+// The calls are serialized because the module instance is not safe for concurrent use.
+// The module instance is closed when the context of a call is done, and the later calls fail.
+type wasmPlugin struct {
+	m       sync.Mutex
+	runtime wazero.Runtime
+	module  api.Module
+	malloc  api.Function
+	free    api.Function
+	symbols map[string]Symbol
+}
+
+func openWASM(path string) (*wasmPlugin, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WASM plugin: %w", err)
+	}
+	ctx := gocontext.Background()
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, r); err != nil {
+		_ = r.Close(ctx)
+		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+	}
+	compiled, err := r.CompileModule(ctx, b)
+	if err != nil {
+		_ = r.Close(ctx)
+		return nil, fmt.Errorf("failed to compile WASM plugin: %w", err)
+	}
+	config := wazero.NewModuleConfig().WithStdout(os.Stdout).WithStderr(os.Stderr).WithStartFunctions()
+	if _, ok := compiled.ExportedFunctions()["_initialize"]; ok {
+		config = config.WithStartFunctions("_initialize")
+	}
+	mod, err := r.InstantiateModule(ctx, compiled, config)
+	if err != nil {
+		_ = r.Close(ctx)
+		return nil, fmt.Errorf("failed to instantiate WASM plugin: %w", err)
+	}
+	p := &wasmPlugin{
+		runtime: r,
+		module:  mod,
+		malloc:  mod.ExportedFunction(wasmMallocName),
+		free:    mod.ExportedFunction(wasmFreeName),
+		symbols: map[string]Symbol{},
+	}
+	if p.malloc == nil || mod.Memory() == nil {
+		_ = r.Close(ctx)
+		return nil, fmt.Errorf("invalid WASM plugin: %s and memory must be exported", wasmMallocName)
+	}
+	for name := range compiled.ExportedFunctions() {
+		switch {
+		case strings.HasPrefix(name, wasmFuncPrefix):
+			p.symbols[strings.TrimPrefix(name, wasmFuncPrefix)] = p.templateFunc(mod.ExportedFunction(name))
+		case strings.HasPrefix(name, wasmStepPrefix):
+			p.symbols[strings.TrimPrefix(name, wasmStepPrefix)] = p.step(mod.ExportedFunction(name))
+		}
+	}
+	return p, nil
+}
+
+// Lookup implements Plugin interface.
+func (p *wasmPlugin) Lookup(name string) (Symbol, error) {
+	sym, ok := p.symbols[name]
+	if !ok {
+		return nil, fmt.Errorf("symbol %s not found in WASM plugin", name)
+	}
+	return sym, nil
+}
+
+// GetSetup implements Plugin interface.
+// WASM plugins don't have setup functions.
+func (p *wasmPlugin) GetSetup() SetupFunc {
+	return nil
+}
+
+// GetSetupEachScenario implements Plugin interface.
+// WASM plugins don't have setup functions.
+func (p *wasmPlugin) GetSetupEachScenario() SetupFunc {
+	return nil
+}
+
+// ExtractByKey implements query.KeyExtractor interface.
+func (p *wasmPlugin) ExtractByKey(key string) (interface{}, bool) {
+	sym, err := p.Lookup(key)
+	if err != nil {
+		return nil, false
+	}
+	return sym, true
+}
+
+type wasmFuncResult struct {
+	Result interface{} `json:"result"`
+	Error  string      `json:"error"`
+}
+
+// templateFunc returns the template function of fn.
+// It isn't stopped by the context because template functions don't receive the context.
+func (p *wasmPlugin) templateFunc(fn api.Function) func(...interface{}) (interface{}, error) {
+	return func(args ...interface{}) (interface{}, error) {
+		if args == nil {
+			args = []interface{}{}
+		}
+		var res wasmFuncResult
+		if err := p.call(gocontext.Background(), fn, args, &res); err != nil {
+			return nil, err
+		}
+		if res.Error != "" {
+			return nil, errors.New(res.Error)
+		}
+		return normalizeJSONValue(res.Result), nil
+	}
+}
+
+type wasmStepInput struct {
+	Title string      `json:"title"`
+	Vars  interface{} `json:"vars"`
+}
+
+type wasmStepResult struct {
+	Logs  []string    `json:"logs"`
+	Vars  interface{} `json:"vars"`
+	Error string      `json:"error"`
+}
+
+func (p *wasmPlugin) step(fn api.Function) StepFunc {
+	return func(ctx *context.Context, step *schema.Step) *context.Context {
+		in := wasmStepInput{
+			Title: step.Title,
+		}
+		if step.Vars != nil {
+			vars, err := ctx.ExecuteTemplate(step.Vars)
+			if err != nil {
+				ctx.Reporter().Fatalf("invalid vars: %s", err)
+			}
+			in.Vars = vars
+		}
+		var res wasmStepResult
+		if err := p.call(ctx.RequestContext(), fn, in, &res); err != nil {
+			ctx.Reporter().Fatal(err)
+		}
+		for _, l := range res.Logs {
+			ctx.Reporter().Log(l)
+		}
+		if res.Error != "" {
+			ctx.Reporter().Fatal(res.Error)
+		}
+		if res.Vars != nil {
+			ctx = ctx.WithVars(normalizeJSONValue(res.Vars))
+		}
+		return ctx
+	}
+}
+
+// call calls fn with the JSON of in and decodes the returned JSON into out.
+// It stops fn when ctx is done.
+func (p *wasmPlugin) call(ctx gocontext.Context, fn api.Function, in, out interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal arguments: %w", err)
+	}
+
+	p.m.Lock()
+	defer p.m.Unlock()
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to call %s: %w", fn.Definition().Name(), err)
+	}
+	size := uint64(len(b))
+	if size == 0 {
+		size = 1
+	}
+	res, err := p.malloc.Call(ctx, size)
+	if err != nil {
+		return fmt.Errorf("failed to allocate memory: %w", err)
+	}
+	ptr := uint32(res[0])
+	defer p.release(ctx, ptr, uint32(size))
+	if !p.module.Memory().Write(ptr, b) {
+		return fmt.Errorf("failed to write arguments: out of range")
+	}
+	res, err = fn.Call(ctx, uint64(ptr), uint64(len(b)))
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", fn.Definition().Name(), err)
+	}
+	resPtr, resLen := uint32(res[0]>>32), uint32(res[0])
+	defer p.release(ctx, resPtr, resLen)
+	v, ok := p.module.Memory().Read(resPtr, resLen)
+	if !ok {
+		return fmt.Errorf("failed to read result of %s: out of range", fn.Definition().Name())
+	}
+	d := json.NewDecoder(bytes.NewReader(v))
+	d.UseNumber()
+	if err := d.Decode(out); err != nil {
+		return fmt.Errorf("failed to unmarshal result of %s: %w", fn.Definition().Name(), err)
+	}
+	return nil
+}
+
+func (p *wasmPlugin) release(ctx gocontext.Context, ptr, size uint32) {
+	if p.free == nil {
+		return
+	}
+	_, _ = p.free.Call(ctx, uint64(ptr), uint64(size))
+}
+
+// normalizeJSONValue converts json.Number into int64 or float64.
+func normalizeJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeJSONValue(e)
+		}
+		return v
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizeJSONValue(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
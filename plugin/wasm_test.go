@@ -0,0 +1,139 @@
+package plugin
+
+import (
+	"bytes"
+	gocontext "context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/reporter"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+func buildWASMPlugin(t *testing.T, dir string) string {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), "plugin.wasm")
+	cmd := exec.Command("go", "build", "-buildmode=c-shared", "-o", out, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm", "GOFLAGS=", "GOWORK=off")
+	if b, err := cmd.CombinedOutput(); err != nil {
+		// go:wasmexport requires Go 1.24 or later
+		t.Skipf("failed to build WASM plugin: %s\n%s", err, b)
+	}
+	return out
+}
+
+func TestOpen_WASM(t *testing.T) {
+	path := buildWASMPlugin(t, "../examples/wasm/plugin/src")
+	p, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open plugin: %s", err)
+	}
+
+	t.Run("function", func(t *testing.T) {
+		sym, err := p.Lookup("Greet")
+		if err != nil {
+			t.Fatalf("failed to lookup: %s", err)
+		}
+		f, ok := sym.(func(...interface{}) (interface{}, error))
+		if !ok {
+			t.Fatalf("unexpected type %T", sym)
+		}
+		v, err := f("scenarigo")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got, expect := v, "Hello, scenarigo!"; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+		if _, err := f(); err == nil || !strings.Contains(err.Error(), "expected 1 argument but got 0") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("template", func(t *testing.T) {
+		ctx := context.FromT(t).WithPlugins(map[string]interface{}{"wasm": p})
+		v, err := ctx.ExecuteTemplate(`{{plugins.wasm.Greet("wasm")}}`)
+		if err != nil {
+			t.Fatalf("failed to execute: %s", err)
+		}
+		if got, expect := v, "Hello, wasm!"; got != expect {
+			t.Errorf("expect %q but got %q", expect, got)
+		}
+	})
+
+	t.Run("step", func(t *testing.T) {
+		sym, err := p.Lookup("Count")
+		if err != nil {
+			t.Fatalf("failed to lookup: %s", err)
+		}
+		step, ok := sym.(Step)
+		if !ok {
+			t.Fatalf("unexpected type %T", sym)
+		}
+		var count interface{}
+		ok = reporter.Run(func(rptr reporter.Reporter) {
+			ctx := step.Run(context.New(rptr), &schema.Step{
+				Title: "count",
+				Vars: map[string]interface{}{
+					"items": []interface{}{"a", "b", "c"},
+				},
+			})
+			count, err = ctx.ExecuteTemplate("{{vars.count}}")
+		})
+		if !ok {
+			t.Fatal("step failed")
+		}
+		if err != nil {
+			t.Fatalf("failed to execute: %s", err)
+		}
+		if got, expect := count, int64(3); got != expect {
+			t.Errorf("expect %v but got %v", expect, got)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		if _, err := p.Lookup("Unknown"); err == nil {
+			t.Fatal("no error")
+		}
+	})
+}
+
+func TestOpen_WASM_Cancel(t *testing.T) {
+	path := buildWASMPlugin(t, "testdata/wasm/loop")
+	p, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open plugin: %s", err)
+	}
+	sym, err := p.Lookup("Loop")
+	if err != nil {
+		t.Fatalf("failed to lookup: %s", err)
+	}
+	step, ok := sym.(Step)
+	if !ok {
+		t.Fatalf("unexpected type %T", sym)
+	}
+	reqCtx, cancel := gocontext.WithTimeout(gocontext.Background(), 100*time.Millisecond)
+	defer cancel()
+	var b bytes.Buffer
+	start := time.Now()
+	ok = reporter.Run(func(rptr reporter.Reporter) {
+		rptr.Run("loop", func(rptr reporter.Reporter) {
+			step.Run(context.New(rptr).WithRequestContext(reqCtx), &schema.Step{Title: "loop"})
+		})
+	}, reporter.WithWriter(&b))
+	if ok {
+		t.Fatal("step succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("too slow: %s", elapsed)
+	}
+	if expect := "failed to call scenarigo_step_Loop"; !strings.Contains(b.String(), expect) {
+		t.Errorf("expect %q in the output but got:\n%s", expect, b.String())
+	}
+}
@@ -0,0 +1,46 @@
+// Package db provides the protocol which executes SQL statements for the scenarigo step.
+package db
+
+import (
+	"bytes"
+
+	"github.com/goccy/go-yaml"
+	"github.com/zoncoen/scenarigo/protocol"
+)
+
+const protocolName = "db"
+
+// Register registers db protocol.
+func Register() {
+	protocol.Register(&DB{})
+}
+
+// DB is a protocol type for the scenarigo step.
+type DB struct{}
+
+// Name implements protocol.Protocol interface.
+func (p *DB) Name() string {
+	return protocolName
+}
+
+// UnmarshalRequest implements protocol.Protocol interface.
+func (p *DB) UnmarshalRequest(b []byte) (protocol.Invoker, error) {
+	var r Request
+	if err := yaml.UnmarshalWithOptions(b, &r, yaml.Strict(), yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// UnmarshalExpect implements protocol.Protocol interface.
+func (p *DB) UnmarshalExpect(b []byte) (protocol.AssertionBuilder, error) {
+	var e Expect
+	if b == nil {
+		return &e, nil
+	}
+	decoder := yaml.NewDecoder(bytes.NewBuffer(b), yaml.UseOrderedMap(), yaml.Strict())
+	if err := decoder.Decode(&e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,10 @@
+// Package drivers registers the database drivers supported by the db protocol.
+// The drivers are "postgres", "mysql", and "sqlite".
+package drivers
+
+import (
+	// register the drivers to database/sql
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// Expect represents expected response values.
+type Expect struct {
+	Columns      interface{} `yaml:"columns,omitempty"`
+	Rows         interface{} `yaml:"rows,omitempty"`
+	RowsAffected interface{} `yaml:"rowsAffected,omitempty"`
+	LastInsertID interface{} `yaml:"lastInsertId,omitempty"`
+}
+
+// Build implements protocol.AssertionBuilder interface.
+func (e *Expect) Build(ctx *context.Context) (assert.Assertion, error) {
+	columnsAssertion, err := assert.Build(ctx.RequestContext(), e.Columns, assert.FromTemplate(ctx))
+	if err != nil {
+		return nil, errors.WrapPathf(err, "columns", "invalid expect columns")
+	}
+	rowsAssertion, err := assert.Build(ctx.RequestContext(), e.Rows, assert.FromTemplate(ctx))
+	if err != nil {
+		return nil, errors.WrapPathf(err, "rows", "invalid expect rows")
+	}
+	rowsAffectedAssertion, err := assert.Build(ctx.RequestContext(), e.RowsAffected, assert.FromTemplate(ctx))
+	if err != nil {
+		return nil, errors.WrapPathf(err, "rowsAffected", "invalid expect rowsAffected")
+	}
+	lastInsertIDAssertion, err := assert.Build(ctx.RequestContext(), e.LastInsertID, assert.FromTemplate(ctx))
+	if err != nil {
+		return nil, errors.WrapPathf(err, "lastInsertId", "invalid expect lastInsertId")
+	}
+
+	return assert.AssertionFunc(func(v interface{}) error {
+		res, ok := v.(response)
+		if !ok {
+			return errors.Errorf("expected response but got %T", v)
+		}
+		if err := columnsAssertion.Assert(res.Columns); err != nil {
+			return errors.WithPath(err, "columns")
+		}
+		if err := rowsAssertion.Assert(res.Rows); err != nil {
+			return errors.WithPath(err, "rows")
+		}
+		if err := rowsAffectedAssertion.Assert(res.RowsAffected); err != nil {
+			return errors.WithPath(err, "rowsAffected")
+		}
+		if err := lastInsertIDAssertion.Assert(res.LastInsertID); err != nil {
+			return errors.WithPath(err, "lastInsertId")
+		}
+		return nil
+	}), nil
+}
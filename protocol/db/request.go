@@ -0,0 +1,285 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+const indentNum = 2
+
+// Request represents a request.
+// Either query or exec must be specified.
+// The statement isn't templated to prevent SQL injection, use args to pass values instead.
+type Request struct {
+	Driver string `yaml:"driver"`
+	DSN    string `yaml:"dsn"`
+
+	// Query is the statement which returns rows such as SELECT.
+	Query string `yaml:"query,omitempty"`
+	// Exec is the statement which doesn't return rows such as INSERT.
+	Exec string `yaml:"exec,omitempty"`
+	// Args is the list of the positional arguments or the map of the named arguments.
+	Args interface{} `yaml:"args,omitempty"`
+}
+
+type response struct {
+	Columns      []string                 `yaml:"columns,omitempty"`
+	Rows         []map[string]interface{} `yaml:"rows,omitempty"`
+	RowsAffected int64                    `yaml:"rowsAffected,omitempty"`
+	LastInsertID int64                    `yaml:"lastInsertId,omitempty"`
+}
+
+func (r *Request) addIndent(s string, indentNum int) string {
+	indent := strings.Repeat(" ", indentNum)
+	lines := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		if line == "" {
+			lines = append(lines, line)
+		} else {
+			lines = append(lines, fmt.Sprintf("%s%s", indent, line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Invoke implements protocol.Invoker interface.
+func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, error) {
+	if (r.Query == "") == (r.Exec == "") {
+		return ctx, nil, errors.New("either query or exec must be specified")
+	}
+	db, closeDB, err := r.open(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+	defer closeDB()
+
+	args, err := r.buildArgs(ctx)
+	if err != nil {
+		return ctx, nil, errors.WithPath(err, "args")
+	}
+
+	//nolint:exhaustruct
+	dumpReq := Request{
+		Driver: r.Driver,
+		Query:  r.Query,
+		Exec:   r.Exec,
+	}
+	if len(args) > 0 {
+		dumpArgs := make([]interface{}, len(args))
+		for i, arg := range args {
+			if named, ok := arg.(sql.NamedArg); ok {
+				arg = yaml.MapSlice{{Key: named.Name, Value: named.Value}}
+			}
+			dumpArgs[i] = arg
+		}
+		dumpReq.Args = dumpArgs
+	}
+	ctx = ctx.WithRequest(dumpReq)
+	if b, err := yaml.Marshal(dumpReq); err == nil {
+		ctx.Reporter().Logf("request:\n%s", r.addIndent(string(b), indentNum))
+	} else {
+		ctx.Reporter().Logf("failed to dump request:\n%s", err)
+	}
+
+	var resp response
+	start := time.Now()
+	if r.Query != "" {
+		resp, err = query(ctx, db, r.Query, args)
+		if err != nil {
+			return ctx, nil, errors.WrapPath(err, "query", "failed to query")
+		}
+	} else {
+		result, err := db.ExecContext(ctx.RequestContext(), r.Exec, args...)
+		if err != nil {
+			return ctx, nil, errors.WrapPath(err, "exec", "failed to execute")
+		}
+		// some drivers don't support them
+		resp.RowsAffected, _ = result.RowsAffected()
+		resp.LastInsertID, _ = result.LastInsertId()
+	}
+	ctx = ctx.WithElapsed(time.Since(start))
+	ctx = ctx.WithResponse(resp)
+	if b, err := yaml.Marshal(resp); err == nil {
+		ctx.Reporter().Logf("response:\n%s", r.addIndent(string(b), indentNum))
+	} else {
+		ctx.Reporter().Logf("failed to dump response:\n%s", err)
+	}
+	return ctx, resp, nil
+}
+
+// open returns the database handle.
+// The handle is reused in the scenario if the context has the connection store.
+func (r *Request) open(ctx *context.Context) (*sql.DB, func(), error) {
+	if r.Driver == "" {
+		return nil, nil, errors.ErrorPath("driver", "driver must be specified")
+	}
+	x, err := ctx.ExecuteTemplate(r.DSN)
+	if err != nil {
+		return nil, nil, errors.WrapPath(err, "dsn", "invalid dsn")
+	}
+	dsn, ok := x.(string)
+	if !ok || dsn == "" {
+		return nil, nil, errors.ErrorPathf("dsn", "dsn must be a string but got %T", x)
+	}
+
+	key := fmt.Sprintf("%s:%s:%s", protocolName, r.Driver, dsn)
+	conns := ctx.Connections()
+	if conns != nil {
+		if db, ok := conns.Get(key).(*sql.DB); ok {
+			return db, func() {}, nil
+		}
+	}
+	db, err := sql.Open(r.Driver, dsn)
+	if err != nil {
+		return nil, nil, errors.WrapPathf(err, "driver", "failed to open database (drivers other than postgres, mysql, and sqlite must be registered by a plugin)")
+	}
+	if conns == nil {
+		return db, func() { db.Close() }, nil
+	}
+	if err := conns.Add(key, db); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return db, func() {}, nil
+}
+
+// buildArgs returns the arguments of the statement.
+func (r *Request) buildArgs(ctx *context.Context) ([]interface{}, error) {
+	if r.Args == nil {
+		return nil, nil
+	}
+	x, err := ctx.ExecuteTemplate(r.Args)
+	if err != nil {
+		return nil, err
+	}
+	switch v := x.(type) {
+	case []interface{}:
+		return v, nil
+	case yaml.MapSlice:
+		args := make([]interface{}, len(v))
+		for i, item := range v {
+			name, ok := item.Key.(string)
+			if !ok {
+				return nil, errors.ErrorPathf(fmt.Sprint(item.Key), "name must be a string but got %T", item.Key)
+			}
+			args[i] = sql.Named(name, item.Value)
+		}
+		return args, nil
+	}
+	return nil, errors.Errorf("args must be a list or a map but got %T", x)
+}
+
+func query(ctx *context.Context, db *sql.DB, q string, args []interface{}) (response, error) {
+	var resp response
+	rows, err := db.QueryContext(ctx.RequestContext(), q, args...)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return resp, err
+	}
+	resp.Columns = make([]string, len(types))
+	for i, t := range types {
+		resp.Columns[i] = t.Name()
+	}
+	resp.Rows = []map[string]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(types))
+		ptrs := make([]interface{}, len(types))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return resp, err
+		}
+		row := make(map[string]interface{}, len(types))
+		for i, t := range types {
+			row[t.Name()] = convertValue(t.DatabaseTypeName(), values[i])
+		}
+		resp.Rows = append(resp.Rows, row)
+	}
+	return resp, rows.Err()
+}
+
+// convertValue converts the scanned value into the type which is easy to assert.
+// Some drivers return the values as bytes, so they are parsed according to the database type name.
+func convertValue(typeName string, v interface{}) interface{} {
+	var s string
+	switch v := v.(type) {
+	case []byte:
+		if isBinaryType(typeName) {
+			return v
+		}
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		// SQLite stores booleans as integers
+		if isBoolType(strings.ToUpper(typeName)) {
+			return v != 0
+		}
+		return v
+	default:
+		return v
+	}
+	t := strings.ToUpper(typeName)
+	switch {
+	case isIntegerType(t):
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return u
+		}
+	case isFloatType(t):
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case isBoolType(t):
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+	return s
+}
+
+func isIntegerType(t string) bool {
+	t = strings.TrimPrefix(t, "UNSIGNED ")
+	switch t {
+	case "INT", "INTEGER", "TINYINT", "SMALLINT", "MEDIUMINT", "BIGINT",
+		"INT2", "INT4", "INT8", "SERIAL", "BIGSERIAL", "SMALLSERIAL", "YEAR":
+		return true
+	}
+	return false
+}
+
+func isFloatType(t string) bool {
+	switch t {
+	case "FLOAT", "DOUBLE", "REAL", "FLOAT4", "FLOAT8", "DECIMAL", "NUMERIC":
+		return true
+	}
+	return false
+}
+
+func isBoolType(t string) bool {
+	return t == "BOOL" || t == "BOOLEAN"
+}
+
+func isBinaryType(t string) bool {
+	switch strings.ToUpper(t) {
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY", "BYTEA":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,317 @@
+package db
+
+import (
+	gocontext "context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+func init() {
+	sql.Register("dbtest", testDriver)
+}
+
+var testDriver = &fakeDriver{}
+
+// fakeDriver records the executed statements and returns the canned rows.
+type fakeDriver struct {
+	mu    sync.Mutex
+	opens int
+	args  []driver.NamedValue
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.opens++
+	return &fakeConn{driver: d}, nil
+}
+
+func (d *fakeDriver) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.opens = 0
+	d.args = nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+func (c *fakeConn) QueryContext(ctx gocontext.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.mu.Lock()
+	c.driver.args = args
+	c.driver.mu.Unlock()
+	if query == "ERROR" {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return &fakeRows{
+		columns: []string{"id", "name", "score", "active", "avatar"},
+		types:   []string{"BIGINT", "VARCHAR", "DECIMAL", "BOOLEAN", "BLOB"},
+		values: [][]driver.Value{
+			{[]byte("1"), []byte("alice"), []byte("1.5"), []byte("true"), []byte{0x01}},
+			{int64(2), "bob", nil, false, []byte{0x02}},
+		},
+	}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx gocontext.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.driver.mu.Lock()
+	c.driver.args = args
+	c.driver.mu.Unlock()
+	return driver.RowsAffected(3), nil
+}
+
+type fakeRows struct {
+	columns []string
+	types   []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(i int) string { return r.types[i] }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestRequest_Invoke(t *testing.T) {
+	t.Run("query", func(t *testing.T) {
+		testDriver.reset()
+		r := &Request{
+			Driver: "dbtest",
+			DSN:    "{{vars.dsn}}",
+			Query:  "SELECT * FROM users WHERE id > ?",
+			Args:   []interface{}{"{{vars.id}}"},
+		}
+		ctx := context.FromT(t).WithVars(map[string]interface{}{
+			"dsn": "test",
+			"id":  0,
+		}).WithConnections(context.NewConnections())
+		defer ctx.Connections().Close()
+
+		ctx, result, err := r.Invoke(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		expect := response{
+			Columns: []string{"id", "name", "score", "active", "avatar"},
+			Rows: []map[string]interface{}{
+				{"id": int64(1), "name": "alice", "score": 1.5, "active": true, "avatar": []byte{0x01}},
+				{"id": int64(2), "name": "bob", "score": nil, "active": false, "avatar": []byte{0x02}},
+			},
+		}
+		if diff := cmp.Diff(expect, result); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]driver.NamedValue{{Ordinal: 1, Value: int64(0)}}, testDriver.args); diff != "" {
+			t.Errorf("args differs (-want +got):\n%s", diff)
+		}
+		got, err := ctx.ExecuteTemplate("{{response.rows[1].name}}")
+		if err != nil {
+			t.Fatalf("failed to execute template: %s", err)
+		}
+		if got != "bob" {
+			t.Errorf(`expected "bob" but got %v`, got)
+		}
+
+		// the handle is reused
+		if _, _, err := r.Invoke(ctx); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if testDriver.opens != 1 {
+			t.Errorf("expected 1 connection but opened %d", testDriver.opens)
+		}
+	})
+	t.Run("exec with named args", func(t *testing.T) {
+		testDriver.reset()
+		r := &Request{
+			Driver: "dbtest",
+			DSN:    "test",
+			Exec:   "UPDATE users SET name = :name",
+			Args: yaml.MapSlice{
+				{Key: "name", Value: "{{vars.name}}"},
+			},
+		}
+		ctx := context.FromT(t).WithVars(map[string]interface{}{
+			"name": "carol",
+		})
+		_, result, err := r.Invoke(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if diff := cmp.Diff(response{RowsAffected: 3}, result); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]driver.NamedValue{{Name: "name", Ordinal: 1, Value: "carol"}}, testDriver.args); diff != "" {
+			t.Errorf("args differs (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("failure", func(t *testing.T) {
+		tests := map[string]struct {
+			request     *Request
+			expectError string
+		}{
+			"no statement": {
+				request: &Request{
+					Driver: "dbtest",
+					DSN:    "test",
+				},
+				expectError: "either query or exec must be specified",
+			},
+			"both query and exec": {
+				request: &Request{
+					Driver: "dbtest",
+					DSN:    "test",
+					Query:  "SELECT 1",
+					Exec:   "DELETE FROM users",
+				},
+				expectError: "either query or exec must be specified",
+			},
+			"no driver": {
+				request: &Request{
+					DSN:   "test",
+					Query: "SELECT 1",
+				},
+				expectError: ".driver: driver must be specified",
+			},
+			"unknown driver": {
+				request: &Request{
+					Driver: "unknown",
+					DSN:    "test",
+					Query:  "SELECT 1",
+				},
+				expectError: "failed to open database",
+			},
+			"invalid dsn": {
+				request: &Request{
+					Driver: "dbtest",
+					DSN:    "{{vars.dsn}}",
+					Query:  "SELECT 1",
+				},
+				expectError: ".dsn: invalid dsn",
+			},
+			"invalid args": {
+				request: &Request{
+					Driver: "dbtest",
+					DSN:    "test",
+					Query:  "SELECT 1",
+					Args:   "1",
+				},
+				expectError: ".args: args must be a list or a map but got string",
+			},
+			"query error": {
+				request: &Request{
+					Driver: "dbtest",
+					DSN:    "test",
+					Query:  "ERROR",
+				},
+				expectError: ".query: failed to query",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				_, _, err := test.request.Invoke(context.FromT(t))
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("expected error %q but got %q", test.expectError, err)
+				}
+			})
+		}
+	})
+}
+
+func TestExpect_Build(t *testing.T) {
+	resp := response{
+		Columns: []string{"id", "name"},
+		Rows: []map[string]interface{}{
+			{"id": int64(1), "name": "alice"},
+		},
+		RowsAffected: 1,
+	}
+	tests := map[string]struct {
+		expect      *Expect
+		expectError string
+	}{
+		"empty": {
+			expect: &Expect{},
+		},
+		"match": {
+			expect: &Expect{
+				Columns: []interface{}{"id", "name"},
+				Rows: []interface{}{
+					yaml.MapSlice{
+						{Key: "id", Value: 1},
+						{Key: "name", Value: "alice"},
+					},
+				},
+				RowsAffected: 1,
+			},
+		},
+		"rows mismatch": {
+			expect: &Expect{
+				Rows: []interface{}{
+					yaml.MapSlice{
+						{Key: "name", Value: "bob"},
+					},
+				},
+			},
+			expectError: ".rows[0].name",
+		},
+		"rowsAffected mismatch": {
+			expect: &Expect{
+				RowsAffected: 2,
+			},
+			expectError: ".rowsAffected",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := test.expect.Build(context.FromT(t))
+			if err != nil {
+				t.Fatalf("failed to build assertion: %s", err)
+			}
+			err = assertion.Assert(resp)
+			if test.expectError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), test.expectError) {
+				t.Errorf("expected error %q but got %q", test.expectError, err)
+			}
+		})
+	}
+}
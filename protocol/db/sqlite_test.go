@@ -0,0 +1,58 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+	_ "github.com/zoncoen/scenarigo/protocol/db/drivers"
+)
+
+func TestRequest_Invoke_SQLite(t *testing.T) {
+	ctx := context.FromT(t).WithVars(map[string]interface{}{
+		"dsn":  filepath.Join(t.TempDir(), "test.db"),
+		"name": "alice",
+	}).WithConnections(context.NewConnections())
+	defer ctx.Connections().Close()
+
+	for i, r := range []*Request{
+		{Exec: "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, score REAL, active BOOLEAN)"},
+		{Exec: "INSERT INTO users (name, score, active) VALUES (?, ?, ?)", Args: []interface{}{"{{vars.name}}", 1.5, true}},
+		{Exec: "INSERT INTO users (name, active) VALUES (:name, false)", Args: yaml.MapSlice{{Key: "name", Value: "bob"}}},
+	} {
+		r.Driver = "sqlite"
+		r.DSN = "{{vars.dsn}}"
+		_, result, err := r.Invoke(ctx)
+		if err != nil {
+			t.Fatalf("[%d] unexpected error: %s", i, err)
+		}
+		if i > 0 {
+			if diff := cmp.Diff(response{RowsAffected: 1, LastInsertID: int64(i)}, result); diff != "" {
+				t.Errorf("[%d] differs (-want +got):\n%s", i, diff)
+			}
+		}
+	}
+
+	r := &Request{
+		Driver: "sqlite",
+		DSN:    "{{vars.dsn}}",
+		Query:  "SELECT id, name, score, active FROM users ORDER BY id",
+	}
+	_, result, err := r.Invoke(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expect := response{
+		Columns: []string{"id", "name", "score", "active"},
+		Rows: []map[string]interface{}{
+			{"id": int64(1), "name": "alice", "score": 1.5, "active": true},
+			{"id": int64(2), "name": "bob", "score": nil, "active": false},
+		},
+	}
+	if diff := cmp.Diff(expect, result); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,63 @@
+package graphql
+
+import (
+	"github.com/goccy/go-yaml"
+	"github.com/zoncoen/query-go"
+
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/protocol/http"
+)
+
+// Expect represents expected response values.
+// If errors isn't specified, the response must not have any GraphQL errors.
+type Expect struct {
+	Code       string        `yaml:"code,omitempty"`
+	Header     yaml.MapSlice `yaml:"header,omitempty"`
+	Data       interface{}   `yaml:"data,omitempty"`
+	Errors     interface{}   `yaml:"errors,omitempty"`
+	Extensions interface{}   `yaml:"extensions,omitempty"`
+	Elapsed    interface{}   `yaml:"elapsed,omitempty"`
+}
+
+var errorsQuery = query.New(query.ExtractByStructTag("yaml", "json")).Key("body").Key("errors")
+
+// Build implements protocol.AssertionBuilder interface.
+func (e *Expect) Build(ctx *context.Context) (assert.Assertion, error) {
+	body := yaml.MapSlice{}
+	for _, item := range []yaml.MapItem{
+		{Key: "data", Value: e.Data},
+		{Key: "errors", Value: e.Errors},
+		{Key: "extensions", Value: e.Extensions},
+	} {
+		if item.Value != nil {
+			body = append(body, item)
+		}
+	}
+	expect := &http.Expect{
+		Code:    e.Code,
+		Header:  e.Header,
+		Elapsed: e.Elapsed,
+	}
+	if len(body) > 0 {
+		expect.Body = body
+	}
+	assertion, err := expect.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if e.Errors != nil {
+		return assertion, nil
+	}
+
+	return assert.AssertionFunc(func(v interface{}) error {
+		if errs, err := errorsQuery.Extract(v); err == nil && errs != nil {
+			if b, err := yaml.Marshal(errs); err == nil {
+				return errors.ErrorPathf("errors", "unexpected GraphQL errors:\n%s", b)
+			}
+			return errors.ErrorPathf("errors", "unexpected GraphQL errors: %v", errs)
+		}
+		return assertion.Assert(v)
+	}), nil
+}
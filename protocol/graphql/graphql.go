@@ -0,0 +1,46 @@
+// Package graphql provides the GraphQL protocol for the scenarigo step.
+package graphql
+
+import (
+	"bytes"
+
+	"github.com/goccy/go-yaml"
+	"github.com/zoncoen/scenarigo/protocol"
+)
+
+const protocolName = "graphql"
+
+// Register registers graphql protocol.
+func Register() {
+	protocol.Register(&GraphQL{})
+}
+
+// GraphQL is a protocol type for the scenarigo step.
+type GraphQL struct{}
+
+// Name implements protocol.Protocol interface.
+func (p *GraphQL) Name() string {
+	return protocolName
+}
+
+// UnmarshalRequest implements protocol.Protocol interface.
+func (p *GraphQL) UnmarshalRequest(b []byte) (protocol.Invoker, error) {
+	var r Request
+	if err := yaml.UnmarshalWithOptions(b, &r, yaml.Strict()); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// UnmarshalExpect implements protocol.Protocol interface.
+func (p *GraphQL) UnmarshalExpect(b []byte) (protocol.AssertionBuilder, error) {
+	var e Expect
+	if b == nil {
+		return &e, nil
+	}
+	decoder := yaml.NewDecoder(bytes.NewBuffer(b), yaml.UseOrderedMap(), yaml.Strict())
+	if err := decoder.Decode(&e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,117 @@
+package graphql
+
+import (
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/protocol/http"
+)
+
+const defaultAccept = "application/graphql-response+json, application/json"
+
+// Request represents a request.
+// The operation is sent by POST with the JSON body as defined by GraphQL over HTTP.
+type Request struct {
+	Client string      `yaml:"client,omitempty"`
+	URL    string      `yaml:"url,omitempty"`
+	Header interface{} `yaml:"header,omitempty"`
+
+	// Query is the query document. Mutation is an alias of it to make the intent clear.
+	Query         string      `yaml:"query,omitempty"`
+	Mutation      string      `yaml:"mutation,omitempty"`
+	Variables     interface{} `yaml:"variables,omitempty"`
+	OperationName string      `yaml:"operationName,omitempty"`
+
+	TLS   *http.TLSConfig   `yaml:"tls,omitempty"`
+	Proxy *http.ProxyConfig `yaml:"proxy,omitempty"`
+
+	req *http.Request
+}
+
+// Prepare implements protocol.Preparer interface.
+func (r *Request) Prepare(ctx *context.Context) error {
+	req, err := r.httpRequest()
+	if err != nil {
+		return err
+	}
+	return req.Prepare(ctx)
+}
+
+// Invoke implements protocol.Invoker interface.
+func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, error) {
+	req, err := r.httpRequest()
+	if err != nil {
+		return ctx, nil, err
+	}
+	return req.Invoke(ctx)
+}
+
+// httpRequest returns the HTTP request which sends the operation.
+// The request is built once to keep the prepared TLS configuration.
+func (r *Request) httpRequest() (*http.Request, error) {
+	if r.req != nil {
+		return r.req, nil
+	}
+	query := r.Query
+	if r.Mutation != "" {
+		if query != "" {
+			return nil, errors.ErrorPath("mutation", "mutation can't be used with query")
+		}
+		query = r.Mutation
+	}
+	if query == "" {
+		return nil, errors.ErrorPath("query", "query or mutation must be specified")
+	}
+
+	header, err := r.header()
+	if err != nil {
+		return nil, err
+	}
+	body := yaml.MapSlice{
+		{Key: "query", Value: query},
+	}
+	if r.OperationName != "" {
+		body = append(body, yaml.MapItem{Key: "operationName", Value: r.OperationName})
+	}
+	if r.Variables != nil {
+		body = append(body, yaml.MapItem{Key: "variables", Value: r.Variables})
+	}
+	r.req = &http.Request{
+		Client: r.Client,
+		Method: "POST",
+		URL:    r.URL,
+		Header: header,
+		Body:   body,
+		TLS:    r.TLS,
+		Proxy:  r.Proxy,
+	}
+	return r.req, nil
+}
+
+// header returns the header which has the default Content-Type and Accept.
+func (r *Request) header() (map[string]interface{}, error) {
+	header := map[string]interface{}{}
+	if r.Header != nil {
+		m, ok := r.Header.(map[string]interface{})
+		if !ok {
+			return nil, errors.ErrorPathf("header", "header must be a map but got %T", r.Header)
+		}
+		for k, v := range m {
+			header[k] = v
+		}
+	}
+	setDefault := func(key, value string) {
+		for k := range header {
+			if strings.EqualFold(k, key) {
+				return
+			}
+		}
+		header[key] = value
+	}
+	setDefault("Content-Type", "application/json")
+	setDefault("Accept", defaultAccept)
+	return header, nil
+}
@@ -0,0 +1,235 @@
+package graphql
+
+import (
+	"encoding/json"
+	gohttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+func startServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(gohttp.HandlerFunc(func(w gohttp.ResponseWriter, req *gohttp.Request) {
+		if req.Method != gohttp.MethodPost {
+			w.WriteHeader(gohttp.StatusMethodNotAllowed)
+			return
+		}
+		if ct := req.Header.Get("Content-Type"); ct != "application/json" {
+			w.WriteHeader(gohttp.StatusUnsupportedMediaType)
+			return
+		}
+		var body struct {
+			Query         string                 `json:"query"`
+			OperationName string                 `json:"operationName"`
+			Variables     map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			w.WriteHeader(gohttp.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/graphql-response+json")
+		if body.Variables["id"] == "unknown" {
+			_, _ = w.Write([]byte(`{"data":{"user":null},"errors":[{"message":"user not found","path":["user"]}]}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"user": map[string]interface{}{
+					"id":            body.Variables["id"],
+					"query":         body.Query,
+					"operationName": body.OperationName,
+					"authorization": req.Header.Get("Authorization"),
+				},
+			},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRequest_Invoke(t *testing.T) {
+	srv := startServer(t)
+	ctx := context.FromT(t).WithVars(map[string]interface{}{
+		"url": srv.URL,
+		"id":  "1",
+	})
+
+	r := &Request{
+		URL: "{{vars.url}}",
+		Header: map[string]interface{}{
+			"Authorization": "Bearer token",
+		},
+		Query:         "query GetUser($id: ID!) { user(id: $id) { id } }",
+		OperationName: "GetUser",
+		Variables: map[string]interface{}{
+			"id": "{{vars.id}}",
+		},
+	}
+	ctx, result, err := r.Invoke(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err := ctx.ExecuteTemplate("{{response.data.user}}")
+	if err != nil {
+		t.Fatalf("failed to execute template: %s", err)
+	}
+	expect := map[string]interface{}{
+		"id":            "1",
+		"query":         "query GetUser($id: ID!) { user(id: $id) { id } }",
+		"operationName": "GetUser",
+		"authorization": "Bearer token",
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+
+	tests := map[string]struct {
+		expect      *Expect
+		expectError string
+	}{
+		"data": {
+			expect: &Expect{
+				Data: yaml.MapSlice{
+					{Key: "user", Value: yaml.MapSlice{
+						{Key: "id", Value: "{{vars.id}}"},
+					}},
+				},
+			},
+		},
+		"data mismatch": {
+			expect: &Expect{
+				Data: yaml.MapSlice{
+					{Key: "user", Value: yaml.MapSlice{
+						{Key: "id", Value: "2"},
+					}},
+				},
+			},
+			expectError: ".body.data.user.id",
+		},
+		"errors not found": {
+			expect: &Expect{
+				Errors: []interface{}{
+					yaml.MapSlice{
+						{Key: "message", Value: "user not found"},
+					},
+				},
+			},
+			expectError: `".errors[0].message" not found`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := test.expect.Build(ctx)
+			if err != nil {
+				t.Fatalf("failed to build assertion: %s", err)
+			}
+			err = assertion.Assert(result)
+			if test.expectError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), test.expectError) {
+				t.Errorf("expected error %q but got %q", test.expectError, err)
+			}
+		})
+	}
+}
+
+func TestRequest_Invoke_Errors(t *testing.T) {
+	srv := startServer(t)
+	r := &Request{
+		URL:      srv.URL,
+		Mutation: "mutation { deleteUser(id: $id) }",
+		Variables: map[string]interface{}{
+			"id": "unknown",
+		},
+	}
+	ctx, result, err := r.Invoke(context.FromT(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	t.Run("success is expected by default", func(t *testing.T) {
+		assertion, err := (&Expect{}).Build(ctx)
+		if err != nil {
+			t.Fatalf("failed to build assertion: %s", err)
+		}
+		err = assertion.Assert(result)
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if expect := ".errors: unexpected GraphQL errors"; !strings.Contains(err.Error(), expect) {
+			t.Errorf("expected error %q but got %q", expect, err)
+		}
+		if expect := "user not found"; !strings.Contains(err.Error(), expect) {
+			t.Errorf("expected error %q but got %q", expect, err)
+		}
+	})
+	t.Run("expect the error", func(t *testing.T) {
+		assertion, err := (&Expect{
+			Errors: []interface{}{
+				yaml.MapSlice{
+					{Key: "message", Value: "user not found"},
+				},
+			},
+		}).Build(ctx)
+		if err != nil {
+			t.Fatalf("failed to build assertion: %s", err)
+		}
+		if err := assertion.Assert(result); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	})
+}
+
+func TestRequest_Invoke_Failure(t *testing.T) {
+	tests := map[string]struct {
+		request     *Request
+		expectError string
+	}{
+		"no query": {
+			request:     &Request{URL: "http://localhost"},
+			expectError: ".query: query or mutation must be specified",
+		},
+		"both query and mutation": {
+			request: &Request{
+				URL:      "http://localhost",
+				Query:    "{ user { id } }",
+				Mutation: "mutation { deleteUser }",
+			},
+			expectError: ".mutation: mutation can't be used with query",
+		},
+		"invalid header": {
+			request: &Request{
+				URL:    "http://localhost",
+				Query:  "{ user { id } }",
+				Header: "Authorization: Bearer token",
+			},
+			expectError: ".header: header must be a map",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			_, _, err := test.request.Invoke(context.FromT(t))
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), test.expectError) {
+				t.Errorf("expected error %q but got %q", test.expectError, err)
+			}
+		})
+	}
+}
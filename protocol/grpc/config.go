@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"os"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+const protocolName = "grpc"
+
+// Config represents the configuration which is applied to all gRPC requests.
+type Config struct {
+	// Files is used to find the method descriptors when the server reflection is unavailable.
+	Files *protoregistry.Files
+}
+
+// WithConfig returns a copy of ctx with the gRPC configuration.
+func WithConfig(ctx *context.Context, config *Config) *context.Context {
+	if config == nil {
+		return ctx
+	}
+	return ctx.WithProtocolConfig(protocolName, config)
+}
+
+func configFrom(ctx *context.Context) *Config {
+	if config, ok := ctx.ProtocolConfig(protocolName).(*Config); ok {
+		return config
+	}
+	return &Config{}
+}
+
+// LoadDescriptorSets loads the files which contain serialized google.protobuf.FileDescriptorSet.
+// The file can be generated by "protoc --include_imports --descriptor_set_out".
+func LoadDescriptorSets(paths ...string) (*protoregistry.Files, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := map[string]struct{}{}
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read descriptor set %s", path)
+		}
+		var s descriptorpb.FileDescriptorSet
+		if err := proto.Unmarshal(b, &s); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal descriptor set %s", path)
+		}
+		for _, f := range s.GetFile() {
+			if _, ok := seen[f.GetName()]; ok {
+				continue
+			}
+			seen[f.GetName()] = struct{}{}
+			set.File = append(set.File, f)
+		}
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid descriptor set")
+	}
+	return files, nil
+}
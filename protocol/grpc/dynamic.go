@@ -0,0 +1,195 @@
+package grpc
+
+import (
+	gocontext "context"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// targetConn is the connection to the target server.
+// It is shared by the steps in a scenario and caches the method descriptors.
+type targetConn struct {
+	*grpc.ClientConn
+	mu      sync.Mutex
+	methods map[string]protoreflect.MethodDescriptor
+}
+
+func (c *targetConn) findMethod(ctx *context.Context, name string) (protoreflect.MethodDescriptor, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if md, ok := c.methods[name]; ok {
+		return md, nil
+	}
+	md, err := findMethod(ctx.RequestContext(), c.ClientConn, name, configFrom(ctx).Files)
+	if err != nil {
+		return nil, err
+	}
+	c.methods[name] = md
+	return md, nil
+}
+
+// connect returns the connection to the target.
+// The connection is reused in the scenario if the context has the connection store.
+func (r *Request) connect(ctx *context.Context) (*targetConn, func(), error) {
+	x, err := ctx.ExecuteTemplate(r.Target)
+	if err != nil {
+		return nil, nil, errors.WrapPath(err, "target", "failed to get target")
+	}
+	target, ok := x.(string)
+	if !ok || target == "" {
+		return nil, nil, errors.ErrorPathf("target", "target must be a string but got %T", x)
+	}
+
+	key := "grpc:" + target
+	creds := insecure.NewCredentials()
+	if r.TLS != nil {
+		key = "grpcs:" + target
+		cfg, err := r.TLS.Build(ctx, filepath.Dir(ctx.ScenarioFilepath()))
+		if err != nil {
+			return nil, nil, errors.WithPath(err, "tls")
+		}
+		creds = credentials.NewTLS(cfg)
+	}
+
+	conns := ctx.Connections()
+	if conns != nil {
+		if c, ok := conns.Get(key).(*targetConn); ok {
+			return c, func() {}, nil
+		}
+	}
+	cc, err := grpc.DialContext(ctx.RequestContext(), target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, nil, errors.WrapPathf(err, "target", "failed to connect to %s", target)
+	}
+	c := &targetConn{
+		ClientConn: cc,
+		mu:         sync.Mutex{},
+		methods:    map[string]protoreflect.MethodDescriptor{},
+	}
+	if conns == nil {
+		return c, func() { cc.Close() }, nil
+	}
+	if err := conns.Add(key, c); err != nil {
+		cc.Close()
+		return nil, nil, errors.WithPath(err, "target")
+	}
+	return c, func() {}, nil
+}
+
+// invokeTarget calls the method of the target server without the generated client.
+// The method descriptor is obtained by the server reflection or the configured descriptor sets.
+func invokeTarget(ctx *context.Context, r *Request) (*context.Context, interface{}, error) {
+	conn, closeConn, err := r.connect(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+	defer closeConn()
+
+	md, err := conn.findMethod(ctx, r.Method)
+	if err != nil {
+		return ctx, nil, errors.WithPath(err, "method")
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return ctx, nil, errors.ErrorPathf("method", "%s is a streaming method, streaming methods are supported only by the client", md.FullName())
+	}
+
+	fullMethod := "/" + string(md.Parent().FullName()) + "/" + string(md.Name())
+	method := reflect.ValueOf(func(ctx gocontext.Context, in proto.Message, opts ...grpc.CallOption) (proto.Message, error) {
+		out := newDynamicMessage(md.Output())
+		if err := conn.Invoke(ctx, fullMethod, in, out, opts...); err != nil {
+			return nil, err
+		}
+		return out, nil
+	})
+	return invoke(ctx, method, r, func() proto.Message {
+		return newDynamicMessage(md.Input())
+	})
+}
+
+// dynamicMessage is the message which is built from the descriptor at runtime.
+// The fields can be accessed by the JSON names or the field names in templates and assertions.
+type dynamicMessage struct {
+	*dynamicpb.Message
+}
+
+func newDynamicMessage(md protoreflect.MessageDescriptor) *dynamicMessage {
+	return &dynamicMessage{dynamicpb.NewMessage(md)}
+}
+
+// ExtractByKey implements query.KeyExtractor interface.
+func (m *dynamicMessage) ExtractByKey(key string) (interface{}, bool) {
+	fields := m.Descriptor().Fields()
+	fd := fields.ByJSONName(key)
+	if fd == nil {
+		fd = fields.ByName(protoreflect.Name(key))
+	}
+	if fd == nil {
+		return nil, false
+	}
+	if fd.Message() != nil && !fd.IsList() && !fd.IsMap() && !m.Has(fd) {
+		return nil, true
+	}
+	return dynamicValue(fd, m.Get(fd)), true
+}
+
+// MarshalYAML implements yaml.InterfaceMarshaler interface.
+func (m *dynamicMessage) MarshalYAML() (interface{}, error) {
+	s := yaml.MapSlice{}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		s = append(s, yaml.MapItem{
+			Key:   fd.JSONName(),
+			Value: dynamicValue(fd, v),
+		})
+		return true
+	})
+	return s, nil
+}
+
+// dynamicValue converts the value of the field to a Go value.
+func dynamicValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch {
+	case fd.IsList():
+		l := v.List()
+		vs := make([]interface{}, l.Len())
+		for i := 0; i < l.Len(); i++ {
+			vs[i] = dynamicScalar(fd, l.Get(i))
+		}
+		return vs
+	case fd.IsMap():
+		m := map[string]interface{}{}
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			m[k.String()] = dynamicScalar(fd.MapValue(), v)
+			return true
+		})
+		return m
+	}
+	return dynamicScalar(fd, v)
+}
+
+func dynamicScalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if msg, ok := v.Message().(*dynamicpb.Message); ok {
+			return &dynamicMessage{msg}
+		}
+		return v.Message().Interface()
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	}
+	return v.Interface()
+}
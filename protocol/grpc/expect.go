@@ -26,6 +26,11 @@ type Expect struct {
 	Status  ExpectStatus  `yaml:"status,omitempty"`
 	Header  yaml.MapSlice `yaml:"header,omitempty"`
 	Trailer yaml.MapSlice `yaml:"trailer,omitempty"`
+	Elapsed interface{}   `yaml:"elapsed,omitempty"`
+
+	// Messages is the assertion for the received messages of server-streaming and bidirectional-streaming methods.
+	// For these methods, Message is asserted for each received message.
+	Messages interface{} `yaml:"messages,omitempty"`
 
 	// for backward compatibility
 	Body interface{} `yaml:"body,omitempty"`
@@ -81,6 +86,16 @@ func (e *Expect) Build(ctx *context.Context) (assert.Assertion, error) {
 		return nil, errors.WrapPathf(err, "message", "invalid expect response message")
 	}
 
+	msgsAssertion, err := assert.Build(ctx.RequestContext(), e.Messages, assert.FromTemplate(ctx))
+	if err != nil {
+		return nil, errors.WrapPathf(err, "messages", "invalid expect response messages")
+	}
+
+	elapsedAssertion, err := assert.Build(ctx.RequestContext(), e.Elapsed, assert.FromTemplate(ctx))
+	if err != nil {
+		return nil, errors.WrapPathf(err, "elapsed", "invalid expect elapsed time")
+	}
+
 	return assert.AssertionFunc(func(v interface{}) error {
 		resp, ok := v.(response)
 		if !ok {
@@ -105,8 +120,25 @@ func (e *Expect) Build(ctx *context.Context) (assert.Assertion, error) {
 		if err := trailerAssertion.Assert(resp.Trailer); err != nil {
 			return errors.WithPath(err, "trailer")
 		}
-		if err := msgAssertion.Assert(message); err != nil {
-			return errors.WithPath(err, "message")
+		if resp.stream {
+			for i, msg := range resp.Messages {
+				if err := msgAssertion.Assert(msg); err != nil {
+					return errors.WrapPathf(err, "message", "messages[%d]", i)
+				}
+			}
+			if err := msgsAssertion.Assert(resp.Messages); err != nil {
+				return errors.WithPath(err, "messages")
+			}
+		} else {
+			if e.Messages != nil {
+				return errors.ErrorPath("messages", "messages can be used only for server-streaming and bidirectional-streaming methods")
+			}
+			if err := msgAssertion.Assert(message); err != nil {
+				return errors.WithPath(err, "message")
+			}
+		}
+		if err := elapsedAssertion.Assert(resp.elapsed); err != nil {
+			return errors.WithPath(err, "elapsed")
 		}
 		return nil
 	}), nil
@@ -201,6 +233,17 @@ func (e *Expect) assertStatusDetails(assertions []assert.Assertion, sts *status.
 }
 
 func extract(v response) (proto.Message, *status.Status, error) {
+	if v.stream {
+		if v.streamErr == nil {
+			return nil, nil, nil
+		}
+		sts, ok := status.FromError(v.streamErr)
+		if !ok {
+			return nil, nil, errors.Errorf(`expected error is status but got %T: "%s"`, v.streamErr, v.streamErr.Error())
+		}
+		return nil, sts, nil
+	}
+
 	vs := v.rvalues
 	if len(vs) != 2 {
 		return nil, nil, errors.Errorf("expected return value length of method call is 2 but %d", len(vs))
@@ -18,7 +18,7 @@ type GRPC struct{}
 
 // Name implements protocol.Protocol interface.
 func (p *GRPC) Name() string {
-	return "grpc"
+	return protocolName
 }
 
 // UnmarshalRequest implements protocol.Protocol interface.
@@ -37,6 +37,25 @@ func (p *GRPC) UnmarshalRequest(b []byte) (protocol.Invoker, error) {
 		r.Body = nil
 	}
 
+	// decode the assertions of the stream actions as ordered maps to build them in the same way as expect
+	if r.Stream != nil && len(r.Stream.Actions) > 0 {
+		var s struct {
+			Stream struct {
+				Actions []struct {
+					Receive interface{} `yaml:"receive"`
+				} `yaml:"actions"`
+			} `yaml:"stream"`
+		}
+		if err := yaml.UnmarshalWithOptions(b, &s, yaml.UseOrderedMap()); err != nil {
+			return nil, err
+		}
+		for i, a := range s.Stream.Actions {
+			if i < len(r.Stream.Actions) {
+				r.Stream.Actions[i].Receive = a.Receive
+			}
+		}
+	}
+
 	return &r, nil
 }
 
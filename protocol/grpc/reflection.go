@@ -0,0 +1,233 @@
+package grpc
+
+import (
+	gocontext "context"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// parseMethodName parses the fully-qualified method name such as "pkg.Service/Method" or "pkg.Service.Method".
+func parseMethodName(name string) (protoreflect.FullName, protoreflect.Name, error) {
+	name = strings.TrimPrefix(name, "/")
+	i := strings.LastIndexAny(name, "/.")
+	if i <= 0 || i == len(name)-1 {
+		return "", "", errors.Errorf(`method must be a fully-qualified name such as "package.Service/Method" but got %q`, name)
+	}
+	svc, method := protoreflect.FullName(name[:i]), protoreflect.Name(name[i+1:])
+	if !svc.IsValid() || !method.IsValid() {
+		return "", "", errors.Errorf("invalid method name %q", name)
+	}
+	return svc, method, nil
+}
+
+// findMethod finds the method descriptor by the server reflection.
+// If it fails, the method is looked up from files which are the configured descriptor sets and the global registry.
+func findMethod(ctx gocontext.Context, conn grpc.ClientConnInterface, name string, files ...*protoregistry.Files) (protoreflect.MethodDescriptor, error) {
+	svc, method, err := parseMethodName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	reflectionFiles, reflectionErr := fetchFiles(ctx, conn, string(svc))
+	if reflectionErr == nil {
+		files = append([]*protoregistry.Files{reflectionFiles}, files...)
+	}
+	for _, f := range append(files, protoregistry.GlobalFiles) {
+		if f == nil {
+			continue
+		}
+		d, err := f.FindDescriptorByName(svc)
+		if err != nil {
+			continue
+		}
+		sd, ok := d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, errors.Errorf("%s is not a service", svc)
+		}
+		md := sd.Methods().ByName(method)
+		if md == nil {
+			return nil, errors.Errorf("method %s not found in service %s", method, svc)
+		}
+		return md, nil
+	}
+	if reflectionErr != nil {
+		return nil, errors.Errorf("service %s not found: failed to get the descriptor by the server reflection: %s", svc, reflectionErr)
+	}
+	return nil, errors.Errorf("service %s not found", svc)
+}
+
+// fetchFiles fetches the file which defines symbol and its dependencies by the server reflection.
+func fetchFiles(ctx gocontext.Context, conn grpc.ClientConnInterface, symbol string) (*protoregistry.Files, error) {
+	ctx, cancel := gocontext.WithCancel(ctx)
+	defer cancel()
+
+	var c reflectionClient = &reflectionClientV1{
+		client: reflectionv1.NewServerReflectionClient(conn),
+	}
+	b, err := c.fileContainingSymbol(ctx, symbol)
+	if status.Code(err) == codes.Unimplemented {
+		// the server supports only the old version
+		c = &reflectionClientV1Alpha{
+			client: reflectionv1alpha.NewServerReflectionClient(conn),
+		}
+		b, err = c.fileContainingSymbol(ctx, symbol)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	protos := map[string]*descriptorpb.FileDescriptorProto{}
+	add := func(bs [][]byte) error {
+		for _, b := range bs {
+			var fd descriptorpb.FileDescriptorProto
+			if err := proto.Unmarshal(b, &fd); err != nil {
+				return errors.Wrap(err, "invalid file descriptor")
+			}
+			protos[fd.GetName()] = &fd
+		}
+		return nil
+	}
+	if err := add(b); err != nil {
+		return nil, err
+	}
+	for {
+		missing := []string{}
+		for _, fd := range protos {
+			for _, dep := range fd.GetDependency() {
+				if _, ok := protos[dep]; !ok {
+					missing = append(missing, dep)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+		sort.Strings(missing)
+		for _, name := range missing {
+			if _, ok := protos[name]; ok {
+				continue
+			}
+			b, err := c.fileByFilename(ctx, name)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get %s", name)
+			}
+			if err := add(b); err != nil {
+				return nil, err
+			}
+			if _, ok := protos[name]; !ok {
+				return nil, errors.Errorf("%s not found", name)
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range protos {
+		set.File = append(set.File, fd)
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid file descriptors")
+	}
+	return files, nil
+}
+
+// reflectionClient is the client of the server reflection service which returns serialized file descriptors.
+type reflectionClient interface {
+	fileContainingSymbol(ctx gocontext.Context, symbol string) ([][]byte, error)
+	fileByFilename(ctx gocontext.Context, name string) ([][]byte, error)
+}
+
+type reflectionClientV1 struct {
+	client reflectionv1.ServerReflectionClient
+	stream reflectionv1.ServerReflection_ServerReflectionInfoClient
+}
+
+func (c *reflectionClientV1) fileContainingSymbol(ctx gocontext.Context, symbol string) ([][]byte, error) {
+	return c.request(ctx, &reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: symbol,
+		},
+	})
+}
+
+func (c *reflectionClientV1) fileByFilename(ctx gocontext.Context, name string) ([][]byte, error) {
+	return c.request(ctx, &reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileByFilename{
+			FileByFilename: name,
+		},
+	})
+}
+
+func (c *reflectionClientV1) request(ctx gocontext.Context, req *reflectionv1.ServerReflectionRequest) ([][]byte, error) {
+	if c.stream == nil {
+		stream, err := c.client.ServerReflectionInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.stream = stream
+	}
+	// the error of Send is obtained by Recv
+	_ = c.stream.Send(req)
+	resp, err := c.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if e := resp.GetErrorResponse(); e != nil {
+		return nil, status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+	}
+	return resp.GetFileDescriptorResponse().GetFileDescriptorProto(), nil
+}
+
+type reflectionClientV1Alpha struct {
+	client reflectionv1alpha.ServerReflectionClient
+	stream reflectionv1alpha.ServerReflection_ServerReflectionInfoClient
+}
+
+func (c *reflectionClientV1Alpha) fileContainingSymbol(ctx gocontext.Context, symbol string) ([][]byte, error) {
+	return c.request(ctx, &reflectionv1alpha.ServerReflectionRequest{
+		MessageRequest: &reflectionv1alpha.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: symbol,
+		},
+	})
+}
+
+func (c *reflectionClientV1Alpha) fileByFilename(ctx gocontext.Context, name string) ([][]byte, error) {
+	return c.request(ctx, &reflectionv1alpha.ServerReflectionRequest{
+		MessageRequest: &reflectionv1alpha.ServerReflectionRequest_FileByFilename{
+			FileByFilename: name,
+		},
+	})
+}
+
+func (c *reflectionClientV1Alpha) request(ctx gocontext.Context, req *reflectionv1alpha.ServerReflectionRequest) ([][]byte, error) {
+	if c.stream == nil {
+		stream, err := c.client.ServerReflectionInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.stream = stream
+	}
+	// the error of Send is obtained by Recv
+	_ = c.stream.Send(req)
+	resp, err := c.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if e := resp.GetErrorResponse(); e != nil {
+		return nil, status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+	}
+	return resp.GetFileDescriptorResponse().GetFileDescriptorProto(), nil
+}
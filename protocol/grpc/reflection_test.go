@@ -0,0 +1,310 @@
+package grpc
+
+import (
+	gocontext "context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/zoncoen/scenarigo/context"
+	testpb "github.com/zoncoen/scenarigo/testdata/gen/pb/test"
+)
+
+type testServer struct {
+	testpb.UnimplementedTestServer
+}
+
+func (s *testServer) Echo(ctx gocontext.Context, req *testpb.EchoRequest) (*testpb.EchoResponse, error) {
+	if err := grpc.SetHeader(ctx, metadata.Pairs("x-request-id", "abc")); err != nil {
+		return nil, err
+	}
+	return &testpb.EchoResponse{
+		MessageId:   req.GetMessageId(),
+		MessageBody: req.GetMessageBody(),
+		ReceivedAt:  123,
+		UserType:    testpb.UserType_CUSTOMER,
+	}, nil
+}
+
+func startTestServer(t *testing.T, enableReflection bool) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	s := grpc.NewServer()
+	testpb.RegisterTestServer(s, &testServer{})
+	if enableReflection {
+		reflection.Register(s)
+	}
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func TestRequest_Invoke_Target(t *testing.T) {
+	for name, enableReflection := range map[string]bool{
+		"server reflection": true,
+		// use the descriptors registered in the global registry
+		"without server reflection": false,
+	} {
+		enableReflection := enableReflection
+		t.Run(name, func(t *testing.T) {
+			addr := startTestServer(t, enableReflection)
+			r := &Request{
+				Target: "{{vars.addr}}",
+				Method: "scenarigo.testdata.test.Test/Echo",
+				Message: yaml.MapSlice{
+					{Key: "messageId", Value: "1"},
+					{Key: "messageBody", Value: "hello"},
+				},
+			}
+			ctx := context.FromT(t).WithVars(map[string]interface{}{
+				"addr": addr,
+			}).WithConnections(context.NewConnections())
+			defer ctx.Connections().Close()
+
+			ctx, result, err := r.Invoke(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			for tmpl, expect := range map[string]interface{}{
+				"{{request.messageBody}}":                     "hello",
+				"{{response.messageId}}":                      "1",
+				"{{response.receivedAt}}":                     int64(123),
+				"{{response.userType}}":                       "CUSTOMER",
+				`{{responseMetadata.header["x-request-id"]}}`: []string{"abc"},
+			} {
+				got, err := ctx.ExecuteTemplate(tmpl)
+				if err != nil {
+					t.Fatalf("failed to execute %s: %s", tmpl, err)
+				}
+				if diff := cmp.Diff(expect, got); diff != "" {
+					t.Errorf("%s differs (-want +got):\n%s", tmpl, diff)
+				}
+			}
+
+			expect := &Expect{
+				Code: "OK",
+				Message: yaml.MapSlice{
+					{Key: "messageId", Value: "1"},
+					{Key: "messageBody", Value: "hello"},
+					{Key: "userType", Value: "CUSTOMER"},
+				},
+				Header: yaml.MapSlice{
+					{Key: "x-request-id", Value: "abc"},
+				},
+			}
+			assertion, err := expect.Build(ctx)
+			if err != nil {
+				t.Fatalf("failed to build assertion: %s", err)
+			}
+			if err := assertion.Assert(result); err != nil {
+				t.Errorf("unexpected assertion error: %s", err)
+			}
+
+			// the connection is reused
+			if _, _, err := r.Invoke(ctx); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+
+	t.Run("failure", func(t *testing.T) {
+		addr := startTestServer(t, true)
+		tests := map[string]struct {
+			request     *Request
+			expectError string
+		}{
+			"not a fully-qualified name": {
+				request: &Request{
+					Target: addr,
+					Method: "Echo",
+				},
+				expectError: ".method: method must be a fully-qualified name",
+			},
+			"service not found": {
+				request: &Request{
+					Target: addr,
+					Method: "scenarigo.testdata.test.Unknown/Echo",
+				},
+				expectError: ".method: service scenarigo.testdata.test.Unknown not found",
+			},
+			"method not found": {
+				request: &Request{
+					Target: addr,
+					Method: "scenarigo.testdata.test.Test/Unknown",
+				},
+				expectError: ".method: method Unknown not found in service scenarigo.testdata.test.Test",
+			},
+			"both client and target": {
+				request: &Request{
+					Client: "{{vars.client}}",
+					Target: addr,
+					Method: "Echo",
+				},
+				expectError: ".target: target can't be used with client",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				_, _, err := test.request.Invoke(context.FromT(t))
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("expected error %q but got %q", test.expectError, err)
+				}
+			})
+		}
+	})
+}
+
+func TestParseMethodName(t *testing.T) {
+	tests := map[string]struct {
+		name          string
+		expectService protoreflect.FullName
+		expectMethod  protoreflect.Name
+		expectError   bool
+	}{
+		"slash": {
+			name:          "pkg.Service/Method",
+			expectService: "pkg.Service",
+			expectMethod:  "Method",
+		},
+		"leading slash": {
+			name:          "/pkg.Service/Method",
+			expectService: "pkg.Service",
+			expectMethod:  "Method",
+		},
+		"period": {
+			name:          "pkg.v1.Service.Method",
+			expectService: "pkg.v1.Service",
+			expectMethod:  "Method",
+		},
+		"method only": {
+			name:        "Method",
+			expectError: true,
+		},
+		"no method": {
+			name:        "pkg.Service/",
+			expectError: true,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			svc, method, err := parseMethodName(test.name)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if svc != test.expectService {
+				t.Errorf("expected service %s but got %s", test.expectService, svc)
+			}
+			if method != test.expectMethod {
+				t.Errorf("expected method %s but got %s", test.expectMethod, method)
+			}
+		})
+	}
+}
+
+func TestFindMethod_DescriptorSets(t *testing.T) {
+	// the service which isn't registered in the global registry
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			{
+				Name:    proto.String("fake/fake.proto"),
+				Package: proto.String("scenarigo.testdata.fake"),
+				Syntax:  proto.String("proto3"),
+				MessageType: []*descriptorpb.DescriptorProto{
+					{Name: proto.String("Empty")},
+				},
+				Service: []*descriptorpb.ServiceDescriptorProto{
+					{
+						Name: proto.String("Fake"),
+						Method: []*descriptorpb.MethodDescriptorProto{
+							{
+								Name:       proto.String("Call"),
+								InputType:  proto.String(".scenarigo.testdata.fake.Empty"),
+								OutputType: proto.String(".scenarigo.testdata.fake.Empty"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	b, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "fake.pb")
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatalf("failed to write: %s", err)
+	}
+	files, err := LoadDescriptorSets(path, path)
+	if err != nil {
+		t.Fatalf("failed to load descriptor sets: %s", err)
+	}
+
+	addr := startTestServer(t, false)
+	cc, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	defer cc.Close()
+
+	md, err := findMethod(gocontext.Background(), cc, "scenarigo.testdata.fake.Fake/Call", files)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, expect := md.FullName(), protoreflect.FullName("scenarigo.testdata.fake.Fake.Call"); got != expect {
+		t.Errorf("expected %s but got %s", expect, got)
+	}
+
+	_, err = findMethod(gocontext.Background(), cc, "scenarigo.testdata.fake.Fake/Call")
+	if err == nil {
+		t.Fatal("no error")
+	}
+	if expect := "failed to get the descriptor by the server reflection"; !strings.Contains(err.Error(), expect) {
+		t.Errorf("expected error %q but got %q", expect, err)
+	}
+}
+
+func TestDynamicMessage_MarshalYAML(t *testing.T) {
+	msg := newDynamicMessage((&testpb.EchoResponse{}).ProtoReflect().Descriptor())
+	if err := buildRequestMsg(context.FromT(t), msg, yaml.MapSlice{
+		{Key: "messageId", Value: "1"},
+		{Key: "userType", Value: "STAFF"},
+	}); err != nil {
+		t.Fatalf("failed to build message: %s", err)
+	}
+	b, err := yaml.Marshal(msg)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	if got, expect := string(b), "messageId: \"1\"\nuserType: STAFF\n"; got != expect {
+		t.Errorf("expected %q but got %q", expect, got)
+	}
+}
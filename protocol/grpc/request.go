@@ -2,10 +2,12 @@ package grpc
 
 import (
 	"bytes"
+	gocontext "context"
 	"encoding/hex"
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/goccy/go-yaml"
@@ -19,15 +21,28 @@ import (
 	"github.com/zoncoen/scenarigo/context"
 	"github.com/zoncoen/scenarigo/errors"
 	"github.com/zoncoen/scenarigo/internal/reflectutil"
+	"github.com/zoncoen/scenarigo/protocol/http"
 )
 
 // Request represents a request.
 type Request struct {
-	Client   string      `yaml:"client,omitempty"`
-	Method   string      `yaml:"method"`
+	Client string `yaml:"client,omitempty"`
+	Method string `yaml:"method"`
+
+	// Target is the address of the server which is called without the client.
+	// The method must be the fully-qualified name such as "package.Service/Method".
+	Target string          `yaml:"target,omitempty"`
+	TLS    *http.TLSConfig `yaml:"tls,omitempty"`
+
 	Metadata interface{} `yaml:"metadata,omitempty"`
 	Message  interface{} `yaml:"message,omitempty"`
 
+	// Messages is the sequence of messages sent to client-streaming and bidirectional-streaming methods.
+	Messages interface{} `yaml:"messages,omitempty"`
+
+	// Stream is the configuration for streaming methods.
+	Stream *StreamConfig `yaml:"stream,omitempty"`
+
 	// for backward compatibility
 	Body interface{} `yaml:"body,omitempty"`
 }
@@ -38,6 +53,32 @@ type response struct {
 	Trailer *mdMarshaler    `yaml:"trailer,omitempty"`
 	Message interface{}     `yaml:"message,omitempty"`
 	rvalues []reflect.Value `yaml:"-"`
+	elapsed time.Duration   `yaml:"-"`
+
+	// for server-streaming and bidirectional-streaming methods
+	Messages  []interface{} `yaml:"messages,omitempty"`
+	stream    bool          `yaml:"-"`
+	streamErr error         `yaml:"-"`
+}
+
+// responseMetadata is the metadata of the response which can be accessed as "responseMetadata" in templates such as {{responseMetadata.header["x-request-id"]}}.
+type responseMetadata struct {
+	Header  metadata.MD `yaml:"header"`
+	Trailer metadata.MD `yaml:"trailer"`
+}
+
+func newResponseMetadata(resp response) *responseMetadata {
+	md := &responseMetadata{
+		Header:  metadata.MD{},
+		Trailer: metadata.MD{},
+	}
+	if resp.Header != nil {
+		md.Header = metadata.MD(*resp.Header)
+	}
+	if resp.Trailer != nil {
+		md.Trailer = metadata.MD(*resp.Trailer)
+	}
+	return md
 }
 
 type responseStatus struct {
@@ -91,8 +132,14 @@ func (r *Request) addIndent(s string, indentNum int) string {
 // Invoke implements protocol.Invoker interface.
 func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, error) {
 	if r.Client == "" {
+		if r.Target != "" {
+			return invokeTarget(ctx, r)
+		}
 		return ctx, nil, errors.New("gRPC client must be specified")
 	}
+	if r.Target != "" {
+		return ctx, nil, errors.ErrorPath("target", "target can't be used with client")
+	}
 
 	x, err := ctx.ExecuteTemplate(r.Client)
 	if err != nil {
@@ -118,11 +165,16 @@ func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, e
 		}
 	}
 
+	if isSendStreamMethod(method) {
+		return invokeSendStream(ctx, method, r)
+	}
 	if err := validateMethod(method); err != nil {
-		return ctx, nil, errors.ErrorPathf("method", `"%s.%s" must be "func(context.Context, proto.Message, ...grpc.CallOption) (proto.Message, error): %s"`, r.Client, r.Method, err)
+		return ctx, nil, errors.ErrorPathf("method", `"%s.%s" must be "func(context.Context, proto.Message, ...grpc.CallOption) (proto.Message, error)" or a streaming method: %s`, r.Client, r.Method, err)
 	}
 
-	return invoke(ctx, method, r)
+	return invoke(ctx, method, r, func() proto.Message {
+		return reflect.New(method.Type().In(1).Elem()).Interface().(proto.Message) //nolint:forcetypeassert
+	})
 }
 
 func validateMethod(method reflect.Value) error {
@@ -152,8 +204,8 @@ func validateMethod(method reflect.Value) error {
 	if n := mt.NumOut(); n != 2 {
 		return errors.Errorf("number of return values must be 2 but got %d", n)
 	}
-	if t := mt.Out(0); !t.Implements(typeMessage) {
-		return errors.Errorf("first return value must be proto.Message but got %s", t.String())
+	if t := mt.Out(0); !t.Implements(typeMessage) && !isServerStream(t) {
+		return errors.Errorf("first return value must be proto.Message or a client of server-streaming but got %s", t.String())
 	}
 	if t := mt.Out(1); !t.Implements(reflectutil.TypeError) {
 		return errors.Errorf("second return value must be error but got %s", t.String())
@@ -162,16 +214,17 @@ func validateMethod(method reflect.Value) error {
 	return nil
 }
 
-func invoke(ctx *context.Context, method reflect.Value, r *Request) (*context.Context, interface{}, error) {
+// outgoingContext returns the request context with the metadata.
+func (r *Request) outgoingContext(ctx *context.Context) (gocontext.Context, error) {
 	reqCtx := ctx.RequestContext()
 	if r.Metadata != nil {
 		x, err := ctx.ExecuteTemplate(r.Metadata)
 		if err != nil {
-			return ctx, nil, errors.WrapPathf(err, "metadata", "failed to set metadata")
+			return nil, errors.WrapPathf(err, "metadata", "failed to set metadata")
 		}
 		md, err := reflectutil.ConvertStringsMap(reflect.ValueOf(x))
 		if err != nil {
-			return nil, nil, errors.WrapPathf(err, "metadata", "failed to set metadata")
+			return nil, errors.WrapPathf(err, "metadata", "failed to set metadata")
 		}
 
 		pairs := []string{}
@@ -183,14 +236,48 @@ func invoke(ctx *context.Context, method reflect.Value, r *Request) (*context.Co
 		}
 		reqCtx = metadata.AppendToOutgoingContext(reqCtx, pairs...)
 	}
+	return reqCtx, nil
+}
+
+// invoke calls the unary or server-streaming method with the request message created by newRequest.
+func invoke(ctx *context.Context, method reflect.Value, r *Request, newRequest func() proto.Message) (*context.Context, interface{}, error) {
+	reqCtx, err := r.outgoingContext(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	// the stream is canceled after receiving messages
+	var (
+		streamCtx = reqCtx
+		cancel    = gocontext.CancelFunc(func() {})
+	)
+	if r.Messages != nil {
+		return ctx, nil, errors.ErrorPath("messages", "messages can be used only for client-streaming and bidirectional-streaming methods")
+	}
+	isStream := isServerStream(method.Type().Out(0))
+	if isStream {
+		if r.Stream != nil && len(r.Stream.Actions) > 0 {
+			return ctx, nil, errors.ErrorPath("stream.actions", "actions can be used only for client-streaming and bidirectional-streaming methods")
+		}
+		timeout, err := r.Stream.timeout()
+		if err != nil {
+			return ctx, nil, errors.WithPath(err, "stream")
+		}
+		if timeout > 0 {
+			streamCtx, cancel = gocontext.WithTimeout(reqCtx, timeout)
+		} else {
+			streamCtx, cancel = gocontext.WithCancel(reqCtx)
+		}
+	}
+	defer cancel()
 
 	var in []reflect.Value
 	for i := 0; i < method.Type().NumIn(); i++ {
 		switch i {
 		case 0:
-			in = append(in, reflect.ValueOf(reqCtx))
+			in = append(in, reflect.ValueOf(streamCtx))
 		case 1:
-			req := reflect.New(method.Type().In(i).Elem()).Interface()
+			req := newRequest()
 			if err := buildRequestMsg(ctx, req, r.Message); err != nil {
 				return ctx, nil, errors.WrapPathf(err, "message", "failed to build request message")
 			}
@@ -216,28 +303,42 @@ func invoke(ctx *context.Context, method reflect.Value, r *Request) (*context.Co
 	}
 
 	var header, trailer metadata.MD
-	in = append(in,
-		reflect.ValueOf(grpc.Header(&header)),
-		reflect.ValueOf(grpc.Trailer(&trailer)),
-	)
+	if !isStream {
+		in = append(in,
+			reflect.ValueOf(grpc.Header(&header)),
+			reflect.ValueOf(grpc.Trailer(&trailer)),
+		)
+	}
 
+	start := time.Now()
 	rvalues := method.Call(in)
-	message := rvalues[0].Interface()
-	var err error
 	if rvalues[1].IsValid() && rvalues[1].CanInterface() {
 		e, ok := rvalues[1].Interface().(error)
 		if ok {
 			err = e
 		}
 	}
+	var messages []interface{}
+	if isStream && err == nil {
+		messages, header, trailer, err = receiveStream(reqCtx, streamCtx, cancel, rvalues[0], r.Stream)
+	}
+	elapsed := time.Since(start)
+	ctx = ctx.WithElapsed(elapsed)
 	resp := response{
 		Status: responseStatus{
 			Code:    codes.OK.String(),
 			Message: "",
 			Details: nil,
 		},
-		Message: message,
 		rvalues: rvalues,
+		elapsed: elapsed,
+	}
+	if isStream {
+		resp.Messages = messages
+		resp.stream = true
+		resp.streamErr = err
+	} else {
+		resp.Message = rvalues[0].Interface()
 	}
 	if len(header) > 0 {
 		resp.Header = newMDMarshaler(header)
@@ -245,37 +346,54 @@ func invoke(ctx *context.Context, method reflect.Value, r *Request) (*context.Co
 	if len(trailer) > 0 {
 		resp.Trailer = newMDMarshaler(trailer)
 	}
-	if err != nil {
-		if sts, ok := status.FromError(err); ok {
-			resp.Status.Code = sts.Code().String()
-			resp.Status.Message = sts.Message()
-			details := sts.Details()
-			if l := len(details); l > 0 {
-				m := make(yaml.MapSlice, l)
-				for i, d := range details {
-					item := yaml.MapItem{
-						Key:   "",
-						Value: d,
-					}
-					if msg, ok := d.(proto.Message); ok {
-						item.Key = string(proto.MessageName(msg))
-					} else {
-						item.Key = fmt.Sprintf("%T (not proto.Message)", d)
-					}
-					m[i] = item
-				}
-				resp.Status.Details = m
+	resp.setStatus(err)
+	if isStream {
+		ctx = ctx.WithResponse(messages)
+	} else {
+		ctx = ctx.WithResponse(resp.Message)
+	}
+	ctx = ctx.WithResponseMetadata(newResponseMetadata(resp))
+	r.dumpResponse(ctx, resp)
+
+	return ctx, resp, nil
+}
+
+// setStatus sets the status of err to resp.
+func (resp *response) setStatus(err error) {
+	if err == nil {
+		return
+	}
+	sts, ok := status.FromError(err)
+	if !ok {
+		return
+	}
+	resp.Status.Code = sts.Code().String()
+	resp.Status.Message = sts.Message()
+	details := sts.Details()
+	if l := len(details); l > 0 {
+		m := make(yaml.MapSlice, l)
+		for i, d := range details {
+			item := yaml.MapItem{
+				Key:   "",
+				Value: d,
+			}
+			if msg, ok := d.(proto.Message); ok {
+				item.Key = string(proto.MessageName(msg))
+			} else {
+				item.Key = fmt.Sprintf("%T (not proto.Message)", d)
 			}
+			m[i] = item
 		}
+		resp.Status.Details = m
 	}
-	ctx = ctx.WithResponse(message)
+}
+
+func (r *Request) dumpResponse(ctx *context.Context, resp response) {
 	if b, err := yaml.Marshal(resp); err == nil {
 		ctx.Reporter().Logf("response:\n%s", r.addIndent(string(b), indentNum))
 	} else {
 		ctx.Reporter().Logf("failed to dump response:\n%s", err)
 	}
-
-	return ctx, resp, nil
 }
 
 func buildRequestMsg(ctx *context.Context, req interface{}, src interface{}) error {
@@ -283,6 +401,11 @@ func buildRequestMsg(ctx *context.Context, req interface{}, src interface{}) err
 	if err != nil {
 		return err
 	}
+	return unmarshalRequestMsg(req, x)
+}
+
+// unmarshalRequestMsg sets the value already executed as a template to req.
+func unmarshalRequestMsg(req interface{}, x interface{}) error {
 	if x == nil {
 		return nil
 	}
@@ -441,6 +441,50 @@ func TestBuildRequestBody(t *testing.T) {
 	}
 }
 
+func TestResponseMetadata(t *testing.T) {
+	resp := response{
+		Message: &testpb.EchoResponse{MessageId: "1", MessageBody: "hello"},
+		Header: newMDMarshaler(metadata.MD{
+			"x-request-id": []string{"a", "b"},
+		}),
+		Trailer: newMDMarshaler(metadata.Pairs("version", "1.0.0")),
+	}
+	tests := map[string]struct {
+		template string
+		expect   interface{}
+	}{
+		"header": {
+			template: `{{responseMetadata.header["x-request-id"]}}`,
+			expect:   []string{"a", "b"},
+		},
+		"repeated header value": {
+			template: `{{responseMetadata.header["x-request-id"][1]}}`,
+			expect:   "b",
+		},
+		"number of header values": {
+			template: `{{size(responseMetadata.header["x-request-id"])}}`,
+			expect:   int64(2),
+		},
+		"trailer": {
+			template: "{{responseMetadata.trailer.version[0]}}",
+			expect:   "1.0.0",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctx := context.FromT(t).WithResponseMetadata(newResponseMetadata(resp))
+			got, err := ctx.ExecuteTemplate(test.template)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(test.expect, got); diff != "" {
+				t.Errorf("differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestMDMarshaler_MarshalYAML(t *testing.T) {
 	tests := map[string]struct {
 		md       metadata.MD
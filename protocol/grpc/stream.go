@@ -0,0 +1,416 @@
+package grpc
+
+import (
+	gocontext "context"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
+)
+
+// StreamConfig represents the configuration of a streaming method.
+//
+//	stream:
+//	  messages: 3  # stop after receiving 3 messages
+//	  timeout: 5s  # stop receiving after 5s
+//
+// If neither messages nor timeout is specified, the messages are received until the stream ends.
+// Actions are performed in order on a bidirectional stream after sending the messages of the request.
+//
+//	stream:
+//	  actions:
+//	  - send:
+//	      messageBody: hello
+//	  - receive:
+//	      messageBody: hello
+type StreamConfig struct {
+	Messages int            `yaml:"messages,omitempty"`
+	Timeout  string         `yaml:"timeout,omitempty"`
+	Actions  []StreamAction `yaml:"actions,omitempty"`
+}
+
+// StreamAction represents an action on a stream.
+// Either send or receive must be specified.
+// The received message is asserted by receive, it can be an empty map to receive a message without assertions.
+type StreamAction struct {
+	Send    interface{} `yaml:"send,omitempty"`
+	Receive interface{} `yaml:"receive,omitempty"`
+}
+
+var typeClientStream = reflect.TypeOf((*grpc.ClientStream)(nil)).Elem()
+
+// isServerStream reports whether t is a client of a server-streaming method such as Test_EchoStreamClient.
+func isServerStream(t reflect.Type) bool {
+	return t.Implements(typeClientStream) && hasRecvMethod(t, "Recv")
+}
+
+// isSendStreamMethod reports whether method is a client-streaming or bidirectional-streaming method such as
+// func(context.Context, ...grpc.CallOption) (Test_EchoChatClient, error).
+func isSendStreamMethod(method reflect.Value) bool {
+	if !method.IsValid() || method.Kind() != reflect.Func || method.IsNil() {
+		return false
+	}
+	mt := method.Type()
+	if mt.NumIn() != 2 || !mt.In(0).Implements(typeContext) || mt.In(1) != typeCallOpts {
+		return false
+	}
+	if mt.NumOut() != 2 || !mt.Out(1).Implements(reflectutil.TypeError) {
+		return false
+	}
+	t := mt.Out(0)
+	if !t.Implements(typeClientStream) || sendMessageType(t) == nil {
+		return false
+	}
+	return hasRecvMethod(t, "CloseAndRecv") || hasRecvMethod(t, "Recv")
+}
+
+// methodSignature returns the argument and return value types of the method of t except for the receiver.
+func methodSignature(t reflect.Type, name string) ([]reflect.Type, []reflect.Type, bool) {
+	m, ok := t.MethodByName(name)
+	if !ok {
+		return nil, nil, false
+	}
+	offset := 0
+	if t.Kind() != reflect.Interface {
+		// the first argument is the receiver
+		offset = 1
+	}
+	in := []reflect.Type{}
+	for i := offset; i < m.Type.NumIn(); i++ {
+		in = append(in, m.Type.In(i))
+	}
+	out := []reflect.Type{}
+	for i := 0; i < m.Type.NumOut(); i++ {
+		out = append(out, m.Type.Out(i))
+	}
+	return in, out, true
+}
+
+// hasRecvMethod reports whether t has the method like "Recv() (proto.Message, error)".
+func hasRecvMethod(t reflect.Type, name string) bool {
+	in, out, ok := methodSignature(t, name)
+	if !ok {
+		return false
+	}
+	return len(in) == 0 && len(out) == 2 && out[0].Implements(typeMessage) && out[1].Implements(reflectutil.TypeError)
+}
+
+// sendMessageType returns the message type of "Send(proto.Message) error" method of t.
+// It returns nil if t doesn't have the method.
+func sendMessageType(t reflect.Type) reflect.Type {
+	in, out, ok := methodSignature(t, "Send")
+	if !ok {
+		return nil
+	}
+	if len(in) != 1 || in[0].Kind() != reflect.Ptr || !in[0].Implements(typeMessage) {
+		return nil
+	}
+	if len(out) != 1 || !out[0].Implements(reflectutil.TypeError) {
+		return nil
+	}
+	return in[0]
+}
+
+// timeout returns the timeout to receive messages.
+func (c *StreamConfig) timeout() (time.Duration, error) {
+	if c == nil || c.Timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.Timeout)
+	if err != nil {
+		return 0, errors.WrapPath(err, "timeout", "invalid timeout")
+	}
+	return d, nil
+}
+
+// receiveStream receives messages from the stream until the stop condition of c is satisfied.
+// It returns the received messages, header and trailer metadata, and the error sent from the server if the stream ends with a non-OK status.
+// The stream is canceled by cancel when the messages are enough or the timeout of c elapses.
+func receiveStream(reqCtx, streamCtx gocontext.Context, cancel gocontext.CancelFunc, stream reflect.Value, c *StreamConfig) ([]interface{}, metadata.MD, metadata.MD, error) {
+	defer cancel()
+	cs, ok := stream.Interface().(grpc.ClientStream)
+	if !ok {
+		return nil, nil, nil, errors.Errorf("expected grpc.ClientStream but got %T", stream.Interface())
+	}
+	// use the methods of the stream instead of the call options because they are set asynchronously when the stream is canceled
+	header, _ := cs.Header()
+	messages, err := receiveMessages(reqCtx, streamCtx, stream, c)
+	return messages, header, cs.Trailer(), err
+}
+
+func receiveMessages(reqCtx, streamCtx gocontext.Context, stream reflect.Value, c *StreamConfig) ([]interface{}, error) {
+	return receiveMoreMessages(reqCtx, streamCtx, stream, c, []interface{}{})
+}
+
+// receiveMoreMessages appends the received messages to messages until the stop condition of c is satisfied.
+func receiveMoreMessages(reqCtx, streamCtx gocontext.Context, stream reflect.Value, c *StreamConfig, messages []interface{}) ([]interface{}, error) {
+	recv := stream.MethodByName("Recv")
+	for {
+		if c != nil && c.Messages > 0 && len(messages) >= c.Messages {
+			return messages, nil
+		}
+		rvalues := recv.Call(nil)
+		if err, ok := rvalues[1].Interface().(error); ok && err != nil {
+			if errors.Is(err, io.EOF) {
+				return messages, nil
+			}
+			// stopped by the timeout of the stream configuration
+			if reqCtx.Err() == nil && errors.Is(streamCtx.Err(), gocontext.DeadlineExceeded) {
+				return messages, nil
+			}
+			return messages, err
+		}
+		msg, ok := rvalues[0].Interface().(proto.Message)
+		if !ok {
+			return messages, errors.Errorf("expected proto.Message but got %T", rvalues[0].Interface())
+		}
+		messages = append(messages, msg)
+	}
+}
+
+// validateActions validates the actions for the stream.
+func (c *StreamConfig) validateActions(bidi bool) error {
+	if c == nil {
+		return nil
+	}
+	for i, a := range c.Actions {
+		path := fmt.Sprintf("actions[%d]", i)
+		if (a.Send == nil) == (a.Receive == nil) {
+			return errors.ErrorPath(path, "either send or receive must be specified")
+		}
+		if a.Receive != nil && !bidi {
+			return errors.ErrorPath(path+".receive", "receive can be used only for bidirectional-streaming methods")
+		}
+	}
+	return nil
+}
+
+// buildRequestMsgs builds the messages to send.
+// Each element of the list is executed as a template, or the template which generates a list is also allowed.
+func (r *Request) buildRequestMsgs(ctx *context.Context, typ reflect.Type) ([]proto.Message, error) {
+	msgs := []proto.Message{}
+	if r.Messages == nil {
+		return msgs, nil
+	}
+	if l, ok := r.Messages.([]interface{}); ok {
+		for i, v := range l {
+			msg := reflect.New(typ.Elem()).Interface().(proto.Message)
+			if err := buildRequestMsg(ctx, msg, v); err != nil {
+				return nil, errors.WrapPathf(err, fmt.Sprintf("messages[%d]", i), "failed to build request message")
+			}
+			msgs = append(msgs, msg)
+		}
+		return msgs, nil
+	}
+	x, err := ctx.ExecuteTemplate(r.Messages)
+	if err != nil {
+		return nil, errors.WrapPathf(err, "messages", "failed to build request messages")
+	}
+	v := reflectutil.Elem(reflect.ValueOf(x))
+	if !v.IsValid() {
+		return msgs, nil
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, errors.ErrorPathf("messages", "messages must be a list but got %T", x)
+	}
+	for i := 0; i < v.Len(); i++ {
+		msg := reflect.New(typ.Elem()).Interface().(proto.Message)
+		if err := unmarshalRequestMsg(msg, v.Index(i).Interface()); err != nil {
+			return nil, errors.WrapPathf(err, fmt.Sprintf("messages[%d]", i), "failed to build request message")
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// invokeSendStream calls a client-streaming or bidirectional-streaming method.
+// It sends the messages of the request, performs the actions, and half-closes the stream.
+// Then it receives the response of a client-streaming method or the rest messages of a bidirectional-streaming method.
+func invokeSendStream(ctx *context.Context, method reflect.Value, r *Request) (*context.Context, interface{}, error) {
+	streamType := method.Type().Out(0)
+	bidi := hasRecvMethod(streamType, "Recv")
+	if r.Message != nil {
+		return ctx, nil, errors.ErrorPath("message", "message can't be used for client-streaming and bidirectional-streaming methods, use messages instead")
+	}
+	if err := r.Stream.validateActions(bidi); err != nil {
+		return ctx, nil, errors.WithPath(err, "stream")
+	}
+	msgs, err := r.buildRequestMsgs(ctx, sendMessageType(streamType))
+	if err != nil {
+		return ctx, nil, err
+	}
+	reqCtx, err := r.outgoingContext(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+	timeout, err := r.Stream.timeout()
+	if err != nil {
+		return ctx, nil, errors.WithPath(err, "stream")
+	}
+	var (
+		streamCtx gocontext.Context
+		cancel    gocontext.CancelFunc
+	)
+	if timeout > 0 {
+		streamCtx, cancel = gocontext.WithTimeout(reqCtx, timeout)
+	} else {
+		streamCtx, cancel = gocontext.WithCancel(reqCtx)
+	}
+	defer cancel()
+
+	//nolint:exhaustruct
+	dumpReq := &Request{
+		Method:   r.Method,
+		Messages: msgs,
+	}
+	if reqMD, _ := metadata.FromOutgoingContext(reqCtx); len(reqMD) > 0 {
+		dumpReq.Metadata = newMDMarshaler(reqMD)
+	}
+	if b, err := yaml.Marshal(dumpReq); err == nil {
+		ctx.Reporter().Logf("request:\n%s", r.addIndent(string(b), indentNum))
+	} else {
+		ctx.Reporter().Logf("failed to dump request:\n%s", err)
+	}
+
+	start := time.Now()
+	rvalues := method.Call([]reflect.Value{reflect.ValueOf(streamCtx)})
+	if err, ok := rvalues[1].Interface().(error); ok && err != nil {
+		return finishSendStream(ctx, r, response{
+			rvalues: []reflect.Value{reflect.Zero(typeMessage), rvalues[1]},
+			stream:  bidi,
+		}, start, []interface{}{}, err)
+	}
+	stream := rvalues[0]
+	cs, ok := stream.Interface().(grpc.ClientStream)
+	if !ok {
+		return ctx, nil, errors.Errorf("expected grpc.ClientStream but got %T", stream.Interface())
+	}
+	// half-close the stream even if an action fails
+	defer cs.CloseSend() //nolint:errcheck
+
+	sent := []interface{}{}
+	// closed becomes true if the stream has been closed by the server or the context.
+	// The status is obtained by receiving messages.
+	closed := false
+	send := func(msg proto.Message) error {
+		if closed {
+			return nil
+		}
+		rvalues := stream.MethodByName("Send").Call([]reflect.Value{reflect.ValueOf(msg)})
+		if err, ok := rvalues[0].Interface().(error); ok && err != nil {
+			if errors.Is(err, io.EOF) {
+				closed = true
+				return nil
+			}
+			return err
+		}
+		sent = append(sent, msg)
+		return nil
+	}
+	for i, msg := range msgs {
+		if err := send(msg); err != nil {
+			return ctx, nil, errors.WrapPathf(err, fmt.Sprintf("messages[%d]", i), "failed to send message")
+		}
+	}
+
+	received := []interface{}{}
+	var streamErr error
+	var actions []StreamAction
+	if r.Stream != nil {
+		actions = r.Stream.Actions
+	}
+	for i, a := range actions {
+		path := fmt.Sprintf("stream.actions[%d]", i)
+		if a.Send != nil {
+			msg := reflect.New(sendMessageType(streamType).Elem()).Interface().(proto.Message)
+			if err := buildRequestMsg(ctx, msg, a.Send); err != nil {
+				return ctx, nil, errors.WrapPathf(err, path+".send", "failed to build request message")
+			}
+			if err := send(msg); err != nil {
+				return ctx, nil, errors.WrapPathf(err, path+".send", "failed to send message")
+			}
+			continue
+		}
+		assertion, err := assert.Build(ctx.RequestContext(), a.Receive, assert.FromTemplate(ctx))
+		if err != nil {
+			return ctx, nil, errors.WrapPathf(err, path+".receive", "invalid assertion")
+		}
+		rvalues := stream.MethodByName("Recv").Call(nil)
+		if err, ok := rvalues[1].Interface().(error); ok && err != nil {
+			if errors.Is(err, io.EOF) {
+				return ctx, nil, errors.ErrorPath(path+".receive", "the stream ended before receiving a message")
+			}
+			if reqCtx.Err() == nil && errors.Is(streamCtx.Err(), gocontext.DeadlineExceeded) {
+				return ctx, nil, errors.ErrorPathf(path+".receive", "no message received within %s", timeout)
+			}
+			// the status is asserted by expect
+			streamErr = err
+			break
+		}
+		msg := rvalues[0].Interface()
+		received = append(received, msg)
+		if err := assertion.Assert(msg); err != nil {
+			return ctx, nil, errors.WithPath(err, path+".receive")
+		}
+	}
+
+	if err := cs.CloseSend(); err != nil {
+		return ctx, nil, errors.Wrap(err, "failed to close the stream")
+	}
+	resp := response{
+		stream: bidi,
+	}
+	if bidi {
+		if streamErr == nil {
+			received, streamErr = receiveMoreMessages(reqCtx, streamCtx, stream, r.Stream, received)
+		}
+	} else {
+		resp.rvalues = stream.MethodByName("CloseAndRecv").Call(nil)
+		resp.Message = resp.rvalues[0].Interface()
+		if err, ok := resp.rvalues[1].Interface().(error); ok {
+			streamErr = err
+		}
+	}
+	// use the methods of the stream instead of the call options because they are set asynchronously when the stream is canceled
+	if header, _ := cs.Header(); len(header) > 0 {
+		resp.Header = newMDMarshaler(header)
+	}
+	if trailer := cs.Trailer(); len(trailer) > 0 {
+		resp.Trailer = newMDMarshaler(trailer)
+	}
+	ctx = ctx.WithRequest(sent)
+	return finishSendStream(ctx, r, resp, start, received, streamErr)
+}
+
+// finishSendStream sets the status and the elapsed time to the response of a client-streaming or bidirectional-streaming method.
+func finishSendStream(ctx *context.Context, r *Request, resp response, start time.Time, received []interface{}, err error) (*context.Context, interface{}, error) {
+	resp.elapsed = time.Since(start)
+	ctx = ctx.WithElapsed(resp.elapsed)
+	resp.Status = responseStatus{
+		Code:    codes.OK.String(),
+		Message: "",
+		Details: nil,
+	}
+	resp.setStatus(err)
+	if resp.stream {
+		resp.Messages = received
+		resp.streamErr = err
+		ctx = ctx.WithResponse(received)
+	} else {
+		ctx = ctx.WithResponse(resp.Message)
+	}
+	ctx = ctx.WithResponseMetadata(newResponseMetadata(resp))
+	r.dumpResponse(ctx, resp)
+	return ctx, resp, nil
+}
@@ -0,0 +1,754 @@
+package grpc
+
+import (
+	gocontext "context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/zoncoen/scenarigo/context"
+	testpb "github.com/zoncoen/scenarigo/testdata/gen/pb/test"
+)
+
+// echoStreamClient is the client of the server-streaming method like generated by protoc-gen-go-grpc.
+type echoStreamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+type testEchoStreamClient interface {
+	Recv() (*testpb.EchoResponse, error)
+	grpc.ClientStream
+}
+
+type testEchoStreamClientImpl struct {
+	grpc.ClientStream
+}
+
+func (x *testEchoStreamClientImpl) Recv() (*testpb.EchoResponse, error) {
+	m := new(testpb.EchoResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var echoStreamDesc = grpc.StreamDesc{
+	StreamName:    "EchoStream",
+	ServerStreams: true,
+	Handler: func(_ interface{}, stream grpc.ServerStream) error {
+		req := new(testpb.EchoRequest)
+		if err := stream.RecvMsg(req); err != nil {
+			return err
+		}
+		if err := stream.SetHeader(metadata.Pairs("content-type", "application/grpc")); err != nil {
+			return err
+		}
+		stream.SetTrailer(metadata.Pairs("version", "1.0.0"))
+		n, err := strconv.Atoi(req.GetMessageBody())
+		if err != nil {
+			return status.Error(codes.InvalidArgument, "message body must be a number")
+		}
+		for i := 1; i <= n; i++ {
+			if err := stream.SendMsg(&testpb.EchoResponse{
+				MessageId:   req.GetMessageId(),
+				MessageBody: strconv.Itoa(i),
+			}); err != nil {
+				return err
+			}
+		}
+		switch req.GetMessageId() {
+		case "error":
+			return status.Error(codes.Internal, "something went wrong")
+		case "wait":
+			<-stream.Context().Done()
+		}
+		return nil
+	},
+}
+
+func (c *echoStreamClient) EchoStream(ctx gocontext.Context, in *testpb.EchoRequest, opts ...grpc.CallOption) (testEchoStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &echoStreamDesc, "/scenarigo.testdata.test.Test/EchoStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &testEchoStreamClientImpl{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// echoCollectDesc is the client-streaming method which returns the received message bodies joined by commas.
+var echoCollectDesc = grpc.StreamDesc{
+	StreamName:    "EchoCollect",
+	ClientStreams: true,
+	Handler: func(_ interface{}, stream grpc.ServerStream) error {
+		bodies := []string{}
+		for {
+			req := new(testpb.EchoRequest)
+			if err := stream.RecvMsg(req); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return err
+			}
+			bodies = append(bodies, req.GetMessageBody())
+		}
+		stream.SetTrailer(metadata.Pairs("count", strconv.Itoa(len(bodies))))
+		return stream.SendMsg(&testpb.EchoResponse{
+			MessageId:   "collect",
+			MessageBody: strings.Join(bodies, ","),
+		})
+	},
+}
+
+type testEchoCollectClient interface {
+	Send(*testpb.EchoRequest) error
+	CloseAndRecv() (*testpb.EchoResponse, error)
+	grpc.ClientStream
+}
+
+type testEchoCollectClientImpl struct {
+	grpc.ClientStream
+}
+
+func (x *testEchoCollectClientImpl) Send(m *testpb.EchoRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *testEchoCollectClientImpl) CloseAndRecv() (*testpb.EchoResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(testpb.EchoResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *echoStreamClient) EchoCollect(ctx gocontext.Context, opts ...grpc.CallOption) (testEchoCollectClient, error) {
+	stream, err := c.cc.NewStream(ctx, &echoCollectDesc, "/scenarigo.testdata.test.Test/EchoCollect", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &testEchoCollectClientImpl{stream}, nil
+}
+
+// echoChatDesc is the bidirectional-streaming method which echoes each received message.
+var echoChatDesc = grpc.StreamDesc{
+	StreamName:    "EchoChat",
+	ServerStreams: true,
+	ClientStreams: true,
+	Handler: func(_ interface{}, stream grpc.ServerStream) error {
+		stream.SetTrailer(metadata.Pairs("version", "1.0.0"))
+		for {
+			req := new(testpb.EchoRequest)
+			if err := stream.RecvMsg(req); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+			if req.GetMessageBody() == "error" {
+				return status.Error(codes.Internal, "something went wrong")
+			}
+			if err := stream.SendMsg(&testpb.EchoResponse{
+				MessageId:   req.GetMessageId(),
+				MessageBody: req.GetMessageBody(),
+			}); err != nil {
+				return err
+			}
+		}
+	},
+}
+
+type testEchoChatClient interface {
+	Send(*testpb.EchoRequest) error
+	Recv() (*testpb.EchoResponse, error)
+	grpc.ClientStream
+}
+
+type testEchoChatClientImpl struct {
+	grpc.ClientStream
+}
+
+func (x *testEchoChatClientImpl) Send(m *testpb.EchoRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *testEchoChatClientImpl) Recv() (*testpb.EchoResponse, error) {
+	m := new(testpb.EchoResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *echoStreamClient) EchoChat(ctx gocontext.Context, opts ...grpc.CallOption) (testEchoChatClient, error) {
+	stream, err := c.cc.NewStream(ctx, &echoChatDesc, "/scenarigo.testdata.test.Test/EchoChat", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &testEchoChatClientImpl{stream}, nil
+}
+
+func startEchoStreamServer(t *testing.T) *echoStreamClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	s := grpc.NewServer()
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "scenarigo.testdata.test.Test",
+		HandlerType: (*interface{})(nil),
+		Streams:     []grpc.StreamDesc{echoStreamDesc, echoCollectDesc, echoChatDesc},
+	}, struct{}{})
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+	cc, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return &echoStreamClient{cc: cc}
+}
+
+func TestRequest_Invoke_ServerStream(t *testing.T) {
+	client := startEchoStreamServer(t)
+	echoResponses := func(id string, n int) []interface{} {
+		msgs := []interface{}{}
+		for i := 1; i <= n; i++ {
+			msgs = append(msgs, &testpb.EchoResponse{MessageId: id, MessageBody: strconv.Itoa(i)})
+		}
+		return msgs
+	}
+
+	tests := map[string]struct {
+		id           string
+		body         string
+		stream       *StreamConfig
+		expect       []interface{}
+		expectCode   codes.Code
+		expectTrails bool
+	}{
+		"until the stream ends": {
+			id:           "1",
+			body:         "3",
+			expect:       echoResponses("1", 3),
+			expectCode:   codes.OK,
+			expectTrails: true,
+		},
+		"up to the number of messages": {
+			id:         "wait",
+			body:       "3",
+			stream:     &StreamConfig{Messages: 2},
+			expect:     echoResponses("wait", 2),
+			expectCode: codes.OK,
+		},
+		"up to the deadline": {
+			id:         "wait",
+			body:       "2",
+			stream:     &StreamConfig{Timeout: "100ms"},
+			expect:     echoResponses("wait", 2),
+			expectCode: codes.OK,
+		},
+		"error status": {
+			id:           "error",
+			body:         "1",
+			expect:       echoResponses("error", 1),
+			expectCode:   codes.Internal,
+			expectTrails: true,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r := &Request{
+				Client: "{{vars.client}}",
+				Method: "EchoStream",
+				Message: yaml.MapSlice{
+					{Key: "messageId", Value: test.id},
+					{Key: "messageBody", Value: test.body},
+				},
+				Stream: test.stream,
+			}
+			ctx := context.FromT(t).WithVars(map[string]interface{}{
+				"client": client,
+			})
+			ctx, result, err := r.Invoke(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			resp, ok := result.(response)
+			if !ok {
+				t.Fatalf("expected response but got %T", result)
+			}
+			if diff := cmp.Diff(test.expect, resp.Messages, protocmp.Transform()); diff != "" {
+				t.Errorf("messages differ (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.expect, ctx.Response(), protocmp.Transform()); diff != "" {
+				t.Errorf("context response differs (-want +got):\n%s", diff)
+			}
+			_, sts, err := extract(resp)
+			if err != nil {
+				t.Fatalf("failed to extract: %s", err)
+			}
+			if got := sts.Code(); got != test.expectCode {
+				t.Errorf("expected code %s but got %s", test.expectCode, got)
+			}
+			if resp.Header == nil {
+				t.Error("header is empty")
+			}
+			if test.expectTrails && resp.Trailer == nil {
+				t.Error("trailer is empty")
+			}
+		})
+	}
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		r := &Request{
+			Client: "{{vars.client}}",
+			Method: "EchoStream",
+			Stream: &StreamConfig{Timeout: "1"},
+		}
+		ctx := context.FromT(t).WithVars(map[string]interface{}{
+			"client": client,
+		})
+		_, _, err := r.Invoke(ctx)
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if expect := ".stream.timeout: invalid timeout"; !strings.Contains(err.Error(), expect) {
+			t.Errorf("expected error %q but got %q", expect, err)
+		}
+	})
+}
+
+func TestRequest_Invoke_ClientStream(t *testing.T) {
+	client := startEchoStreamServer(t)
+	body := func(b string) yaml.MapSlice {
+		return yaml.MapSlice{{Key: "messageBody", Value: b}}
+	}
+
+	tests := map[string]struct {
+		messages interface{}
+		actions  []StreamAction
+		expect   string
+	}{
+		"messages": {
+			messages: []interface{}{body("a"), body("{{vars.body}}"), body("c")},
+			expect:   "a,b,c",
+		},
+		"messages generated by a template": {
+			messages: "{{vars.messages}}",
+			expect:   "x,y",
+		},
+		"send actions": {
+			messages: []interface{}{body("a")},
+			actions: []StreamAction{
+				{Send: body("{{vars.body}}")},
+			},
+			expect: "a,b",
+		},
+		"no messages": {
+			expect: "",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r := &Request{
+				Client:   "{{vars.client}}",
+				Method:   "EchoCollect",
+				Messages: test.messages,
+			}
+			if test.actions != nil {
+				r.Stream = &StreamConfig{Actions: test.actions}
+			}
+			ctx := context.FromT(t).WithVars(map[string]interface{}{
+				"client": client,
+				"body":   "b",
+				"messages": []interface{}{
+					map[string]interface{}{"messageBody": "x"},
+					map[string]interface{}{"messageBody": "y"},
+				},
+			})
+			ctx, result, err := r.Invoke(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			resp, ok := result.(response)
+			if !ok {
+				t.Fatalf("expected response but got %T", result)
+			}
+			if diff := cmp.Diff(&testpb.EchoResponse{
+				MessageId:   "collect",
+				MessageBody: test.expect,
+			}, resp.Message, protocmp.Transform()); diff != "" {
+				t.Errorf("message differs (-want +got):\n%s", diff)
+			}
+			if _, ok := ctx.Request().([]interface{}); !ok {
+				t.Errorf("expected sent messages but got %T", ctx.Request())
+			}
+			msg, sts, err := extract(resp)
+			if err != nil {
+				t.Fatalf("failed to extract: %s", err)
+			}
+			if msg == nil {
+				t.Error("message is nil")
+			}
+			if got := sts.Code(); got != codes.OK {
+				t.Errorf("expected code OK but got %s", got)
+			}
+			if resp.Trailer == nil {
+				t.Error("trailer is empty")
+			}
+		})
+	}
+
+	t.Run("failure", func(t *testing.T) {
+		tests := map[string]struct {
+			request     *Request
+			expectError string
+		}{
+			"receive action": {
+				request: &Request{
+					Stream: &StreamConfig{
+						Actions: []StreamAction{{Receive: body("a")}},
+					},
+				},
+				expectError: ".stream.actions[0].receive: receive can be used only for bidirectional-streaming methods",
+			},
+			"message": {
+				request: &Request{
+					Message: body("a"),
+				},
+				expectError: ".message: message can't be used",
+			},
+			"not a list": {
+				request: &Request{
+					Messages: "{{vars.body}}",
+				},
+				expectError: ".messages: messages must be a list",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				test.request.Client = "{{vars.client}}"
+				test.request.Method = "EchoCollect"
+				ctx := context.FromT(t).WithVars(map[string]interface{}{
+					"client": client,
+					"body":   "b",
+				})
+				_, _, err := test.request.Invoke(ctx)
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("expected error %q but got %q", test.expectError, err)
+				}
+			})
+		}
+	})
+}
+
+func TestRequest_Invoke_BidiStream(t *testing.T) {
+	client := startEchoStreamServer(t)
+	body := func(b string) yaml.MapSlice {
+		return yaml.MapSlice{{Key: "messageBody", Value: b}}
+	}
+	echoResponses := func(bodies ...string) []interface{} {
+		msgs := []interface{}{}
+		for _, b := range bodies {
+			msgs = append(msgs, &testpb.EchoResponse{MessageBody: b})
+		}
+		return msgs
+	}
+
+	t.Run("success", func(t *testing.T) {
+		tests := map[string]struct {
+			messages   interface{}
+			stream     *StreamConfig
+			expect     []interface{}
+			expectCode codes.Code
+		}{
+			"messages": {
+				messages:   []interface{}{body("a"), body("b")},
+				expect:     echoResponses("a", "b"),
+				expectCode: codes.OK,
+			},
+			"interleaved actions": {
+				messages: []interface{}{body("a")},
+				stream: &StreamConfig{
+					Actions: []StreamAction{
+						{Receive: body("a")},
+						{Send: body("{{vars.body}}")},
+						{Receive: yaml.MapSlice{}},
+						{Send: body("c")},
+					},
+				},
+				expect:     echoResponses("a", "b", "c"),
+				expectCode: codes.OK,
+			},
+			"up to the number of messages": {
+				messages:   []interface{}{body("a"), body("b"), body("c")},
+				stream:     &StreamConfig{Messages: 2},
+				expect:     echoResponses("a", "b"),
+				expectCode: codes.OK,
+			},
+			"error status": {
+				messages:   []interface{}{body("a"), body("error")},
+				expect:     echoResponses("a"),
+				expectCode: codes.Internal,
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				r := &Request{
+					Client:   "{{vars.client}}",
+					Method:   "EchoChat",
+					Messages: test.messages,
+					Stream:   test.stream,
+				}
+				ctx := context.FromT(t).WithVars(map[string]interface{}{
+					"client": client,
+					"body":   "b",
+				})
+				ctx, result, err := r.Invoke(ctx)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				resp, ok := result.(response)
+				if !ok {
+					t.Fatalf("expected response but got %T", result)
+				}
+				if diff := cmp.Diff(test.expect, resp.Messages, protocmp.Transform()); diff != "" {
+					t.Errorf("messages differ (-want +got):\n%s", diff)
+				}
+				if diff := cmp.Diff(test.expect, ctx.Response(), protocmp.Transform()); diff != "" {
+					t.Errorf("context response differs (-want +got):\n%s", diff)
+				}
+				_, sts, err := extract(resp)
+				if err != nil {
+					t.Fatalf("failed to extract: %s", err)
+				}
+				if got := sts.Code(); got != test.expectCode {
+					t.Errorf("expected code %s but got %s", test.expectCode, got)
+				}
+			})
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		tests := map[string]struct {
+			stream      *StreamConfig
+			expectError string
+		}{
+			"receive assertion": {
+				stream: &StreamConfig{
+					Actions: []StreamAction{
+						{Send: body("a")},
+						{Receive: body("b")},
+					},
+				},
+				expectError: ".stream.actions[1].receive.messageBody",
+			},
+			"timeout": {
+				stream: &StreamConfig{
+					Timeout: "100ms",
+					Actions: []StreamAction{
+						{Receive: yaml.MapSlice{}},
+					},
+				},
+				expectError: ".stream.actions[0].receive: no message received within 100ms",
+			},
+			"both send and receive": {
+				stream: &StreamConfig{
+					Actions: []StreamAction{
+						{Send: body("a"), Receive: body("a")},
+					},
+				},
+				expectError: ".stream.actions[0]: either send or receive must be specified",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				r := &Request{
+					Client: "{{vars.client}}",
+					Method: "EchoChat",
+					Stream: test.stream,
+				}
+				ctx := context.FromT(t).WithVars(map[string]interface{}{
+					"client": client,
+				})
+				_, _, err := r.Invoke(ctx)
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("expected error %q but got %q", test.expectError, err)
+				}
+			})
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		reqCtx, cancel := gocontext.WithCancel(gocontext.Background())
+		cancel()
+		r := &Request{
+			Client:   "{{vars.client}}",
+			Method:   "EchoChat",
+			Messages: []interface{}{body("a")},
+		}
+		ctx := context.FromT(t).WithRequestContext(reqCtx).WithVars(map[string]interface{}{
+			"client": client,
+		})
+		_, result, err := r.Invoke(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		_, sts, err := extract(result.(response))
+		if err != nil {
+			t.Fatalf("failed to extract: %s", err)
+		}
+		if got := sts.Code(); got != codes.Canceled {
+			t.Errorf("expected code Canceled but got %s", got)
+		}
+	})
+}
+
+func TestExpect_Build_ServerStream(t *testing.T) {
+	msgs := func(bodies ...string) []interface{} {
+		l := make([]interface{}, len(bodies))
+		for i, b := range bodies {
+			l[i] = proto.Message(&testpb.EchoResponse{MessageId: "1", MessageBody: b})
+		}
+		return l
+	}
+	tests := map[string]struct {
+		expect      *Expect
+		response    response
+		expectError string
+	}{
+		"each message": {
+			expect: &Expect{
+				Message: yaml.MapSlice{
+					{Key: "messageId", Value: "1"},
+				},
+			},
+			response: response{
+				Messages: msgs("1", "2"),
+				stream:   true,
+			},
+		},
+		"messages": {
+			expect: &Expect{
+				Messages: []interface{}{
+					yaml.MapSlice{{Key: "messageBody", Value: "1"}},
+					yaml.MapSlice{{Key: "messageBody", Value: "2"}},
+				},
+			},
+			response: response{
+				Messages: msgs("1", "2"),
+				stream:   true,
+			},
+		},
+		"number of messages": {
+			expect: &Expect{
+				Messages: "{{assert.length(2)}}",
+			},
+			response: response{
+				Messages: msgs("1", "2"),
+				stream:   true,
+			},
+		},
+		"trailer": {
+			expect: &Expect{
+				Trailer: yaml.MapSlice{
+					{Key: "version", Value: "1.0.0"},
+				},
+			},
+			response: response{
+				Trailer:  newMDMarshaler(metadata.Pairs("version", "1.0.0")),
+				Messages: msgs("1"),
+				stream:   true,
+			},
+		},
+		"wrong message": {
+			expect: &Expect{
+				Message: yaml.MapSlice{
+					{Key: "messageBody", Value: "1"},
+				},
+			},
+			response: response{
+				Messages: msgs("1", "2"),
+				stream:   true,
+			},
+			expectError: "messages[1]",
+		},
+		"wrong number of messages": {
+			expect: &Expect{
+				Messages: "{{assert.length(3)}}",
+			},
+			response: response{
+				Messages: msgs("1", "2"),
+				stream:   true,
+			},
+			expectError: ".messages",
+		},
+		"unexpected status": {
+			expect: &Expect{},
+			response: response{
+				Messages:  msgs("1"),
+				stream:    true,
+				streamErr: status.Error(codes.Internal, "something went wrong"),
+			},
+			expectError: ".code: expected OK",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := test.expect.Build(context.FromT(t))
+			if err != nil {
+				t.Fatalf("failed to build assertion: %s", err)
+			}
+			err = assertion.Assert(test.response)
+			if test.expectError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), test.expectError) {
+				t.Errorf("expected error %q but got %q", test.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/reporter"
+)
+
+// AuthConfig represents an authentication configuration of HTTP requests.
+// Only one of Basic, Bearer, and OAuth2 can be specified.
+type AuthConfig struct {
+	Basic  *BasicAuthConfig  `yaml:"basic,omitempty"`
+	Bearer *BearerAuthConfig `yaml:"bearer,omitempty"`
+	OAuth2 *OAuth2Config     `yaml:"oauth2,omitempty"`
+}
+
+// authorize sets the Authorization header of the request.
+// It returns a copy of ctx with the bearer token which is available as {{authToken}} in templates.
+func (c *AuthConfig) authorize(ctx *context.Context, client *http.Client, req *http.Request) (*context.Context, error) {
+	n := 0
+	for _, specified := range []bool{c.Basic != nil, c.Bearer != nil, c.OAuth2 != nil} {
+		if specified {
+			n++
+		}
+	}
+	if n > 1 {
+		return ctx, errors.New("only one of basic, bearer, and oauth2 can be specified")
+	}
+	switch {
+	case c.Basic != nil:
+		if err := c.Basic.authorize(ctx, req); err != nil {
+			return ctx, errors.WithPath(err, "basic")
+		}
+	case c.Bearer != nil:
+		token, err := c.Bearer.token(ctx)
+		if err != nil {
+			return ctx, errors.WithPath(err, "bearer")
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return ctx.WithAuthToken(token), nil
+	case c.OAuth2 != nil:
+		token, err := c.OAuth2.token(ctx, client)
+		if err != nil {
+			return ctx, errors.WithPath(err, "oauth2")
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return ctx.WithAuthToken(token), nil
+	}
+	return ctx, nil
+}
+
+// BasicAuthConfig represents a configuration of the HTTP Basic authentication.
+// The fields are templated, and the password and the encoded credentials are masked in the outputs.
+type BasicAuthConfig struct {
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+func (c *BasicAuthConfig) authorize(ctx *context.Context, req *http.Request) error {
+	username, err := executeStringTemplate(ctx, "username", c.Username)
+	if err != nil {
+		return err
+	}
+	if username == "" {
+		return errors.ErrorPath("username", "username is required")
+	}
+	if strings.Contains(username, ":") {
+		return errors.ErrorPath("username", "username must not contain a colon")
+	}
+	password, err := executeStringTemplate(ctx, "password", c.Password)
+	if err != nil {
+		return err
+	}
+	if password != "" {
+		reporter.AddSecret(ctx.Reporter(), password)
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	reporter.AddSecret(ctx.Reporter(), credentials)
+	req.Header.Set("Authorization", "Basic "+credentials)
+	return nil
+}
+
+// BearerAuthConfig represents a configuration of the bearer token authentication.
+// The token is templated and masked in the outputs.
+type BearerAuthConfig struct {
+	Token string `yaml:"token,omitempty"`
+}
+
+func (c *BearerAuthConfig) token(ctx *context.Context) (string, error) {
+	token, err := executeStringTemplate(ctx, "token", c.Token)
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", errors.ErrorPath("token", "token is required")
+	}
+	reporter.AddSecret(ctx.Reporter(), token)
+	return token, nil
+}
+
+const (
+	oauth2AuthStyleHeader = "header"
+	oauth2AuthStyleParams = "params"
+
+	// oauth2ExpiryDelta is how earlier the tokens are refreshed than they actually expire.
+	oauth2ExpiryDelta = 10 * time.Second
+)
+
+// OAuth2Config represents a configuration of the OAuth2 client credentials grant.
+// The fields are templated, and the client secret and the access token are masked in the outputs.
+// AuthStyle specifies how to send the client credentials, "header" (HTTP Basic authentication, default) or "params" (the request body).
+// Params specifies the additional parameters of the token request such as "audience".
+type OAuth2Config struct {
+	TokenURL     string            `yaml:"tokenURL,omitempty"`
+	ClientID     string            `yaml:"clientID,omitempty"`
+	ClientSecret string            `yaml:"clientSecret,omitempty"`
+	Scopes       []string          `yaml:"scopes,omitempty"`
+	Params       map[string]string `yaml:"params,omitempty"`
+	AuthStyle    string            `yaml:"authStyle,omitempty"`
+}
+
+// oauth2Tokens caches the tokens in the process to share them by the requests of the same configuration.
+var oauth2Tokens = &oauth2TokenCache{
+	entries: map[string]*oauth2TokenEntry{},
+}
+
+type oauth2TokenCache struct {
+	m       sync.Mutex
+	entries map[string]*oauth2TokenEntry
+}
+
+func (c *oauth2TokenCache) entry(key string) *oauth2TokenEntry {
+	c.m.Lock()
+	defer c.m.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = &oauth2TokenEntry{}
+		c.entries[key] = e
+	}
+	return e
+}
+
+type oauth2TokenEntry struct {
+	m           sync.Mutex
+	accessToken string
+	expiry      time.Time // zero means the token doesn't expire
+}
+
+func (e *oauth2TokenEntry) valid(now time.Time) bool {
+	if e.accessToken == "" {
+		return false
+	}
+	return e.expiry.IsZero() || now.Add(oauth2ExpiryDelta).Before(e.expiry)
+}
+
+// token returns the cached access token or gets a new one from the token endpoint if it has expired.
+func (c *OAuth2Config) token(ctx *context.Context, client *http.Client) (string, error) {
+	cfg, err := c.build(ctx)
+	if err != nil {
+		return "", err
+	}
+	if cfg.ClientSecret != "" {
+		reporter.AddSecret(ctx.Reporter(), cfg.ClientSecret)
+	}
+	key, err := json.Marshal(cfg)
+	if err != nil {
+		return "", errors.Errorf("failed to get OAuth2 token: %s", err)
+	}
+	e := oauth2Tokens.entry(string(key))
+	e.m.Lock()
+	defer e.m.Unlock()
+	if !e.valid(time.Now()) {
+		token, expiry, err := cfg.fetch(ctx, client)
+		if err != nil {
+			return "", errors.Errorf("failed to get OAuth2 token from %s: %s", cfg.TokenURL, err)
+		}
+		e.accessToken = token
+		e.expiry = expiry
+	}
+	reporter.AddSecret(ctx.Reporter(), e.accessToken)
+	return e.accessToken, nil
+}
+
+// build returns a copy of c whose fields are templated.
+func (c *OAuth2Config) build(ctx *context.Context) (*OAuth2Config, error) {
+	var (
+		cfg = &OAuth2Config{
+			AuthStyle: c.AuthStyle,
+		}
+		err error
+	)
+	if cfg.TokenURL, err = executeStringTemplate(ctx, "tokenURL", c.TokenURL); err != nil {
+		return nil, err
+	}
+	if cfg.TokenURL == "" {
+		return nil, errors.ErrorPath("tokenURL", "tokenURL is required")
+	}
+	if _, err := url.Parse(cfg.TokenURL); err != nil {
+		return nil, errors.ErrorPathf("tokenURL", "invalid token URL: %s", err)
+	}
+	if cfg.ClientID, err = executeStringTemplate(ctx, "clientID", c.ClientID); err != nil {
+		return nil, err
+	}
+	if cfg.ClientID == "" {
+		return nil, errors.ErrorPath("clientID", "clientID is required")
+	}
+	if cfg.ClientSecret, err = executeStringTemplate(ctx, "clientSecret", c.ClientSecret); err != nil {
+		return nil, err
+	}
+	for i, scope := range c.Scopes {
+		s, err := executeStringTemplate(ctx, fmt.Sprintf("scopes[%d]", i), scope)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Scopes = append(cfg.Scopes, s)
+	}
+	if len(c.Params) > 0 {
+		cfg.Params = make(map[string]string, len(c.Params))
+		for k, v := range c.Params {
+			s, err := executeStringTemplate(ctx, fmt.Sprintf("params.%s", k), v)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Params[k] = s
+		}
+	}
+	switch cfg.AuthStyle {
+	case "", oauth2AuthStyleHeader, oauth2AuthStyleParams:
+	default:
+		return nil, errors.ErrorPathf("authStyle", "authStyle must be %q or %q but got %q", oauth2AuthStyleHeader, oauth2AuthStyleParams, cfg.AuthStyle)
+	}
+	return cfg, nil
+}
+
+// fetch gets a new access token from the token endpoint by the client credentials grant.
+func (c *OAuth2Config) fetch(ctx *context.Context, client *http.Client) (string, time.Time, error) {
+	form := url.Values{
+		"grant_type": []string{"client_credentials"},
+	}
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	for k, v := range c.Params {
+		form.Set(k, v)
+	}
+	if c.AuthStyle == oauth2AuthStyleParams {
+		form.Set("client_id", c.ClientID)
+		if c.ClientSecret != "" {
+			form.Set("client_secret", c.ClientSecret)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx.RequestContext(), http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", defaultUserAgent)
+	if c.AuthStyle != oauth2AuthStyleParams {
+		req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", time.Time{}, errors.Errorf("failed to read token response: %s", err)
+	}
+	tr, err := parseTokenResponse(resp.Header.Get("Content-Type"), b)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if err == nil && tr.Error != "" {
+			if tr.ErrorDescription != "" {
+				return "", time.Time{}, errors.Errorf("%s: %s: %s", resp.Status, tr.Error, tr.ErrorDescription)
+			}
+			return "", time.Time{}, errors.Errorf("%s: %s", resp.Status, tr.Error)
+		}
+		return "", time.Time{}, errors.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+	if err != nil {
+		return "", time.Time{}, errors.Errorf("failed to parse token response: %s", err)
+	}
+	if tr.AccessToken == "" {
+		return "", time.Time{}, errors.New("token response doesn't contain access_token")
+	}
+	if tr.TokenType != "" && !strings.EqualFold(tr.TokenType, "bearer") {
+		return "", time.Time{}, errors.Errorf("unsupported token type %q", tr.TokenType)
+	}
+	var expiry time.Time
+	if tr.ExpiresIn != "" {
+		sec, err := tr.ExpiresIn.Int64()
+		if err != nil {
+			return "", time.Time{}, errors.Errorf("invalid expires_in %q: %s", tr.ExpiresIn, err)
+		}
+		if sec > 0 {
+			expiry = time.Now().Add(time.Duration(sec) * time.Second)
+		}
+	}
+	return tr.AccessToken, expiry, nil
+}
+
+type tokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	ExpiresIn        json.Number `json:"expires_in"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+}
+
+// parseTokenResponse parses the token response as JSON or form-encoded values by the media type.
+func parseTokenResponse(contentType string, b []byte) (*tokenResponse, error) {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/x-www-form-urlencoded" || mediaType == "text/plain" {
+		vs, err := url.ParseQuery(string(b))
+		if err != nil {
+			return nil, err
+		}
+		return &tokenResponse{
+			AccessToken:      vs.Get("access_token"),
+			TokenType:        vs.Get("token_type"),
+			ExpiresIn:        json.Number(vs.Get("expires_in")),
+			Error:            vs.Get("error"),
+			ErrorDescription: vs.Get("error_description"),
+		}, nil
+	}
+	var tr tokenResponse
+	if err := json.Unmarshal(b, &tr); err != nil {
+		return nil, err
+	}
+	return &tr, nil
+}
+
+func executeStringTemplate(ctx *context.Context, path, s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	x, err := ctx.ExecuteTemplate(s)
+	if err != nil {
+		return "", errors.WrapPathf(err, path, "failed to get %s", path)
+	}
+	v, ok := x.(string)
+	if !ok {
+		return "", errors.ErrorPathf(path, "%s must be string but got %T", path, x)
+	}
+	return v, nil
+}
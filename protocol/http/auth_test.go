@@ -0,0 +1,403 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/reporter"
+)
+
+type tokenServer struct {
+	*httptest.Server
+	count int32
+}
+
+func newTokenServer(t *testing.T, f func(w http.ResponseWriter, req *http.Request)) *tokenServer {
+	t.Helper()
+	s := &tokenServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&s.count, 1)
+		f(w, req)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *tokenServer) requests() int {
+	return int(atomic.LoadInt32(&s.count))
+}
+
+func TestRequest_Invoke_Auth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(req.Header.Get("Authorization")))
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		auth        *AuthConfig
+		defaultAuth *AuthConfig
+		vars        map[string]interface{}
+		expect      string
+	}{
+		"basic": {
+			auth: &AuthConfig{
+				Basic: &BasicAuthConfig{
+					Username: "{{vars.username}}",
+					Password: "{{vars.password}}",
+				},
+			},
+			vars:   map[string]interface{}{"username": "Aladdin", "password": "open sesame"},
+			expect: "Basic QWxhZGRpbjpvcGVuIHNlc2FtZQ==",
+		},
+		"basic without password": {
+			auth: &AuthConfig{
+				Basic: &BasicAuthConfig{Username: "user"},
+			},
+			expect: "Basic dXNlcjo=",
+		},
+		"bearer": {
+			auth: &AuthConfig{
+				Bearer: &BearerAuthConfig{Token: "{{vars.token}}"},
+			},
+			vars:   map[string]interface{}{"token": "t0k3n"},
+			expect: "Bearer t0k3n",
+		},
+		"default": {
+			defaultAuth: &AuthConfig{
+				Bearer: &BearerAuthConfig{Token: "default"},
+			},
+			expect: "Bearer default",
+		},
+		"override default": {
+			auth: &AuthConfig{
+				Basic: &BasicAuthConfig{Username: "user"},
+			},
+			defaultAuth: &AuthConfig{
+				Bearer: &BearerAuthConfig{Token: "default"},
+			},
+			expect: "Basic dXNlcjo=",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctx := WithAuth(context.FromT(t).WithVars(test.vars), test.defaultAuth)
+			req := &Request{
+				Method: http.MethodGet,
+				URL:    srv.URL,
+				Auth:   test.auth,
+			}
+			_, res, err := req.Invoke(ctx)
+			if err != nil {
+				t.Fatalf("failed to invoke: %s", err)
+			}
+			if got := res.(response).Body; got != test.expect {
+				t.Errorf("expected %q but got %q", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestRequest_Invoke_Auth_Error(t *testing.T) {
+	tests := map[string]struct {
+		auth   *AuthConfig
+		expect string
+	}{
+		"multiple auth": {
+			auth: &AuthConfig{
+				Basic:  &BasicAuthConfig{Username: "user"},
+				Bearer: &BearerAuthConfig{Token: "token"},
+			},
+			expect: ".auth: only one of basic, bearer, and oauth2 can be specified",
+		},
+		"no username": {
+			auth: &AuthConfig{
+				Basic: &BasicAuthConfig{Password: "password"},
+			},
+			expect: ".auth.basic.username: username is required",
+		},
+		"invalid username": {
+			auth: &AuthConfig{
+				Basic: &BasicAuthConfig{Username: "a:b"},
+			},
+			expect: ".auth.basic.username: username must not contain a colon",
+		},
+		"no token": {
+			auth: &AuthConfig{
+				Bearer: &BearerAuthConfig{},
+			},
+			expect: ".auth.bearer.token: token is required",
+		},
+		"invalid token": {
+			auth: &AuthConfig{
+				Bearer: &BearerAuthConfig{Token: "{{1}}"},
+			},
+			expect: ".auth.bearer.token: token must be string but got int64",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			req := &Request{
+				Method: http.MethodGet,
+				URL:    "http://127.0.0.1:0",
+				Auth:   test.auth,
+			}
+			_, _, err := req.Invoke(context.FromT(t))
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), test.expect) {
+				t.Errorf("expected error %q but got %q", test.expect, err)
+			}
+		})
+	}
+}
+
+func TestRequest_Invoke_OAuth2(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(req.Header.Get("Authorization")))
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		handler   func(w http.ResponseWriter, req *http.Request)
+		config    func(tokenURL string) *OAuth2Config
+		vars      map[string]interface{}
+		invoke    int
+		expect    string
+		expectReq int
+	}{
+		"header auth style": {
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				id, secret, ok := req.BasicAuth()
+				if !ok || id != "client" || secret != "secret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if err := req.ParseForm(); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"access_token":"%s:%s","token_type":"bearer","expires_in":3600}`, req.PostForm.Get("grant_type"), req.PostForm.Get("scope"))
+			},
+			config: func(tokenURL string) *OAuth2Config {
+				return &OAuth2Config{
+					TokenURL:     tokenURL,
+					ClientID:     "client",
+					ClientSecret: "{{vars.secret}}",
+					Scopes:       []string{"read", "write"},
+				}
+			},
+			vars:      map[string]interface{}{"secret": "secret"},
+			invoke:    2,
+			expect:    "Bearer client_credentials:read write",
+			expectReq: 1,
+		},
+		"params auth style": {
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				if err := req.ParseForm(); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				if req.PostForm.Get("client_id") != "client" || req.PostForm.Get("client_secret") != "secret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+				fmt.Fprintf(w, "access_token=%s&token_type=bearer", req.PostForm.Get("audience"))
+			},
+			config: func(tokenURL string) *OAuth2Config {
+				return &OAuth2Config{
+					TokenURL:     tokenURL,
+					ClientID:     "client",
+					ClientSecret: "secret",
+					Params:       map[string]string{"audience": "api"},
+					AuthStyle:    "params",
+				}
+			},
+			invoke:    1,
+			expect:    "Bearer api",
+			expectReq: 1,
+		},
+		"refresh expired token": {
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token":"token","expires_in":1}`))
+			},
+			config: func(tokenURL string) *OAuth2Config {
+				return &OAuth2Config{
+					TokenURL: tokenURL,
+					ClientID: "client",
+				}
+			},
+			invoke:    2,
+			expect:    "Bearer token",
+			expectReq: 2,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ts := newTokenServer(t, test.handler)
+			for i := 0; i < test.invoke; i++ {
+				ctx := context.FromT(t).WithVars(test.vars)
+				req := &Request{
+					Method: http.MethodGet,
+					URL:    srv.URL,
+					Auth: &AuthConfig{
+						OAuth2: test.config(ts.URL),
+					},
+				}
+				ctx, res, err := req.Invoke(ctx)
+				if err != nil {
+					t.Fatalf("failed to invoke: %s", err)
+				}
+				if got := res.(response).Body; got != test.expect {
+					t.Errorf("expected %q but got %q", test.expect, got)
+				}
+				if got, _ := ctx.AuthToken(); "Bearer "+got != test.expect {
+					t.Errorf("expected token %q but got %q", test.expect, "Bearer "+got)
+				}
+			}
+			if got := ts.requests(); got != test.expectReq {
+				t.Errorf("expected %d token requests but got %d", test.expectReq, got)
+			}
+		})
+	}
+}
+
+func TestRequest_Invoke_OAuth2_Error(t *testing.T) {
+	tests := map[string]struct {
+		handler func(w http.ResponseWriter, req *http.Request)
+		config  func(tokenURL string) *OAuth2Config
+		expect  string
+	}{
+		"no token URL": {
+			config: func(string) *OAuth2Config {
+				return &OAuth2Config{ClientID: "client"}
+			},
+			expect: ".auth.oauth2.tokenURL: tokenURL is required",
+		},
+		"no client ID": {
+			config: func(tokenURL string) *OAuth2Config {
+				return &OAuth2Config{TokenURL: tokenURL}
+			},
+			expect: ".auth.oauth2.clientID: clientID is required",
+		},
+		"invalid auth style": {
+			config: func(tokenURL string) *OAuth2Config {
+				return &OAuth2Config{TokenURL: tokenURL, ClientID: "client", AuthStyle: "query"}
+			},
+			expect: `.auth.oauth2.authStyle: authStyle must be "header" or "params" but got "query"`,
+		},
+		"invalid template": {
+			config: func(tokenURL string) *OAuth2Config {
+				return &OAuth2Config{TokenURL: tokenURL, ClientID: "{{vars.undefined}}"}
+			},
+			expect: ".auth.oauth2.clientID: failed to get clientID",
+		},
+		"error response": {
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"unknown client"}`))
+			},
+			config: func(tokenURL string) *OAuth2Config {
+				return &OAuth2Config{TokenURL: tokenURL, ClientID: "client"}
+			},
+			expect: "401 Unauthorized: invalid_client: unknown client",
+		},
+		"no access token": {
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"token_type":"bearer"}`))
+			},
+			config: func(tokenURL string) *OAuth2Config {
+				return &OAuth2Config{TokenURL: tokenURL, ClientID: "client"}
+			},
+			expect: "token response doesn't contain access_token",
+		},
+		"unsupported token type": {
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token":"token","token_type":"mac"}`))
+			},
+			config: func(tokenURL string) *OAuth2Config {
+				return &OAuth2Config{TokenURL: tokenURL, ClientID: "client"}
+			},
+			expect: `unsupported token type "mac"`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			handler := test.handler
+			if handler == nil {
+				handler = func(w http.ResponseWriter, req *http.Request) {}
+			}
+			ts := newTokenServer(t, handler)
+			req := &Request{
+				Method: http.MethodGet,
+				URL:    "http://127.0.0.1:0",
+				Auth: &AuthConfig{
+					OAuth2: test.config(ts.URL),
+				},
+			}
+			_, _, err := req.Invoke(context.FromT(t))
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), test.expect) {
+				t.Errorf("expected error %q but got %q", test.expect, err)
+			}
+		})
+	}
+}
+
+func TestRequest_Invoke_OAuth2_MaskSecret(t *testing.T) {
+	ts := newTokenServer(t, func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access-token-value","token_type":"Bearer"}`))
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer srv.Close()
+
+	var b bytes.Buffer
+	reporter.Run(func(rptr reporter.Reporter) {
+		rptr.Run("test.yaml", func(rptr reporter.Reporter) {
+			req := &Request{
+				Method: http.MethodGet,
+				URL:    srv.URL,
+				Auth: &AuthConfig{
+					OAuth2: &OAuth2Config{
+						TokenURL:     ts.URL,
+						ClientID:     "client",
+						ClientSecret: "client-secret-value",
+					},
+				},
+			}
+			if _, _, err := req.Invoke(context.New(rptr)); err != nil {
+				t.Fatalf("failed to invoke: %s", err)
+			}
+			rptr.Logf("%s %s", "client-secret-value", "access-token-value")
+		})
+	}, reporter.WithWriter(&b), reporter.WithVerboseLog())
+	for _, secret := range []string{"client-secret-value", "access-token-value"} {
+		if strings.Contains(b.String(), secret) {
+			t.Fatalf("output contains the secret %q:\n%s", secret, b.String())
+		}
+	}
+	if expect := "Bearer ****"; !strings.Contains(b.String(), expect) {
+		t.Errorf("output doesn't contain %q:\n%s", expect, b.String())
+	}
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// readBodyFile reads the request body from the file of the bodyFile field.
+// The JSON and YAML files are decoded and the template strings in the values are executed, then the body is marshaled as the other bodies.
+// The other files are executed as a template string and sent as they are.
+// It returns the raw body if it should be sent as it is.
+func (r *Request) readBodyFile(ctx *context.Context) (interface{}, []byte, error) {
+	path, err := resolveBodyFile(ctx, r.BodyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, errors.Errorf("failed to read body file: %s", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		var v interface{}
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			return nil, nil, errors.Errorf("failed to decode body file %s: %s", r.BodyFile, err)
+		}
+		body, err := ctx.ExecuteTemplate(v)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to execute body file %s", r.BodyFile)
+		}
+		return body, nil, nil
+	default:
+		body, err := ctx.ExecuteTemplate(string(b))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to execute body file %s", r.BodyFile)
+		}
+		switch body := body.(type) {
+		case string:
+			return body, []byte(body), nil
+		case []byte:
+			return body, body, nil
+		}
+		return body, nil, nil
+	}
+}
+
+// resolveBodyFile executes the template of the bodyFile field and returns the path of the file.
+// The relative path is resolved from the directory of the scenario file.
+func resolveBodyFile(ctx *context.Context, bodyFile string) (string, error) {
+	x, err := ctx.ExecuteTemplate(bodyFile)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid bodyFile")
+	}
+	path, ok := x.(string)
+	if !ok {
+		return "", errors.Errorf(`bodyFile must be "string" but got "%T"`, x)
+	}
+	if !filepath.IsAbs(path) {
+		if scenarioPath := ctx.ScenarioFilepath(); scenarioPath != "" {
+			path = filepath.Join(filepath.Dir(scenarioPath), path)
+		}
+	}
+	return path, nil
+}
+
+// readGoldenFile reads the expected response body from the golden file of the bodyFile field.
+// The JSON and YAML files are decoded into the expected values which can have the assertion templates.
+// The other files are the expected strings.
+func (e *Expect) readGoldenFile(path string) (interface{}, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Errorf("failed to read golden file (run with --update to create it): %s", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		var v interface{}
+		if err := yaml.UnmarshalWithOptions(b, &v, yaml.UseOrderedMap()); err != nil {
+			return nil, errors.Errorf("failed to decode golden file %s: %s", e.BodyFile, err)
+		}
+		return v, nil
+	default:
+		return string(b), nil
+	}
+}
+
+// writeGoldenFile rewrites the golden file by the actual response body.
+// The body is encoded by the file extension, and the other files have the raw body.
+func writeGoldenFile(path string, body interface{}) error {
+	var (
+		b   []byte
+		err error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		b, err = json.MarshalIndent(body, "", "  ")
+		b = append(b, '\n')
+	case ".yaml", ".yml":
+		b, err = yaml.Marshal(body)
+	default:
+		switch body := body.(type) {
+		case nil:
+		case string:
+			b = []byte(body)
+		case []byte:
+			b = body
+		default:
+			b = []byte(fmt.Sprint(body))
+		}
+	}
+	if err != nil {
+		return errors.Errorf("failed to encode golden file: %s", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Errorf("failed to update golden file: %s", err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil { //nolint:gosec
+		return errors.Errorf("failed to update golden file: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package http
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+func TestRequest_buildRequest_BodyFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"payloads/create.json": `{"name": "{{vars.name}}", "age": "{{vars.age}}"}`,
+		"payloads/create.yaml": "name: '{{vars.name}}'\nage: '{{vars.age}}'\n",
+		"payloads/create.txt":  "name={{vars.name}}",
+		"payloads/invalid.txt": "{{vars.unknown}}",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newContext := func(t *testing.T) *context.Context {
+		t.Helper()
+		return context.FromT(t).
+			WithScenarioFilepath(filepath.Join(dir, "scenario.yaml")).
+			WithVars(map[string]interface{}{"name": "alice", "age": 20})
+	}
+
+	t.Run("success", func(t *testing.T) {
+		tests := map[string]struct {
+			req    *Request
+			expect string
+		}{
+			"JSON": {
+				req: &Request{
+					BodyFile: "./payloads/create.json",
+				},
+				expect: "{\"age\": 20, \"name\": \"alice\"}\n",
+			},
+			"YAML as form": {
+				req: &Request{
+					Header:   map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+					BodyFile: "payloads/create.yaml",
+				},
+				expect: "age=20&name=alice",
+			},
+			"raw": {
+				req: &Request{
+					BodyFile: "payloads/create.txt",
+				},
+				expect: "name=alice",
+			},
+			"absolute path": {
+				req: &Request{
+					BodyFile: filepath.Join(dir, "payloads/create.txt"),
+				},
+				expect: "name=alice",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				req, _, err := test.req.buildRequest(newContext(t))
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				b, err := io.ReadAll(req.Body)
+				if err != nil {
+					t.Fatalf("failed to read body: %s", err)
+				}
+				if diff := cmp.Diff(test.expect, string(b)); diff != "" {
+					t.Errorf("body differs (-want +got):\n%s", diff)
+				}
+			})
+		}
+	})
+	t.Run("failure", func(t *testing.T) {
+		tests := map[string]struct {
+			req    *Request
+			expect string
+		}{
+			"not found": {
+				req: &Request{
+					BodyFile: "payloads/not-found.json",
+				},
+				expect: ".bodyFile: failed to create request: failed to read body file: open ",
+			},
+			"template error": {
+				req: &Request{
+					BodyFile: "payloads/invalid.txt",
+				},
+				expect: `.bodyFile: failed to create request: failed to execute body file payloads/invalid.txt: failed to execute: {{vars.unknown}}: ".vars.unknown" not found`,
+			},
+			"with body": {
+				req: &Request{
+					Body:     "test",
+					BodyFile: "payloads/create.json",
+				},
+				expect: ".bodyFile: bodyFile can't be used with body",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				_, _, err := test.req.buildRequest(newContext(t))
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if !strings.Contains(err.Error(), test.expect) {
+					t.Errorf("expect %q but got %q", test.expect, err)
+				}
+			})
+		}
+	})
+}
+
+func TestExpect_Build_BodyFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"golden/user.json": `{"id": "{{assert.notZero}}", "name": "{{vars.name}}"}`,
+		"golden/user.yaml": "id: '{{assert.notZero}}'\nname: '{{vars.name}}'\n",
+		"golden/user.txt":  "hello {{vars.name}}",
+		"golden/bad.json":  `{"id": `,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newContext := func(t *testing.T) *context.Context {
+		t.Helper()
+		return context.FromT(t).
+			WithScenarioFilepath(filepath.Join(dir, "scenario.yaml")).
+			WithVars(map[string]interface{}{"name": "alice"})
+	}
+
+	t.Run("assert", func(t *testing.T) {
+		tests := map[string]struct {
+			bodyFile string
+			ok       interface{}
+			ng       interface{}
+		}{
+			"JSON": {
+				bodyFile: "golden/user.json",
+				ok:       map[string]interface{}{"id": 1, "name": "alice"},
+				ng:       map[string]interface{}{"id": 0, "name": "alice"},
+			},
+			"YAML": {
+				bodyFile: "./golden/user.yaml",
+				ok:       map[string]interface{}{"id": 1, "name": "alice"},
+				ng:       map[string]interface{}{"id": 1, "name": "bob"},
+			},
+			"text": {
+				bodyFile: filepath.Join(dir, "golden/user.txt"),
+				ok:       "hello alice",
+				ng:       "hello bob",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				assertion, err := (&Expect{BodyFile: test.bodyFile}).Build(newContext(t))
+				if err != nil {
+					t.Fatalf("failed to build assertion: %s", err)
+				}
+				if err := assertion.Assert(response{status: "200 OK", Body: test.ok}); err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				if err := assertion.Assert(response{status: "200 OK", Body: test.ng}); err == nil {
+					t.Error("no error")
+				}
+			})
+		}
+	})
+
+	t.Run("build error", func(t *testing.T) {
+		tests := map[string]struct {
+			expect *Expect
+			err    string
+		}{
+			"not found": {
+				expect: &Expect{BodyFile: "golden/unknown.json"},
+				err:    "failed to read golden file (run with --update to create it)",
+			},
+			"invalid file": {
+				expect: &Expect{BodyFile: "golden/bad.json"},
+				err:    "failed to decode golden file golden/bad.json",
+			},
+			"with body": {
+				expect: &Expect{BodyFile: "golden/user.json", Body: "foo"},
+				err:    "bodyFile can't be used with body",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				_, err := test.expect.Build(newContext(t))
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if !strings.Contains(err.Error(), test.err) {
+					t.Errorf("%q not found in %q", test.err, err)
+				}
+			})
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		tests := map[string]struct {
+			bodyFile string
+			body     interface{}
+			expect   string
+		}{
+			"JSON": {
+				bodyFile: "updated/user.json",
+				body:     map[string]interface{}{"name": "alice", "id": 1},
+				expect:   "{\n  \"id\": 1,\n  \"name\": \"alice\"\n}\n",
+			},
+			"YAML": {
+				bodyFile: "updated/user.yaml",
+				body:     map[string]interface{}{"name": "alice", "id": 1},
+				expect:   "id: 1\nname: alice\n",
+			},
+			"text": {
+				bodyFile: "golden/user.txt",
+				body:     "hello bob",
+				expect:   "hello bob",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				assertion, err := (&Expect{BodyFile: test.bodyFile}).Build(newContext(t).WithUpdateGolden(true))
+				if err != nil {
+					t.Fatalf("failed to build assertion: %s", err)
+				}
+				if err := assertion.Assert(response{status: "200 OK", Body: test.body}); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				b, err := os.ReadFile(filepath.Join(dir, test.bodyFile))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(test.expect, string(b)); diff != "" {
+					t.Errorf("differs (-want +got):\n%s", diff)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+const protocolName = "http"
+
+// Config represents the configuration which is applied to all HTTP requests.
+// The fields of each request take precedence over it.
+type Config struct {
+	Redirect *RedirectPolicy
+	TLS      *tls.Config
+	Proxy    func(*http.Request) (*url.URL, error)
+	Auth     *AuthConfig
+}
+
+// WithConfig returns a copy of ctx with the HTTP configuration.
+func WithConfig(ctx *context.Context, config *Config) *context.Context {
+	if config == nil {
+		return ctx
+	}
+	return ctx.WithProtocolConfig(protocolName, config)
+}
+
+// WithAuth returns a copy of ctx with the default authentication of HTTP requests such as the scenario-level auth.
+func WithAuth(ctx *context.Context, auth *AuthConfig) *context.Context {
+	if auth == nil {
+		return ctx
+	}
+	config := *configFrom(ctx)
+	config.Auth = auth
+	return WithConfig(ctx, &config)
+}
+
+func configFrom(ctx *context.Context) *Config {
+	if config, ok := ctx.ProtocolConfig(protocolName).(*Config); ok {
+		return config
+	}
+	return &Config{}
+}
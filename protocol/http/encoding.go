@@ -0,0 +1,127 @@
+package http
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// encodingRoundTripper requests gzip compressed responses and decompresses the response bodies by the Content-Encoding header.
+// The bodies are not decompressed if disabled is true.
+type encodingRoundTripper struct {
+	base     http.RoundTripper
+	disabled bool
+}
+
+func (rt *encodingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") == "" {
+		// RoundTrip must not modify the request
+		req = req.Clone(req.Context())
+		req.Header.Add("Accept-Encoding", "gzip")
+	}
+	resp, err := rt.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if rt.disabled {
+		return resp, nil
+	}
+	body, ok, err := decompress(resp.Body, resp.Header.Values("Content-Encoding"))
+	if err != nil {
+		resp.Body.Close()
+		return nil, errors.Errorf("failed to read response body: %s", err)
+	}
+	resp.Body = &readCloser{
+		Reader: body,
+		Closer: resp.Body,
+	}
+	resp.Uncompressed = ok
+	return resp, nil
+}
+
+// decompress returns the reader which decompresses r by the content codings in the order of the Content-Encoding header values.
+// It reports whether all content codings are decoded. The unknown content codings are not decoded.
+func decompress(r io.Reader, values []string) (io.Reader, bool, error) {
+	var codings []string
+	for _, v := range values {
+		for _, c := range strings.Split(v, ",") {
+			if c := strings.ToLower(strings.TrimSpace(c)); c != "" && c != "identity" {
+				codings = append(codings, c)
+			}
+		}
+	}
+	// the content codings are listed in the order in which they were applied
+	for i := len(codings) - 1; i >= 0; i-- {
+		var (
+			dr  io.Reader
+			err error
+		)
+		switch codings[i] {
+		case "gzip", "x-gzip":
+			dr, err = gzip.NewReader(r)
+		case "deflate":
+			dr, err = newDeflateReader(r)
+		case "br":
+			dr = brotli.NewReader(r)
+		default:
+			return r, false, nil
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return http.NoBody, true, nil // empty body such as the response of HEAD requests
+			}
+			return nil, false, &decompressError{coding: codings[i], err: err}
+		}
+		r = &decompressReader{r: dr, coding: codings[i]}
+	}
+	return r, true, nil
+}
+
+// newDeflateReader returns the reader of the zlib format, or the raw deflate format which some servers send.
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	b, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	// the zlib header: CM = 8 (deflate) and the check bits
+	if b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// decompressReader annotates the errors while decompressing.
+type decompressReader struct {
+	r      io.Reader
+	coding string
+}
+
+func (r *decompressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF { //nolint:errorlint
+		var derr *decompressError
+		if !errors.As(err, &derr) {
+			err = &decompressError{coding: r.coding, err: err}
+		}
+	}
+	return n, err
+}
+
+type decompressError struct {
+	coding string
+	err    error
+}
+
+func (e *decompressError) Error() string {
+	return fmt.Sprintf("failed to decompress response body as %s: %s", e.coding, e.err)
+}
@@ -0,0 +1,233 @@
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+func compress(t *testing.T, coding string, b []byte) []byte {
+	t.Helper()
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+	)
+	switch coding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw deflate":
+		fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = fw
+	case "br":
+		w = brotli.NewWriter(&buf)
+	default:
+		t.Fatalf("unknown coding %s", coding)
+	}
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	body := []byte("hello")
+	tests := map[string]struct {
+		values  []string
+		body    []byte
+		decoded bool
+	}{
+		"gzip": {
+			values:  []string{"gzip"},
+			body:    compress(t, "gzip", body),
+			decoded: true,
+		},
+		"deflate": {
+			values:  []string{"deflate"},
+			body:    compress(t, "deflate", body),
+			decoded: true,
+		},
+		"raw deflate": {
+			values:  []string{"deflate"},
+			body:    compress(t, "raw deflate", body),
+			decoded: true,
+		},
+		"br": {
+			values:  []string{"br"},
+			body:    compress(t, "br", body),
+			decoded: true,
+		},
+		"multiple codings": {
+			values:  []string{"gzip, identity", "BR"},
+			body:    compress(t, "br", compress(t, "gzip", body)),
+			decoded: true,
+		},
+		"identity": {
+			values:  []string{"identity"},
+			body:    body,
+			decoded: true,
+		},
+		"unknown coding": {
+			values: []string{"unknown"},
+			body:   body,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r, decoded, err := decompress(bytes.NewReader(test.body), test.values)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if decoded != test.decoded {
+				t.Errorf("expect %t but got %t", test.decoded, decoded)
+			}
+			b, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("failed to read: %s", err)
+			}
+			if diff := cmp.Diff(string(body), string(b)); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecompress_Error(t *testing.T) {
+	gzipped := compress(t, "gzip", []byte("hello"))
+	tests := map[string]struct {
+		values []string
+		body   []byte
+		expect string
+	}{
+		"invalid header": {
+			values: []string{"gzip"},
+			body:   []byte("not compressed body"),
+			expect: "failed to decompress response body as gzip: gzip: invalid header",
+		},
+		"truncated": {
+			values: []string{"gzip"},
+			body:   gzipped[:len(gzipped)-4],
+			expect: "failed to decompress response body as gzip: unexpected EOF",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r, _, err := decompress(bytes.NewReader(test.body), test.values)
+			if err == nil {
+				_, err = io.ReadAll(r)
+			}
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if got := err.Error(); got != test.expect {
+				t.Errorf("expect %q but got %q", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestRequest_Invoke_Decompression(t *testing.T) {
+	body := []byte(`{"message":"hello"}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		coding := req.URL.Query().Get("coding")
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Content-Encoding", coding)
+		if coding == "gzip" && req.URL.Query().Get("malformed") != "" {
+			_, _ = w.Write([]byte("not compressed body"))
+			return
+		}
+		_, _ = w.Write(compress(t, coding, body))
+	}))
+	defer srv.Close()
+
+	t.Run("decompress", func(t *testing.T) {
+		for _, coding := range []string{"gzip", "deflate", "br"} {
+			coding := coding
+			t.Run(coding, func(t *testing.T) {
+				req := &Request{
+					URL: srv.URL + "?coding=" + coding,
+				}
+				_, resp, err := req.Invoke(context.FromT(t))
+				if err != nil {
+					t.Fatalf("failed to invoke: %s", err)
+				}
+				if diff := cmp.Diff(map[string]interface{}{"message": "hello"}, resp.(response).Body); diff != "" { //nolint:forcetypeassert
+					t.Errorf("differs (-want +got):\n%s", diff)
+				}
+			})
+		}
+	})
+	t.Run("disable decompression", func(t *testing.T) {
+		req := &Request{
+			URL:                  srv.URL + "?coding=gzip",
+			DisableDecompression: true,
+		}
+		_, resp, err := req.Invoke(context.FromT(t))
+		if err != nil {
+			t.Fatalf("failed to invoke: %s", err)
+		}
+		if diff := cmp.Diff(compress(t, "gzip", body), resp.(response).Body); diff != "" { //nolint:forcetypeassert
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("malformed", func(t *testing.T) {
+		req := &Request{
+			URL: srv.URL + "?coding=gzip&malformed=true",
+		}
+		_, _, err := req.Invoke(context.FromT(t))
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if expect := "failed to decompress response body as gzip: gzip: invalid header"; !strings.Contains(err.Error(), expect) {
+			t.Errorf("%q not found in %q", expect, err)
+		}
+	})
+}
+
+func TestEncodingRoundTripper_RoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Accept-Encoding")))
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %s", err)
+	}
+	rt := &encodingRoundTripper{base: http.DefaultTransport}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %s", err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %s", err)
+	}
+	if got, expect := string(b), "gzip"; got != expect {
+		t.Errorf("expect Accept-Encoding %q but got %q", expect, got)
+	}
+	if v := req.Header.Get("Accept-Encoding"); v != "" {
+		t.Errorf("the request is modified: Accept-Encoding %q", v)
+	}
+}
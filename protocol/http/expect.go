@@ -1,6 +1,7 @@
 package http
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/goccy/go-yaml"
@@ -15,6 +16,10 @@ type Expect struct {
 	Code   string        `yaml:"code,omitempty"`
 	Header yaml.MapSlice `yaml:"header,omitempty"`
 	Body   interface{}   `yaml:"body,omitempty"`
+	// BodyFile is the path of the golden file which has the expected body.
+	// The file is rewritten by the actual body if the golden files are updated by the --update flag.
+	BodyFile string      `yaml:"bodyFile,omitempty"`
+	Elapsed  interface{} `yaml:"elapsed,omitempty"`
 }
 
 // Build implements protocol.AssertionBuilder interface.
@@ -34,9 +39,14 @@ func (e *Expect) Build(ctx *context.Context) (assert.Assertion, error) {
 		return nil, errors.WrapPathf(err, "header", "invalid expect header")
 	}
 
-	assertion, err := assert.Build(ctx.RequestContext(), e.Body, assert.FromTemplate(ctx))
+	assertion, err := e.buildBodyAssertion(ctx)
 	if err != nil {
-		return nil, errors.WrapPathf(err, "body", "invalid expect response body")
+		return nil, err
+	}
+
+	elapsedAssertion, err := assert.Build(ctx.RequestContext(), e.Elapsed, assert.FromTemplate(ctx))
+	if err != nil {
+		return nil, errors.WrapPathf(err, "elapsed", "invalid expect elapsed time")
 	}
 
 	return assert.AssertionFunc(func(v interface{}) error {
@@ -44,7 +54,7 @@ func (e *Expect) Build(ctx *context.Context) (assert.Assertion, error) {
 		if !ok {
 			return errors.Errorf("expected response but got %T", v)
 		}
-		if err := assertCode(codeAssertion, res.status); err != nil {
+		if err := assertCode(codeAssertion, res.status, isStatusText(expectCode)); err != nil {
 			return errors.WithPath(err, "code")
 		}
 		if err := headerAssertion.Assert(res.Header); err != nil {
@@ -53,21 +63,74 @@ func (e *Expect) Build(ctx *context.Context) (assert.Assertion, error) {
 		if err := assertion.Assert(res.Body); err != nil {
 			return errors.WithPath(err, "body")
 		}
+		if err := elapsedAssertion.Assert(res.elapsed); err != nil {
+			return errors.WithPath(err, "elapsed")
+		}
 		return nil
 	}), nil
 }
 
-func assertCode(assertion assert.Assertion, status string) error {
+// assertCode asserts the status code such as "200" or the status text such as "OK".
+// If both fail, it returns the error of the status text only if the expected value is a status text.
+func assertCode(assertion assert.Assertion, status string, byText bool) error {
 	strs := strings.SplitN(status, " ", 2)
 	if len(strs) != 2 {
 		return errors.Errorf(`unexpected response status string: "%s"`, status)
 	}
-	if err := assertion.Assert(strs[0]); err == nil {
+	codeErr := assertion.Assert(strs[0])
+	if codeErr == nil {
 		return nil
 	}
 	err := assertion.Assert(strs[1])
 	if err == nil {
 		return nil
 	}
+	if !byText {
+		return codeErr
+	}
 	return err
 }
+
+// isStatusText reports whether the expected code is a status text such as "Not Found" instead of a code or a template.
+func isStatusText(code string) bool {
+	if strings.Contains(code, "{{") {
+		return false
+	}
+	_, err := strconv.Atoi(code)
+	return err != nil
+}
+
+func (e *Expect) buildBodyAssertion(ctx *context.Context) (assert.Assertion, error) {
+	if e.BodyFile == "" {
+		assertion, err := assert.Build(ctx.RequestContext(), e.Body, assert.FromTemplate(ctx))
+		if err != nil {
+			return nil, errors.WrapPathf(err, "body", "invalid expect response body")
+		}
+		return assertion, nil
+	}
+	if e.Body != nil {
+		return nil, errors.ErrorPath("bodyFile", "bodyFile can't be used with body")
+	}
+	path, err := resolveBodyFile(ctx, e.BodyFile)
+	if err != nil {
+		return nil, errors.WrapPathf(err, "bodyFile", "invalid expect response body")
+	}
+	if ctx.UpdateGolden() {
+		return assert.AssertionFunc(func(v interface{}) error {
+			if err := writeGoldenFile(path, v); err != nil {
+				return err
+			}
+			ctx.Reporter().Logf("updated golden file %s", path)
+			return nil
+		}), nil
+	}
+	expect, err := e.readGoldenFile(path)
+	if err != nil {
+		return nil, errors.WrapPathf(err, "bodyFile", "invalid expect response body")
+	}
+	assertion, err := assert.Build(ctx.RequestContext(), expect, assert.FromTemplate(ctx))
+	if err != nil {
+		return nil, errors.WrapPathf(err, "bodyFile", "invalid expect response body")
+	}
+	return assertion, nil
+}
@@ -1,7 +1,9 @@
 package http
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/goccy/go-yaml"
 	"github.com/zoncoen/scenarigo/context"
@@ -44,6 +46,22 @@ func TestExpect_Build(t *testing.T) {
 					status: "404 Not Found",
 				},
 			},
+			"status class": {
+				expect: &Expect{
+					Code: "{{assert.status2xx}}",
+				},
+				response: response{
+					status: "201 Created",
+				},
+			},
+			"status class (statusClass)": {
+				expect: &Expect{
+					Code: "{{assert.statusClass(4)}}",
+				},
+				response: response{
+					status: "404 Not Found",
+				},
+			},
 			"header": {
 				expect: &Expect{
 					Header: yaml.MapSlice{
@@ -60,6 +78,42 @@ func TestExpect_Build(t *testing.T) {
 					status: "200 OK",
 				},
 			},
+			"header (case-insensitive)": {
+				expect: &Expect{
+					Header: yaml.MapSlice{
+						{
+							Key:   "content-type",
+							Value: "application/json",
+						},
+					},
+				},
+				response: response{
+					Header: map[string][]string{
+						"Content-Type": {"application/json"},
+					},
+					status: "200 OK",
+				},
+			},
+			"header (multiple values)": {
+				expect: &Expect{
+					Header: yaml.MapSlice{
+						{
+							Key:   "Set-Cookie",
+							Value: "{{assert.length(2)}}",
+						},
+						{
+							Key:   "set-cookie",
+							Value: "b=2",
+						},
+					},
+				},
+				response: response{
+					Header: map[string][]string{
+						"Set-Cookie": {"a=1", "b=2"},
+					},
+					status: "200 OK",
+				},
+			},
 			"response body": {
 				expect: &Expect{
 					Body: yaml.MapSlice{
@@ -117,6 +171,15 @@ func TestExpect_Build(t *testing.T) {
 					status: "200 OK",
 				},
 			},
+			"elapsed": {
+				expect: &Expect{
+					Elapsed: `{{assert.lessThan(duration("1s"))}}`,
+				},
+				response: response{
+					status:  "200 OK",
+					elapsed: 100 * time.Millisecond,
+				},
+			},
 		}
 		for name, test := range tests {
 			test := test
@@ -230,6 +293,16 @@ func TestExpect_Build(t *testing.T) {
 				},
 				expectAssertError: true,
 			},
+			"too slow": {
+				expect: &Expect{
+					Elapsed: `{{assert.lessThan(duration("1s"))}}`,
+				},
+				response: response{
+					status:  "200 OK",
+					elapsed: 2 * time.Second,
+				},
+				expectAssertError: true,
+			},
 		}
 		for name, test := range tests {
 			test := test
@@ -257,3 +330,43 @@ func TestExpect_Build(t *testing.T) {
 		}
 	})
 }
+
+func TestExpect_Build_StatusCodeError(t *testing.T) {
+	tests := map[string]struct {
+		code   string
+		status string
+		expect string
+	}{
+		"code": {
+			code:   "200",
+			status: "404 Not Found",
+			expect: "expected 200 but got 404",
+		},
+		"status text": {
+			code:   "OK",
+			status: "404 Not Found",
+			expect: "expected OK but got Not Found",
+		},
+		"status class": {
+			code:   "{{assert.status2xx}}",
+			status: "404 Not Found",
+			expect: "expected 2xx status code but got 404",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := (&Expect{Code: test.code}).Build(context.FromT(t))
+			if err != nil {
+				t.Fatalf("failed to build assertion: %s", err)
+			}
+			err = assertion.Assert(response{status: test.status})
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), test.expect) {
+				t.Errorf("expected error %q but got %q", test.expect, err)
+			}
+		})
+	}
+}
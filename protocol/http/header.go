@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+)
+
+// responseHeader is the response header which can be accessed as "header" of the response.
+// The values of each name are the list of the received values, and the names are looked up case-insensitively.
+type responseHeader map[string][]string
+
+// ExtractByKey implements query.KeyExtractor interface.
+func (h responseHeader) ExtractByKey(key string) (interface{}, bool) {
+	if vs, ok := h[key]; ok {
+		return vs, true
+	}
+	if vs, ok := h[http.CanonicalHeaderKey(key)]; ok {
+		return vs, true
+	}
+	for k, vs := range h {
+		if strings.EqualFold(k, key) {
+			return vs, true
+		}
+	}
+	return nil, false
+}
+
+// Get returns the first value of the header name such as {{steps.login.response.header.Get("set-cookie")}}.
+// It returns an empty string if the header doesn't exist.
+func (h responseHeader) Get(name string) string {
+	if vs, ok := h.ExtractByKey(name); ok {
+		if vs := vs.([]string); len(vs) > 0 {
+			return vs[0]
+		}
+	}
+	return ""
+}
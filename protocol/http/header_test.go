@@ -0,0 +1,62 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/template"
+)
+
+func TestResponseHeader(t *testing.T) {
+	h := responseHeader{
+		"Set-Cookie":   {"a=1", "b=2"},
+		"X-Request-Id": {},
+		"x-custom":     {"foo"},
+	}
+	tests := map[string]struct {
+		str    string
+		expect interface{}
+	}{
+		"canonical name": {
+			str:    `{{header["Set-Cookie"]}}`,
+			expect: []string{"a=1", "b=2"},
+		},
+		"lower case": {
+			str:    `{{header["set-cookie"]}}`,
+			expect: []string{"a=1", "b=2"},
+		},
+		"non-canonical key": {
+			str:    `{{header["X-CUSTOM"]}}`,
+			expect: []string{"foo"},
+		},
+		"size": {
+			str:    `{{size(header["set-cookie"])}}`,
+			expect: int64(2),
+		},
+		"first value": {
+			str:    `{{header.Get("set-cookie")}}`,
+			expect: "a=1",
+		},
+		"first value (no values)": {
+			str:    `{{header.Get("x-request-id")}}`,
+			expect: "",
+		},
+		"first value (not found)": {
+			str:    `{{header.Get("x-unknown")}}`,
+			expect: "",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			v, err := template.Execute(test.str, map[string]interface{}{"header": h})
+			if err != nil {
+				t.Fatalf("failed to execute: %s", err)
+			}
+			if diff := cmp.Diff(test.expect, v); diff != "" {
+				t.Errorf("diff: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
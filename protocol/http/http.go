@@ -17,7 +17,7 @@ type HTTP struct{}
 
 // Name implements protocol.Protocol interface.
 func (p *HTTP) Name() string {
-	return "http"
+	return protocolName
 }
 
 // UnmarshalRequest implements protocol.Protocol interface.
@@ -26,6 +26,18 @@ func (p *HTTP) UnmarshalRequest(b []byte) (protocol.Invoker, error) {
 	if err := yaml.UnmarshalWithOptions(b, &r, yaml.Strict()); err != nil {
 		return nil, err
 	}
+	if r.SSE != nil {
+		// decode sse.until as an ordered map to build the assertion in the same way as expect
+		var v struct {
+			SSE struct {
+				Until interface{} `yaml:"until"`
+			} `yaml:"sse"`
+		}
+		if err := yaml.UnmarshalWithOptions(b, &v, yaml.UseOrderedMap()); err != nil {
+			return nil, err
+		}
+		r.SSE.Until = v.SSE.Until
+	}
 	return &r, nil
 }
 
@@ -3,6 +3,7 @@ package http
 import (
 	"testing"
 
+	"github.com/goccy/go-yaml"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -22,6 +23,23 @@ func TestHTTP_UnmarshalRequest(t *testing.T) {
 					Method: "GET",
 				},
 			},
+			"sse": {
+				bytes: []byte(`
+sse:
+  events: 3
+  until:
+    event: done
+    data: finished`),
+				expect: &Request{
+					SSE: &SSEConfig{
+						Events: 3,
+						Until: yaml.MapSlice{
+							{Key: "event", Value: "done"},
+							{Key: "data", Value: "finished"},
+						},
+					},
+				},
+			},
 		}
 		for name, test := range tests {
 			test := test
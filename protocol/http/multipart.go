@@ -0,0 +1,245 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
+)
+
+const mediaTypeMultipartFormData = "multipart/form-data"
+
+// isMultipartFormData reports whether the Content-Type header value is multipart/form-data.
+func isMultipartFormData(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(strings.Trim(contentType, " "))
+	if err != nil {
+		return false
+	}
+	return mediaType == mediaTypeMultipartFormData
+}
+
+// multipartPart represents a part of multipart/form-data request body.
+type multipartPart struct {
+	name        string
+	value       string
+	isFile      bool
+	filename    string
+	contentType string
+	r           io.Reader
+}
+
+// multipartFile represents a file part in the request body.
+//
+//	file:
+//	  file: ./testdata/image.png # read from the file (relative to the scenario file)
+//	  filename: image.png        # default value is the base name of the file
+//	  contentType: image/png     # default value is application/octet-stream
+//
+// The content can be specified inline by content instead of file.
+type multipartFile struct {
+	File        string `yaml:"file,omitempty"`
+	Content     string `yaml:"content,omitempty"`
+	Filename    string `yaml:"filename,omitempty"`
+	ContentType string `yaml:"contentType,omitempty"`
+}
+
+// buildMultipartBody returns the reader which streams v as multipart/form-data and its Content-Type header value.
+// The files are opened before returning and closed after the body is read.
+func buildMultipartBody(ctx *context.Context, contentType string, v interface{}) (io.ReadCloser, string, error) {
+	parts, closers, err := buildMultipartParts(ctx, v)
+	if err != nil {
+		closeAll(closers)
+		return nil, "", err
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	if _, params, err := mime.ParseMediaType(strings.Trim(contentType, " ")); err == nil {
+		if boundary, ok := params["boundary"]; ok {
+			if err := mw.SetBoundary(boundary); err != nil {
+				closeAll(closers)
+				return nil, "", errors.Errorf("invalid boundary: %s", err)
+			}
+		}
+	}
+	go func() {
+		defer closeAll(closers)
+		pw.CloseWithError(writeMultipartParts(mw, parts))
+	}()
+	return pr, mw.FormDataContentType(), nil
+}
+
+func writeMultipartParts(mw *multipart.Writer, parts []*multipartPart) error {
+	for _, p := range parts {
+		if !p.isFile {
+			if err := mw.WriteField(p.name, p.value); err != nil {
+				return err
+			}
+			continue
+		}
+		h := make(textproto.MIMEHeader)
+		params := map[string]string{"name": p.name}
+		if p.filename != "" {
+			params["filename"] = p.filename
+		}
+		h.Set("Content-Disposition", mime.FormatMediaType("form-data", params))
+		h.Set("Content-Type", p.contentType)
+		w, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		r := p.r
+		if r == nil {
+			r = strings.NewReader(p.value)
+		}
+		if _, err := io.Copy(w, r); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+func buildMultipartParts(ctx *context.Context, v interface{}) ([]*multipartPart, []io.Closer, error) {
+	var (
+		parts   []*multipartPart
+		closers []io.Closer
+	)
+	add := func(name string, v interface{}) error {
+		vv := reflectutil.Elem(reflect.ValueOf(v))
+		if vv.Kind() == reflect.Slice && vv.Type().Elem().Kind() != reflect.Uint8 {
+			for i := 0; i < vv.Len(); i++ {
+				p, c, err := buildMultipartPart(ctx, name, vv.Index(i).Interface())
+				if err != nil {
+					return errors.WithPath(err, fmt.Sprintf("%s[%d]", name, i))
+				}
+				parts = append(parts, p)
+				if c != nil {
+					closers = append(closers, c)
+				}
+			}
+			return nil
+		}
+		p, c, err := buildMultipartPart(ctx, name, v)
+		if err != nil {
+			return errors.WithPath(err, name)
+		}
+		parts = append(parts, p)
+		if c != nil {
+			closers = append(closers, c)
+		}
+		return nil
+	}
+
+	if m, ok := v.(yaml.MapSlice); ok {
+		for _, item := range m {
+			name, err := reflectutil.ConvertString(reflect.ValueOf(item.Key))
+			if err != nil {
+				return nil, closers, errors.Errorf("expected field name is string but got %T", item.Key)
+			}
+			if err := add(name, item.Value); err != nil {
+				return nil, closers, err
+			}
+		}
+		return parts, closers, nil
+	}
+
+	vv := reflectutil.Elem(reflect.ValueOf(v))
+	if vv.Kind() != reflect.Map {
+		return nil, nil, errors.Errorf("expected map but got %T", v)
+	}
+	fields := map[string]interface{}{}
+	names := make([]string, 0, vv.Len())
+	iter := vv.MapRange()
+	for iter.Next() {
+		name, err := reflectutil.ConvertString(iter.Key())
+		if err != nil {
+			return nil, nil, errors.Errorf("expected field name is string but got %T", iter.Key().Interface())
+		}
+		fields[name] = iter.Value().Interface()
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := add(name, fields[name]); err != nil {
+			return nil, closers, err
+		}
+	}
+	return parts, closers, nil
+}
+
+func buildMultipartPart(ctx *context.Context, name string, v interface{}) (*multipartPart, io.Closer, error) {
+	vv := reflectutil.Elem(reflect.ValueOf(v))
+	if !isMapValue(v, vv) {
+		s, err := reflectutil.ConvertString(vv)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &multipartPart{name: name, value: s}, nil, nil
+	}
+
+	var f multipartFile
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, nil, errors.Errorf("invalid file: %s", err)
+	}
+	if err := yaml.UnmarshalWithOptions(b, &f, yaml.Strict()); err != nil {
+		return nil, nil, errors.Errorf("invalid file: %s", err)
+	}
+	if f.File != "" && f.Content != "" {
+		return nil, nil, errors.New("file and content can't be specified at the same time")
+	}
+	p := &multipartPart{
+		name:        name,
+		value:       f.Content,
+		isFile:      true,
+		filename:    f.Filename,
+		contentType: f.ContentType,
+	}
+	if p.contentType == "" {
+		p.contentType = "application/octet-stream"
+	}
+	if f.File == "" {
+		return p, nil, nil
+	}
+
+	path := f.File
+	if !filepath.IsAbs(path) {
+		if scenarioPath := ctx.ScenarioFilepath(); scenarioPath != "" {
+			path = filepath.Join(filepath.Dir(scenarioPath), path)
+		}
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, errors.Errorf("failed to open file: %s", err)
+	}
+	if p.filename == "" {
+		p.filename = filepath.Base(path)
+	}
+	p.r = file
+	return p, file, nil
+}
+
+func isMapValue(v interface{}, vv reflect.Value) bool {
+	if _, ok := v.(yaml.MapSlice); ok {
+		return true
+	}
+	return vv.Kind() == reflect.Map
+}
+
+func closeAll(closers []io.Closer) {
+	for _, c := range closers {
+		c.Close()
+	}
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// ProxyConfig represents a proxy configuration of HTTP requests.
+// If URL is empty, the proxy is determined by the environment variables HTTP_PROXY, HTTPS_PROXY, and NO_PROXY.
+// NoProxy specifies the additional hosts which don't use the proxy in the same format as NO_PROXY.
+type ProxyConfig struct {
+	URL     string   `yaml:"url,omitempty"`
+	NoProxy []string `yaml:"noProxy,omitempty"`
+}
+
+// Build returns the function for http.Transport.Proxy.
+func (c *ProxyConfig) Build(ctx *context.Context) (func(*http.Request) (*url.URL, error), error) {
+	cfg := httpproxy.FromEnvironment()
+	if c.URL != "" {
+		x, err := ctx.ExecuteTemplate(c.URL)
+		if err != nil {
+			return nil, errors.WrapPath(err, "url", "failed to get proxy URL")
+		}
+		s, ok := x.(string)
+		if !ok {
+			return nil, errors.ErrorPathf("url", "proxy URL must be string but got %T", x)
+		}
+		if _, err := url.Parse(s); err != nil {
+			return nil, errors.ErrorPathf("url", "invalid proxy URL: %s", err)
+		}
+		cfg.HTTPProxy = s
+		cfg.HTTPSProxy = s
+	}
+	if len(c.NoProxy) > 0 {
+		noProxy := make([]string, 0, len(c.NoProxy)+1)
+		if cfg.NoProxy != "" {
+			noProxy = append(noProxy, cfg.NoProxy)
+		}
+		noProxy = append(noProxy, c.NoProxy...)
+		cfg.NoProxy = strings.Join(noProxy, ",")
+	}
+	f := cfg.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return f(req.URL)
+	}, nil
+}
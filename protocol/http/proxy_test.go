@@ -0,0 +1,132 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+func TestProxyConfig_Build(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "http://env-proxy.test:8080")
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("NO_PROXY", "internal.test")
+
+	tests := map[string]struct {
+		vars   interface{}
+		config *ProxyConfig
+		url    string
+		expect string
+	}{
+		"environment variables": {
+			config: &ProxyConfig{},
+			url:    "http://example.test",
+			expect: "http://env-proxy.test:8080",
+		},
+		"NO_PROXY": {
+			config: &ProxyConfig{},
+			url:    "http://internal.test",
+		},
+		"override by url": {
+			config: &ProxyConfig{URL: "http://proxy.test:3128"},
+			url:    "http://example.test",
+			expect: "http://proxy.test:3128",
+		},
+		"url template": {
+			vars:   map[string]string{"proxy": "http://proxy.test:3128"},
+			config: &ProxyConfig{URL: "{{vars.proxy}}"},
+			url:    "https://example.test",
+			expect: "http://proxy.test:3128",
+		},
+		"noProxy": {
+			config: &ProxyConfig{
+				URL:     "http://proxy.test:3128",
+				NoProxy: []string{"example.test"},
+			},
+			url: "http://api.example.test",
+		},
+		"keep NO_PROXY": {
+			config: &ProxyConfig{
+				URL:     "http://proxy.test:3128",
+				NoProxy: []string{"example.test"},
+			},
+			url: "http://internal.test",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctx := context.FromT(t)
+			if test.vars != nil {
+				ctx = ctx.WithVars(test.vars)
+			}
+			f, err := test.config.Build(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			req, err := http.NewRequest(http.MethodGet, test.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			u, err := f(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			var got string
+			if u != nil {
+				got = u.String()
+			}
+			if got != test.expect {
+				t.Errorf("expect %q but got %q", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestRequest_Invoke_Proxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"host":%q}`, req.URL.Host)
+	}))
+	t.Cleanup(proxy.Close)
+
+	defaultProxy, err := (&ProxyConfig{URL: proxy.URL}).Build(context.FromT(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		config *Config
+		proxy  *ProxyConfig
+	}{
+		"request config": {
+			proxy: &ProxyConfig{URL: proxy.URL},
+		},
+		"default config": {
+			config: &Config{Proxy: defaultProxy},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctx := WithConfig(context.FromT(t), test.config)
+			req := &Request{
+				URL:   "http://example.test/path",
+				Proxy: test.proxy,
+			}
+			_, res, err := req.Invoke(ctx)
+			if err != nil {
+				t.Fatalf("failed to invoke: %s", err)
+			}
+			resp, ok := res.(response)
+			if !ok {
+				t.Fatalf("failed to convert from %T to response", res)
+			}
+			if got, expect := fmt.Sprint(resp.Body), fmt.Sprint(map[string]interface{}{"host": "example.test"}); got != expect {
+				t.Errorf("expect %s but got %s", expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+const (
+	redirectFollow   = "follow"
+	redirectNoFollow = "no-follow"
+
+	// defaultMaxRedirects is the same as the default of net/http.Client.
+	defaultMaxRedirects = 10
+)
+
+// RedirectPolicy represents a policy to follow redirects.
+// It is specified by "follow", "no-follow", or the maximum number of redirects to follow.
+type RedirectPolicy struct {
+	noFollow     bool
+	maxRedirects int
+}
+
+// NewRedirectPolicy returns a new redirect policy which follows redirects up to maxRedirects times.
+// If maxRedirects is 0, the policy never follows redirects.
+func NewRedirectPolicy(maxRedirects int) (*RedirectPolicy, error) {
+	if maxRedirects < 0 {
+		return nil, errors.Errorf("the maximum number of redirects must not be negative but got %d", maxRedirects)
+	}
+	if maxRedirects == 0 {
+		return &RedirectPolicy{noFollow: true}, nil
+	}
+	return &RedirectPolicy{maxRedirects: maxRedirects}, nil
+}
+
+// UnmarshalYAML implements yaml.InterfaceUnmarshaler interface.
+func (p *RedirectPolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var v interface{}
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	var n int
+	switch v := v.(type) {
+	case string:
+		switch v {
+		case redirectFollow:
+			*p = RedirectPolicy{}
+			return nil
+		case redirectNoFollow:
+			*p = RedirectPolicy{noFollow: true}
+			return nil
+		}
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.Errorf(`redirect policy must be "%s", "%s", or the maximum number of redirects but got %q`, redirectFollow, redirectNoFollow, v)
+		}
+		n = i
+	case int:
+		n = v
+	case int64:
+		n = int(v)
+	case uint64:
+		n = int(v)
+	default:
+		return errors.Errorf(`redirect policy must be "%s", "%s", or the maximum number of redirects but got %T`, redirectFollow, redirectNoFollow, v)
+	}
+	policy, err := NewRedirectPolicy(n)
+	if err != nil {
+		return err
+	}
+	*p = *policy
+	return nil
+}
+
+// MarshalYAML implements yaml.InterfaceMarshaler interface.
+func (p *RedirectPolicy) MarshalYAML() (interface{}, error) {
+	if p.noFollow {
+		return redirectNoFollow, nil
+	}
+	if p.maxRedirects == 0 {
+		return redirectFollow, nil
+	}
+	return p.maxRedirects, nil
+}
+
+// checkRedirect returns the function for http.Client.CheckRedirect.
+func (p *RedirectPolicy) checkRedirect() func(*http.Request, []*http.Request) error {
+	if p.noFollow {
+		return func(*http.Request, []*http.Request) error {
+			// return the redirect response to assert it
+			return http.ErrUseLastResponse
+		}
+	}
+	max := p.maxRedirects
+	if max == 0 {
+		max = defaultMaxRedirects
+	}
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > max {
+			return errors.Errorf("stopped after %d redirects: exceeded the maximum number of redirects", max)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+func TestRedirectPolicy_UnmarshalYAML(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		tests := map[string]struct {
+			yaml   string
+			expect *RedirectPolicy
+		}{
+			"follow": {
+				yaml:   "follow",
+				expect: &RedirectPolicy{},
+			},
+			"no-follow": {
+				yaml:   "no-follow",
+				expect: &RedirectPolicy{noFollow: true},
+			},
+			"max": {
+				yaml:   "3",
+				expect: &RedirectPolicy{maxRedirects: 3},
+			},
+			"zero": {
+				yaml:   "0",
+				expect: &RedirectPolicy{noFollow: true},
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				var got RedirectPolicy
+				if err := yaml.Unmarshal([]byte(test.yaml), &got); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if diff := cmp.Diff(test.expect, &got, cmp.AllowUnexported(RedirectPolicy{})); diff != "" {
+					t.Errorf("differs (-want +got):\n%s", diff)
+				}
+				b, err := yaml.Marshal(&got)
+				if err != nil {
+					t.Fatalf("failed to marshal: %s", err)
+				}
+				if name != "zero" {
+					if got, expect := strings.TrimSpace(string(b)), test.yaml; got != expect {
+						t.Errorf("expect %q but got %q", expect, got)
+					}
+				}
+			})
+		}
+	})
+	t.Run("failure", func(t *testing.T) {
+		tests := map[string]string{
+			"unknown policy": "always",
+			"negative":       "-1",
+			"invalid type":   "[1]",
+		}
+		for name, in := range tests {
+			in := in
+			t.Run(name, func(t *testing.T) {
+				var got RedirectPolicy
+				if err := yaml.Unmarshal([]byte(in), &got); err == nil {
+					t.Fatal("no error")
+				}
+			})
+		}
+	})
+}
+
+func TestRequest_Invoke_Redirect(t *testing.T) {
+	m := http.NewServeMux()
+	m.HandleFunc("/redirect/", func(w http.ResponseWriter, req *http.Request) {
+		var n int
+		if _, err := fmt.Sscanf(req.URL.Path, "/redirect/%d", &n); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if n <= 0 {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"message":"done"}`))
+			return
+		}
+		http.Redirect(w, req, fmt.Sprintf("/redirect/%d", n-1), http.StatusFound)
+	})
+	m.HandleFunc("/loop", func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "/loop", http.StatusFound)
+	})
+	srv := httptest.NewServer(m)
+	t.Cleanup(srv.Close)
+
+	policy := func(t *testing.T, s string) *RedirectPolicy {
+		t.Helper()
+		var p RedirectPolicy
+		if err := yaml.Unmarshal([]byte(s), &p); err != nil {
+			t.Fatal(err)
+		}
+		return &p
+	}
+
+	tests := map[string]struct {
+		config         *Config
+		redirect       string
+		path           string
+		expectStatus   string
+		expectLocation string
+		expectError    string
+	}{
+		"follow by default": {
+			path:         "/redirect/2",
+			expectStatus: "200 OK",
+		},
+		"no-follow": {
+			redirect:       "no-follow",
+			path:           "/redirect/2",
+			expectStatus:   "302 Found",
+			expectLocation: "/redirect/1",
+		},
+		"within the maximum": {
+			redirect:     "2",
+			path:         "/redirect/2",
+			expectStatus: "200 OK",
+		},
+		"exceeded the maximum": {
+			redirect:    "2",
+			path:        "/redirect/3",
+			expectError: "stopped after 2 redirects: exceeded the maximum number of redirects",
+		},
+		"redirect loop": {
+			path:        "/loop",
+			expectError: "stopped after 10 redirects",
+		},
+		"global config": {
+			config:         &Config{Redirect: &RedirectPolicy{noFollow: true}},
+			path:           "/redirect/1",
+			expectStatus:   "302 Found",
+			expectLocation: "/redirect/0",
+		},
+		"request overrides global config": {
+			config:       &Config{Redirect: &RedirectPolicy{noFollow: true}},
+			redirect:     "follow",
+			path:         "/redirect/1",
+			expectStatus: "200 OK",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctx := WithConfig(context.FromT(t), test.config)
+			req := &Request{
+				URL: srv.URL + test.path,
+			}
+			if test.redirect != "" {
+				req.Redirect = policy(t, test.redirect)
+			}
+			_, res, err := req.Invoke(ctx)
+			if test.expectError != "" {
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if got := err.Error(); !strings.Contains(got, test.expectError) {
+					t.Errorf("%q doesn't contain %q", got, test.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to invoke: %s", err)
+			}
+			resp, ok := res.(response)
+			if !ok {
+				t.Fatalf("failed to convert from %T to response", res)
+			}
+			if got, expect := resp.status, test.expectStatus; got != expect {
+				t.Errorf("expect %q but got %q", expect, got)
+			}
+			if got, expect := http.Header(resp.Header).Get("Location"), test.expectLocation; got != expect {
+				t.Errorf("expect Location %q but got %q", expect, got)
+			}
+		})
+	}
+}
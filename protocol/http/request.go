@@ -2,14 +2,17 @@ package http
 
 import (
 	"bytes"
-	"compress/gzip"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"mime"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/goccy/go-yaml"
 	"github.com/mattn/go-encoding"
@@ -25,18 +28,51 @@ var defaultUserAgent = fmt.Sprintf("scenarigo/%s", version.String())
 
 // Request represents a request.
 type Request struct {
-	Client string      `yaml:"client,omitempty"`
-	Method string      `yaml:"method,omitempty"`
-	URL    string      `yaml:"url,omitempty"`
-	Query  interface{} `yaml:"query,omitempty"`
-	Header interface{} `yaml:"header,omitempty"`
-	Body   interface{} `yaml:"body,omitempty"`
+	Client   string          `yaml:"client,omitempty"`
+	Method   string          `yaml:"method,omitempty"`
+	URL      string          `yaml:"url,omitempty"`
+	Query    interface{}     `yaml:"query,omitempty"`
+	Header   interface{}     `yaml:"header,omitempty"`
+	Body     interface{}     `yaml:"body,omitempty"`
+	BodyFile string          `yaml:"bodyFile,omitempty"`
+	Redirect *RedirectPolicy `yaml:"redirect,omitempty"`
+	TLS      *TLSConfig      `yaml:"tls,omitempty"`
+	Proxy    *ProxyConfig    `yaml:"proxy,omitempty"`
+	Auth     *AuthConfig     `yaml:"auth,omitempty"`
+	Sign     *SignConfig     `yaml:"sign,omitempty"`
+	SSE      *SSEConfig      `yaml:"sse,omitempty"`
+
+	// ResponseContentType is the media type to decode the response body instead of the Content-Type response header.
+	ResponseContentType string `yaml:"responseContentType,omitempty"`
+	// DisableDecompression disables decompressing the response body by the Content-Encoding response header.
+	// The compressed body is decoded as bytes unless ResponseContentType is specified.
+	DisableDecompression bool `yaml:"disableDecompression,omitempty"`
 }
 
 type response struct {
-	Header map[string][]string `yaml:"header,omitempty"`
-	Body   interface{}         `yaml:"body,omitempty"`
-	status string              `yaml:"-"` // http.Response.Status format e.g. "200 OK"
+	Header  responseHeader `yaml:"header,omitempty"`
+	Body    interface{}    `yaml:"body,omitempty"`
+	status  string         `yaml:"-"` // http.Response.Status format e.g. "200 OK"
+	elapsed time.Duration  `yaml:"-"` // from sending the request to reading the whole body
+}
+
+// ExtractByKey implements query.KeyExtractor interface.
+// The status code and text are available as "code" and "status" in addition to the header and body.
+func (r response) ExtractByKey(key string) (interface{}, bool) {
+	switch key {
+	case "header":
+		return r.Header, true
+	case "body":
+		return r.Body, true
+	case "status":
+		return r.status, true
+	case "code":
+		code, _, _ := strings.Cut(r.status, " ")
+		if i, err := strconv.Atoi(code); err == nil {
+			return i, true
+		}
+	}
+	return nil, false
 }
 
 const (
@@ -56,6 +92,18 @@ func (r *Request) addIndent(s string, indentNum int) string {
 	return strings.Join(lines, "\n")
 }
 
+// Prepare implements protocol.Preparer interface.
+// It loads the certificates of the TLS configuration before running the scenario.
+func (r *Request) Prepare(ctx *context.Context) error {
+	if r.TLS == nil {
+		return nil
+	}
+	if err := r.TLS.prepare(ctx, filepath.Dir(ctx.ScenarioFilepath())); err != nil {
+		return errors.WithPath(err, "tls")
+	}
+	return nil
+}
+
 // Invoke implements protocol.Invoker interface.
 func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, error) {
 	client, err := r.buildClient(ctx)
@@ -66,6 +114,16 @@ func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, e
 	if err != nil {
 		return ctx, nil, err
 	}
+	// the auth of the request overrides the default one
+	if auth := r.Auth; auth != nil || configFrom(ctx).Auth != nil {
+		if auth == nil {
+			auth = configFrom(ctx).Auth
+		}
+		ctx, err = auth.authorize(ctx, client, req)
+		if err != nil {
+			return ctx, nil, errors.WithPath(err, "auth")
+		}
+	}
 
 	ctx = ctx.WithRequest(reqBody)
 	//nolint:exhaustruct
@@ -80,24 +138,45 @@ func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, e
 		ctx.Reporter().Logf("failed to dump request:\n%s", err)
 	}
 
+	if r.Sign != nil {
+		if err := r.Sign.sign(ctx, req); err != nil {
+			return ctx, nil, errors.WithPath(err, "sign")
+		}
+	}
+
+	start := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
 		return ctx, nil, errors.Errorf("failed to send request: %s", err)
 	}
 	defer resp.Body.Close()
 
+	if r.SSE != nil && isEventStream(resp.Header.Get("Content-Type")) {
+		return r.consumeEventStream(ctx, resp, start)
+	}
+
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return ctx, nil, errors.Errorf("failed to read response body: %s", err)
 	}
+	elapsed := time.Since(start)
+	ctx = ctx.WithElapsed(elapsed)
 
 	rvalue := response{
-		Header: resp.Header,
-		Body:   nil,
-		status: resp.Status,
+		Header:  responseHeader(resp.Header),
+		Body:    nil,
+		status:  resp.Status,
+		elapsed: elapsed,
 	}
 	if len(b) > 0 {
-		unmarshaler := unmarshaler.Get(resp.Header.Get("Content-Type"))
+		contentType := resp.Header.Get("Content-Type")
+		if r.DisableDecompression && resp.Header.Get("Content-Encoding") != "" {
+			contentType = ""
+		}
+		if r.ResponseContentType != "" {
+			contentType = r.ResponseContentType
+		}
+		unmarshaler := unmarshaler.Get(contentType)
 		var respBody interface{}
 		if err := unmarshaler.Unmarshal(b, &respBody); err != nil {
 			return ctx, nil, errors.Errorf("failed to unmarshal response body as %s: %s: %s", unmarshaler.MediaType(), string(b), err)
@@ -113,15 +192,51 @@ func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, e
 	return ctx, rvalue, nil
 }
 
+// consumeEventStream reads the response body as Server-Sent Events.
+// The events are set as the response body.
+func (r *Request) consumeEventStream(ctx *context.Context, resp *http.Response, start time.Time) (*context.Context, interface{}, error) {
+	events, err := r.SSE.consume(ctx, resp.Body)
+	elapsed := time.Since(start)
+	ctx = ctx.WithElapsed(elapsed)
+
+	rvalue := response{
+		Header:  responseHeader(resp.Header),
+		Body:    events,
+		status:  resp.Status,
+		elapsed: elapsed,
+	}
+	if b, err := yaml.Marshal(rvalue); err == nil {
+		ctx.Reporter().Logf("response:\n%s", r.addIndent(string(b), indentNum))
+	} else {
+		ctx.Reporter().Logf("failed to dump response:\n%s", err)
+	}
+	if err != nil {
+		return ctx, nil, errors.WithPath(err, "sse")
+	}
+	ctx = ctx.WithResponse(events)
+	return ctx, rvalue, nil
+}
+
 func (r *Request) buildClient(ctx *context.Context) (*http.Client, error) {
+	transport, err := r.buildTransport(ctx)
+	if err != nil {
+		return nil, err
+	}
 	client := &http.Client{
 		Transport: &charsetRoundTripper{
 			base: &encodingRoundTripper{
-				base: http.DefaultTransport,
+				base:     transport,
+				disabled: r.DisableDecompression,
 			},
 		},
 	}
 	if r.Client != "" {
+		if r.TLS != nil {
+			return nil, errors.ErrorPath("tls", "tls can't be used with the custom client")
+		}
+		if r.Proxy != nil {
+			return nil, errors.ErrorPath("proxy", "proxy can't be used with the custom client")
+		}
 		x, err := ctx.ExecuteTemplate(r.Client)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to get client")
@@ -131,9 +246,71 @@ func (r *Request) buildClient(ctx *context.Context) (*http.Client, error) {
 			return nil, errors.Errorf(`client must be "*http.Client" but got "%T"`, x)
 		}
 	}
+	if jar := ctx.CookieJar(); jar != nil && client.Jar == nil {
+		// copy not to modify the custom client
+		c := *client
+		c.Jar = jar
+		client = &c
+	}
+	// the default policy doesn't override the custom client's one
+	if policy := r.Redirect; policy != nil || client.CheckRedirect == nil {
+		if policy == nil {
+			policy = configFrom(ctx).Redirect
+		}
+		if policy != nil {
+			c := *client
+			c.CheckRedirect = policy.checkRedirect()
+			client = &c
+		}
+	}
 	return client, nil
 }
 
+// buildTransport returns the transport which applies the TLS and proxy configurations.
+func (r *Request) buildTransport(ctx *context.Context) (http.RoundTripper, error) {
+	tlsConfig, err := r.tlsConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	proxy := configFrom(ctx).Proxy
+	if r.Proxy != nil {
+		proxy, err = r.Proxy.Build(ctx)
+		if err != nil {
+			return nil, errors.WithPath(err, "proxy")
+		}
+	}
+	if tlsConfig == nil && proxy == nil {
+		return http.DefaultTransport, nil
+	}
+	t, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.Errorf("failed to configure transport: unexpected default transport %T", http.DefaultTransport)
+	}
+	t = t.Clone()
+	if tlsConfig != nil {
+		t.TLSClientConfig = tlsConfig
+	}
+	if proxy != nil {
+		t.Proxy = proxy
+	}
+	return t, nil
+}
+
+// tlsConfig returns the TLS configuration of the request or the default configuration.
+// It returns nil if both are not specified.
+func (r *Request) tlsConfig(ctx *context.Context) (*tls.Config, error) {
+	if r.TLS == nil {
+		return configFrom(ctx).TLS, nil
+	}
+	if r.TLS.config == nil {
+		// not prepared yet
+		if err := r.Prepare(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return r.TLS.config, nil
+}
+
 type charsetRoundTripper struct {
 	base http.RoundTripper
 }
@@ -143,6 +320,9 @@ func (rt *charsetRoundTripper) RoundTrip(req *http.Request) (*http.Response, err
 	if err != nil {
 		return resp, err
 	}
+	if resp.Header.Get("Content-Encoding") != "" && !resp.Uncompressed {
+		return resp, nil // the compressed body can't be decoded
+	}
 	if ct := resp.Header.Get("Content-Type"); ct != "" {
 		_, params, err := mime.ParseMediaType(strings.Trim(ct, " "))
 		if err != nil {
@@ -167,28 +347,6 @@ type readCloser struct {
 	io.Closer
 }
 
-type encodingRoundTripper struct {
-	base http.RoundTripper
-}
-
-func (rt *encodingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	if req.Header.Get("Accept-Encoding") == "" {
-		req.Header.Add("Accept-Encoding", "gzip")
-	}
-	resp, err := rt.base.RoundTrip(req)
-	if err != nil {
-		return resp, err
-	}
-	if resp.Header.Get("Content-Encoding") == "gzip" {
-		r, err := gzip.NewReader(resp.Body)
-		if err != nil {
-			return nil, errors.Errorf("failed to read response body: %s", err)
-		}
-		resp.Body = r
-	}
-	return resp, err
-}
-
 func (r *Request) buildRequest(ctx *context.Context) (*http.Request, interface{}, error) {
 	method := http.MethodGet
 	if r.Method != "" {
@@ -223,23 +381,51 @@ func (r *Request) buildRequest(ctx *context.Context) (*http.Request, interface{}
 
 	var reader io.Reader
 	var body interface{}
-	if r.Body != nil {
+	hasBody := r.Body != nil
+	if r.BodyFile != "" {
+		if r.Body != nil {
+			return nil, nil, errors.ErrorPath("bodyFile", "bodyFile can't be used with body")
+		}
+		x, raw, err := r.readBodyFile(ctx)
+		if err != nil {
+			return nil, nil, errors.WrapPathf(err, "bodyFile", "failed to create request")
+		}
+		body = x
+		hasBody = true
+		if raw != nil {
+			reader = bytes.NewReader(raw)
+		}
+	} else if r.Body != nil {
 		x, err := ctx.ExecuteTemplate(r.Body)
 		if err != nil {
 			return nil, nil, errors.WrapPathf(err, "body", "failed to create request")
 		}
 		body = x
-
-		marshaler := marshaler.Get(header.Get("Content-Type"))
-		b, err := marshaler.Marshal(body)
-		if err != nil {
-			return nil, nil, errors.ErrorPathf("body", "failed to marshal request body as %s: %#v: %s", marshaler.MediaType(), body, err)
+	}
+	if hasBody && reader == nil {
+		if ct := header.Get("Content-Type"); isMultipartFormData(ct) {
+			// stream the body to avoid loading large files into memory
+			rc, contentType, err := buildMultipartBody(ctx, ct, body)
+			if err != nil {
+				return nil, nil, errors.WrapPathf(err, "body", "failed to create multipart/form-data body")
+			}
+			header.Set("Content-Type", contentType)
+			reader = rc
+		} else {
+			marshaler := marshaler.Get(ct)
+			b, err := marshaler.Marshal(body)
+			if err != nil {
+				return nil, nil, errors.ErrorPathf("body", "failed to marshal request body as %s: %#v: %s", marshaler.MediaType(), body, err)
+			}
+			reader = bytes.NewReader(b)
 		}
-		reader = bytes.NewReader(b)
 	}
 
 	req, err := http.NewRequest(strings.ToUpper(method), urlStr, reader)
 	if err != nil {
+		if c, ok := reader.(io.Closer); ok {
+			c.Close()
+		}
 		return nil, nil, errors.Errorf("failed to create request: %s", err)
 	}
 	req = req.WithContext(ctx.RequestContext())
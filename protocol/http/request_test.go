@@ -5,12 +5,16 @@ import (
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/goccy/go-yaml"
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	"golang.org/x/text/encoding/japanese"
@@ -18,6 +22,7 @@ import (
 	"github.com/zoncoen/scenarigo/context"
 	"github.com/zoncoen/scenarigo/internal/testutil"
 	"github.com/zoncoen/scenarigo/reporter"
+	"github.com/zoncoen/scenarigo/template"
 )
 
 type transport struct {
@@ -120,6 +125,10 @@ func TestRequest_Invoke(t *testing.T) {
 		w.Header().Set("Content-Type", "application/json; charset=Shift_JIS")
 		_, _ = w.Write(b)
 	})
+	m.HandleFunc("/xml", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", req.URL.Query().Get("type"))
+		_, _ = w.Write([]byte(`<?xml version="1.0"?><message id="123">hey</message>`))
+	})
 	srv := httptest.NewServer(m)
 	defer srv.Close()
 
@@ -128,6 +137,27 @@ func TestRequest_Invoke(t *testing.T) {
 		request  *Request
 		response response
 	}{
+		"XML": {
+			request: &Request{
+				URL:   srv.URL + "/xml",
+				Query: url.Values{"type": []string{"application/xml"}},
+			},
+			response: response{
+				status: "200 OK",
+				Body:   map[string]interface{}{"message": map[string]interface{}{"@id": "123", "#text": "hey"}},
+			},
+		},
+		"override response content type": {
+			request: &Request{
+				URL:                 srv.URL + "/xml",
+				Query:               url.Values{"type": []string{"text/plain"}},
+				ResponseContentType: "application/xml",
+			},
+			response: response{
+				status: "200 OK",
+				Body:   map[string]interface{}{"message": map[string]interface{}{"@id": "123", "#text": "hey"}},
+			},
+		},
 		"default": {
 			request: &Request{
 				URL: srv.URL,
@@ -347,6 +377,195 @@ func TestRequest_Invoke_Error(t *testing.T) {
 	}
 }
 
+func TestRequest_Invoke_Multipart(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := req.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(err.Error()))
+			return
+		}
+		body := map[string]interface{}{}
+		for k, vs := range req.MultipartForm.Value {
+			body[k] = strings.Join(vs, ",")
+		}
+		for k, fhs := range req.MultipartForm.File {
+			var files []string
+			for _, fh := range fhs {
+				f, err := fh.Open()
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				b, _ := io.ReadAll(f)
+				f.Close()
+				files = append(files, fmt.Sprintf("%s:%s:%s", fh.Filename, fh.Header.Get("Content-Type"), b))
+			}
+			body[k] = strings.Join(files, ",")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(t.TempDir(), "hello.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		vars   interface{}
+		body   interface{}
+		expect interface{}
+		err    string
+	}{
+		"fields": {
+			vars: map[string]interface{}{"name": "scenarigo"},
+			body: map[string]interface{}{
+				"name": "{{vars.name}}",
+				"tags": []interface{}{"a", "b"},
+				"num":  1,
+			},
+			expect: map[string]interface{}{
+				"name": "scenarigo",
+				"tags": "a,b",
+				"num":  "1",
+			},
+		},
+		"files": {
+			vars: map[string]interface{}{"path": path},
+			body: yaml.MapSlice{
+				{Key: "name", Value: "test"},
+				{Key: "file", Value: map[string]interface{}{
+					"file": "{{vars.path}}",
+				}},
+				{Key: "inline", Value: map[string]interface{}{
+					"content":     "inline content",
+					"filename":    "inline.json",
+					"contentType": "application/json",
+				}},
+			},
+			expect: map[string]interface{}{
+				"name":   "test",
+				"file":   "hello.txt:application/octet-stream:hello",
+				"inline": "inline.json:application/json:inline content",
+			},
+		},
+		"file not found": {
+			body: map[string]interface{}{
+				"file": map[string]interface{}{
+					"file": filepath.Join(t.TempDir(), "not-found.txt"),
+				},
+			},
+			err: ".body.file: failed to create multipart/form-data body: failed to open file",
+		},
+		"both file and content": {
+			body: map[string]interface{}{
+				"file": map[string]interface{}{
+					"file":    path,
+					"content": "test",
+				},
+			},
+			err: "file and content can't be specified at the same time",
+		},
+		"unknown field": {
+			body: map[string]interface{}{
+				"file": map[string]interface{}{
+					"path": path,
+				},
+			},
+			err: "invalid file",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctx := context.FromT(t)
+			if test.vars != nil {
+				ctx = ctx.WithVars(test.vars)
+			}
+			req := &Request{
+				Method: http.MethodPost,
+				URL:    srv.URL,
+				Header: map[string]string{"Content-Type": "multipart/form-data"},
+				Body:   test.body,
+			}
+			_, res, err := req.Invoke(ctx)
+			if test.err != "" {
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if got := err.Error(); !strings.Contains(got, test.err) {
+					t.Errorf("%q doesn't contain %q", got, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to invoke: %s", err)
+			}
+			actualRes, ok := res.(response)
+			if !ok {
+				t.Fatalf("failed to convert from %T to response", res)
+			}
+			if diff := cmp.Diff(test.expect, actualRes.Body); diff != "" {
+				t.Errorf("differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRequest_Invoke_UUID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"header": req.Header.Get("X-Request-Id"),
+			"body":   string(b),
+			"path":   strings.TrimPrefix(req.URL.Path, "/orders/"),
+			"query":  req.URL.Query().Get("id"),
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	invoke := func(t *testing.T, ctx *context.Context) map[string]interface{} {
+		t.Helper()
+		req := &Request{
+			Method: http.MethodPost,
+			URL:    srv.URL + "/orders/{{uuid()}}",
+			Query:  map[string]string{"id": "{{uuid()}}"},
+			Header: map[string]string{
+				"Content-Type": "text/plain",
+				"X-Request-Id": "{{uuid()}}",
+			},
+			Body: "{{uuid()}}",
+		}
+		_, res, err := req.Invoke(ctx)
+		if err != nil {
+			t.Fatalf("failed to invoke: %s", err)
+		}
+		body, ok := res.(response).Body.(map[string]interface{})
+		if !ok {
+			t.Fatalf("unexpected body type %T", res.(response).Body)
+		}
+		return body
+	}
+
+	ctx := context.FromT(t).WithTemplateState(template.NewState())
+	body := invoke(t, ctx)
+	id, ok := body["header"].(string)
+	if !ok || id == "" {
+		t.Fatalf("no request id: %v", body)
+	}
+	for _, k := range []string{"body", "path", "query"} {
+		if body[k] != id {
+			t.Errorf("expected the %s %q but got %q", k, id, body[k])
+		}
+	}
+
+	if next := invoke(t, ctx.WithTemplateState(template.NewState())); next["header"] == id {
+		t.Errorf("the request with another state has the same request id %q", id)
+	}
+}
+
 func TestRequest_Invoke_Log(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		m := http.NewServeMux()
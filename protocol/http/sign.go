@@ -0,0 +1,101 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
+	"github.com/zoncoen/scenarigo/protocol/http/signer"
+	"github.com/zoncoen/scenarigo/reporter"
+)
+
+// SignConfig represents a configuration to sign HTTP requests by the signer such as "sigv4".
+// The values of Config are templated, and the secret values of the signer are masked in the outputs.
+type SignConfig struct {
+	Signer string      `yaml:"signer,omitempty"`
+	Config interface{} `yaml:"config,omitempty"`
+}
+
+// sign signs the request just before it is sent.
+func (c *SignConfig) sign(ctx *context.Context, req *http.Request) error {
+	s, err := signer.Get(c.Signer)
+	if err != nil {
+		return errors.WithPath(err, "signer")
+	}
+	config, err := c.buildConfig(ctx)
+	if err != nil {
+		return errors.WithPath(err, "config")
+	}
+	if sk, ok := s.(signer.SecretConfigKeys); ok {
+		for _, k := range sk.SecretConfigKeys() {
+			if v := config[k]; v != "" {
+				reporter.AddSecret(ctx.Reporter(), v)
+			}
+		}
+	}
+	body, err := requestBody(req)
+	if err != nil {
+		return err
+	}
+	if err := s.Sign(req, body, config); err != nil {
+		return errors.Errorf("failed to sign request: %s", err)
+	}
+	return nil
+}
+
+func (c *SignConfig) buildConfig(ctx *context.Context) (map[string]string, error) {
+	config := map[string]string{}
+	if c.Config == nil {
+		return config, nil
+	}
+	x, err := ctx.ExecuteTemplate(c.Config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get signer config")
+	}
+	m, err := reflectutil.ConvertStringsMap(reflect.ValueOf(x))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid signer config")
+	}
+	for k, vs := range m {
+		if len(vs) != 1 {
+			return nil, errors.ErrorPathf(k, "invalid signer config: expected string but got %d values", len(vs))
+		}
+		config[k] = vs[0]
+	}
+	return config, nil
+}
+
+// requestBody returns the body of the request without consuming it.
+// The streaming body such as multipart/form-data is read into memory to be signed.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, errors.Errorf("failed to read request body: %s", err)
+		}
+		defer rc.Close()
+		b, err := io.ReadAll(rc)
+		if err != nil {
+			return nil, errors.Errorf("failed to read request body: %s", err)
+		}
+		return b, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, errors.Errorf("failed to read request body: %s", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+	req.ContentLength = int64(len(b))
+	return b, nil
+}
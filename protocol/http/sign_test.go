@@ -0,0 +1,188 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/protocol/http/signer"
+	"github.com/zoncoen/scenarigo/reporter"
+)
+
+func TestRequest_Invoke_Sign(t *testing.T) {
+	if err := signer.Register("test-sign", signer.SignerFunc(func(req *http.Request, body []byte, config map[string]string) error {
+		req.Header.Set("X-Signature", fmt.Sprintf("%s:%s", config["key"], body))
+		return nil
+	})); err != nil {
+		t.Fatalf("failed to register signer: %s", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(fmt.Sprintf("%s|%s|%s", req.Header.Get("X-Signature"), req.Header.Get("Authorization"), b)))
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		vars   map[string]interface{}
+		sign   *SignConfig
+		body   interface{}
+		expect func(t *testing.T, s string)
+	}{
+		"custom signer": {
+			vars: map[string]interface{}{"key": "abc"},
+			sign: &SignConfig{
+				Signer: "test-sign",
+				Config: map[string]interface{}{"key": "{{vars.key}}"},
+			},
+			body: "hello",
+			expect: func(t *testing.T, s string) {
+				t.Helper()
+				if expect := "abc:hello||hello"; s != expect {
+					t.Errorf("expected %q but got %q", expect, s)
+				}
+			},
+		},
+		"sigv4": {
+			vars: map[string]interface{}{"secret": "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"},
+			sign: &SignConfig{
+				Signer: "sigv4",
+				Config: map[string]interface{}{
+					"accessKeyID":     "AKIDEXAMPLE",
+					"secretAccessKey": "{{vars.secret}}",
+					"region":          "us-east-1",
+					"service":         "execute-api",
+				},
+			},
+			body: "hello",
+			expect: func(t *testing.T, s string) {
+				t.Helper()
+				parts := strings.Split(s, "|")
+				if len(parts) != 3 {
+					t.Fatalf("unexpected response: %q", s)
+				}
+				if prefix := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"; !strings.HasPrefix(parts[1], prefix) {
+					t.Errorf("expected prefix %q but got %q", prefix, parts[1])
+				}
+				if expect := "/us-east-1/execute-api/aws4_request"; !strings.Contains(parts[1], expect) {
+					t.Errorf("%q doesn't contain %q", parts[1], expect)
+				}
+				if parts[2] != "hello" {
+					t.Errorf("expected body %q but got %q", "hello", parts[2])
+				}
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctx := context.FromT(t).WithVars(test.vars)
+			req := &Request{
+				Method: http.MethodPost,
+				URL:    srv.URL,
+				Header: map[string][]string{"Content-Type": {"text/plain"}},
+				Body:   test.body,
+				Sign:   test.sign,
+			}
+			_, res, err := req.Invoke(ctx)
+			if err != nil {
+				t.Fatalf("failed to invoke: %s", err)
+			}
+			resp, ok := res.(response)
+			if !ok {
+				t.Fatalf("unexpected response type %T", res)
+			}
+			s, ok := resp.Body.(string)
+			if !ok {
+				t.Fatalf("unexpected body type %T", resp.Body)
+			}
+			test.expect(t, s)
+		})
+	}
+}
+
+func TestRequest_Invoke_Sign_Error(t *testing.T) {
+	tests := map[string]struct {
+		sign   *SignConfig
+		expect string
+	}{
+		"unknown signer": {
+			sign:   &SignConfig{Signer: "unknown"},
+			expect: `.sign.signer: unknown signer "unknown"`,
+		},
+		"invalid config": {
+			sign: &SignConfig{
+				Signer: "sigv4",
+				Config: map[string]interface{}{"accessKeyID": "{{vars.undefined}}"},
+			},
+			expect: ".sign.config",
+		},
+		"missing config": {
+			sign: &SignConfig{
+				Signer: "sigv4",
+				Config: map[string]interface{}{"accessKeyID": "AKIDEXAMPLE"},
+			},
+			expect: "failed to sign request: sigv4: secretAccessKey is required",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			req := &Request{
+				Method: http.MethodGet,
+				URL:    "http://127.0.0.1:0",
+				Sign:   test.sign,
+			}
+			_, _, err := req.Invoke(context.FromT(t))
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), test.expect) {
+				t.Errorf("expected error %q but got %q", test.expect, err)
+			}
+		})
+	}
+}
+
+func TestRequest_Invoke_Sign_MaskSecret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer srv.Close()
+
+	secret := "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	var b bytes.Buffer
+	reporter.Run(func(rptr reporter.Reporter) {
+		rptr.Run("test.yaml", func(rptr reporter.Reporter) {
+			ctx := context.New(rptr).WithVars(map[string]string{"secret": secret})
+			req := &Request{
+				Method: http.MethodGet,
+				URL:    srv.URL,
+				Sign: &SignConfig{
+					Signer: "sigv4",
+					Config: map[string]interface{}{
+						"accessKeyID":     "AKIDEXAMPLE",
+						"secretAccessKey": "{{vars.secret}}",
+						"region":          "us-east-1",
+						"service":         "execute-api",
+					},
+				},
+			}
+			if _, _, err := req.Invoke(ctx); err != nil {
+				t.Fatalf("failed to invoke: %s", err)
+			}
+			rptr.Logf("secret: %s", secret)
+		})
+	}, reporter.WithWriter(&b), reporter.WithVerboseLog())
+	if strings.Contains(b.String(), secret) {
+		t.Fatalf("output contains the secret:\n%s", b.String())
+	}
+}
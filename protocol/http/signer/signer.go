@@ -0,0 +1,69 @@
+// Package signer provides the request signers of the HTTP protocol such as AWS Signature Version 4.
+package signer
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Signer is the interface that signs HTTP requests just before they are sent.
+type Signer interface {
+	// Sign signs the request by setting the headers such as Authorization.
+	// The body is the encoded request body, and the config is the configuration of the request which is already templated.
+	Sign(req *http.Request, body []byte, config map[string]string) error
+}
+
+// SecretConfigKeys is the interface that reports the keys of the configuration whose values are secrets.
+// The secret values are masked in the logs and the test reports.
+type SecretConfigKeys interface {
+	SecretConfigKeys() []string
+}
+
+// SignerFunc is an adaptor to allow the use of ordinary functions as Signer.
+type SignerFunc func(req *http.Request, body []byte, config map[string]string) error
+
+// Sign implements Signer interface.
+func (f SignerFunc) Sign(req *http.Request, body []byte, config map[string]string) error {
+	return f(req, body, config)
+}
+
+var (
+	m        sync.Mutex
+	registry = map[string]Signer{
+		"sigv4": &SigV4{},
+	}
+)
+
+// Register registers the signer to the registry by the name.
+// It overrides the signer of the same name including the built-in ones.
+func Register(name string, s Signer) error {
+	if name == "" {
+		return errors.New("failed to register signer: name is empty")
+	}
+	if s == nil {
+		return errors.Errorf("failed to register signer %q: signer is nil", name)
+	}
+	m.Lock()
+	defer m.Unlock()
+	registry[name] = s
+	return nil
+}
+
+// Get returns the signer of the name.
+func Get(name string) (Signer, error) {
+	m.Lock()
+	defer m.Unlock()
+	s, ok := registry[name]
+	if !ok {
+		names := make([]string, 0, len(registry))
+		for n := range registry {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, errors.Errorf("unknown signer %q: signer must be one of %q", name, names)
+	}
+	return s, nil
+}
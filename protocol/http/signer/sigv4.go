@@ -0,0 +1,211 @@
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat = "20060102T150405Z"
+)
+
+// sigV4IgnoredHeaders are the headers which are not signed because proxies may modify them.
+var sigV4IgnoredHeaders = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+}
+
+// SigV4 is the signer of AWS Signature Version 4.
+// The config has the following keys.
+//
+//   - accessKeyID: the access key ID (required)
+//   - secretAccessKey: the secret access key (required)
+//   - sessionToken: the session token of the temporary credentials
+//   - region: the region such as us-east-1 (required)
+//   - service: the service name such as execute-api (required)
+//
+// It sets the Authorization and X-Amz-Date headers, and X-Amz-Security-Token if sessionToken is specified.
+// The X-Amz-Date header of the request is used as the signing time if it exists.
+type SigV4 struct {
+	now func() time.Time
+}
+
+// SecretConfigKeys implements SecretConfigKeys interface.
+func (s *SigV4) SecretConfigKeys() []string {
+	return []string{"secretAccessKey", "sessionToken"}
+}
+
+// Sign implements Signer interface.
+func (s *SigV4) Sign(req *http.Request, body []byte, config map[string]string) error {
+	for _, k := range []string{"accessKeyID", "secretAccessKey", "region", "service"} {
+		if config[k] == "" {
+			return errors.Errorf("sigv4: %s is required", k)
+		}
+	}
+	service := config["service"]
+
+	amzDate := req.Header.Get("X-Amz-Date")
+	if amzDate == "" {
+		now := time.Now
+		if s.now != nil {
+			now = s.now
+		}
+		amzDate = now().UTC().Format(sigV4TimeFormat)
+		req.Header.Set("X-Amz-Date", amzDate)
+	} else if _, err := time.Parse(sigV4TimeFormat, amzDate); err != nil {
+		return errors.Errorf("sigv4: invalid X-Amz-Date header %q", amzDate)
+	}
+	if token := config["sessionToken"]; token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+	payloadHash := hashHex(body)
+	if service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	creq, signedHeaders := sigV4CanonicalRequest(req, payloadHash, service != "s3")
+	scope := strings.Join([]string{amzDate[:8], config["region"], service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, hashHex([]byte(creq))}, "\n")
+
+	key := []byte("AWS4" + config["secretAccessKey"])
+	for _, v := range []string{amzDate[:8], config["region"], service, "aws4_request"} {
+		key = hmacSHA256(key, v)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, config["accessKeyID"], scope, signedHeaders, signature,
+	))
+	return nil
+}
+
+// sigV4CanonicalRequest returns the canonical request and the signed headers.
+// The path is normalized and encoded again except for Amazon S3, as the AWS SDKs do.
+func sigV4CanonicalRequest(req *http.Request, payloadHash string, normalize bool) (string, string) {
+	path := req.URL.EscapedPath()
+	if normalize {
+		path = uriEncode(normalizePath(path), false)
+	} else if path == "" {
+		path = "/"
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string][]string{"host": {host}}
+	for k, vs := range req.Header {
+		name := strings.ToLower(k)
+		if sigV4IgnoredHeaders[name] || name == "host" {
+			continue
+		}
+		for _, v := range vs {
+			headers[name] = append(headers[name], strings.Join(strings.Fields(v), " "))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.Join(headers[name], ","))
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	return strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.RawQuery),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n"), signedHeaders
+}
+
+// normalizePath removes the empty and dot segments of the path.
+func normalizePath(path string) string {
+	var segments []string
+	for _, s := range strings.Split(path, "/") {
+		switch s {
+		case "", ".":
+		case "..":
+			if len(segments) > 0 {
+				segments = segments[:len(segments)-1]
+			}
+		default:
+			segments = append(segments, s)
+		}
+	}
+	normalized := "/" + strings.Join(segments, "/")
+	if len(segments) > 0 && strings.HasSuffix(path, "/") {
+		normalized += "/"
+	}
+	return normalized
+}
+
+// canonicalQuery returns the encoded query parameters sorted by the names and the values.
+func canonicalQuery(rawQuery string) string {
+	// the invalid parameters are ignored as url.URL.Query does
+	q, _ := url.ParseQuery(rawQuery)
+	type param struct{ k, v string }
+	params := []param{}
+	for k, vs := range q {
+		for _, v := range vs {
+			params = append(params, param{uriEncode(k, true), uriEncode(v, true)})
+		}
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].k != params[j].k {
+			return params[i].k < params[j].k
+		}
+		return params[i].v < params[j].v
+	})
+	encoded := make([]string, len(params))
+	for i, p := range params {
+		encoded[i] = p.k + "=" + p.v
+	}
+	return strings.Join(encoded, "&")
+}
+
+// uriEncode encodes the characters except for the unreserved characters of RFC 3986.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hashHex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
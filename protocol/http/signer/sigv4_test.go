@@ -0,0 +1,283 @@
+package signer
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSigV4Config is the credential of the AWS Signature Version 4 test suite.
+var testSigV4Config = map[string]string{
+	"accessKeyID":     "AKIDEXAMPLE",
+	"secretAccessKey": "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	"region":          "us-east-1",
+	"service":         "service",
+}
+
+// TestSigV4_TestSuite tests the signer by the test vectors of the AWS Signature Version 4 test suite.
+// The vectors whose paths have the characters to be escaped are excluded because the paths of the requests sent by net/http are always escaped.
+func TestSigV4_TestSuite(t *testing.T) {
+	const header = "Host:example.amazonaws.com\r\nX-Amz-Date:20150830T123600Z\r\n"
+	tests := map[string]struct {
+		request       string
+		signedHeaders string
+		signature     string
+	}{
+		"get-vanilla": {
+			request:       "GET / HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		"get-vanilla-empty-query-key": {
+			request:       "GET /?Param1=value1 HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "a67d582fa61cc504c4bae71f336f98b97f1ea3c7a6bfe1b6e45aec72011b9aeb",
+		},
+		"get-vanilla-query-order-key": {
+			request:       "GET /?Param1=value2&Param1=Value1 HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "eedbc4e291e521cf13422ffca22be7d2eb8146eecf653089df300a15b2382bd1",
+		},
+		"get-vanilla-query-order-key-case": {
+			request:       "GET /?Param2=value2&Param1=value1 HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		"get-vanilla-query-unreserved": {
+			request:       "GET /?-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz=-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "9c3e54bfcdf0b19771a7f523ee5669cdf59bc7cc0884027167c21bb143a40197",
+		},
+		"get-vanilla-utf8-query": {
+			request:       "GET /?ሴ=bar HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "2cdec8eed098649ff3a119c94853b13c643bcf08f8b0a1d91e12c9027818dd04",
+		},
+		"get-unreserved": {
+			request:       "GET /-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "07ef7494c76fa4850883e2b006601f940f8a34d404d0cfa977f52a65bbf5f24f",
+		},
+		"normalize-path/get-slash": {
+			request:       "GET // HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		"normalize-path/get-slashes": {
+			request:       "GET //example// HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "9a624bd73a37c9a373b5312afbebe7a714a789de108f0bdfe846570885f57e84",
+		},
+		"normalize-path/get-relative": {
+			request:       "GET /example/.. HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		"normalize-path/get-relative-relative": {
+			request:       "GET /example1/example2/../.. HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		"normalize-path/get-slash-dot-slash": {
+			request:       "GET /./ HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		"normalize-path/get-slash-pointless-dot": {
+			request:       "GET /./example HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "ef75d96142cf21edca26f06005da7988e4f8dc83a165a80865db7089db637ec5",
+		},
+		"get-header-key-duplicate": {
+			request:       "GET / HTTP/1.1\r\n" + header + "My-Header1:value2\r\nMy-Header1:value2\r\nMy-Header1:value1\r\n",
+			signedHeaders: "host;my-header1;x-amz-date",
+			signature:     "c9d5ea9f3f72853aea855b47ea873832890dbdd183b4468f858259531a5138ea",
+		},
+		"get-header-value-order": {
+			request:       "GET / HTTP/1.1\r\n" + header + "My-Header1:value4\r\nMy-Header1:value1\r\nMy-Header1:value3\r\nMy-Header1:value2\r\n",
+			signedHeaders: "host;my-header1;x-amz-date",
+			signature:     "08c7e5a9acfcfeb3ab6b2185e75ce8b1deb5e634ec47601a50643f830c755c01",
+		},
+		"get-header-value-trim": {
+			request:       "GET / HTTP/1.1\r\n" + header + "My-Header1: value1\r\nMy-Header2: \"a   b   c\"\r\n",
+			signedHeaders: "host;my-header1;my-header2;x-amz-date",
+			signature:     "acc3ed3afb60bb290fc8d2dd0098b9911fcaa05412b367055dee359757a9c736",
+		},
+		"post-vanilla": {
+			request:       "POST / HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		"post-vanilla-query": {
+			request:       "POST /?Param1=value1 HTTP/1.1\r\n" + header,
+			signedHeaders: "host;x-amz-date",
+			signature:     "28038455d6de14eafc1f9222cf5aa6f1a96197d7deb8263271d420d138af7f11",
+		},
+		"post-header-key-case": {
+			request:       "POST / HTTP/1.1\r\nhost:example.amazonaws.com\r\nx-amz-date:20150830T123600Z\r\n",
+			signedHeaders: "host;x-amz-date",
+			signature:     "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		"post-header-key-sort": {
+			request:       "POST / HTTP/1.1\r\n" + header + "My-Header1:value1\r\n",
+			signedHeaders: "host;my-header1;x-amz-date",
+			signature:     "c5410059b04c1ee005303aed430f6e6645f61f4dc9e1461ec8f8916fdf18852c",
+		},
+		"post-header-value-case": {
+			request:       "POST / HTTP/1.1\r\n" + header + "My-Header1:VALUE1\r\n",
+			signedHeaders: "host;my-header1;x-amz-date",
+			signature:     "cdbc9802e29d2942e5e10b5bccfdd67c5f22c7c4e8ae67b53629efa58b974b7d",
+		},
+		"post-x-www-form-urlencoded": {
+			request:       "POST / HTTP/1.1\r\nContent-Type:application/x-www-form-urlencoded\r\n" + header + "\r\nParam1=value1",
+			signedHeaders: "content-type;host;x-amz-date",
+			signature:     "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+		"post-x-www-form-urlencoded-parameters": {
+			request:       "POST / HTTP/1.1\r\nContent-Type:application/x-www-form-urlencoded; charset=utf8\r\n" + header + "\r\nParam1=value1",
+			signedHeaders: "content-type;host;x-amz-date",
+			signature:     "1a72ec8f64bd914b0e42e42607c7fbce7fb2c7465f63e3092b3b0d39fa77a6fe",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			raw, body, _ := strings.Cut(test.request, "\r\n\r\n")
+			req, err := http.ReadRequest(bufio.NewReader(strings.NewReader(raw + "\r\n\r\n")))
+			if err != nil {
+				t.Fatalf("failed to read request: %s", err)
+			}
+			if err := (&SigV4{}).Sign(req, []byte(body), testSigV4Config); err != nil {
+				t.Fatalf("failed to sign: %s", err)
+			}
+			expect := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=" + test.signedHeaders + ", Signature=" + test.signature
+			if got := req.Header.Get("Authorization"); got != expect {
+				t.Errorf("expected %q but got %q", expect, got)
+			}
+		})
+	}
+}
+
+func TestSigV4_CanonicalRequest(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Amz-Date", "20150830T123600Z")
+	req.Header.Set("User-Agent", "scenarigo")
+	creq, signedHeaders := sigV4CanonicalRequest(req, hashHex(nil), true)
+	expect := "GET\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	if creq != expect {
+		t.Errorf("expected %q but got %q", expect, creq)
+	}
+	if got := hashHex([]byte(creq)); got != "bb579772317eb040ac9ed261061d46c1f17a8133879d6129b6e1c25292927e63" {
+		t.Errorf("unexpected hash of the canonical request: %s", got)
+	}
+	if signedHeaders != "host;x-amz-date" {
+		t.Errorf("unexpected signed headers: %s", signedHeaders)
+	}
+}
+
+func TestSigV4_Sign(t *testing.T) {
+	now := func() time.Time {
+		return time.Date(2015, 8, 30, 21, 36, 0, 0, time.FixedZone("JST", 9*60*60))
+	}
+	t.Run("signing time", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := (&SigV4{now: now}).Sign(req, nil, testSigV4Config); err != nil {
+			t.Fatalf("failed to sign: %s", err)
+		}
+		if got, expect := req.Header.Get("X-Amz-Date"), "20150830T123600Z"; got != expect {
+			t.Errorf("expected %q but got %q", expect, got)
+		}
+		if got, expect := req.Header.Get("Authorization"), "Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"; !strings.HasSuffix(got, expect) {
+			t.Errorf("expected %q to end with %q", got, expect)
+		}
+	})
+	t.Run("session token", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config := map[string]string{"sessionToken": "TOKEN"}
+		for k, v := range testSigV4Config {
+			config[k] = v
+		}
+		if err := (&SigV4{now: now}).Sign(req, nil, config); err != nil {
+			t.Fatalf("failed to sign: %s", err)
+		}
+		if got, expect := req.Header.Get("X-Amz-Security-Token"), "TOKEN"; got != expect {
+			t.Errorf("expected %q but got %q", expect, got)
+		}
+		if got, expect := req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-date;x-amz-security-token,"; !strings.Contains(got, expect) {
+			t.Errorf("expected %q to contain %q", got, expect)
+		}
+	})
+	t.Run("s3", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPut, "https://examplebucket.s3.amazonaws.com/a//b.txt", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Amz-Date", "20150830T123600Z")
+		config := map[string]string{}
+		for k, v := range testSigV4Config {
+			config[k] = v
+		}
+		config["service"] = "s3"
+		if err := (&SigV4{}).Sign(req, []byte("test"), config); err != nil {
+			t.Fatalf("failed to sign: %s", err)
+		}
+		if got, expect := req.Header.Get("X-Amz-Content-Sha256"), "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"; got != expect {
+			t.Errorf("expected %q but got %q", expect, got)
+		}
+		creq, _ := sigV4CanonicalRequest(req, hashHex([]byte("test")), false)
+		if path := strings.Split(creq, "\n")[1]; path != "/a//b.txt" {
+			t.Errorf("the path of S3 must not be normalized: %s", path)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		tests := map[string]struct {
+			config map[string]string
+			date   string
+			expect string
+		}{
+			"no access key ID": {
+				config: map[string]string{"secretAccessKey": "secret", "region": "us-east-1", "service": "service"},
+				expect: "sigv4: accessKeyID is required",
+			},
+			"no service": {
+				config: map[string]string{"accessKeyID": "AKIDEXAMPLE", "secretAccessKey": "secret", "region": "us-east-1"},
+				expect: "sigv4: service is required",
+			},
+			"invalid date": {
+				config: testSigV4Config,
+				date:   "2015-08-30",
+				expect: `sigv4: invalid X-Amz-Date header "2015-08-30"`,
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if test.date != "" {
+					req.Header.Set("X-Amz-Date", test.date)
+				}
+				err = (&SigV4{}).Sign(req, nil, test.config)
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if got := err.Error(); got != test.expect {
+					t.Errorf("expected %q but got %q", test.expect, got)
+				}
+			})
+		}
+	})
+}
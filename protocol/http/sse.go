@@ -0,0 +1,219 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+const (
+	mediaTypeEventStream = "text/event-stream"
+	defaultSSETimeout    = 10 * time.Second
+)
+
+// SSEConfig represents the configuration to consume the response as Server-Sent Events.
+//
+//	sse:
+//	  events: 3        # stop after receiving 3 events
+//	  until:           # stop when an event satisfies the assertion
+//	    event: done
+//	  timeout: 10s     # default value is 10s
+//
+// If neither events nor until is specified, the events are consumed until the stream is closed or the timeout elapses.
+type SSEConfig struct {
+	Events  int         `yaml:"events,omitempty"`
+	Until   interface{} `yaml:"until,omitempty"`
+	Timeout string      `yaml:"timeout,omitempty"`
+}
+
+// sseEvent represents a dispatched event of Server-Sent Events.
+type sseEvent struct {
+	ID    string `yaml:"id,omitempty"`
+	Event string `yaml:"event"`
+	Data  string `yaml:"data"`
+	Retry int    `yaml:"retry,omitempty"`
+}
+
+// isEventStream reports whether the Content-Type header value is text/event-stream.
+func isEventStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(strings.Trim(contentType, " "))
+	if err != nil {
+		return false
+	}
+	return mediaType == mediaTypeEventStream
+}
+
+// consume reads the events from body until the stop condition is satisfied.
+// The body is closed when it returns.
+func (c *SSEConfig) consume(ctx *context.Context, body io.ReadCloser) ([]*sseEvent, error) {
+	defer body.Close()
+
+	timeout := defaultSSETimeout
+	if c.Timeout != "" {
+		d, err := time.ParseDuration(c.Timeout)
+		if err != nil {
+			return nil, errors.WrapPath(err, "timeout", "invalid timeout")
+		}
+		timeout = d
+	}
+	var until assert.Assertion
+	if c.Until != nil {
+		var err error
+		until, err = assert.Build(ctx.RequestContext(), c.Until, assert.FromTemplate(ctx))
+		if err != nil {
+			return nil, errors.WrapPathf(err, "until", "invalid until")
+		}
+	}
+
+	ch := make(chan *sseEvent)
+	errCh := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		errCh <- parseEventStream(body, func(ev *sseEvent) bool {
+			select {
+			case ch <- ev:
+				return true
+			case <-done:
+				return false
+			}
+		})
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	events := []*sseEvent{}
+	for {
+		select {
+		case ev := <-ch:
+			events = append(events, ev)
+			if until != nil && until.Assert(ev) == nil {
+				return events, nil
+			}
+			if c.Events > 0 && len(events) >= c.Events {
+				return events, nil
+			}
+		case err := <-errCh:
+			if err != nil {
+				return events, errors.Errorf("failed to read event stream: %s", err)
+			}
+			return events, waitError(c, until, len(events), "the stream is closed")
+		case <-timer.C:
+			return events, waitError(c, until, len(events), "timeout "+timeout.String()+" exceeded")
+		case <-ctx.RequestContext().Done():
+			return events, waitError(c, until, len(events), ctx.RequestContext().Err().Error())
+		}
+	}
+}
+
+// waitError returns the error if the stop condition isn't satisfied.
+func waitError(c *SSEConfig, until assert.Assertion, n int, reason string) error {
+	if until != nil {
+		return errors.Errorf("no event satisfied until: %s after receiving %d events", reason, n)
+	}
+	if c.Events > 0 {
+		return errors.Errorf("received %d events but expected %d: %s", n, c.Events, reason)
+	}
+	return nil
+}
+
+// parseEventStream parses r as an event stream and calls dispatch for each event.
+// It stops when dispatch returns false or r reaches EOF.
+// See https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation.
+func parseEventStream(r io.Reader, dispatch func(*sseEvent) bool) error {
+	s := bufio.NewScanner(r)
+	s.Split(scanEventStreamLines)
+	var (
+		lastEventID string
+		eventType   string
+		data        strings.Builder
+		retry       int
+	)
+	first := true
+	for s.Scan() {
+		line := s.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+		if line == "" {
+			if data.Len() > 0 {
+				if eventType == "" {
+					eventType = "message"
+				}
+				ev := &sseEvent{
+					ID:    lastEventID,
+					Event: eventType,
+					Data:  strings.TrimSuffix(data.String(), "\n"),
+					Retry: retry,
+				}
+				if !dispatch(ev) {
+					return nil
+				}
+			}
+			eventType = ""
+			data.Reset()
+			retry = 0
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment
+		}
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				lastEventID = value
+			}
+		case "retry":
+			if n, err := strconv.Atoi(value); err == nil && n >= 0 && strings.Trim(value, "0123456789") == "" {
+				retry = n
+			}
+		}
+	}
+	return s.Err()
+}
+
+// scanEventStreamLines is a split function for bufio.Scanner which splits lines by CRLF, LF, or CR.
+func scanEventStreamLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// CR
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// request more data to check whether CR is followed by LF
+		return 0, nil, nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
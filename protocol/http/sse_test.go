@@ -0,0 +1,224 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+func TestParseEventStream(t *testing.T) {
+	tests := map[string]struct {
+		stream string
+		expect []*sseEvent
+	}{
+		"simple": {
+			stream: "data: hello\n\n",
+			expect: []*sseEvent{
+				{Event: "message", Data: "hello"},
+			},
+		},
+		"fields": {
+			stream: "id: 1\nevent: update\nretry: 3000\ndata: {\"status\":\"ok\"}\n\n",
+			expect: []*sseEvent{
+				{ID: "1", Event: "update", Data: `{"status":"ok"}`, Retry: 3000},
+			},
+		},
+		"multi-line data": {
+			stream: "data: first\ndata:second\ndata\n\n",
+			expect: []*sseEvent{
+				{Event: "message", Data: "first\nsecond\n"},
+			},
+		},
+		"comments": {
+			stream: ": keep-alive\n\n:comment\ndata: hello\n\n",
+			expect: []*sseEvent{
+				{Event: "message", Data: "hello"},
+			},
+		},
+		"last event id": {
+			stream: "id: 1\ndata: a\n\ndata: b\n\nid\ndata: c\n\n",
+			expect: []*sseEvent{
+				{ID: "1", Event: "message", Data: "a"},
+				{ID: "1", Event: "message", Data: "b"},
+				{Event: "message", Data: "c"},
+			},
+		},
+		"CRLF and CR": {
+			stream: "event: a\r\ndata: 1\r\n\r\nevent: b\rdata: 2\r\r",
+			expect: []*sseEvent{
+				{Event: "a", Data: "1"},
+				{Event: "b", Data: "2"},
+			},
+		},
+		"no data": {
+			stream: "event: ping\n\n",
+			expect: []*sseEvent{},
+		},
+		"incomplete event": {
+			stream: "data: a\n\ndata: b",
+			expect: []*sseEvent{
+				{Event: "message", Data: "a"},
+			},
+		},
+		"invalid retry": {
+			stream: "retry: 1s\ndata: a\n\n",
+			expect: []*sseEvent{
+				{Event: "message", Data: "a"},
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			got := []*sseEvent{}
+			if err := parseEventStream(strings.NewReader(test.stream), func(ev *sseEvent) bool {
+				got = append(got, ev)
+				return true
+			}); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(test.expect, got); diff != "" {
+				t.Errorf("events differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRequest_Invoke_SSE(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("type") == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"message":"not a stream"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		f := w.(http.Flusher)
+		fmt.Fprint(w, ": connected\n\n")
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(w, "id: %d\nevent: progress\ndata: %d\n\n", i, i)
+			f.Flush()
+		}
+		fmt.Fprint(w, "event: done\ndata: finished\n\n")
+		f.Flush()
+		if req.URL.Query().Get("close") == "true" {
+			return
+		}
+		// keep the stream open
+		<-req.Context().Done()
+	}))
+	defer srv.Close()
+
+	t.Run("success", func(t *testing.T) {
+		tests := map[string]struct {
+			url    string
+			sse    *SSEConfig
+			expect interface{}
+		}{
+			"first N events": {
+				url: srv.URL,
+				sse: &SSEConfig{Events: 2},
+				expect: []*sseEvent{
+					{ID: "1", Event: "progress", Data: "1"},
+					{ID: "2", Event: "progress", Data: "2"},
+				},
+			},
+			"until": {
+				url: srv.URL,
+				sse: &SSEConfig{
+					Until: yaml.MapSlice{{Key: "event", Value: "done"}},
+				},
+				expect: []*sseEvent{
+					{ID: "1", Event: "progress", Data: "1"},
+					{ID: "2", Event: "progress", Data: "2"},
+					{ID: "3", Event: "progress", Data: "3"},
+					{ID: "3", Event: "done", Data: "finished"},
+				},
+			},
+			"until the stream is closed": {
+				url: srv.URL + "?close=true",
+				sse: &SSEConfig{},
+				expect: []*sseEvent{
+					{ID: "1", Event: "progress", Data: "1"},
+					{ID: "2", Event: "progress", Data: "2"},
+					{ID: "3", Event: "progress", Data: "3"},
+					{ID: "3", Event: "done", Data: "finished"},
+				},
+			},
+			"not an event stream": {
+				url: srv.URL + "?type=json",
+				sse: &SSEConfig{Events: 1},
+				expect: map[string]interface{}{
+					"message": "not a stream",
+				},
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				req := &Request{
+					URL: test.url,
+					SSE: test.sse,
+				}
+				_, resp, err := req.Invoke(context.FromT(t))
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				res, ok := resp.(response)
+				if !ok {
+					t.Fatalf("expected response but got %T", resp)
+				}
+				if diff := cmp.Diff(test.expect, res.Body); diff != "" {
+					t.Errorf("body differs (-want +got):\n%s", diff)
+				}
+			})
+		}
+	})
+	t.Run("failure", func(t *testing.T) {
+		tests := map[string]struct {
+			url         string
+			sse         *SSEConfig
+			expectError string
+		}{
+			"invalid timeout": {
+				url:         srv.URL,
+				sse:         &SSEConfig{Timeout: "1"},
+				expectError: ".sse.timeout: invalid timeout",
+			},
+			"timeout": {
+				url:         srv.URL,
+				sse:         &SSEConfig{Events: 10, Timeout: "100ms"},
+				expectError: "received 4 events but expected 10: timeout 100ms exceeded",
+			},
+			"no event satisfied until": {
+				url: srv.URL + "?close=true",
+				sse: &SSEConfig{
+					Until: yaml.MapSlice{{Key: "event", Value: "error"}},
+				},
+				expectError: "no event satisfied until: the stream is closed after receiving 4 events",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				req := &Request{
+					URL: test.url,
+					SSE: test.sse,
+				}
+				_, _, err := req.Invoke(context.FromT(t))
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("expected error %q but got %q", test.expectError, err)
+				}
+			})
+		}
+	})
+}
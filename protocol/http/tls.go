@@ -0,0 +1,99 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// TLSConfig represents a TLS configuration of HTTP requests.
+// Each certificate and key is specified by a file path or an inline PEM string.
+type TLSConfig struct {
+	CACert             string `yaml:"caCert,omitempty"`
+	Cert               string `yaml:"cert,omitempty"`
+	Key                string `yaml:"key,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify,omitempty"`
+
+	// loaded configuration by prepare
+	config *tls.Config
+}
+
+// Build loads the certificates and returns the configuration as *tls.Config.
+// The relative file paths are resolved from baseDir.
+func (c *TLSConfig) Build(ctx *context.Context, baseDir string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify, //nolint:gosec
+	}
+	if c.CACert != "" {
+		b, err := loadPEM(ctx, c.CACert, baseDir)
+		if err != nil {
+			return nil, errors.WrapPath(err, "caCert", "failed to load CA certificate")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, errors.ErrorPath("caCert", "failed to load CA certificate: no valid certificates")
+		}
+		cfg.RootCAs = pool
+	}
+	if c.Cert != "" || c.Key != "" {
+		if c.Cert == "" {
+			return nil, errors.ErrorPath("cert", "cert is required to use the client certificate")
+		}
+		if c.Key == "" {
+			return nil, errors.ErrorPath("key", "key is required to use the client certificate")
+		}
+		cert, err := loadPEM(ctx, c.Cert, baseDir)
+		if err != nil {
+			return nil, errors.WrapPath(err, "cert", "failed to load client certificate")
+		}
+		key, err := loadPEM(ctx, c.Key, baseDir)
+		if err != nil {
+			return nil, errors.WrapPath(err, "key", "failed to load client key")
+		}
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, errors.ErrorPathf("cert", "failed to load client certificate: %s", err)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+	return cfg, nil
+}
+
+// prepare loads the certificates and keeps the configuration to use it for the request.
+func (c *TLSConfig) prepare(ctx *context.Context, baseDir string) error {
+	cfg, err := c.Build(ctx, baseDir)
+	if err != nil {
+		return err
+	}
+	c.config = cfg
+	return nil
+}
+
+// loadPEM returns s as PEM if s is the PEM string. Otherwise, it reads the file at s.
+func loadPEM(ctx *context.Context, s, baseDir string) ([]byte, error) {
+	x, err := ctx.ExecuteTemplate(s)
+	if err != nil {
+		return nil, err
+	}
+	str, ok := x.(string)
+	if !ok {
+		return nil, errors.Errorf("expected string but got %T", x)
+	}
+	if strings.Contains(str, "-----BEGIN") {
+		return []byte(str), nil
+	}
+	path := str
+	if !filepath.IsAbs(path) && baseDir != "" {
+		path = filepath.Join(baseDir, path)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Errorf("failed to read file: %s", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,251 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+func TestTLSConfig_Build(t *testing.T) {
+	certPEM, keyPEM := generateCertificate(t, "client")
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "client.crt"), certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "client.key"), keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		tests := map[string]struct {
+			vars           interface{}
+			config         *TLSConfig
+			expectCA       bool
+			expectCert     bool
+			expectInsecure bool
+		}{
+			"empty": {
+				config: &TLSConfig{},
+			},
+			"insecureSkipVerify": {
+				config:         &TLSConfig{InsecureSkipVerify: true},
+				expectInsecure: true,
+			},
+			"inline PEM": {
+				config: &TLSConfig{
+					CACert: string(certPEM),
+					Cert:   string(certPEM),
+					Key:    string(keyPEM),
+				},
+				expectCA:   true,
+				expectCert: true,
+			},
+			"file path": {
+				config: &TLSConfig{
+					CACert: "client.crt",
+					Cert:   "client.crt",
+					Key:    filepath.Join(dir, "client.key"),
+				},
+				expectCA:   true,
+				expectCert: true,
+			},
+			"template": {
+				vars: map[string]string{
+					"cert": string(certPEM),
+					"key":  "client.key",
+				},
+				config: &TLSConfig{
+					Cert: "{{vars.cert}}",
+					Key:  "{{vars.key}}",
+				},
+				expectCert: true,
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				ctx := context.FromT(t)
+				if test.vars != nil {
+					ctx = ctx.WithVars(test.vars)
+				}
+				cfg, err := test.config.Build(ctx, dir)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if got := cfg.RootCAs != nil; got != test.expectCA {
+					t.Errorf("expect %t but got %t", test.expectCA, got)
+				}
+				if got := len(cfg.Certificates) == 1; got != test.expectCert {
+					t.Errorf("expect %t but got %t", test.expectCert, got)
+				}
+				if got := cfg.InsecureSkipVerify; got != test.expectInsecure {
+					t.Errorf("expect %t but got %t", test.expectInsecure, got)
+				}
+			})
+		}
+	})
+	t.Run("failure", func(t *testing.T) {
+		tests := map[string]struct {
+			config *TLSConfig
+			expect string
+		}{
+			"invalid CA": {
+				config: &TLSConfig{CACert: "-----BEGIN CERTIFICATE-----\ninvalid\n-----END CERTIFICATE-----"},
+				expect: ".caCert: failed to load CA certificate: no valid certificates",
+			},
+			"file not found": {
+				config: &TLSConfig{CACert: "not-found.crt"},
+				expect: ".caCert: failed to load CA certificate: failed to read file",
+			},
+			"key is required": {
+				config: &TLSConfig{Cert: string(certPEM)},
+				expect: ".key: key is required to use the client certificate",
+			},
+			"cert is required": {
+				config: &TLSConfig{Key: string(keyPEM)},
+				expect: ".cert: cert is required to use the client certificate",
+			},
+			"invalid key pair": {
+				config: &TLSConfig{Cert: string(certPEM), Key: string(certPEM)},
+				expect: ".cert: failed to load client certificate",
+			},
+			"invalid template": {
+				config: &TLSConfig{CACert: "{{vars.ca}}"},
+				expect: `.caCert: failed to load CA certificate: failed to execute: {{vars.ca}}: ".vars.ca" not found`,
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				_, err := test.config.Build(context.FromT(t), dir)
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if got := err.Error(); !strings.Contains(got, test.expect) {
+					t.Errorf("%q doesn't contain %q", got, test.expect)
+				}
+			})
+		}
+	})
+}
+
+func TestRequest_Invoke_TLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"cn":%q}`, req.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	srv.TLS = &tls.Config{
+		ClientAuth: tls.RequireAnyClientCert,
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	certPEM, keyPEM := generateCertificate(t, "scenarigo")
+	clientTLS := &TLSConfig{
+		CACert: string(caPEM),
+		Cert:   string(certPEM),
+		Key:    string(keyPEM),
+	}
+	defaultTLS, err := clientTLS.Build(context.FromT(t), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		config      *Config
+		tls         *TLSConfig
+		expectError string
+	}{
+		"request config": {
+			tls: clientTLS,
+		},
+		"default config": {
+			config: &Config{TLS: defaultTLS},
+		},
+		"request overrides default config": {
+			config:      &Config{TLS: defaultTLS},
+			tls:         &TLSConfig{CACert: string(caPEM)},
+			expectError: "failed to send request",
+		},
+		"unknown authority": {
+			expectError: "certificate signed by unknown authority",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctx := WithConfig(context.FromT(t), test.config)
+			req := &Request{
+				URL: srv.URL,
+				TLS: test.tls,
+			}
+			if err := req.Prepare(ctx); err != nil {
+				t.Fatalf("failed to prepare: %s", err)
+			}
+			_, res, err := req.Invoke(ctx)
+			if test.expectError != "" {
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if got := err.Error(); !strings.Contains(got, test.expectError) {
+					t.Errorf("%q doesn't contain %q", got, test.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to invoke: %s", err)
+			}
+			resp, ok := res.(response)
+			if !ok {
+				t.Fatalf("failed to convert from %T to response", res)
+			}
+			if got, expect := resp.Body, map[string]interface{}{"cn": "scenarigo"}; fmt.Sprint(got) != fmt.Sprint(expect) {
+				t.Errorf("expect %v but got %v", expect, got)
+			}
+		})
+	}
+}
+
+func generateCertificate(t *testing.T, cn string) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: b})
+}
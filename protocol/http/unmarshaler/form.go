@@ -0,0 +1,55 @@
+package unmarshaler
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+)
+
+func init() {
+	if err := Register(&formURLEncodedUnmarshaler{}); err != nil {
+		panic(err)
+	}
+}
+
+// formURLEncodedUnmarshaler unmarshals the form into a map.
+// The value of a key is a string, or a list of strings if the key has multiple values.
+type formURLEncodedUnmarshaler struct{}
+
+// MediaType implements ResponseUnmarshaler interface.
+func (um *formURLEncodedUnmarshaler) MediaType() string {
+	return "application/x-www-form-urlencoded"
+}
+
+// Unmarshal implements ResponseUnmarshaler interface.
+func (um *formURLEncodedUnmarshaler) Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return errors.New("v must be a pointer")
+	}
+	if rv.IsNil() {
+		return errors.New("v is nil")
+	}
+	rv = rv.Elem()
+	if !rv.CanSet() {
+		return errors.New("v is not settable")
+	}
+	form, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	m := make(map[string]interface{}, len(form))
+	for k, vs := range form {
+		if len(vs) == 1 {
+			m[k] = vs[0]
+			continue
+		}
+		values := make([]interface{}, len(vs))
+		for i, v := range vs {
+			values[i] = v
+		}
+		m[k] = values
+	}
+	rv.Set(reflect.ValueOf(m))
+	return nil
+}
@@ -0,0 +1,23 @@
+package unmarshaler
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormURLEncodedUnmarshaler_Unmarshal(t *testing.T) {
+	var um formURLEncodedUnmarshaler
+	var got interface{}
+	if err := um.Unmarshal([]byte("name=alice&role=admin&role=editor&message=hello+world"), &got); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]interface{}{
+		"name":    "alice",
+		"role":    []interface{}{"admin", "editor"},
+		"message": "hello world",
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+}
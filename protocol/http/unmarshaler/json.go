@@ -9,6 +9,9 @@ func init() {
 	if err := Register(&jsonUnmarshaler{}); err != nil {
 		panic(err)
 	}
+	if err := Register(&graphQLResponseUnmarshaler{}); err != nil {
+		panic(err)
+	}
 }
 
 type jsonUnmarshaler struct{}
@@ -24,3 +27,13 @@ func (um *jsonUnmarshaler) Unmarshal(data []byte, v interface{}) error {
 	d.UseNumber()
 	return d.Decode(v)
 }
+
+// graphQLResponseUnmarshaler unmarshals the response of GraphQL over HTTP as JSON.
+type graphQLResponseUnmarshaler struct {
+	jsonUnmarshaler
+}
+
+// MediaType implements ResponseUnmarshaler interface.
+func (um *graphQLResponseUnmarshaler) MediaType() string {
+	return "application/graphql-response+json"
+}
@@ -28,9 +28,15 @@ func Register(um ResponseUnmarshaler) error {
 	return nil
 }
 
+// structuredSyntaxSuffixes are the media types used for the media types which have the structured syntax suffixes such as application/problem+json.
+var structuredSyntaxSuffixes = map[string]string{
+	"+json": "application/json",
+	"+xml":  "application/xml",
+}
+
 // Get returns the response unmarshaler for the given media type.
 //
-// If the unmarshaler is not found, returns the Default.
+// If the unmarshaler is not found, it returns the unmarshaler of the structured syntax suffix such as +json, or the Default.
 func Get(mediaType string) ResponseUnmarshaler {
 	resm.Lock()
 	defer resm.Unlock()
@@ -39,10 +45,15 @@ func Get(mediaType string) ResponseUnmarshaler {
 		return Default
 	}
 	um, ok := resRegistry[mt]
-	if !ok {
-		return Default
+	if ok {
+		return um
+	}
+	if i := strings.LastIndex(mt, "+"); i >= 0 {
+		if um, ok := resRegistry[structuredSyntaxSuffixes[mt[i:]]]; ok {
+			return um
+		}
 	}
-	return um
+	return Default
 }
 
 // ResponseUnmarshaler is the interface that unmarshals the HTTP response body.
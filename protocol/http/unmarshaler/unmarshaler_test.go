@@ -10,4 +10,15 @@ func TestGet(t *testing.T) {
 			t.Errorf("expected *binaryUnmarshaler but got %T", um)
 		}
 	})
+	t.Run("structured syntax suffix", func(t *testing.T) {
+		if um := Get("application/problem+json"); um.MediaType() != "application/json" {
+			t.Errorf("expected application/json but got %s", um.MediaType())
+		}
+		if um := Get("application/atom+xml; charset=utf-8"); um.MediaType() != "application/xml" {
+			t.Errorf("expected application/xml but got %s", um.MediaType())
+		}
+		if um := Get("application/graphql-response+json"); um.MediaType() != "application/graphql-response+json" {
+			t.Errorf("expected application/graphql-response+json but got %s", um.MediaType())
+		}
+	})
 }
@@ -0,0 +1,129 @@
+package unmarshaler
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+)
+
+func init() {
+	if err := Register(&xmlUnmarshaler{}); err != nil {
+		panic(err)
+	}
+	if err := Register(&textXMLUnmarshaler{}); err != nil {
+		panic(err)
+	}
+}
+
+const (
+	xmlAttrPrefix = "@"
+	xmlTextKey    = "#text"
+)
+
+// xmlUnmarshaler unmarshals XML into maps to query the elements by the paths.
+//
+//   - An element is a map from its name to the value, e.g. <a>1</a> is {"a": "1"}.
+//   - An element which has only text is the string of the text.
+//   - The attributes are prefixed by "@", e.g. <a id="1">x</a> is {"a": {"@id": "1", "#text": "x"}}.
+//   - The text of an element which has attributes or child elements is "#text", and it is omitted if it is whitespace.
+//   - The elements with the same name are a list, e.g. <a><b>1</b><b>2</b></a> is {"a": {"b": ["1", "2"]}}.
+//   - The names don't have the namespace prefixes.
+type xmlUnmarshaler struct{}
+
+// MediaType implements ResponseUnmarshaler interface.
+func (um *xmlUnmarshaler) MediaType() string {
+	return "application/xml"
+}
+
+// Unmarshal implements ResponseUnmarshaler interface.
+func (um *xmlUnmarshaler) Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return errors.New("v must be a pointer")
+	}
+	if rv.IsNil() {
+		return errors.New("v is nil")
+	}
+	rv = rv.Elem()
+	if !rv.CanSet() {
+		return errors.New("v is not settable")
+	}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return errors.New("no root element")
+			}
+			return err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			x, err := decodeXMLElement(d, start)
+			if err != nil {
+				return err
+			}
+			rv.Set(reflect.ValueOf(map[string]interface{}{start.Name.Local: x}))
+			return nil
+		}
+	}
+}
+
+func decodeXMLElement(d *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	m := map[string]interface{}{}
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue // namespace declarations
+		}
+		m[xmlAttrPrefix+attr.Name.Local] = attr.Value
+	}
+	var (
+		text     strings.Builder
+		children bool
+	)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			children = true
+			x, err := decodeXMLElement(d, tok)
+			if err != nil {
+				return nil, err
+			}
+			name := tok.Name.Local
+			switch v := m[name].(type) {
+			case nil:
+				m[name] = x
+			case []interface{}:
+				m[name] = append(v, x)
+			default:
+				m[name] = []interface{}{v, x}
+			}
+		case xml.CharData:
+			text.Write(tok)
+		case xml.EndElement:
+			if len(m) == 0 && !children {
+				return text.String(), nil
+			}
+			if s := text.String(); strings.TrimSpace(s) != "" {
+				m[xmlTextKey] = s
+			}
+			return m, nil
+		}
+	}
+}
+
+// textXMLUnmarshaler unmarshals text/xml as application/xml.
+type textXMLUnmarshaler struct {
+	xmlUnmarshaler
+}
+
+// MediaType implements ResponseUnmarshaler interface.
+func (um *textXMLUnmarshaler) MediaType() string {
+	return "text/xml"
+}
@@ -0,0 +1,106 @@
+package unmarshaler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestXMLUnmarshaler_Unmarshal(t *testing.T) {
+	tests := map[string]struct {
+		data   string
+		expect interface{}
+	}{
+		"text": {
+			data:   `<message>hello</message>`,
+			expect: map[string]interface{}{"message": "hello"},
+		},
+		"empty": {
+			data:   `<message/>`,
+			expect: map[string]interface{}{"message": ""},
+		},
+		"attributes": {
+			data: `<message id="1" lang="en">hello</message>`,
+			expect: map[string]interface{}{
+				"message": map[string]interface{}{
+					"@id":   "1",
+					"@lang": "en",
+					"#text": "hello",
+				},
+			},
+		},
+		"children": {
+			data: `<?xml version="1.0" encoding="UTF-8"?>
+<user xmlns="http://example.com/user" xmlns:ex="http://example.com/ex">
+  <name>alice</name>
+  <ex:email>alice@example.com</ex:email>
+  <roles>
+    <role>admin</role>
+    <role>editor</role>
+  </roles>
+</user>`,
+			expect: map[string]interface{}{
+				"user": map[string]interface{}{
+					"name":  "alice",
+					"email": "alice@example.com",
+					"roles": map[string]interface{}{
+						"role": []interface{}{"admin", "editor"},
+					},
+				},
+			},
+		},
+		"mixed content": {
+			data: `<p>hello <b>world</b></p>`,
+			expect: map[string]interface{}{
+				"p": map[string]interface{}{
+					"#text": "hello ",
+					"b":     "world",
+				},
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			var um xmlUnmarshaler
+			var got interface{}
+			if err := um.Unmarshal([]byte(test.data), &got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.expect, got); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestXMLUnmarshaler_Unmarshal_Error(t *testing.T) {
+	tests := map[string]struct {
+		data   string
+		expect string
+	}{
+		"no root element": {
+			data:   `<?xml version="1.0"?>`,
+			expect: "no root element",
+		},
+		"unclosed element": {
+			data:   `<message>hello`,
+			expect: "unexpected EOF",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			var um xmlUnmarshaler
+			var got interface{}
+			err := um.Unmarshal([]byte(test.data), &got)
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), test.expect) {
+				t.Errorf("expect %q but got %q", test.expect, err)
+			}
+		})
+	}
+}
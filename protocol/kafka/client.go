@@ -0,0 +1,54 @@
+package kafka
+
+import (
+	gocontext "context"
+	"io"
+	"time"
+)
+
+// Client is the Kafka client.
+// The steps use the default client connecting to the brokers unless a plugin provides another implementation.
+type Client interface {
+	// Produce writes the message to msg.Topic.
+	// The implementation should set the partition and the offset of the written message.
+	Produce(ctx gocontext.Context, msg *Message) error
+	// Consumer returns the consumer reading from the topic.
+	Consumer(ctx gocontext.Context, config *ConsumerConfig) (Consumer, error)
+}
+
+// Consumer reads messages from a topic.
+// The consumer is shared by the steps in a scenario and closed when the scenario finishes.
+type Consumer interface {
+	io.Closer
+	// Fetch returns the next message.
+	// It waits until a message arrives or ctx is done.
+	Fetch(ctx gocontext.Context) (*Message, error)
+	// Commit commits the offset of the message to the consumer group.
+	Commit(ctx gocontext.Context, msg *Message) error
+}
+
+// ConsumerConfig represents the configuration of a consumer.
+type ConsumerConfig struct {
+	Topic string
+	// Group is the consumer group. If it is empty, the consumer reads without a group.
+	Group string
+	// Offset is the position to start reading when the group has no committed offset, "earliest" or "latest".
+	Offset string
+}
+
+// Message represents a Kafka message.
+type Message struct {
+	Topic     string
+	Key       []byte
+	Value     []byte
+	Headers   []Header
+	Partition int32
+	Offset    int64
+	Timestamp time.Time
+}
+
+// Header represents a header of a Kafka message.
+type Header struct {
+	Key   string
+	Value []byte
+}
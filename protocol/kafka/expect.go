@@ -0,0 +1,54 @@
+package kafka
+
+import (
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// Expect represents expected response values.
+type Expect struct {
+	Topic     interface{} `yaml:"topic,omitempty"`
+	Key       interface{} `yaml:"key,omitempty"`
+	Value     interface{} `yaml:"value,omitempty"`
+	Headers   interface{} `yaml:"headers,omitempty"`
+	Partition interface{} `yaml:"partition,omitempty"`
+	Offset    interface{} `yaml:"offset,omitempty"`
+}
+
+// Build implements protocol.AssertionBuilder interface.
+func (e *Expect) Build(ctx *context.Context) (assert.Assertion, error) {
+	fields := []struct {
+		name   string
+		expect interface{}
+		get    func(response) interface{}
+	}{
+		{"topic", e.Topic, func(r response) interface{} { return r.Topic }},
+		{"key", e.Key, func(r response) interface{} { return r.Key }},
+		{"value", e.Value, func(r response) interface{} { return r.Value }},
+		{"headers", e.Headers, func(r response) interface{} { return r.Headers }},
+		{"partition", e.Partition, func(r response) interface{} { return r.Partition }},
+		{"offset", e.Offset, func(r response) interface{} { return r.Offset }},
+	}
+	assertions := make([]assert.Assertion, len(fields))
+	for i, f := range fields {
+		assertion, err := assert.Build(ctx.RequestContext(), f.expect, assert.FromTemplate(ctx))
+		if err != nil {
+			return nil, errors.WrapPathf(err, f.name, "invalid expect %s", f.name)
+		}
+		assertions[i] = assertion
+	}
+
+	return assert.AssertionFunc(func(v interface{}) error {
+		res, ok := v.(response)
+		if !ok {
+			return errors.Errorf("expected response but got %T", v)
+		}
+		for i, f := range fields {
+			if err := assertions[i].Assert(f.get(res)); err != nil {
+				return errors.WithPath(err, f.name)
+			}
+		}
+		return nil
+	}), nil
+}
@@ -0,0 +1,57 @@
+// Package kafka provides the Kafka protocol for the scenarigo step.
+// The steps connect to the brokers by the default client, and a plugin can override it by implementing Client.
+package kafka
+
+import (
+	"bytes"
+
+	"github.com/goccy/go-yaml"
+	"github.com/zoncoen/scenarigo/protocol"
+)
+
+const protocolName = "kafka"
+
+// Register registers kafka protocol.
+func Register() {
+	protocol.Register(&Kafka{})
+}
+
+// Kafka is a protocol type for the scenarigo step.
+type Kafka struct{}
+
+// Name implements protocol.Protocol interface.
+func (p *Kafka) Name() string {
+	return protocolName
+}
+
+// UnmarshalRequest implements protocol.Protocol interface.
+func (p *Kafka) UnmarshalRequest(b []byte) (protocol.Invoker, error) {
+	var r Request
+	if err := yaml.UnmarshalWithOptions(b, &r, yaml.Strict()); err != nil {
+		return nil, err
+	}
+
+	// decode match as an ordered map to build the assertion in the same way as expect
+	var m struct {
+		Match interface{} `yaml:"match"`
+	}
+	if err := yaml.UnmarshalWithOptions(b, &m, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	r.Match = m.Match
+
+	return &r, nil
+}
+
+// UnmarshalExpect implements protocol.Protocol interface.
+func (p *Kafka) UnmarshalExpect(b []byte) (protocol.AssertionBuilder, error) {
+	var e Expect
+	if b == nil {
+		return &e, nil
+	}
+	decoder := yaml.NewDecoder(bytes.NewBuffer(b), yaml.UseOrderedMap(), yaml.Strict())
+	if err := decoder.Decode(&e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,210 @@
+package kafka
+
+import (
+	gocontext "context"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// kgoClient is the default Client which connects to the brokers by franz-go.
+type kgoClient struct {
+	brokers  []string
+	producer *kgo.Client
+}
+
+func newKgoClient(brokers []string) (*kgoClient, error) {
+	producer, err := kgo.NewClient(kgo.SeedBrokers(brokers...))
+	if err != nil {
+		return nil, err
+	}
+	return &kgoClient{
+		brokers:  brokers,
+		producer: producer,
+	}, nil
+}
+
+// Produce implements Client interface.
+func (c *kgoClient) Produce(ctx gocontext.Context, msg *Message) error {
+	rec := &kgo.Record{
+		Topic: msg.Topic,
+		Key:   msg.Key,
+		Value: msg.Value,
+	}
+	for _, h := range msg.Headers {
+		rec.Headers = append(rec.Headers, kgo.RecordHeader{Key: h.Key, Value: h.Value})
+	}
+	if err := c.producer.ProduceSync(ctx, rec).FirstErr(); err != nil {
+		return err
+	}
+	msg.Partition = rec.Partition
+	msg.Offset = rec.Offset
+	msg.Timestamp = rec.Timestamp
+	return nil
+}
+
+// Consumer implements Client interface.
+// The latest offset is the end offset when the consumer is created, so the consumer reads the messages written after the creation
+// even if the partitions are assigned to the consumer later.
+func (c *kgoClient) Consumer(ctx gocontext.Context, config *ConsumerConfig) (Consumer, error) {
+	start := kgo.NewOffset().AtStart()
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(c.brokers...),
+		kgo.ConsumeResetOffset(start),
+	}
+	var ends map[int32]int64
+	if config.Offset == offsetLatest {
+		var err error
+		ends, err = c.endOffsets(ctx, config.Topic)
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case config.Group != "":
+		opts = append(opts,
+			kgo.ConsumeTopics(config.Topic),
+			kgo.ConsumerGroup(config.Group),
+			kgo.DisableAutoCommit(),
+		)
+		if len(ends) > 0 {
+			opts = append(opts, kgo.AdjustFetchOffsetsFn(func(_ gocontext.Context, offsets map[string]map[int32]kgo.Offset) (map[string]map[int32]kgo.Offset, error) {
+				// the partitions without committed offsets have the reset offset
+				for p, o := range offsets[config.Topic] {
+					if end, ok := ends[p]; ok && o == start {
+						offsets[config.Topic][p] = kgo.NewOffset().At(end)
+					}
+				}
+				return offsets, nil
+			}))
+		}
+	case len(ends) > 0:
+		partitions := make(map[int32]kgo.Offset, len(ends))
+		for p, end := range ends {
+			partitions[p] = kgo.NewOffset().At(end)
+		}
+		opts = append(opts, kgo.ConsumePartitions(map[string]map[int32]kgo.Offset{config.Topic: partitions}))
+	default:
+		opts = append(opts, kgo.ConsumeTopics(config.Topic))
+	}
+	cl, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &kgoConsumer{
+		client: cl,
+		group:  config.Group != "",
+	}, nil
+}
+
+// endOffsets returns the end offsets of the partitions of the topic.
+// It returns no offsets if the topic doesn't exist yet.
+func (c *kgoClient) endOffsets(ctx gocontext.Context, topic string) (map[int32]int64, error) {
+	metaReq := kmsg.NewPtrMetadataRequest()
+	metaTopic := kmsg.NewMetadataRequestTopic()
+	metaTopic.Topic = kmsg.StringPtr(topic)
+	metaReq.Topics = append(metaReq.Topics, metaTopic)
+	metaResp, err := metaReq.RequestWith(ctx, c.producer)
+	if err != nil {
+		return nil, err
+	}
+	listReq := kmsg.NewPtrListOffsetsRequest()
+	listReq.ReplicaID = -1
+	listTopic := kmsg.NewListOffsetsRequestTopic()
+	listTopic.Topic = topic
+	for _, t := range metaResp.Topics {
+		if err := kerr.ErrorForCode(t.ErrorCode); err != nil {
+			if errors.Is(err, kerr.UnknownTopicOrPartition) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		for _, p := range t.Partitions {
+			listPartition := kmsg.NewListOffsetsRequestTopicPartition()
+			listPartition.Partition = p.Partition
+			listPartition.Timestamp = -1 // latest
+			listTopic.Partitions = append(listTopic.Partitions, listPartition)
+		}
+	}
+	listReq.Topics = append(listReq.Topics, listTopic)
+	listResp, err := listReq.RequestWith(ctx, c.producer)
+	if err != nil {
+		return nil, err
+	}
+	offsets := map[int32]int64{}
+	for _, t := range listResp.Topics {
+		for _, p := range t.Partitions {
+			if err := kerr.ErrorForCode(p.ErrorCode); err != nil {
+				return nil, err
+			}
+			offsets[p.Partition] = p.Offset
+		}
+	}
+	return offsets, nil
+}
+
+// Close closes the producer.
+func (c *kgoClient) Close() error {
+	c.producer.Close()
+	return nil
+}
+
+// kgoConsumer is the Consumer of kgoClient.
+type kgoConsumer struct {
+	client  *kgo.Client
+	group   bool
+	records []*kgo.Record
+}
+
+// Fetch implements Consumer interface.
+func (c *kgoConsumer) Fetch(ctx gocontext.Context) (*Message, error) {
+	for len(c.records) == 0 {
+		fetches := c.client.PollFetches(ctx)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		c.records = fetches.Records()
+		if len(c.records) == 0 {
+			if err := fetches.Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	rec := c.records[0]
+	c.records = c.records[1:]
+	msg := &Message{
+		Topic:     rec.Topic,
+		Key:       rec.Key,
+		Value:     rec.Value,
+		Partition: rec.Partition,
+		Offset:    rec.Offset,
+		Timestamp: rec.Timestamp,
+	}
+	for _, h := range rec.Headers {
+		msg.Headers = append(msg.Headers, Header{Key: h.Key, Value: h.Value})
+	}
+	return msg, nil
+}
+
+// Commit implements Consumer interface.
+// It does nothing if the consumer doesn't belong to a group.
+func (c *kgoConsumer) Commit(ctx gocontext.Context, msg *Message) error {
+	if !c.group {
+		return nil
+	}
+	return c.client.CommitRecords(ctx, &kgo.Record{
+		Topic:       msg.Topic,
+		Partition:   msg.Partition,
+		Offset:      msg.Offset,
+		LeaderEpoch: -1,
+	})
+}
+
+// Close implements Consumer interface.
+func (c *kgoConsumer) Close() error {
+	c.client.Close()
+	return nil
+}
@@ -0,0 +1,120 @@
+package kafka
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+	"github.com/twmb/franz-go/pkg/kfake"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+func TestRequest_Invoke_DefaultClient(t *testing.T) {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, "events"))
+	if err != nil {
+		t.Fatalf("failed to start cluster: %s", err)
+	}
+	defer cluster.Close()
+
+	ctx := context.FromT(t).WithVars(map[string]interface{}{
+		"brokers": strings.Join(cluster.ListenAddrs(), ","),
+	}).WithConnections(context.NewConnections())
+	defer func() {
+		ctx.Connections().Close()
+	}()
+
+	invoke := func(t *testing.T, r *Request) response {
+		t.Helper()
+		r.Brokers = "{{vars.brokers}}"
+		var (
+			result interface{}
+			err    error
+		)
+		ctx, result, err = r.Invoke(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		resp, ok := result.(response)
+		if !ok {
+			t.Fatalf("expected response but got %T", result)
+		}
+		return resp
+	}
+
+	invoke(t, &Request{Action: actionProduce, Topic: "events", Value: "old"})
+	invoke(t, &Request{Action: actionSubscribe, Topic: "events", Group: "test"})
+	resp := invoke(t, &Request{
+		Action:  actionProduce,
+		Topic:   "events",
+		Key:     "user-1",
+		Value:   map[string]interface{}{"type": "created"},
+		Headers: map[string]string{"trace-id": "abc"},
+	})
+	if diff := cmp.Diff(response{Topic: "events", Offset: 1}, resp); diff != "" {
+		t.Errorf("produce response differs (-want +got):\n%s", diff)
+	}
+
+	t.Run("consume from the latest offset", func(t *testing.T) {
+		resp := invoke(t, &Request{Action: actionConsume, Topic: "events", Group: "test"})
+		expect := response{
+			Topic:   "events",
+			Key:     "user-1",
+			Value:   map[string]interface{}{"type": "created"},
+			Headers: map[string]string{"trace-id": "abc"},
+			Offset:  1,
+		}
+		if diff := cmp.Diff(expect, resp); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("consume from the committed offset by another consumer", func(t *testing.T) {
+		ctx.Connections().Close()
+		ctx = ctx.WithConnections(context.NewConnections())
+		invoke(t, &Request{Action: actionProduce, Topic: "events", Value: "next"})
+		resp := invoke(t, &Request{Action: actionConsume, Topic: "events", Group: "test"})
+		if diff := cmp.Diff(response{Topic: "events", Value: "next", Offset: 2}, resp); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("consume from the earliest offset without commit", func(t *testing.T) {
+		commit := false
+		resp := invoke(t, &Request{Action: actionConsume, Topic: "events", Group: "replay", Offset: offsetEarliest, Commit: &commit})
+		if diff := cmp.Diff(response{Topic: "events", Value: "old"}, resp); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+		ctx.Connections().Close()
+		ctx = ctx.WithConnections(context.NewConnections())
+		resp = invoke(t, &Request{Action: actionConsume, Topic: "events", Group: "replay", Offset: offsetEarliest})
+		if diff := cmp.Diff(response{Topic: "events", Value: "old"}, resp); diff != "" {
+			t.Errorf("offset is committed (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("consume without group", func(t *testing.T) {
+		resp := invoke(t, &Request{
+			Action: actionConsume,
+			Topic:  "events",
+			Offset: offsetEarliest,
+			Match: yaml.MapSlice{
+				{Key: "value", Value: "next"},
+			},
+		})
+		if diff := cmp.Diff(response{Topic: "events", Value: "next", Offset: 2}, resp); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("brokers with client", func(t *testing.T) {
+		_, _, err := (&Request{Client: "{{vars.brokers}}", Brokers: "{{vars.brokers}}", Action: actionProduce, Topic: "events"}).Invoke(ctx)
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if expect := ".brokers: brokers can't be specified with client"; !strings.Contains(err.Error(), expect) {
+			t.Errorf("expected error %q but got %q", expect, err)
+		}
+	})
+}
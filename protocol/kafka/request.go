@@ -0,0 +1,440 @@
+package kafka
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
+)
+
+const (
+	actionProduce   = "produce"
+	actionSubscribe = "subscribe"
+	actionConsume   = "consume"
+
+	offsetEarliest = "earliest"
+	offsetLatest   = "latest"
+
+	decoderRaw  = "raw"
+	decoderJSON = "json"
+
+	defaultBrokers = "localhost:9092"
+	defaultTimeout = 10 * time.Second
+
+	indentNum = 2
+)
+
+// Request represents a request.
+type Request struct {
+	// Client is the Client created by a plugin to override the default client.
+	Client string `yaml:"client,omitempty"`
+	// Brokers is the comma-separated addresses of the brokers for the default client.
+	Brokers string `yaml:"brokers,omitempty"` // default value is "localhost:9092"
+	// Action is one of produce, subscribe, and consume.
+	Action string `yaml:"action"`
+	Topic  string `yaml:"topic"`
+
+	// produce
+	Key     interface{} `yaml:"key,omitempty"`
+	Value   interface{} `yaml:"value,omitempty"`
+	Headers interface{} `yaml:"headers,omitempty"`
+
+	// subscribe and consume
+	Group  string `yaml:"group,omitempty"`
+	Offset string `yaml:"offset,omitempty"` // earliest or latest (default)
+
+	// consume
+	Commit  *bool       `yaml:"commit,omitempty"`  // default value is true
+	Timeout string      `yaml:"timeout,omitempty"` // default value is 10s
+	Decoder string      `yaml:"decoder,omitempty"` // raw, json, or a decode function
+	Match   interface{} `yaml:"match,omitempty"`   // skip messages until a message satisfies the assertion
+}
+
+type response struct {
+	Topic     string            `yaml:"topic,omitempty"`
+	Key       string            `yaml:"key,omitempty"`
+	Value     interface{}       `yaml:"value,omitempty"`
+	Headers   map[string]string `yaml:"headers,omitempty"`
+	Partition int32             `yaml:"partition"`
+	Offset    int64             `yaml:"offset"`
+}
+
+// decodeFunc decodes the message value.
+type decodeFunc func([]byte) (interface{}, error)
+
+func (r *Request) addIndent(s string, indentNum int) string {
+	indent := strings.Repeat(" ", indentNum)
+	lines := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		if line == "" {
+			lines = append(lines, line)
+		} else {
+			lines = append(lines, fmt.Sprintf("%s%s", indent, line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Invoke implements protocol.Invoker interface.
+func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, error) {
+	client, err := r.client(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+	topic, err := r.topic(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	switch r.Action {
+	case actionProduce:
+		return r.produce(ctx, client, topic)
+	case actionSubscribe:
+		if _, err := r.consumer(ctx, client, topic); err != nil {
+			return ctx, nil, err
+		}
+		return ctx, response{}, nil
+	case actionConsume:
+		consumer, err := r.consumer(ctx, client, topic)
+		if err != nil {
+			return ctx, nil, err
+		}
+		return r.consume(ctx, consumer)
+	case "":
+		return ctx, nil, errors.ErrorPath("action", "action must be specified")
+	default:
+		return ctx, nil, errors.ErrorPathf("action", `unknown action %q: must be one of "produce", "subscribe", and "consume"`, r.Action)
+	}
+}
+
+// client returns the Client of the plugin if specified, otherwise the default client connecting to the brokers.
+// The default client is created on the first use and shared by the steps in the scenario.
+func (r *Request) client(ctx *context.Context) (Client, error) {
+	if r.Client != "" {
+		if r.Brokers != "" {
+			return nil, errors.ErrorPath("brokers", "brokers can't be specified with client")
+		}
+		x, err := ctx.ExecuteTemplate(r.Client)
+		if err != nil {
+			return nil, errors.WrapPath(err, "client", "failed to get client")
+		}
+		client, ok := x.(Client)
+		if !ok {
+			return nil, errors.ErrorPathf("client", "client must implement kafka.Client interface but got %T", x)
+		}
+		return client, nil
+	}
+
+	brokers := defaultBrokers
+	if r.Brokers != "" {
+		x, err := ctx.ExecuteTemplate(r.Brokers)
+		if err != nil {
+			return nil, errors.WrapPath(err, "brokers", "invalid brokers")
+		}
+		s, ok := x.(string)
+		if !ok || s == "" {
+			return nil, errors.ErrorPathf("brokers", "brokers must be a string but got %T", x)
+		}
+		brokers = s
+	}
+	conns := ctx.Connections()
+	if conns == nil {
+		return nil, errors.New("connections are not available in this context")
+	}
+	// "/" isn't allowed in topic names, so the key doesn't conflict with the keys of the consumers
+	key := fmt.Sprintf("%s/client:%s", protocolName, brokers)
+	if c, ok := conns.Get(key).(*kgoClient); ok {
+		return c, nil
+	}
+	c, err := newKgoClient(strings.Split(brokers, ","))
+	if err != nil {
+		return nil, errors.WrapPath(err, "brokers", "failed to create client")
+	}
+	if err := conns.Add(key, c); err != nil {
+		c.Close()
+		return nil, errors.WithPath(err, "brokers")
+	}
+	return c, nil
+}
+
+func (r *Request) topic(ctx *context.Context) (string, error) {
+	x, err := ctx.ExecuteTemplate(r.Topic)
+	if err != nil {
+		return "", errors.WrapPath(err, "topic", "invalid topic")
+	}
+	topic, ok := x.(string)
+	if !ok || topic == "" {
+		return "", errors.ErrorPathf("topic", "topic must be a string but got %T", x)
+	}
+	return topic, nil
+}
+
+func (r *Request) produce(ctx *context.Context, client Client, topic string) (*context.Context, interface{}, error) {
+	msg := &Message{Topic: topic}
+	if r.Key != nil {
+		b, err := encode(ctx, r.Key)
+		if err != nil {
+			return ctx, nil, errors.WithPath(err, "key")
+		}
+		msg.Key = b
+	}
+	b, err := encode(ctx, r.Value)
+	if err != nil {
+		return ctx, nil, errors.WithPath(err, "value")
+	}
+	msg.Value = b
+	if r.Headers != nil {
+		x, err := ctx.ExecuteTemplate(r.Headers)
+		if err != nil {
+			return ctx, nil, errors.WrapPathf(err, "headers", "invalid headers")
+		}
+		headers, err := reflectutil.ConvertStringsMap(reflect.ValueOf(x))
+		if err != nil {
+			return ctx, nil, errors.WrapPathf(err, "headers", "invalid headers")
+		}
+		keys := make([]string, 0, len(headers))
+		for k := range headers {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			for _, v := range headers[k] {
+				msg.Headers = append(msg.Headers, Header{Key: k, Value: []byte(v)})
+			}
+		}
+	}
+
+	req, _ := newResponse(msg, decodeValue) // decodeValue never fails
+	ctx = ctx.WithRequest(req)
+	//nolint:exhaustruct
+	if b, err := yaml.Marshal(Request{
+		Action:  r.Action,
+		Topic:   topic,
+		Key:     req.Key,
+		Value:   req.Value,
+		Headers: req.Headers,
+	}); err == nil {
+		ctx.Reporter().Logf("request:\n%s", r.addIndent(string(b), indentNum))
+	} else {
+		ctx.Reporter().Logf("failed to dump request:\n%s", err)
+	}
+
+	if err := client.Produce(ctx.RequestContext(), msg); err != nil {
+		return ctx, nil, errors.Errorf("failed to produce message: %s", err)
+	}
+	//nolint:exhaustruct
+	resp := response{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+	}
+	ctx = ctx.WithResponse(resp)
+	ctx.Reporter().Logf("response:\n%s", r.addIndent(dump(resp), indentNum))
+	return ctx, resp, nil
+}
+
+// encode encodes the value as is if it is a string or bytes, otherwise as JSON.
+func encode(ctx *context.Context, v interface{}) ([]byte, error) {
+	x, err := ctx.ExecuteTemplate(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid value")
+	}
+	switch v := x.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	}
+	b, err := json.Marshal(x)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode value as JSON")
+	}
+	return b, nil
+}
+
+// consumer returns the consumer of the topic.
+// The consumer is created on the first use and shared by the steps in the scenario to continue reading.
+func (r *Request) consumer(ctx *context.Context, client Client, topic string) (Consumer, error) {
+	conns := ctx.Connections()
+	if conns == nil {
+		return nil, errors.New("connections are not available in this context")
+	}
+	x, err := ctx.ExecuteTemplate(r.Group)
+	if err != nil {
+		return nil, errors.WrapPath(err, "group", "invalid group")
+	}
+	group, ok := x.(string)
+	if !ok {
+		return nil, errors.ErrorPathf("group", "group must be a string but got %T", x)
+	}
+	key := fmt.Sprintf("%s:%s:%s", protocolName, topic, group)
+	if c, ok := conns.Get(key).(Consumer); ok {
+		return c, nil
+	}
+
+	offset := r.Offset
+	switch offset {
+	case "":
+		offset = offsetLatest
+	case offsetEarliest, offsetLatest:
+	default:
+		return nil, errors.ErrorPathf("offset", `offset must be "earliest" or "latest" but got %q`, offset)
+	}
+	c, err := client.Consumer(ctx.RequestContext(), &ConsumerConfig{
+		Topic:  topic,
+		Group:  group,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, errors.Errorf("failed to create consumer: %s", err)
+	}
+	if err := conns.Add(key, c); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *Request) consume(ctx *context.Context, consumer Consumer) (*context.Context, interface{}, error) {
+	timeout := defaultTimeout
+	if r.Timeout != "" {
+		d, err := time.ParseDuration(r.Timeout)
+		if err != nil {
+			return ctx, nil, errors.WrapPath(err, "timeout", "invalid timeout")
+		}
+		timeout = d
+	}
+	decode, err := r.decoder(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+	var match assert.Assertion
+	if r.Match != nil {
+		match, err = assert.Build(ctx.RequestContext(), r.Match, assert.FromTemplate(ctx))
+		if err != nil {
+			return ctx, nil, errors.WrapPathf(err, "match", "invalid match")
+		}
+	}
+	commit := r.Commit == nil || *r.Commit
+
+	fetchCtx, cancel := gocontext.WithTimeout(ctx.RequestContext(), timeout)
+	defer cancel()
+	for {
+		msg, err := consumer.Fetch(fetchCtx)
+		if err != nil {
+			if fetchCtx.Err() != nil && ctx.RequestContext().Err() == nil {
+				if match != nil {
+					return ctx, nil, errors.Errorf("no message satisfied the match within %s", timeout)
+				}
+				return ctx, nil, errors.Errorf("no message received within %s", timeout)
+			}
+			return ctx, nil, errors.Errorf("failed to consume message: %s", err)
+		}
+		resp, err := newResponse(msg, decode)
+		if err != nil {
+			return ctx, nil, errors.Errorf("failed to decode value of the message at offset %d: %s", msg.Offset, err)
+		}
+		if commit {
+			if err := consumer.Commit(ctx.RequestContext(), msg); err != nil {
+				return ctx, nil, errors.Errorf("failed to commit offset: %s", err)
+			}
+		}
+		if match != nil {
+			if err := match.Assert(resp); err != nil {
+				ctx.Reporter().Logf("skip the message which doesn't satisfy the match:\n%s", r.addIndent(dump(resp), indentNum))
+				continue
+			}
+		}
+		ctx = ctx.WithResponse(resp)
+		ctx.Reporter().Logf("response:\n%s", r.addIndent(dump(resp), indentNum))
+		return ctx, resp, nil
+	}
+}
+
+// decoder returns the function to decode the message value.
+func (r *Request) decoder(ctx *context.Context) (decodeFunc, error) {
+	x, err := ctx.ExecuteTemplate(r.Decoder)
+	if err != nil {
+		return nil, errors.WrapPath(err, "decoder", "invalid decoder")
+	}
+	switch v := x.(type) {
+	case string:
+		switch v {
+		case "":
+			return decodeValue, nil
+		case decoderRaw:
+			return func(b []byte) (interface{}, error) {
+				return string(b), nil
+			}, nil
+		case decoderJSON:
+			return decodeJSON, nil
+		}
+		return nil, errors.ErrorPathf("decoder", `decoder must be "raw", "json", or a function but got %q`, v)
+	case func([]byte) (interface{}, error):
+		return v, nil
+	}
+	return nil, errors.ErrorPathf("decoder", "decoder must be func([]byte) (interface{}, error) but got %T", x)
+}
+
+// decodeValue decodes the value as JSON if possible.
+func decodeValue(b []byte) (interface{}, error) {
+	if v, err := decodeJSON(b); err == nil {
+		return v, nil
+	}
+	return string(b), nil
+}
+
+func decodeJSON(b []byte) (interface{}, error) {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	if d.More() {
+		return nil, errors.New("invalid JSON: unexpected data after the value")
+	}
+	return v, nil
+}
+
+func newResponse(msg *Message, decode decodeFunc) (response, error) {
+	resp := response{
+		Topic:     msg.Topic,
+		Key:       string(msg.Key),
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+	}
+	v, err := decode(msg.Value)
+	if err != nil {
+		return resp, err
+	}
+	resp.Value = v
+	if len(msg.Headers) > 0 {
+		resp.Headers = make(map[string]string, len(msg.Headers))
+		for _, h := range msg.Headers {
+			resp.Headers[h.Key] = string(h.Value)
+		}
+	}
+	return resp, nil
+}
+
+func dump(resp response) string {
+	b, err := yaml.Marshal(resp)
+	if err != nil {
+		return fmt.Sprintf("failed to dump response: %s", err)
+	}
+	return string(b)
+}
@@ -0,0 +1,348 @@
+package kafka
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+// fakeClient is the in-memory Kafka client which has a single partition per topic.
+type fakeClient struct {
+	mu        sync.Mutex
+	topics    map[string][]*Message
+	committed map[string]int64
+	notify    chan struct{}
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		topics:    map[string][]*Message{},
+		committed: map[string]int64{},
+		notify:    make(chan struct{}),
+	}
+}
+
+func (c *fakeClient) Produce(ctx gocontext.Context, msg *Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	msg.Offset = int64(len(c.topics[msg.Topic]))
+	c.topics[msg.Topic] = append(c.topics[msg.Topic], msg)
+	close(c.notify)
+	c.notify = make(chan struct{})
+	return nil
+}
+
+func (c *fakeClient) Consumer(ctx gocontext.Context, config *ConsumerConfig) (Consumer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	offset, ok := c.committed[config.Group]
+	if !ok && config.Offset == offsetLatest {
+		offset = int64(len(c.topics[config.Topic]))
+	}
+	return &fakeConsumer{client: c, config: config, offset: offset}, nil
+}
+
+type fakeConsumer struct {
+	client *fakeClient
+	config *ConsumerConfig
+	offset int64
+	closed bool
+}
+
+func (c *fakeConsumer) Fetch(ctx gocontext.Context) (*Message, error) {
+	for {
+		c.client.mu.Lock()
+		msgs := c.client.topics[c.config.Topic]
+		notify := c.client.notify
+		if c.offset < int64(len(msgs)) {
+			msg := msgs[c.offset]
+			c.offset++
+			c.client.mu.Unlock()
+			return msg, nil
+		}
+		c.client.mu.Unlock()
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *fakeConsumer) Commit(ctx gocontext.Context, msg *Message) error {
+	c.client.mu.Lock()
+	defer c.client.mu.Unlock()
+	if c.config.Group != "" {
+		c.client.committed[c.config.Group] = msg.Offset + 1
+	}
+	return nil
+}
+
+func (c *fakeConsumer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRequest_Invoke(t *testing.T) {
+	client := newFakeClient()
+	ctx := context.FromT(t).WithVars(map[string]interface{}{
+		"client": client,
+		"id":     "1",
+		"decode": func(b []byte) (interface{}, error) {
+			return strings.ToUpper(string(b)), nil
+		},
+	}).WithConnections(context.NewConnections())
+	defer ctx.Connections().Close()
+
+	invoke := func(t *testing.T, r *Request) (*context.Context, response) {
+		t.Helper()
+		r.Client = "{{vars.client}}"
+		var (
+			result interface{}
+			err    error
+		)
+		ctx, result, err = r.Invoke(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		resp, ok := result.(response)
+		if !ok {
+			t.Fatalf("expected response but got %T", result)
+		}
+		return ctx, resp
+	}
+
+	invoke(t, &Request{Action: actionSubscribe, Topic: "events", Group: "test"})
+	_, resp := invoke(t, &Request{
+		Action: actionProduce,
+		Topic:  "events",
+		Key:    "user-{{vars.id}}",
+		Value: map[string]interface{}{
+			"id":   "{{vars.id}}",
+			"type": "created",
+		},
+		Headers: map[string]string{
+			"trace-id": "abc",
+		},
+	})
+	if diff := cmp.Diff(response{Topic: "events", Offset: 0}, resp); diff != "" {
+		t.Errorf("produce response differs (-want +got):\n%s", diff)
+	}
+	invoke(t, &Request{Action: actionProduce, Topic: "events", Value: "plain text"})
+
+	t.Run("consume with match", func(t *testing.T) {
+		ctx, resp := invoke(t, &Request{
+			Action: actionConsume,
+			Topic:  "events",
+			Group:  "test",
+			Match: yaml.MapSlice{
+				{Key: "value", Value: "plain text"},
+			},
+		})
+		if diff := cmp.Diff(response{Topic: "events", Value: "plain text", Offset: 1}, resp); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+		got, err := ctx.ExecuteTemplate("{{response.offset}}")
+		if err != nil {
+			t.Fatalf("failed to execute template: %s", err)
+		}
+		if got != int64(1) {
+			t.Errorf("expected 1 but got %v", got)
+		}
+	})
+
+	t.Run("consume from the committed offset by another consumer", func(t *testing.T) {
+		ctx.Connections().Close()
+		ctx = ctx.WithConnections(context.NewConnections())
+		invoke(t, &Request{Action: actionProduce, Topic: "events", Value: "next"})
+		_, resp := invoke(t, &Request{Action: actionConsume, Topic: "events", Group: "test", Decoder: "{{vars.decode}}"})
+		if diff := cmp.Diff(response{Topic: "events", Value: "NEXT", Offset: 2}, resp); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("consume from the earliest offset without commit", func(t *testing.T) {
+		commit := false
+		for i, expect := range []response{
+			{
+				Topic:   "events",
+				Key:     "user-1",
+				Value:   map[string]interface{}{"id": "1", "type": "created"},
+				Headers: map[string]string{"trace-id": "abc"},
+			},
+			{Topic: "events", Value: "plain text", Offset: 1},
+		} {
+			_, resp := invoke(t, &Request{Action: actionConsume, Topic: "events", Group: "replay", Offset: offsetEarliest, Commit: &commit})
+			if diff := cmp.Diff(expect, resp); diff != "" {
+				t.Errorf("[%d] differs (-want +got):\n%s", i, diff)
+			}
+		}
+		client.mu.Lock()
+		defer client.mu.Unlock()
+		if _, ok := client.committed["replay"]; ok {
+			t.Error("offset is committed")
+		}
+	})
+
+	t.Run("decoders", func(t *testing.T) {
+		invoke(t, &Request{Action: actionSubscribe, Topic: "numbers"})
+		invoke(t, &Request{Action: actionProduce, Topic: "numbers", Value: "1"})
+		invoke(t, &Request{Action: actionProduce, Topic: "numbers", Value: "1"})
+		_, resp := invoke(t, &Request{Action: actionConsume, Topic: "numbers", Decoder: decoderRaw})
+		if resp.Value != "1" {
+			t.Errorf(`expected "1" but got %#v`, resp.Value)
+		}
+		_, resp = invoke(t, &Request{Action: actionConsume, Topic: "numbers", Decoder: decoderJSON})
+		if resp.Value != json.Number("1") {
+			t.Errorf("expected 1 but got %#v", resp.Value)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		tests := map[string]struct {
+			request     *Request
+			expectError string
+		}{
+			"no action": {
+				request:     &Request{Topic: "events"},
+				expectError: ".action: action must be specified",
+			},
+			"unknown action": {
+				request:     &Request{Action: "fetch", Topic: "events"},
+				expectError: `.action: unknown action "fetch"`,
+			},
+			"no topic": {
+				request:     &Request{Action: actionConsume},
+				expectError: ".topic: topic must be a string",
+			},
+			"invalid offset": {
+				request:     &Request{Action: actionConsume, Topic: "events", Group: "new", Offset: "middle"},
+				expectError: `.offset: offset must be "earliest" or "latest"`,
+			},
+			"invalid decoder": {
+				request:     &Request{Action: actionConsume, Topic: "events", Decoder: "xml"},
+				expectError: `.decoder: decoder must be "raw", "json", or a function`,
+			},
+			"timeout": {
+				request:     &Request{Action: actionConsume, Topic: "empty", Timeout: "10ms"},
+				expectError: "no message received within 10ms",
+			},
+			"no matched message": {
+				request: &Request{
+					Action:  actionConsume,
+					Topic:   "events",
+					Group:   "unmatched",
+					Offset:  offsetEarliest,
+					Timeout: "10ms",
+					Match: yaml.MapSlice{
+						{Key: "key", Value: "unknown"},
+					},
+				},
+				expectError: "no message satisfied the match within 10ms",
+			},
+			"decode error": {
+				request:     &Request{Action: actionConsume, Topic: "events", Group: "json", Offset: offsetEarliest, Decoder: decoderJSON},
+				expectError: "failed to decode value of the message at offset 1",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				test.request.Client = "{{vars.client}}"
+				// the first message is JSON
+				if test.request.Group == "json" {
+					if _, _, err := (&Request{Client: "{{vars.client}}", Action: actionConsume, Topic: "events", Group: "json", Offset: offsetEarliest}).Invoke(ctx); err != nil {
+						t.Fatalf("unexpected error: %s", err)
+					}
+				}
+				_, _, err := test.request.Invoke(ctx)
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("expected error %q but got %q", test.expectError, err)
+				}
+			})
+		}
+	})
+
+	t.Run("invalid client", func(t *testing.T) {
+		_, _, err := (&Request{Client: "client", Action: actionProduce, Topic: "events"}).Invoke(ctx)
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if expect := ".client: client must implement kafka.Client interface"; !strings.Contains(err.Error(), expect) {
+			t.Errorf("expected error %q but got %q", expect, err)
+		}
+	})
+}
+
+func TestExpect_Build(t *testing.T) {
+	resp := response{
+		Topic:   "events",
+		Key:     "user-1",
+		Value:   map[string]interface{}{"id": "1", "type": "created"},
+		Headers: map[string]string{"trace-id": "abc"},
+		Offset:  3,
+	}
+	tests := map[string]struct {
+		expect      *Expect
+		expectError string
+	}{
+		"match": {
+			expect: &Expect{
+				Key: "user-1",
+				Value: yaml.MapSlice{
+					{Key: "type", Value: "created"},
+				},
+				Headers: yaml.MapSlice{
+					{Key: "trace-id", Value: "abc"},
+				},
+				Offset: 3,
+			},
+		},
+		"value mismatch": {
+			expect: &Expect{
+				Value: yaml.MapSlice{
+					{Key: "type", Value: "deleted"},
+				},
+			},
+			expectError: ".value.type",
+		},
+		"offset mismatch": {
+			expect: &Expect{
+				Offset: 1,
+			},
+			expectError: ".offset",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := test.expect.Build(context.FromT(t))
+			if err != nil {
+				t.Fatalf("failed to build assertion: %s", err)
+			}
+			err = assertion.Assert(resp)
+			if test.expectError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), test.expectError) {
+				t.Errorf("expected error %q but got %q", test.expectError, err)
+			}
+		})
+	}
+}
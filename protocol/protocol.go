@@ -51,6 +51,11 @@ type Invoker interface {
 	Invoke(*context.Context) (*context.Context, interface{}, error)
 }
 
+// Preparer is the optional interface implemented by Invoker to load resources before running the scenario.
+type Preparer interface {
+	Prepare(*context.Context) error
+}
+
 // AssertionBuilder builds the assertion for the result of Invoke.
 type AssertionBuilder interface {
 	Build(*context.Context) (assert.Assertion, error)
@@ -0,0 +1,156 @@
+package redis
+
+import (
+	"bufio"
+	gocontext "context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// conn is the connection to the Redis server which speaks RESP (REdis Serialization Protocol).
+type conn struct {
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// replyError is the error reply returned by the server.
+type replyError string
+
+func (e replyError) Error() string {
+	return string(e)
+}
+
+func dial(ctx gocontext.Context, addr, password string, db int) (*conn, error) {
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &conn{
+		conn: nc,
+		r:    bufio.NewReader(nc),
+	}
+	if password != "" {
+		if _, err := c.do(ctx, "AUTH", password); err != nil {
+			c.Close()
+			return nil, errors.Wrap(err, "failed to authenticate")
+		}
+	}
+	if db != 0 {
+		if _, err := c.do(ctx, "SELECT", strconv.Itoa(db)); err != nil {
+			c.Close()
+			return nil, errors.Wrapf(err, "failed to select db %d", db)
+		}
+	}
+	return c, nil
+}
+
+// Close implements io.Closer interface.
+func (c *conn) Close() error {
+	return c.conn.Close()
+}
+
+// do sends the command and returns the reply.
+// The error reply is returned as replyError.
+func (c *conn) do(ctx gocontext.Context, args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Time{}
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if _, err := c.conn.Write(encodeCommand(args)); err != nil {
+		return nil, err
+	}
+	return readReply(c.r)
+}
+
+func encodeCommand(args []string) []byte {
+	b := []byte(fmt.Sprintf("*%d\r\n", len(args)))
+	for _, arg := range args {
+		b = append(b, fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)...)
+	}
+	return b
+}
+
+// readReply reads a RESP2 reply.
+// Bulk strings are returned as string, integers as int64, and arrays as []interface{}.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("invalid reply: empty line")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, replyError(line[1:])
+	case ':':
+		i, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid integer reply")
+		}
+		return i, nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid bulk string length")
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		return string(b[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid array length")
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		vs := make([]interface{}, n)
+		for i := range vs {
+			v, err := readReply(r)
+			if err != nil {
+				// nested error replies such as the results of EXEC are kept as values
+				var e replyError
+				if !errors.As(err, &e) {
+					return nil, err
+				}
+				v = string(e)
+			}
+			vs[i] = v
+		}
+		return vs, nil
+	}
+	return nil, errors.Errorf("invalid reply: unknown type %q", line[0])
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errors.Errorf("invalid reply: %q", line)
+	}
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// Expect represents expected response values.
+type Expect struct {
+	Reply interface{} `yaml:"reply,omitempty"`
+	// Error is the expected error reply.
+	// If it isn't specified, the error reply fails the assertion.
+	Error interface{} `yaml:"error,omitempty"`
+}
+
+// Build implements protocol.AssertionBuilder interface.
+func (e *Expect) Build(ctx *context.Context) (assert.Assertion, error) {
+	replyAssertion, err := assert.Build(ctx.RequestContext(), e.Reply, assert.FromTemplate(ctx))
+	if err != nil {
+		return nil, errors.WrapPathf(err, "reply", "invalid expect reply")
+	}
+	errAssertion, err := assert.Build(ctx.RequestContext(), e.Error, assert.FromTemplate(ctx))
+	if err != nil {
+		return nil, errors.WrapPathf(err, "error", "invalid expect error")
+	}
+
+	return assert.AssertionFunc(func(v interface{}) error {
+		res, ok := v.(response)
+		if !ok {
+			return errors.Errorf("expected response but got %T", v)
+		}
+		if e.Error == nil {
+			if res.Error != "" {
+				return errors.ErrorPathf("error", "unexpected error reply: %s", res.Error)
+			}
+		} else if err := errAssertion.Assert(res.Error); err != nil {
+			return errors.WithPath(err, "error")
+		}
+		if err := replyAssertion.Assert(res.Reply); err != nil {
+			return errors.WithPath(err, "reply")
+		}
+		return nil
+	}), nil
+}
@@ -0,0 +1,46 @@
+// Package redis provides the Redis protocol for the scenarigo step.
+package redis
+
+import (
+	"bytes"
+
+	"github.com/goccy/go-yaml"
+	"github.com/zoncoen/scenarigo/protocol"
+)
+
+const protocolName = "redis"
+
+// Register registers redis protocol.
+func Register() {
+	protocol.Register(&Redis{})
+}
+
+// Redis is a protocol type for the scenarigo step.
+type Redis struct{}
+
+// Name implements protocol.Protocol interface.
+func (p *Redis) Name() string {
+	return protocolName
+}
+
+// UnmarshalRequest implements protocol.Protocol interface.
+func (p *Redis) UnmarshalRequest(b []byte) (protocol.Invoker, error) {
+	var r Request
+	if err := yaml.UnmarshalWithOptions(b, &r, yaml.Strict(), yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// UnmarshalExpect implements protocol.Protocol interface.
+func (p *Redis) UnmarshalExpect(b []byte) (protocol.AssertionBuilder, error) {
+	var e Expect
+	if b == nil {
+		return &e, nil
+	}
+	decoder := yaml.NewDecoder(bytes.NewBuffer(b), yaml.UseOrderedMap(), yaml.Strict())
+	if err := decoder.Decode(&e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
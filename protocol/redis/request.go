@@ -0,0 +1,221 @@
+package redis
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+const (
+	defaultAddr = "localhost:6379"
+
+	indentNum = 2
+)
+
+// Request represents a request.
+type Request struct {
+	Addr     string `yaml:"addr,omitempty"` // default value is "localhost:6379"
+	Password string `yaml:"password,omitempty"`
+	DB       int    `yaml:"db,omitempty"`
+
+	// Command is the command name such as GET and HGETALL.
+	Command string        `yaml:"command"`
+	Args    []interface{} `yaml:"args,omitempty"`
+}
+
+type response struct {
+	Reply interface{} `yaml:"reply"`
+	// Error is the error reply of the command.
+	Error string `yaml:"error,omitempty"`
+}
+
+func (r *Request) addIndent(s string, indentNum int) string {
+	indent := strings.Repeat(" ", indentNum)
+	lines := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		if line == "" {
+			lines = append(lines, line)
+		} else {
+			lines = append(lines, fmt.Sprintf("%s%s", indent, line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Invoke implements protocol.Invoker interface.
+func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, error) {
+	if r.Command == "" {
+		return ctx, nil, errors.ErrorPath("command", "command must be specified")
+	}
+	args, err := r.buildArgs(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+	c, release, err := r.connect(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	cmd := strings.ToUpper(r.Command)
+	//nolint:exhaustruct
+	dumpReq := Request{
+		Command: cmd,
+	}
+	for _, arg := range args {
+		dumpReq.Args = append(dumpReq.Args, arg)
+	}
+	ctx = ctx.WithRequest(dumpReq)
+	if b, err := yaml.Marshal(dumpReq); err == nil {
+		ctx.Reporter().Logf("request:\n%s", r.addIndent(string(b), indentNum))
+	} else {
+		ctx.Reporter().Logf("failed to dump request:\n%s", err)
+	}
+
+	var resp response
+	start := time.Now()
+	reply, err := c.do(ctx.RequestContext(), append([]string{cmd}, args...)...)
+	release(err)
+	ctx = ctx.WithElapsed(time.Since(start))
+	if err != nil {
+		var e replyError
+		if !errors.As(err, &e) {
+			return ctx, nil, errors.WrapPathf(err, "command", "failed to execute %s", cmd)
+		}
+		resp.Error = string(e)
+	} else {
+		resp.Reply = structureReply(cmd, reply)
+	}
+	ctx = ctx.WithResponse(resp)
+	if b, err := yaml.Marshal(resp); err == nil {
+		ctx.Reporter().Logf("response:\n%s", r.addIndent(string(b), indentNum))
+	} else {
+		ctx.Reporter().Logf("failed to dump response:\n%s", err)
+	}
+	return ctx, resp, nil
+}
+
+// connect returns the connection to the server and the function to release it by the result of the command.
+// The connection is reused in the scenario if the context has the connection store.
+// The reused connection is discarded if the command fails by an error other than the error reply
+// because the reply of the command may be left unread in the connection.
+func (r *Request) connect(ctx *context.Context) (*conn, func(error), error) {
+	addr := defaultAddr
+	if r.Addr != "" {
+		x, err := ctx.ExecuteTemplate(r.Addr)
+		if err != nil {
+			return nil, nil, errors.WrapPath(err, "addr", "invalid addr")
+		}
+		s, ok := x.(string)
+		if !ok {
+			return nil, nil, errors.ErrorPathf("addr", "addr must be a string but got %T", x)
+		}
+		addr = s
+	}
+	var password string
+	if r.Password != "" {
+		x, err := ctx.ExecuteTemplate(r.Password)
+		if err != nil {
+			return nil, nil, errors.WrapPath(err, "password", "invalid password")
+		}
+		s, ok := x.(string)
+		if !ok {
+			return nil, nil, errors.ErrorPathf("password", "password must be a string but got %T", x)
+		}
+		password = s
+	}
+
+	// the connections are not shared by the different credentials
+	key := fmt.Sprintf("%s:%s/%d", protocolName, addr, r.DB)
+	if password != "" {
+		key = fmt.Sprintf("%s#%x", key, sha256.Sum256([]byte(password)))
+	}
+	conns := ctx.Connections()
+	release := func(c *conn) func(error) {
+		return func(err error) {
+			if !isConnError(err) {
+				return
+			}
+			if conns.Get(key) == c {
+				conns.Remove(key)
+			}
+			c.Close()
+		}
+	}
+	if conns != nil {
+		if c, ok := conns.Get(key).(*conn); ok {
+			return c, release(c), nil
+		}
+	}
+	c, err := dial(ctx.RequestContext(), addr, password, r.DB)
+	if err != nil {
+		return nil, nil, errors.WrapPathf(err, "addr", "failed to connect to %s", addr)
+	}
+	if conns == nil {
+		return c, func(error) { c.Close() }, nil
+	}
+	if err := conns.Add(key, c); err != nil {
+		c.Close()
+		return nil, nil, errors.WithPath(err, "addr")
+	}
+	return c, release(c), nil
+}
+
+// isConnError reports whether err is the error of the connection such as a timeout instead of the error reply.
+func isConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var e replyError
+	return !errors.As(err, &e)
+}
+
+// buildArgs returns the arguments of the command as strings.
+func (r *Request) buildArgs(ctx *context.Context) ([]string, error) {
+	args := make([]string, len(r.Args))
+	for i, arg := range r.Args {
+		x, err := ctx.ExecuteTemplate(arg)
+		if err != nil {
+			return nil, errors.WithPath(err, fmt.Sprintf("args[%d]", i))
+		}
+		switch v := x.(type) {
+		case string:
+			args[i] = v
+		case []byte:
+			args[i] = string(v)
+		case float64:
+			args[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, bool:
+			args[i] = fmt.Sprint(v)
+		default:
+			return nil, errors.ErrorPathf(fmt.Sprintf("args[%d]", i), "argument must be a scalar value but got %T", x)
+		}
+	}
+	return args, nil
+}
+
+// structureReply converts the flat field-value reply into a map to make it easy to assert.
+func structureReply(cmd string, reply interface{}) interface{} {
+	if cmd != "HGETALL" && cmd != "CONFIG" {
+		return reply
+	}
+	vs, ok := reply.([]interface{})
+	if !ok || len(vs)%2 != 0 {
+		return reply
+	}
+	m := make(map[string]interface{}, len(vs)/2)
+	for i := 0; i < len(vs); i += 2 {
+		k, ok := vs[i].(string)
+		if !ok {
+			return reply
+		}
+		m[k] = vs[i+1]
+	}
+	return m
+}
@@ -0,0 +1,381 @@
+package redis
+
+import (
+	"bufio"
+	gocontext "context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+// fakeServer is the Redis server which supports a few commands.
+type fakeServer struct {
+	mu      sync.Mutex
+	strings map[string]string
+	hashes  map[string]map[string]string
+	conns   int
+}
+
+func startFakeServer(t *testing.T, password string) (string, *fakeServer) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	t.Cleanup(func() { lis.Close() })
+	s := &fakeServer{
+		strings: map[string]string{},
+		hashes:  map[string]map[string]string{},
+	}
+	go func() {
+		for {
+			c, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+			go s.serve(c, password)
+		}
+	}()
+	return lis.Addr().String(), s
+}
+
+func (s *fakeServer) serve(c net.Conn, password string) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	authenticated := password == ""
+	for {
+		v, err := readReply(r)
+		if err != nil {
+			return
+		}
+		vs, _ := v.([]interface{})
+		args := make([]string, len(vs))
+		for i, v := range vs {
+			args[i], _ = v.(string)
+		}
+		if len(args) == 0 {
+			return
+		}
+		cmd := strings.ToUpper(args[0])
+		if cmd == "SLOW" {
+			time.Sleep(100 * time.Millisecond) // replies after the client gives up
+		}
+		if !authenticated && cmd != "AUTH" {
+			fmt.Fprint(c, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		fmt.Fprint(c, s.exec(cmd, args[1:], password, &authenticated))
+	}
+}
+
+func (s *fakeServer) exec(cmd string, args []string, password string, authenticated *bool) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch cmd {
+	case "AUTH":
+		if args[0] != password {
+			return "-WRONGPASS invalid password\r\n"
+		}
+		*authenticated = true
+		return "+OK\r\n"
+	case "SELECT", "EXPIRE":
+		return ":1\r\n"
+	case "SET":
+		s.strings[args[0]] = args[1]
+		return "+OK\r\n"
+	case "GET":
+		v, ok := s.strings[args[0]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	case "HSET":
+		h, ok := s.hashes[args[0]]
+		if !ok {
+			h = map[string]string{}
+			s.hashes[args[0]] = h
+		}
+		for i := 1; i+1 < len(args); i += 2 {
+			h[args[i]] = args[i+1]
+		}
+		return fmt.Sprintf(":%d\r\n", (len(args)-1)/2)
+	case "HGETALL":
+		h := s.hashes[args[0]]
+		keys := make([]string, 0, len(h))
+		for k := range h {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var b strings.Builder
+		fmt.Fprintf(&b, "*%d\r\n", len(keys)*2)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "$%d\r\n%s\r\n$%d\r\n%s\r\n", len(k), k, len(h[k]), h[k])
+		}
+		return b.String()
+	case "SLOW":
+		return "+late\r\n"
+	case "LRANGE":
+		return "*2\r\n$1\r\na\r\n$1\r\nb\r\n"
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", cmd)
+}
+
+func TestRequest_Invoke(t *testing.T) {
+	addr, s := startFakeServer(t, "secret")
+	ctx := context.FromT(t).WithVars(map[string]interface{}{
+		"addr":     addr,
+		"password": "secret",
+		"ttl":      60,
+	}).WithConnections(context.NewConnections())
+	defer ctx.Connections().Close()
+
+	tests := []struct {
+		request *Request
+		expect  response
+	}{
+		{
+			request: &Request{Command: "set", Args: []interface{}{"name", "alice"}},
+			expect:  response{Reply: "OK"},
+		},
+		{
+			request: &Request{Command: "GET", Args: []interface{}{"name"}},
+			expect:  response{Reply: "alice"},
+		},
+		{
+			request: &Request{Command: "GET", Args: []interface{}{"unknown"}},
+			expect:  response{Reply: nil},
+		},
+		{
+			request: &Request{Command: "EXPIRE", Args: []interface{}{"name", "{{vars.ttl}}"}},
+			expect:  response{Reply: int64(1)},
+		},
+		{
+			request: &Request{Command: "HSET", Args: []interface{}{"user", "name", "bob", "age", 20}},
+			expect:  response{Reply: int64(2)},
+		},
+		{
+			request: &Request{Command: "HGETALL", Args: []interface{}{"user"}},
+			expect: response{Reply: map[string]interface{}{
+				"name": "bob",
+				"age":  "20",
+			}},
+		},
+		{
+			request: &Request{Command: "LRANGE", Args: []interface{}{"list", 0, -1}},
+			expect:  response{Reply: []interface{}{"a", "b"}},
+		},
+		{
+			request: &Request{Command: "UNKNOWN"},
+			expect:  response{Error: "ERR unknown command 'UNKNOWN'"},
+		},
+	}
+	for i, test := range tests {
+		test.request.Addr = "{{vars.addr}}"
+		test.request.Password = "{{vars.password}}"
+		test.request.DB = 1
+		_, result, err := test.request.Invoke(ctx)
+		if err != nil {
+			t.Fatalf("[%d] unexpected error: %s", i, err)
+		}
+		if diff := cmp.Diff(test.expect, result); diff != "" {
+			t.Errorf("[%d] differs (-want +got):\n%s", i, diff)
+		}
+	}
+	s.mu.Lock()
+	if s.conns != 1 {
+		t.Errorf("expected 1 connection but got %d", s.conns)
+	}
+	s.mu.Unlock()
+
+	t.Run("template", func(t *testing.T) {
+		r := &Request{Addr: addr, Password: "secret", Command: "HGETALL", Args: []interface{}{"user"}}
+		ctx, _, err := r.Invoke(context.FromT(t))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		got, err := ctx.ExecuteTemplate("{{response.reply.name}}")
+		if err != nil {
+			t.Fatalf("failed to execute template: %s", err)
+		}
+		if got != "bob" {
+			t.Errorf(`expected "bob" but got %v`, got)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		tests := map[string]struct {
+			request     *Request
+			expectError string
+		}{
+			"no command": {
+				request:     &Request{Addr: addr},
+				expectError: ".command: command must be specified",
+			},
+			"invalid argument": {
+				request: &Request{
+					Addr:    addr,
+					Command: "SET",
+					Args:    []interface{}{"key", []interface{}{1}},
+				},
+				expectError: ".args[1]: argument must be a scalar value",
+			},
+			"wrong password": {
+				request: &Request{
+					Addr:     addr,
+					Password: "wrong",
+					Command:  "GET",
+					Args:     []interface{}{"name"},
+				},
+				expectError: "failed to authenticate: WRONGPASS invalid password",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				_, _, err := test.request.Invoke(context.FromT(t))
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("expected error %q but got %q", test.expectError, err)
+				}
+			})
+		}
+	})
+}
+
+func TestRequest_Invoke_ReuseConnection(t *testing.T) {
+	t.Run("discard the connection after a timeout", func(t *testing.T) {
+		addr, s := startFakeServer(t, "")
+		ctx := context.FromT(t).WithConnections(context.NewConnections())
+		defer ctx.Connections().Close()
+
+		reqCtx, cancel := gocontext.WithTimeout(ctx.RequestContext(), 20*time.Millisecond)
+		defer cancel()
+		if _, _, err := (&Request{Addr: addr, Command: "SLOW"}).Invoke(ctx.WithRequestContext(reqCtx)); err == nil {
+			t.Fatal("no error")
+		}
+		if _, _, err := (&Request{Addr: addr, Command: "SET", Args: []interface{}{"key", "value"}}).Invoke(ctx); err != nil {
+			t.Fatalf("failed to invoke: %s", err)
+		}
+		_, resp, err := (&Request{Addr: addr, Command: "GET", Args: []interface{}{"key"}}).Invoke(ctx)
+		if err != nil {
+			t.Fatalf("failed to invoke: %s", err)
+		}
+		if got := resp.(response).Reply; got != "value" {
+			t.Errorf(`expected "value" but got %v`, got)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.conns != 2 {
+			t.Errorf("expected 2 connections but got %d", s.conns)
+		}
+	})
+	t.Run("keep the connection after an error reply", func(t *testing.T) {
+		addr, s := startFakeServer(t, "")
+		ctx := context.FromT(t).WithConnections(context.NewConnections())
+		defer ctx.Connections().Close()
+
+		for _, cmd := range []string{"UNKNOWN", "GET"} {
+			if _, _, err := (&Request{Addr: addr, Command: cmd, Args: []interface{}{"key"}}).Invoke(ctx); err != nil {
+				t.Fatalf("failed to invoke: %s", err)
+			}
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.conns != 1 {
+			t.Errorf("expected 1 connection but got %d", s.conns)
+		}
+	})
+	t.Run("don't share the connection by different passwords", func(t *testing.T) {
+		addr, _ := startFakeServer(t, "secret")
+		ctx := context.FromT(t).WithConnections(context.NewConnections())
+		defer ctx.Connections().Close()
+
+		if _, _, err := (&Request{Addr: addr, Password: "secret", Command: "GET", Args: []interface{}{"key"}}).Invoke(ctx); err != nil {
+			t.Fatalf("failed to invoke: %s", err)
+		}
+		_, _, err := (&Request{Addr: addr, Password: "wrong", Command: "GET", Args: []interface{}{"key"}}).Invoke(ctx)
+		if err == nil {
+			t.Fatal("no error")
+		}
+		if expect := "WRONGPASS invalid password"; !strings.Contains(err.Error(), expect) {
+			t.Errorf("expected error %q but got %q", expect, err)
+		}
+	})
+}
+
+func TestExpect_Build(t *testing.T) {
+	tests := map[string]struct {
+		expect      *Expect
+		response    response
+		expectError string
+	}{
+		"hash": {
+			expect: &Expect{
+				Reply: yaml.MapSlice{
+					{Key: "name", Value: "bob"},
+				},
+			},
+			response: response{Reply: map[string]interface{}{"name": "bob", "age": "20"}},
+		},
+		"list length": {
+			expect: &Expect{
+				Reply: "{{assert.length(2)}}",
+			},
+			response: response{Reply: []interface{}{"a", "b"}},
+		},
+		"expected error reply": {
+			expect: &Expect{
+				Error: `{{assert.regexp("^ERR")}}`,
+			},
+			response: response{Error: "ERR unknown command"},
+		},
+		"unexpected error reply": {
+			expect:      &Expect{},
+			response:    response{Error: "ERR unknown command"},
+			expectError: ".error: unexpected error reply: ERR unknown command",
+		},
+		"reply mismatch": {
+			expect: &Expect{
+				Reply: "alice",
+			},
+			response:    response{Reply: "bob"},
+			expectError: ".reply",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assertion, err := test.expect.Build(context.FromT(t))
+			if err != nil {
+				t.Fatalf("failed to build assertion: %s", err)
+			}
+			err = assertion.Assert(test.response)
+			if test.expectError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), test.expectError) {
+				t.Errorf("expected error %q but got %q", test.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package websocket
+
+import (
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// Expect represents expected response values.
+type Expect struct {
+	Type    string      `yaml:"type,omitempty"` // text or binary
+	Message interface{} `yaml:"message,omitempty"`
+}
+
+// Build implements protocol.AssertionBuilder interface.
+func (e *Expect) Build(ctx *context.Context) (assert.Assertion, error) {
+	var typeAssertion assert.Assertion
+	if e.Type != "" {
+		if e.Type != frameTypeText && e.Type != frameTypeBinary {
+			return nil, errors.ErrorPathf("type", `type must be "text" or "binary" but got %q`, e.Type)
+		}
+		typeAssertion = assert.Equal(e.Type)
+	}
+
+	msgAssertion, err := assert.Build(ctx.RequestContext(), e.Message, assert.FromTemplate(ctx))
+	if err != nil {
+		return nil, errors.WrapPathf(err, "message", "invalid expect message")
+	}
+
+	return assert.AssertionFunc(func(v interface{}) error {
+		res, ok := v.(response)
+		if !ok {
+			return errors.Errorf("expected response but got %T", v)
+		}
+		if typeAssertion != nil {
+			if err := typeAssertion.Assert(res.Type); err != nil {
+				return errors.WithPath(err, "type")
+			}
+		}
+		if err := msgAssertion.Assert(res.Message); err != nil {
+			return errors.WithPath(err, "message")
+		}
+		return nil
+	}), nil
+}
@@ -0,0 +1,350 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"golang.org/x/net/websocket"
+
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
+)
+
+const (
+	actionConnect = "connect"
+	actionSend    = "send"
+	actionReceive = "receive"
+	actionClose   = "close"
+
+	defaultConnection = "default"
+	defaultTimeout    = 10 * time.Second
+
+	frameTypeText   = "text"
+	frameTypeBinary = "binary"
+
+	indentNum = 2
+)
+
+// Request represents a request.
+type Request struct {
+	// Action is one of connect, send, receive, and close.
+	Action     string `yaml:"action"`
+	Connection string `yaml:"connection,omitempty"` // default value is "default"
+
+	// connect
+	URL    string      `yaml:"url,omitempty"`
+	Header interface{} `yaml:"header,omitempty"`
+	Origin string      `yaml:"origin,omitempty"` // default value is generated from url
+
+	// send
+	Message interface{} `yaml:"message,omitempty"`
+	Binary  bool        `yaml:"binary,omitempty"`
+
+	// receive
+	Timeout string      `yaml:"timeout,omitempty"` // default value is 10s
+	Match   interface{} `yaml:"match,omitempty"`   // skip messages until a message satisfies the assertion
+}
+
+type response struct {
+	Type    string      `yaml:"type,omitempty"`
+	Message interface{} `yaml:"message,omitempty"`
+}
+
+// frame represents a received WebSocket frame.
+type frame struct {
+	payloadType byte
+	data        []byte
+}
+
+var frameCodec = websocket.Codec{
+	Marshal: func(v interface{}) ([]byte, byte, error) {
+		f, ok := v.(*frame)
+		if !ok {
+			return nil, websocket.UnknownFrame, websocket.ErrNotSupported
+		}
+		return f.data, f.payloadType, nil
+	},
+	Unmarshal: func(data []byte, payloadType byte, v interface{}) error {
+		f, ok := v.(*frame)
+		if !ok {
+			return websocket.ErrNotSupported
+		}
+		f.payloadType = payloadType
+		f.data = data
+		return nil
+	},
+}
+
+func (r *Request) addIndent(s string, indentNum int) string {
+	indent := strings.Repeat(" ", indentNum)
+	lines := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		if line == "" {
+			lines = append(lines, line)
+		} else {
+			lines = append(lines, fmt.Sprintf("%s%s", indent, line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Invoke implements protocol.Invoker interface.
+func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, error) {
+	conns := ctx.Connections()
+	if conns == nil {
+		return ctx, nil, errors.New("connections are not available in this context")
+	}
+	name := r.Connection
+	if name == "" {
+		name = defaultConnection
+	}
+	key := fmt.Sprintf("%s:%s", protocolName, name)
+
+	switch r.Action {
+	case actionConnect:
+		return r.connect(ctx, conns, key)
+	case actionSend:
+		ws, err := getConn(conns, key, name)
+		if err != nil {
+			return ctx, nil, err
+		}
+		return r.send(ctx, ws)
+	case actionReceive:
+		ws, err := getConn(conns, key, name)
+		if err != nil {
+			return ctx, nil, err
+		}
+		return r.receive(ctx, ws)
+	case actionClose:
+		conn := conns.Remove(key)
+		if conn == nil {
+			return ctx, nil, errors.ErrorPathf("connection", "connection %q is not found", name)
+		}
+		if err := conn.Close(); err != nil {
+			return ctx, nil, errors.Errorf("failed to close connection: %s", err)
+		}
+		return ctx, response{}, nil
+	case "":
+		return ctx, nil, errors.ErrorPath("action", "action must be specified")
+	default:
+		return ctx, nil, errors.ErrorPathf("action", `unknown action %q: must be one of "connect", "send", "receive", and "close"`, r.Action)
+	}
+}
+
+func getConn(conns *context.Connections, key, name string) (*websocket.Conn, error) {
+	ws, ok := conns.Get(key).(*websocket.Conn)
+	if !ok {
+		return nil, errors.ErrorPathf("connection", "connection %q is not found: connect before sending or receiving messages", name)
+	}
+	return ws, nil
+}
+
+func (r *Request) connect(ctx *context.Context, conns *context.Connections, key string) (*context.Context, interface{}, error) {
+	x, err := ctx.ExecuteTemplate(r.URL)
+	if err != nil {
+		return ctx, nil, errors.WrapPathf(err, "url", "invalid url")
+	}
+	rawURL, ok := x.(string)
+	if !ok {
+		return ctx, nil, errors.ErrorPathf("url", "url must be string but %T", x)
+	}
+	origin := r.Origin
+	if origin == "" {
+		origin, err = defaultOrigin(rawURL)
+		if err != nil {
+			return ctx, nil, errors.WrapPath(err, "url", "invalid url")
+		}
+	}
+	config, err := websocket.NewConfig(rawURL, origin)
+	if err != nil {
+		return ctx, nil, errors.WrapPath(err, "url", "invalid url")
+	}
+	if r.Header != nil {
+		x, err := ctx.ExecuteTemplate(r.Header)
+		if err != nil {
+			return ctx, nil, errors.WrapPathf(err, "header", "invalid header")
+		}
+		header, err := reflectutil.ConvertStringsMap(reflect.ValueOf(x))
+		if err != nil {
+			return ctx, nil, errors.WrapPathf(err, "header", "invalid header")
+		}
+		for k, vs := range header {
+			for _, v := range vs {
+				config.Header.Add(k, v)
+			}
+		}
+	}
+	config.Dialer = &net.Dialer{}
+	if deadline, ok := ctx.RequestContext().Deadline(); ok {
+		config.Dialer.Deadline = deadline
+	}
+
+	//nolint:exhaustruct
+	if b, err := yaml.Marshal(Request{
+		Action: r.Action,
+		URL:    rawURL,
+		Header: config.Header,
+	}); err == nil {
+		ctx.Reporter().Logf("request:\n%s", r.addIndent(string(b), indentNum))
+	} else {
+		ctx.Reporter().Logf("failed to dump request:\n%s", err)
+	}
+
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		return ctx, nil, errors.Errorf("failed to connect: %s", err)
+	}
+	if err := conns.Add(key, ws); err != nil {
+		ws.Close()
+		return ctx, nil, errors.WithPath(err, "connection")
+	}
+	return ctx, response{}, nil
+}
+
+// defaultOrigin returns the origin generated from the WebSocket URL.
+func defaultOrigin(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	scheme := "http"
+	if u.Scheme == "wss" || u.Scheme == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, u.Host), nil
+}
+
+func (r *Request) send(ctx *context.Context, ws *websocket.Conn) (*context.Context, interface{}, error) {
+	x, err := ctx.ExecuteTemplate(r.Message)
+	if err != nil {
+		return ctx, nil, errors.WrapPathf(err, "message", "invalid message")
+	}
+	var data []byte
+	switch v := x.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		data, err = json.Marshal(v)
+		if err != nil {
+			return ctx, nil, errors.WrapPathf(err, "message", "failed to encode message as JSON")
+		}
+	}
+
+	ctx = ctx.WithRequest(x)
+	//nolint:exhaustruct
+	if b, err := yaml.Marshal(Request{
+		Action:  r.Action,
+		Message: x,
+		Binary:  r.Binary,
+	}); err == nil {
+		ctx.Reporter().Logf("request:\n%s", r.addIndent(string(b), indentNum))
+	} else {
+		ctx.Reporter().Logf("failed to dump request:\n%s", err)
+	}
+
+	if deadline, ok := ctx.RequestContext().Deadline(); ok {
+		if err := ws.SetWriteDeadline(deadline); err != nil {
+			return ctx, nil, errors.Errorf("failed to set deadline: %s", err)
+		}
+		defer ws.SetWriteDeadline(time.Time{}) //nolint:errcheck
+	}
+	f := &frame{payloadType: websocket.TextFrame, data: data}
+	if r.Binary {
+		f.payloadType = websocket.BinaryFrame
+	}
+	if err := frameCodec.Send(ws, f); err != nil {
+		return ctx, nil, errors.Errorf("failed to send message: %s", err)
+	}
+	return ctx, response{}, nil
+}
+
+func (r *Request) receive(ctx *context.Context, ws *websocket.Conn) (*context.Context, interface{}, error) {
+	timeout := defaultTimeout
+	if r.Timeout != "" {
+		d, err := time.ParseDuration(r.Timeout)
+		if err != nil {
+			return ctx, nil, errors.WrapPath(err, "timeout", "invalid timeout")
+		}
+		timeout = d
+	}
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.RequestContext().Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	var match assert.Assertion
+	if r.Match != nil {
+		var err error
+		match, err = assert.Build(ctx.RequestContext(), r.Match, assert.FromTemplate(ctx))
+		if err != nil {
+			return ctx, nil, errors.WrapPathf(err, "match", "invalid match")
+		}
+	}
+	if err := ws.SetReadDeadline(deadline); err != nil {
+		return ctx, nil, errors.Errorf("failed to set deadline: %s", err)
+	}
+	defer ws.SetReadDeadline(time.Time{}) //nolint:errcheck
+
+	for {
+		var f frame
+		if err := frameCodec.Receive(ws, &f); err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				if match != nil {
+					return ctx, nil, errors.Errorf("no message satisfied the match within %s", timeout)
+				}
+				return ctx, nil, errors.Errorf("no message received within %s", timeout)
+			}
+			return ctx, nil, errors.Errorf("failed to receive message: %s", err)
+		}
+		resp := response{
+			Type:    frameTypeText,
+			Message: decodeMessage(f.data),
+		}
+		if f.payloadType == websocket.BinaryFrame {
+			resp.Type = frameTypeBinary
+			resp.Message = f.data
+		}
+		if match != nil {
+			if err := match.Assert(resp.Message); err != nil {
+				ctx.Reporter().Logf("skip the message which doesn't satisfy the match:\n%s", r.addIndent(dump(resp), indentNum))
+				continue
+			}
+		}
+		ctx = ctx.WithResponse(resp.Message)
+		ctx.Reporter().Logf("response:\n%s", r.addIndent(dump(resp), indentNum))
+		return ctx, resp, nil
+	}
+}
+
+// decodeMessage decodes the text message as JSON if possible.
+func decodeMessage(data []byte) interface{} {
+	if !json.Valid(data) {
+		return string(data)
+	}
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return string(data)
+	}
+	return v
+}
+
+func dump(resp response) string {
+	b, err := yaml.Marshal(resp)
+	if err != nil {
+		return fmt.Sprintf("failed to dump response: %s", err)
+	}
+	return string(b)
+}
@@ -0,0 +1,271 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/net/websocket"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+func startServer(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		if got := ws.Request().Header.Get("Authorization"); got != "Bearer xxxxx" {
+			_ = websocket.Message.Send(ws, "unauthorized")
+			return
+		}
+		for {
+			var f frame
+			if err := frameCodec.Receive(ws, &f); err != nil {
+				return
+			}
+			if f.payloadType == websocket.BinaryFrame {
+				_ = frameCodec.Send(ws, &f)
+				continue
+			}
+			var msg map[string]string
+			if err := json.Unmarshal(f.data, &msg); err != nil {
+				_ = websocket.Message.Send(ws, "invalid message")
+				continue
+			}
+			// send a notification before the reply to test match
+			_ = websocket.Message.Send(ws, `{"type":"notification"}`)
+			b, _ := json.Marshal(map[string]string{"type": "reply", "message": msg["message"]})
+			_ = websocket.Message.Send(ws, string(b))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestRequest_Invoke(t *testing.T) {
+	url := startServer(t)
+	header := map[string]string{"Authorization": "Bearer xxxxx"}
+
+	t.Run("success", func(t *testing.T) {
+		tests := map[string]struct {
+			reqs   []*Request
+			expect response
+		}{
+			"receive the next message": {
+				reqs: []*Request{
+					{Action: actionConnect, URL: url, Header: header},
+					{Action: actionSend, Message: map[string]string{"message": "hello"}},
+					{Action: actionReceive},
+				},
+				expect: response{
+					Type:    frameTypeText,
+					Message: map[string]interface{}{"type": "notification"},
+				},
+			},
+			"receive the message which satisfies the match": {
+				reqs: []*Request{
+					{Action: actionConnect, URL: url, Header: header},
+					{Action: actionSend, Message: `{"message": "{{vars.message}}"}`},
+					{Action: actionReceive, Match: yaml.MapSlice{{Key: "type", Value: "reply"}}},
+				},
+				expect: response{
+					Type:    frameTypeText,
+					Message: map[string]interface{}{"type": "reply", "message": "hello"},
+				},
+			},
+			"binary": {
+				reqs: []*Request{
+					{Action: actionConnect, Connection: "bin", URL: url, Header: header},
+					{Action: actionSend, Connection: "bin", Message: "hello", Binary: true},
+					{Action: actionReceive, Connection: "bin", Timeout: "1s"},
+				},
+				expect: response{
+					Type:    frameTypeBinary,
+					Message: []byte("hello"),
+				},
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				conns := context.NewConnections()
+				defer conns.Close()
+				ctx := context.FromT(t).WithConnections(conns).WithVars(map[string]string{"message": "hello"})
+				var resp interface{}
+				for i, req := range test.reqs {
+					var err error
+					ctx, resp, err = req.Invoke(ctx)
+					if err != nil {
+						t.Fatalf("[%d] unexpected error: %s", i, err)
+					}
+				}
+				if diff := cmp.Diff(test.expect, resp, cmp.AllowUnexported(response{})); diff != "" {
+					t.Errorf("response differs (-want +got):\n%s", diff)
+				}
+				if diff := cmp.Diff(test.expect.Message, ctx.Response()); diff != "" {
+					t.Errorf("context response differs (-want +got):\n%s", diff)
+				}
+
+				close := &Request{Action: actionClose, Connection: test.reqs[0].Connection}
+				if _, _, err := close.Invoke(ctx); err != nil {
+					t.Fatalf("failed to close: %s", err)
+				}
+				if _, _, err := close.Invoke(ctx); err == nil {
+					t.Fatal("no error")
+				}
+			})
+		}
+	})
+	t.Run("failure", func(t *testing.T) {
+		tests := map[string]struct {
+			reqs        []*Request
+			expectError string
+		}{
+			"no action": {
+				reqs:        []*Request{{}},
+				expectError: "action must be specified",
+			},
+			"unknown action": {
+				reqs:        []*Request{{Action: "open"}},
+				expectError: `unknown action "open"`,
+			},
+			"not connected": {
+				reqs:        []*Request{{Action: actionSend, Message: "hello"}},
+				expectError: `connection "default" is not found`,
+			},
+			"already connected": {
+				reqs: []*Request{
+					{Action: actionConnect, URL: url, Header: header},
+					{Action: actionConnect, URL: url, Header: header},
+				},
+				expectError: `connection "websocket:default" already exists`,
+			},
+			"failed to connect": {
+				reqs:        []*Request{{Action: actionConnect, URL: "ws://127.0.0.1:0"}},
+				expectError: "failed to connect",
+			},
+			"invalid timeout": {
+				reqs: []*Request{
+					{Action: actionConnect, URL: url, Header: header},
+					{Action: actionReceive, Timeout: "1"},
+				},
+				expectError: "invalid timeout",
+			},
+			"timeout": {
+				reqs: []*Request{
+					{Action: actionConnect, URL: url, Header: header},
+					{Action: actionReceive, Timeout: "10ms"},
+				},
+				expectError: "no message received within 10ms",
+			},
+			"no message satisfies the match": {
+				reqs: []*Request{
+					{Action: actionConnect, URL: url, Header: header},
+					{Action: actionSend, Message: map[string]string{"message": "hello"}},
+					{Action: actionReceive, Timeout: "100ms", Match: yaml.MapSlice{{Key: "type", Value: "error"}}},
+				},
+				expectError: "no message satisfied the match within 100ms",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				conns := context.NewConnections()
+				defer conns.Close()
+				ctx := context.FromT(t).WithConnections(conns)
+				var err error
+				for _, req := range test.reqs {
+					ctx, _, err = req.Invoke(ctx)
+					if err != nil {
+						break
+					}
+				}
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("expected error %q but got %q", test.expectError, err)
+				}
+			})
+		}
+	})
+	t.Run("no connections", func(t *testing.T) {
+		req := &Request{Action: actionConnect, URL: url}
+		if _, _, err := req.Invoke(context.FromT(t)); err == nil {
+			t.Fatal("no error")
+		}
+	})
+}
+
+func TestExpect_Build(t *testing.T) {
+	tests := map[string]struct {
+		expect      *Expect
+		response    response
+		expectError string
+	}{
+		"default": {
+			expect:   &Expect{},
+			response: response{},
+		},
+		"message": {
+			expect: &Expect{
+				Type:    frameTypeText,
+				Message: yaml.MapSlice{{Key: "type", Value: "{{vars.type}}"}},
+			},
+			response: response{
+				Type:    frameTypeText,
+				Message: map[string]interface{}{"type": "reply"},
+			},
+		},
+		"wrong type": {
+			expect: &Expect{
+				Type: frameTypeBinary,
+			},
+			response: response{
+				Type:    frameTypeText,
+				Message: "hello",
+			},
+			expectError: ".type: expected binary but got text",
+		},
+		"wrong message": {
+			expect: &Expect{
+				Message: "hello",
+			},
+			response: response{
+				Type:    frameTypeText,
+				Message: "bye",
+			},
+			expectError: ".message: expected hello but got bye",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctx := context.FromT(t).WithVars(map[string]string{"type": "reply"})
+			assertion, err := test.expect.Build(ctx)
+			if err != nil {
+				t.Fatalf("failed to build assertion: %s", err)
+			}
+			err = assertion.Assert(test.response)
+			if test.expectError == "" && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if test.expectError != "" {
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("expected error %q but got %q", test.expectError, err)
+				}
+			}
+		})
+	}
+	t.Run("invalid type", func(t *testing.T) {
+		e := &Expect{Type: "json"}
+		if _, err := e.Build(context.FromT(t)); err == nil {
+			t.Fatal("no error")
+		}
+	})
+}
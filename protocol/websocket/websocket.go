@@ -0,0 +1,56 @@
+// Package websocket provides the WebSocket protocol for the scenarigo step.
+package websocket
+
+import (
+	"bytes"
+
+	"github.com/goccy/go-yaml"
+	"github.com/zoncoen/scenarigo/protocol"
+)
+
+const protocolName = "websocket"
+
+// Register registers websocket protocol.
+func Register() {
+	protocol.Register(&WebSocket{})
+}
+
+// WebSocket is a protocol type for the scenarigo step.
+type WebSocket struct{}
+
+// Name implements protocol.Protocol interface.
+func (p *WebSocket) Name() string {
+	return protocolName
+}
+
+// UnmarshalRequest implements protocol.Protocol interface.
+func (p *WebSocket) UnmarshalRequest(b []byte) (protocol.Invoker, error) {
+	var r Request
+	if err := yaml.UnmarshalWithOptions(b, &r, yaml.Strict()); err != nil {
+		return nil, err
+	}
+
+	// decode match as an ordered map to build the assertion in the same way as expect
+	var m struct {
+		Match interface{} `yaml:"match"`
+	}
+	if err := yaml.UnmarshalWithOptions(b, &m, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	r.Match = m.Match
+
+	return &r, nil
+}
+
+// UnmarshalExpect implements protocol.Protocol interface.
+func (p *WebSocket) UnmarshalExpect(b []byte) (protocol.AssertionBuilder, error) {
+	var e Expect
+	if b == nil {
+		return &e, nil
+	}
+	decoder := yaml.NewDecoder(bytes.NewBuffer(b), yaml.UseOrderedMap(), yaml.Strict())
+	if err := decoder.Decode(&e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
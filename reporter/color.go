@@ -0,0 +1,48 @@
+package reporter
+
+import (
+	"github.com/fatih/color"
+)
+
+// ColorEnabled reports whether the colored output is enabled by default.
+// It is disabled when the output is not a terminal or a NO_COLOR environment variable is set.
+func ColorEnabled() bool {
+	return !color.NoColor
+}
+
+// palette provides the colors of the outputs.
+// All colors are disabled if disabled is true.
+type palette struct {
+	disabled bool
+}
+
+func (p palette) color(attrs ...color.Attribute) *color.Color {
+	if p.disabled {
+		return color.New()
+	}
+	return color.New(attrs...)
+}
+
+func (p palette) pass() *color.Color {
+	return p.color(color.FgGreen)
+}
+
+func (p palette) fail() *color.Color {
+	return p.color(color.FgHiRed)
+}
+
+func (p palette) skip() *color.Color {
+	return p.color(color.FgYellow)
+}
+
+func (p palette) path() *color.Color {
+	return p.color(color.Bold)
+}
+
+func (p palette) expected() *color.Color {
+	return p.color(color.FgGreen)
+}
+
+func (p palette) actual() *color.Color {
+	return p.color(color.FgHiRed)
+}
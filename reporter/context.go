@@ -24,10 +24,28 @@ func WithWriter(w io.Writer) Option {
 	}
 }
 
+// Verbosity represents the verbosity level of the test log.
+type Verbosity int
+
+const (
+	// VerbosityDefault prints the failed tests and their logs.
+	VerbosityDefault Verbosity = iota
+	// VerbosityVerbose also prints the passed tests and their logs such as the request dumps.
+	VerbosityVerbose
+	// VerbosityDebug also prints the query path, the expected value, and the actual value of each assertion error.
+	VerbosityDebug
+)
+
 // WithVerboseLog returns an option to enable verbose log.
+// It is equivalent to WithVerbosity(VerbosityVerbose).
 func WithVerboseLog() Option {
+	return WithVerbosity(VerbosityVerbose)
+}
+
+// WithVerbosity returns an option to set the verbosity level.
+func WithVerbosity(v Verbosity) Option {
 	return func(ctx *testContext) {
-		ctx.verbose = true
+		ctx.verbosity = v
 	}
 }
 
@@ -57,11 +75,14 @@ type testContext struct {
 	// maxParallel is a copy of the parallel flag.
 	maxParallel int
 
-	// verbose indicates that prints verbose log or not.
-	verbose bool
+	// verbosity is the verbosity level of the log.
+	verbosity Verbosity
 
 	noColor bool
 
+	// secrets are masked in the outputs.
+	secrets secrets
+
 	// for FromT
 	matcher *matcher
 }
@@ -107,11 +128,17 @@ func (c *testContext) release() {
 	c.startParallel <- true // Pick a waiting test to be run.
 }
 
+// isVerbose reports whether prints verbose log or not.
+func (c *testContext) isVerbose() bool {
+	return c.verbosity >= VerbosityVerbose
+}
+
 func (c *testContext) printf(format string, a ...interface{}) (int, error) {
 	if c.w == nil {
 		return 0, nil
 	}
-	return fmt.Fprintf(c.w, format, a...)
+	// mask again because the secrets may be added after logging
+	return fmt.Fprint(c.w, c.secrets.mask(fmt.Sprintf(format, a...)))
 }
 
 type nopWriter struct{}
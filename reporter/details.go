@@ -0,0 +1,43 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// StepDetails represents the structured details of a step for the test reports.
+type StepDetails struct {
+	Request  interface{} `json:"request,omitempty"`
+	Response interface{} `json:"response,omitempty"`
+	Diffs    []Diff      `json:"diffs,omitempty"`
+}
+
+// Diff represents the difference between the expected and actual values found by an assertion.
+type Diff struct {
+	Path     string      `json:"path,omitempty"`
+	Matcher  string      `json:"matcher,omitempty"`
+	Expected interface{} `json:"expected,omitempty"`
+	Actual   interface{} `json:"actual,omitempty"`
+}
+
+// SetStepDetails records the structured details of the step which r reports.
+func SetStepDetails(r Reporter, d *StepDetails) {
+	r.setDetails(d)
+}
+
+// formatValue returns the YAML representation of v for the outputs.
+func formatValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimRight(string(b), "\n")
+}
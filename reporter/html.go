@@ -0,0 +1,173 @@
+package reporter
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"time"
+)
+
+//go:embed templates/report.html.tmpl
+var htmlTemplateText string
+
+var (
+	htmlTemplate = template.Must(template.New("report").Parse(htmlTemplateText))
+
+	// ansiEscapeRegexp matches the ANSI escape codes of the colored logs.
+	ansiEscapeRegexp = regexp.MustCompile("\x1b\\[[0-9;]*m")
+)
+
+type htmlReport struct {
+	Result   string
+	Passed   int
+	Failed   int
+	Skipped  int
+	Failures []htmlLink
+	Files    []htmlFile
+}
+
+type htmlLink struct {
+	ID   string
+	Name string
+}
+
+type htmlFile struct {
+	ID        string
+	Name      string
+	Result    string
+	Duration  string
+	Scenarios []htmlScenario
+}
+
+type htmlScenario struct {
+	ID       string
+	Name     string
+	Result   string
+	Duration string
+	Steps    []htmlStep
+}
+
+type htmlStep struct {
+	ID       string
+	Name     string
+	Result   string
+	Duration string
+	Skip     string
+	Errors   []string
+	Logs     []string
+	Request  string
+	Response string
+	Diffs    []htmlDiff
+	SubSteps []htmlStep
+}
+
+type htmlDiff struct {
+	Path     string
+	Matcher  string
+	Expected string
+	Actual   string
+}
+
+// WriteHTML writes r as a self-contained HTML document.
+// The failed scenarios and steps are expanded and listed at the top with the links to them.
+func (r *TestReport) WriteHTML(w io.Writer) error {
+	report := &htmlReport{
+		Result: r.Result.String(),
+	}
+	for i, file := range r.Files {
+		f := htmlFile{
+			ID:       fmt.Sprintf("file-%d", i),
+			Name:     file.Name,
+			Result:   file.Result.String(),
+			Duration: htmlDuration(file.Duration),
+		}
+		for j, scenario := range file.Scenarios {
+			s := htmlScenario{
+				ID:       fmt.Sprintf("%s-scenario-%d", f.ID, j),
+				Name:     scenario.Name,
+				Result:   scenario.Result.String(),
+				Duration: htmlDuration(scenario.Duration),
+			}
+			switch scenario.Result {
+			case TestResultPassed:
+				report.Passed++
+			case TestResultFailed:
+				report.Failed++
+			case TestResultSkipped:
+				report.Skipped++
+			default:
+			}
+			for k, step := range scenario.Steps {
+				id := fmt.Sprintf("%s-step-%d", s.ID, k)
+				name := fmt.Sprintf("%s / %s / %s", file.Name, scenario.Name, step.Name)
+				s.Steps = append(s.Steps, report.step(id, name, step.Name, step.Result, step.Duration, step.Logs, step.Details, step.SubSteps))
+			}
+			f.Scenarios = append(f.Scenarios, s)
+		}
+		report.Files = append(report.Files, f)
+	}
+	return htmlTemplate.Execute(w, report)
+}
+
+func (r *htmlReport) step(id, fullName, name string, result TestResult, d TestDuration, logs ReportLogs, details *StepDetails, subSteps []SubStepReport) htmlStep {
+	s := htmlStep{
+		ID:       id,
+		Name:     name,
+		Result:   result.String(),
+		Duration: htmlDuration(d),
+		Errors:   stripANSI(logs.Error),
+		Logs:     stripANSI(logs.Info),
+	}
+	if logs.Skip != nil {
+		s.Skip = ansiEscapeRegexp.ReplaceAllString(*logs.Skip, "")
+	}
+	if details != nil {
+		s.Request = formatValue(details.Request)
+		s.Response = formatValue(details.Response)
+		for _, diff := range details.Diffs {
+			s.Diffs = append(s.Diffs, htmlDiff{
+				Path:     diff.Path,
+				Matcher:  diff.Matcher,
+				Expected: formatValue(diff.Expected),
+				Actual:   formatValue(diff.Actual),
+			})
+		}
+	}
+	// link to the innermost failed steps
+	if result == TestResultFailed && !hasFailedSubStep(subSteps) {
+		r.Failures = append(r.Failures, htmlLink{ID: id, Name: fullName})
+	}
+	for i, sub := range subSteps {
+		s.SubSteps = append(s.SubSteps, r.step(
+			fmt.Sprintf("%s-%d", id, i), fmt.Sprintf("%s / %s", fullName, sub.Name), sub.Name,
+			sub.Result, sub.Duration, sub.Logs, sub.Details, sub.SubSteps,
+		))
+	}
+	return s
+}
+
+func hasFailedSubStep(subSteps []SubStepReport) bool {
+	for _, sub := range subSteps {
+		if sub.Result == TestResultFailed {
+			return true
+		}
+	}
+	return false
+}
+
+func htmlDuration(d TestDuration) string {
+	return time.Duration(d).Round(time.Microsecond).String()
+}
+
+func stripANSI(strs []string) []string {
+	if strs == nil {
+		return nil
+	}
+	stripped := make([]string, len(strs))
+	for i, s := range strs {
+		stripped[i] = ansiEscapeRegexp.ReplaceAllString(s, "")
+	}
+	return stripped
+}
@@ -0,0 +1,77 @@
+package reporter
+
+// ParallelTest represents a subtest which is run by RunParallel.
+type ParallelTest struct {
+	Name string
+	F    func(Reporter)
+	// Done is called with the reporter of the subtest after the subtest and all preceding subtests finished.
+	Done func(Reporter)
+}
+
+// RunParallel runs tests as the subtests of r concurrently up to n at a time.
+// The running subtests share the limit of the parallel tests set by WithMaxParallel with their parallel subtests.
+// The subtests are recorded and printed in the order of tests regardless of the completion order.
+// It reports whether all subtests succeeded.
+func RunParallel(r Reporter, n int, tests []ParallelTest) bool {
+	return r.runParallel(n, tests)
+}
+
+func (r *reporter) runParallel(n int, tests []ParallelTest) bool {
+	if n < 1 {
+		n = 1
+	}
+	children := make([]*reporter, len(tests))
+	finished := make([]chan struct{}, len(tests))
+	for i := range tests {
+		finished[i] = make(chan struct{})
+	}
+	sem := make(chan struct{}, n)
+
+	// Release the count for this test while waiting for the subtests like the sequential test which has parallel subtests.
+	r.context.release()
+	go func() {
+		for i, test := range tests {
+			sem <- struct{}{}
+			go func(i int, test ParallelTest) {
+				defer func() {
+					<-sem
+					close(finished[i])
+				}()
+				if !r.context.matcher.match(r.goTestName, rewrite(test.Name)) {
+					return
+				}
+				child := r.spawn(test.Name)
+				if r.context.isVerbose() {
+					r.context.printf("=== RUN   %s\n", child.goTestName)
+				}
+				r.context.waitParallel()
+				go child.run(test.F)
+				<-child.done
+				r.context.release()
+				children[i] = child
+			}(i, test)
+		}
+	}()
+
+	defer r.context.waitParallel()
+
+	ok := true
+	for i, test := range tests {
+		<-finished[i]
+		child := children[i]
+		if child == nil {
+			continue
+		}
+		r.appendChildren(child)
+		if r.isRoot() {
+			printReport(child)
+		}
+		if child.Failed() {
+			ok = false
+		}
+		if test.Done != nil {
+			test.Done(child)
+		}
+	}
+	return ok
+}
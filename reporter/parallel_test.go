@@ -0,0 +1,57 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunParallel(t *testing.T) {
+	var (
+		b    bytes.Buffer
+		ok   bool
+		done []string
+	)
+	Run(func(r Reporter) {
+		ok = RunParallel(r, 3, []ParallelTest{
+			{
+				Name: "a",
+				F: func(r Reporter) {
+					// finish after the other tests
+					time.Sleep(100 * time.Millisecond)
+				},
+				Done: func(r Reporter) { done = append(done, r.Name()) },
+			},
+			{
+				Name: "b",
+				F:    func(r Reporter) {},
+				Done: func(r Reporter) { done = append(done, r.Name()) },
+			},
+			{
+				Name: "c",
+				F: func(r Reporter) {
+					r.Error("error!")
+				},
+				Done: func(r Reporter) { done = append(done, r.Name()) },
+			},
+		})
+	}, WithWriter(&b), WithMaxParallel(3))
+	if ok {
+		t.Error("no failure")
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c"}, done); diff != "" {
+		t.Errorf("done mismatch (-want +got):\n%s", diff)
+	}
+	var names []string
+	for _, l := range strings.Split(b.String(), "\n") {
+		if strings.HasPrefix(l, "ok") || strings.HasPrefix(l, "FAIL\t") {
+			names = append(names, strings.Split(l, "\t")[1])
+		}
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c"}, names); diff != "" {
+		t.Errorf("output order mismatch (-want +got):\n%s\n%s", diff, b.String())
+	}
+}
@@ -24,41 +24,53 @@ func GenerateTestReport(r Reporter) (*TestReport, error) {
 		Result: testResult(r),
 	}
 	for _, file := range r.getChildren() {
-		file := file
-		fileReport := ScenarioFileReport{
-			Name:     file.getName(),
-			Result:   testResult(file),
-			Duration: TestDuration(file.getDuration()),
+		report.Files = append(report.Files, generateScenarioFileReport(file))
+	}
+	return report, nil
+}
+
+// GenerateScenarioFileReport generates a result report of a scenario file from r.
+// It enables to report the results incrementally for each file.
+func GenerateScenarioFileReport(r Reporter) (*ScenarioFileReport, error) {
+	if r == nil {
+		return nil, errors.New("reporter is nil")
+	}
+	if r.isRoot() {
+		return nil, errors.New("must be a reporter of a scenario file")
+	}
+	report := generateScenarioFileReport(r)
+	return &report, nil
+}
+
+func generateScenarioFileReport(file Reporter) ScenarioFileReport {
+	fileReport := ScenarioFileReport{
+		Name:     file.getName(),
+		Result:   testResult(file),
+		Duration: TestDuration(file.getDuration()),
+	}
+	for _, scenario := range file.getChildren() {
+		scenario := scenario
+		scenarioReport := ScenarioReport{
+			Name:     scenario.getName(),
+			File:     file.getName(),
+			Result:   testResult(scenario),
+			Duration: TestDuration(scenario.getDuration()),
 		}
-		for _, scenario := range file.getChildren() {
-			scenario := scenario
-			scenarioReport := ScenarioReport{
-				Name:     scenario.getName(),
-				File:     file.getName(),
-				Result:   testResult(scenario),
-				Duration: TestDuration(scenario.getDuration()),
-			}
-			for _, step := range scenario.getChildren() {
-				step := step
-				logs := step.getLogs()
-				stepReport := StepReport{
-					Name:     step.getName(),
-					Result:   testResult(step),
-					Duration: TestDuration(step.getDuration()),
-					Logs: ReportLogs{
-						Info:  logs.infoLogs(),
-						Error: logs.errorLogs(),
-						Skip:  logs.skipLog(),
-					},
-					SubSteps: generateSubStepReports(step),
-				}
-				scenarioReport.Steps = append(scenarioReport.Steps, stepReport)
+		for _, step := range scenario.getChildren() {
+			step := step
+			stepReport := StepReport{
+				Name:     step.getName(),
+				Result:   testResult(step),
+				Duration: TestDuration(step.getDuration()),
+				Logs:     reportLogs(step),
+				Details:  reportDetails(step),
+				SubSteps: generateSubStepReports(step),
 			}
-			fileReport.Scenarios = append(fileReport.Scenarios, scenarioReport)
+			scenarioReport.Steps = append(scenarioReport.Steps, stepReport)
 		}
-		report.Files = append(report.Files, fileReport)
+		fileReport.Scenarios = append(fileReport.Scenarios, scenarioReport)
 	}
-	return report, nil
+	return fileReport
 }
 
 func generateSubStepReports(r Reporter) []SubStepReport {
@@ -69,22 +81,73 @@ func generateSubStepReports(r Reporter) []SubStepReport {
 	reports := make([]SubStepReport, len(children))
 	for i, child := range children {
 		child := child
-		logs := child.getLogs()
 		reports[i] = SubStepReport{
 			Name:     child.getName(),
 			Result:   testResult(child),
 			Duration: TestDuration(child.getDuration()),
-			Logs: ReportLogs{
-				Info:  logs.infoLogs(),
-				Error: logs.errorLogs(),
-				Skip:  logs.skipLog(),
-			},
+			Logs:     reportLogs(child),
+			Details:  reportDetails(child),
 			SubSteps: generateSubStepReports(child),
 		}
 	}
 	return reports
 }
 
+// reportLogs returns the logs of r whose secret values are masked.
+func reportLogs(r Reporter) ReportLogs {
+	logs := r.getLogs()
+	secrets := r.getSecrets()
+	skip := logs.skipLog()
+	if skip != nil {
+		s := secrets.mask(*skip)
+		skip = &s
+	}
+	return ReportLogs{
+		Info:  secrets.maskAll(logs.infoLogs()),
+		Error: secrets.maskAll(logs.errorLogs()),
+		Skip:  skip,
+	}
+}
+
+// reportDetails returns the details of r which are converted into the plain values with masking the secret values.
+func reportDetails(r Reporter) *StepDetails {
+	d := r.getDetails()
+	if d == nil {
+		return nil
+	}
+	secrets := r.getSecrets()
+	details := &StepDetails{
+		Request:  secrets.maskValue(plainValue(d.Request)),
+		Response: secrets.maskValue(plainValue(d.Response)),
+	}
+	for _, diff := range d.Diffs {
+		details.Diffs = append(details.Diffs, Diff{
+			Path:     secrets.mask(diff.Path),
+			Matcher:  diff.Matcher,
+			Expected: secrets.maskValue(plainValue(diff.Expected)),
+			Actual:   secrets.maskValue(plainValue(diff.Actual)),
+		})
+	}
+	return details
+}
+
+// plainValue converts v into the value which consists of maps, slices, and scalars through YAML.
+// The fields are named by the YAML struct tags same as the logs.
+func plainValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	var plain interface{}
+	if err := yaml.Unmarshal(b, &plain); err != nil {
+		return string(b)
+	}
+	return plain
+}
+
 func TestResultString(r Reporter) string {
 	return testResult(r).String()
 }
@@ -116,6 +179,29 @@ type TestReport struct {
 	Files   []ScenarioFileReport `json:"files" xml:"testsuite"`
 }
 
+// StepTestCases returns a copy of r whose test cases are the steps instead of the scenarios.
+// The name of the test case is the scenario name and the step name joined by "/".
+func (r *TestReport) StepTestCases() *TestReport {
+	report := *r
+	report.Files = make([]ScenarioFileReport, len(r.Files))
+	for i, file := range r.Files {
+		file.Scenarios = make([]ScenarioReport, 0, len(r.Files[i].Scenarios))
+		for _, scenario := range r.Files[i].Scenarios {
+			for _, step := range scenario.Steps {
+				file.Scenarios = append(file.Scenarios, ScenarioReport{
+					Name:     fmt.Sprintf("%s/%s", scenario.Name, step.Name),
+					File:     scenario.File,
+					Result:   step.Result,
+					Duration: step.Duration,
+					Steps:    []StepReport{step},
+				})
+			}
+		}
+		report.Files[i] = file
+	}
+	return &report
+}
+
 // ScenarioFileReport represents a result report of a test scenario file.
 type ScenarioFileReport struct {
 	Name      string           `json:"name" xml:"name,attr,omitempty"`
@@ -179,6 +265,27 @@ type xmlCDATA struct {
 	CDATA string `xml:",cdata"`
 }
 
+// validXMLString replaces the characters which are not allowed in XML documents such as ANSI escape codes.
+// CDATA sections aren't escaped by the encoder, so the characters make the document invalid.
+func validXMLString(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isXMLChar(r) {
+			return r
+		}
+		return '\uFFFD'
+	}, s)
+}
+
+// isXMLChar reports whether r is in the character range of XML 1.0.
+func isXMLChar(r rune) bool {
+	return r == 0x09 ||
+		r == 0x0A ||
+		r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
+
 // MarshalXML implements xml.Marshaler interface.
 func (r ScenarioReport) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	xr := &xmlScenarioReport{
@@ -192,7 +299,7 @@ func (r ScenarioReport) MarshalXML(e *xml.Encoder, start xml.StartElement) error
 			if step.Result == TestResultFailed {
 				if len(step.Logs.Info) > 0 {
 					xr.SystemOut = &xmlCDATA{
-						CDATA: strings.Join(step.Logs.Info, "\n"),
+						CDATA: validXMLString(strings.Join(step.Logs.Info, "\n")),
 					}
 				}
 				xr.Failure = &xmlScenarioReportDetail{
@@ -225,6 +332,7 @@ type StepReport struct {
 	Result   TestResult      `json:"result"`
 	Duration TestDuration    `json:"duration"`
 	Logs     ReportLogs      `json:"logs"`
+	Details  *StepDetails    `json:"details,omitempty"`
 	SubSteps []SubStepReport `json:"subSteps,omitempty"`
 }
 
@@ -240,6 +348,7 @@ type SubStepReport struct {
 	Result   TestResult      `json:"result"`
 	Duration TestDuration    `json:"duration"`
 	Logs     ReportLogs      `json:"logs"`
+	Details  *StepDetails    `json:"details,omitempty"`
 	SubSteps []SubStepReport `json:"subSteps,omitempty"`
 }
 
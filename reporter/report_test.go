@@ -3,6 +3,7 @@ package reporter
 import (
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -473,3 +474,207 @@ func TestTestReport_MarshalXML(t *testing.T) {
 		})
 	}
 }
+
+func TestTestReport_StepTestCases(t *testing.T) {
+	report := &TestReport{
+		Result: TestResultFailed,
+		Files: []ScenarioFileReport{
+			{
+				Name:     "file1.yaml",
+				Result:   TestResultFailed,
+				Duration: TestDuration(23 * time.Millisecond),
+				Scenarios: []ScenarioReport{
+					{
+						Name:     "scenario",
+						File:     "file1.yaml",
+						Result:   TestResultFailed,
+						Duration: TestDuration(23 * time.Millisecond),
+						Steps: []StepReport{
+							{
+								Name:     "passed step",
+								Result:   TestResultPassed,
+								Duration: TestDuration(20 * time.Millisecond),
+							},
+							{
+								Name:     "failed step",
+								Result:   TestResultFailed,
+								Duration: TestDuration(3 * time.Millisecond),
+								Logs: ReportLogs{
+									Info: []string{
+										"\x1b[31m<html>]]></html>\x1b[0m",
+									},
+									Error: []string{
+										`.body: expected "<a & b>" but got "a"`,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	b, err := xml.MarshalIndent(report.StepTestCases(), "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	expected, err := os.ReadFile("testdata/report_steps.xml")
+	if err != nil {
+		t.Fatalf("failed to read: %s", err)
+	}
+	if diff := cmp.Diff(
+		strings.Trim(string(expected), "\n"),
+		string(b),
+	); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+
+	// the document must be valid even if the logs have special characters
+	dec := xml.NewDecoder(strings.NewReader(string(b)))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			t.Fatalf("invalid XML: %s", err)
+		}
+	}
+}
+
+func TestTestReport_WriteTAP(t *testing.T) {
+	skipMsg := "skip # reason"
+	report := &TestReport{
+		Result: TestResultFailed,
+		Files: []ScenarioFileReport{
+			{
+				Name:   "file1.yaml",
+				Result: TestResultFailed,
+				Scenarios: []ScenarioReport{
+					{
+						Name:   "passed scenario",
+						File:   "file1.yaml",
+						Result: TestResultPassed,
+						Steps: []StepReport{
+							{
+								Name:   "passed step",
+								Result: TestResultPassed,
+							},
+						},
+					},
+					{
+						Name:   "failed scenario",
+						File:   "file1.yaml",
+						Result: TestResultFailed,
+						Steps: []StepReport{
+							{
+								Name:     "failed step",
+								Result:   TestResultFailed,
+								Duration: TestDuration(3 * time.Millisecond),
+								Logs: ReportLogs{
+									Error: []string{
+										"expected bye but got hello\n  >  11 |       message: bye",
+									},
+								},
+								Details: &StepDetails{
+									Diffs: []Diff{
+										{
+											Path:     ".body.message",
+											Matcher:  "Equal",
+											Expected: "bye",
+											Actual:   "hello",
+										},
+									},
+								},
+							},
+							{
+								Name:   "skipped step",
+								Result: TestResultSkipped,
+								Logs: ReportLogs{
+									Skip: &skipMsg,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	var b strings.Builder
+	if err := report.WriteTAP(&b); err != nil {
+		t.Fatalf("failed to write: %s", err)
+	}
+	expected, err := os.ReadFile("testdata/report.tap")
+	if err != nil {
+		t.Fatalf("failed to read: %s", err)
+	}
+	if diff := cmp.Diff(string(expected), b.String()); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTestReport_WriteHTML(t *testing.T) {
+	report := &TestReport{
+		Result: TestResultFailed,
+		Files: []ScenarioFileReport{
+			{
+				Name:   "file1.yaml",
+				Result: TestResultFailed,
+				Scenarios: []ScenarioReport{
+					{
+						Name:   "<script>alert(1)</script>",
+						File:   "file1.yaml",
+						Result: TestResultFailed,
+						Steps: []StepReport{
+							{
+								Name:     "failed step",
+								Result:   TestResultFailed,
+								Duration: TestDuration(3 * time.Millisecond),
+								Logs: ReportLogs{
+									Error: []string{"\x1b[31mexpected bye but got hello\x1b[0m"},
+								},
+								Details: &StepDetails{
+									Request: map[string]interface{}{
+										"method": "GET",
+									},
+									Response: map[string]interface{}{
+										"message": "hello",
+									},
+									Diffs: []Diff{
+										{
+											Path:     ".body.message",
+											Matcher:  "Equal",
+											Expected: "bye",
+											Actual:   "hello",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	var b strings.Builder
+	if err := report.WriteHTML(&b); err != nil {
+		t.Fatalf("failed to write: %s", err)
+	}
+	out := b.String()
+	for _, s := range []string{
+		`<a href="#file-0-scenario-0-step-0">`,
+		`<details id="file-0-scenario-0-step-0" class="failed" open>`,
+		`&lt;script&gt;alert(1)&lt;/script&gt;`,
+		`<td>.body.message</td><td>Equal</td><td class="expected"><pre>bye</pre></td><td class="actual"><pre>hello</pre></td>`,
+		`<pre>method: GET</pre>`,
+		`<pre>message: hello</pre>`,
+		`<pre>expected bye but got hello</pre>`,
+		`3ms`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("%q not found in the output:\n%s", s, out)
+		}
+	}
+	if strings.Contains(out, "<script>") {
+		t.Errorf("script tag is not escaped:\n%s", out)
+	}
+}
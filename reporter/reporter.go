@@ -16,7 +16,6 @@ import (
 	"unicode"
 
 	"github.com/cenkalti/backoff/v4"
-	"github.com/fatih/color"
 )
 
 // A Reporter is something that can be used to report test results.
@@ -38,13 +37,18 @@ type Reporter interface {
 	Parallel()
 	Run(name string, f func(r Reporter)) bool
 
-	runWithRetry(string, func(t Reporter), RetryPolicy) bool
+	runWithRetry(context.Context, string, func(t Reporter), RetryPolicy) bool
+	runParallel(int, []ParallelTest) bool
 	setNoFailurePropagation()
+	addSecret(string)
+	setDetails(*StepDetails)
 
 	// for test reports
 	getName() string
 	getDuration() time.Duration
 	getLogs() *logRecorder
+	getSecrets() *secrets
+	getDetails() *StepDetails
 	getChildren() []Reporter
 	isRoot() bool
 }
@@ -82,6 +86,7 @@ type reporter struct {
 	skipped          int32
 	isParallel       bool
 	logs             *logRecorder
+	details          *StepDetails
 	durationMeasurer testDurationMeasurer
 	children         []*reporter
 
@@ -89,6 +94,7 @@ type reporter struct {
 	done    chan bool // To signal a test is done.
 
 	testing              bool
+	retryCtx             context.Context
 	retryPolicy          RetryPolicy
 	retryable            bool
 	noFailurePropagation bool
@@ -133,26 +139,26 @@ func (r *reporter) FailNow() {
 // and records the text in the log.
 // The text will be printed only if the test fails or the --verbose flag is set.
 func (r *reporter) Log(args ...interface{}) {
-	r.logs.log(fmt.Sprint(args...))
+	r.logs.log(r.mask(fmt.Sprint(args...)))
 }
 
 // Logf formats its arguments according to the format, analogous to fmt.Printf, and
 // records the text in the log.
 // The text will be printed only if the test fails or the --verbose flag is set.
 func (r *reporter) Logf(format string, args ...interface{}) {
-	r.logs.log(fmt.Sprintf(format, args...))
+	r.logs.log(r.mask(fmt.Sprintf(format, args...)))
 }
 
 // Error is equivalent to Log followed by Fail.
 func (r *reporter) Error(args ...interface{}) {
 	r.Fail()
-	r.logs.error(fmt.Sprint(args...))
+	r.logs.error(r.mask(fmt.Sprint(args...)))
 }
 
 // Errorf is equivalent to Logf followed by Fail.
 func (r *reporter) Errorf(format string, args ...interface{}) {
 	r.Fail()
-	r.logs.error(fmt.Sprintf(format, args...))
+	r.logs.error(r.mask(fmt.Sprintf(format, args...)))
 }
 
 // Fatal is equivalent to Log followed by FailNow.
@@ -169,13 +175,13 @@ func (r *reporter) Fatalf(format string, args ...interface{}) {
 
 // Skip is equivalent to Log followed by SkipNow.
 func (r *reporter) Skip(args ...interface{}) {
-	r.logs.skip(fmt.Sprint(args...))
+	r.logs.skip(r.mask(fmt.Sprint(args...)))
 	r.SkipNow()
 }
 
 // Skipf is equivalent to Logf followed by SkipNow.
 func (r *reporter) Skipf(format string, args ...interface{}) {
-	r.logs.skip(fmt.Sprintf(format, args...))
+	r.logs.skip(r.mask(fmt.Sprintf(format, args...)))
 	r.SkipNow()
 }
 
@@ -213,14 +219,14 @@ func (r *reporter) Parallel() {
 		return
 	}
 
-	if r.context.verbose {
+	if r.context.isVerbose() {
 		r.context.printf("=== PAUSE %s\n", r.goTestName)
 	}
 	r.done <- true     // Release calling test.
 	<-r.parent.barrier // Wait for the parent test to complete.
 	r.context.waitParallel()
 
-	if r.context.verbose {
+	if r.context.isVerbose() {
 		r.context.printf("=== CONT  %s\n", r.goTestName)
 	}
 }
@@ -259,16 +265,17 @@ func (r *reporter) isRoot() bool {
 // Run may be called simultaneously from multiple goroutines,
 // but all such calls must return before the outer test function for r returns.
 func (r *reporter) Run(name string, f func(t Reporter)) bool {
-	return r.runWithRetry(name, f, nil)
+	return r.runWithRetry(context.Background(), name, f, nil)
 }
 
-func (r *reporter) runWithRetry(name string, f func(t Reporter), policy RetryPolicy) bool {
+func (r *reporter) runWithRetry(ctx context.Context, name string, f func(t Reporter), policy RetryPolicy) bool {
 	if !r.context.matcher.match(r.goTestName, rewrite(name)) {
 		return true
 	}
 	child := r.spawn(name)
+	child.retryCtx = ctx
 	child.retryPolicy = policy
-	if r.context.verbose {
+	if r.context.isVerbose() {
 		r.context.printf("=== RUN   %s\n", child.goTestName)
 	}
 	go child.run(f)
@@ -303,13 +310,23 @@ func (r *reporter) run(f func(r Reporter)) {
 	if r.retryPolicy == nil {
 		r.runFunc(f)
 	} else {
-		_, cancel, b, err := r.retryPolicy.Build(context.Background())
+		ctx := r.retryCtx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, cancel, b, err := r.retryPolicy.Build(ctx)
 		if err != nil {
 			r.Fatalf("invalid retry policy: %s", err)
 		}
 		defer cancel()
-		var retried bool
+		// stop retrying promptly if the context is canceled
+		b = backoff.WithContext(b, ctx)
+		var (
+			retried  bool
+			attempts int
+		)
 		child, err := backoff.RetryNotifyWithData(func() (*reporter, error) {
+			attempts++
 			child := r.spawn("retryable")
 			child.name = r.name
 			child.goTestName = r.goTestName
@@ -329,9 +346,16 @@ func (r *reporter) run(f func(r Reporter)) {
 		})
 		r.noFailurePropagation = child.noFailurePropagation
 		if retried && err != nil {
-			r.Error("retry limit exceeded")
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				r.Errorf("retry canceled after %d attempts: %s", attempts, ctxErr)
+			} else {
+				r.Errorf("retry limit exceeded (%d attempts)", attempts)
+			}
 		}
 		r.logs.append(child.logs)
+		if d := child.getDetails(); d != nil {
+			r.setDetails(d)
+		}
 		r.appendChildren(child.children...)
 		if err != nil {
 			if child.Failed() {
@@ -413,30 +437,35 @@ func printReport(r *reporter) {
 	results := collectOutput(r)
 	r.context.printf("%s\n", strings.Join(results, "\n"))
 	if r.Failed() && !r.testing {
-		r.context.printf(r.failColor().Sprintln("FAIL"))
+		r.context.printf(r.colors().fail().Sprintln("FAIL"))
 	}
 }
 
 func collectOutput(r *reporter) []string {
 	var results []string
-	if (r.Failed() && !r.noFailurePropagation) || r.context.verbose {
+	if (r.Failed() && !r.noFailurePropagation) || r.context.isVerbose() {
 		prefix := strings.Repeat("    ", r.depth-1)
 		status := "PASS"
-		c := r.passColor()
+		c := r.colors().pass()
 		if r.Failed() {
 			status = "FAIL"
-			c = r.failColor()
+			c = r.colors().fail()
 		} else if r.Skipped() {
 			status = "SKIP"
-			c = r.skipColor()
+			c = r.colors().skip()
 		}
 		results = []string{
 			c.Sprintf("%s--- %s: %s (%.2fs)", prefix, status, r.goTestName, r.durationMeasurer.getDuration().Seconds()),
 		}
+		padding := fmt.Sprintf("%s    ", prefix)
 		for _, l := range r.logs.all() {
-			padding := fmt.Sprintf("%s    ", prefix)
 			results = append(results, pad(l, padding))
 		}
+		if r.Failed() && r.context.verbosity >= VerbosityDebug {
+			if d := r.getDetails(); d != nil && len(d.Diffs) > 0 {
+				results = append(results, pad(r.formatDiffs(d.Diffs), padding))
+			}
+		}
 	}
 	for _, child := range r.children {
 		results = append(results, collectOutput(child)...)
@@ -445,20 +474,47 @@ func collectOutput(r *reporter) []string {
 		if r.Failed() {
 			results = append(results,
 				//nolint:dupword
-				r.failColor().Sprintf("FAIL\nFAIL\t%s\t%.3fs", r.goTestName, r.durationMeasurer.getDuration().Seconds()),
+				r.colors().fail().Sprintf("FAIL\nFAIL\t%s\t%.3fs", r.goTestName, r.durationMeasurer.getDuration().Seconds()),
 			)
 		} else {
-			if r.context.verbose {
-				results = append(results, r.passColor().Sprint("PASS"))
+			if r.context.isVerbose() {
+				results = append(results, r.colors().pass().Sprint("PASS"))
 			}
 			results = append(results,
-				r.passColor().Sprintf("ok  \t%s\t%.3fs", r.goTestName, r.durationMeasurer.getDuration().Seconds()),
+				r.colors().pass().Sprintf("ok  \t%s\t%.3fs", r.goTestName, r.durationMeasurer.getDuration().Seconds()),
 			)
 		}
 	}
 	return results
 }
 
+// formatDiffs returns the query path, the expected value, and the actual value of each assertion error.
+func (r *reporter) formatDiffs(diffs []Diff) string {
+	c := r.colors()
+	var b strings.Builder
+	b.WriteString("diffs:")
+	for _, d := range diffs {
+		b.WriteString("\n  ")
+		b.WriteString(c.path().Sprint(d.Path))
+		if d.Matcher != "" {
+			fmt.Fprintf(&b, " (%s)", d.Matcher)
+		}
+		fmt.Fprintf(&b, "\n    %s", c.expected().Sprintf("- expected:%s", formatDiffValue(d.Expected)))
+		fmt.Fprintf(&b, "\n    %s", c.actual().Sprintf("+ actual:  %s", formatDiffValue(d.Actual)))
+	}
+	return r.mask(b.String())
+}
+
+// formatDiffValue returns v with the leading space.
+// The multi-line values such as maps start from the next line.
+func formatDiffValue(v interface{}) string {
+	s := formatValue(v)
+	if !strings.Contains(s, "\n") {
+		return " " + s
+	}
+	return "\n" + pad(s, "  ")
+}
+
 func pad(s string, padding string) string {
 	s = strings.Trim(s, "\n")
 	indent := strings.Repeat(" ", 4)
@@ -479,6 +535,17 @@ func (r *reporter) setNoFailurePropagation() {
 	r.noFailurePropagation = true
 }
 
+func (r *reporter) addSecret(v string) {
+	if r.context != nil {
+		r.context.secrets.add(v)
+	}
+}
+
+// mask replaces the secret values in s.
+func (r *reporter) mask(s string) string {
+	return r.getSecrets().mask(s)
+}
+
 func (r *reporter) getName() string {
 	return r.name
 }
@@ -491,31 +558,33 @@ func (r *reporter) getLogs() *logRecorder {
 	return r.logs
 }
 
-func (r *reporter) getChildren() []Reporter {
-	children := make([]Reporter, len(r.children))
-	for i, child := range r.children {
-		children[i] = child
-	}
-	return children
+func (r *reporter) setDetails(d *StepDetails) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.details = d
 }
 
-func (r *reporter) passColor() *color.Color {
-	if r.context.noColor {
-		return color.New()
-	}
-	return color.New(color.FgGreen)
+func (r *reporter) getDetails() *StepDetails {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return r.details
 }
 
-func (r *reporter) failColor() *color.Color {
-	if r.context.noColor {
-		return color.New()
+func (r *reporter) getSecrets() *secrets {
+	if r.context == nil {
+		return nil
 	}
-	return color.New(color.FgHiRed)
+	return &r.context.secrets
 }
 
-func (r *reporter) skipColor() *color.Color {
-	if r.context.noColor {
-		return color.New()
+func (r *reporter) getChildren() []Reporter {
+	children := make([]Reporter, len(r.children))
+	for i, child := range r.children {
+		children[i] = child
 	}
-	return color.New(color.FgYellow)
+	return children
+}
+
+func (r *reporter) colors() palette {
+	return palette{disabled: r.context.noColor}
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -104,6 +105,23 @@ func TestReporter_Logf(t *testing.T) {
 	}
 }
 
+func TestReporter_AddSecret(t *testing.T) {
+	var b bytes.Buffer
+	Run(func(r Reporter) {
+		AddSecret(r, "secret")
+		AddSecret(r, "secret-token")
+		r.Run("child", func(r Reporter) {
+			r.Logf("token=%s, %s", "secret-token", "ssecrett")
+		})
+	}, WithWriter(&b), WithVerboseLog())
+	if strings.Contains(b.String(), "secret") {
+		t.Fatalf("output contains the secret:\n%s", b.String())
+	}
+	if expect := "token=****, s****t"; !strings.Contains(b.String(), expect) {
+		t.Errorf("output doesn't contain %q:\n%s", expect, b.String())
+	}
+}
+
 func TestReporter_Error(t *testing.T) {
 	name := "testname"
 	r := newReporter()
@@ -404,13 +422,13 @@ FAIL
 			expect: `
 --- FAIL: a (0.00s)
         retry after 1µs
-        retry limit exceeded
+        retry limit exceeded (2 attempts)
     --- FAIL: a/b (0.00s)
             retry after 1µs
-            retry limit exceeded
+            retry limit exceeded (2 attempts)
         --- FAIL: a/b/c (0.00s)
                 retry after 1µs
-                retry limit exceeded
+                retry limit exceeded (2 attempts)
                 error!
 FAIL
 FAIL	a	0.000s
@@ -420,7 +438,7 @@ FAIL
 		"ok nest verbose": {
 			f: func(t *testing.T, r *reporter) {
 				t.Helper()
-				r.context.verbose = true
+				r.context.verbosity = VerbosityVerbose
 				r.Run("a", func(r Reporter) {
 					r.Run("b", func(r Reporter) {
 						r.Run("c", func(r Reporter) {
@@ -444,7 +462,7 @@ ok  	a	0.000s
 		"FAIL nest verbose": {
 			f: func(t *testing.T, r *reporter) {
 				t.Helper()
-				r.context.verbose = true
+				r.context.verbosity = VerbosityVerbose
 				r.Run("a", func(r Reporter) {
 					r.Run("b", func(r Reporter) {
 						r.Run("c", func(r Reporter) {
@@ -468,6 +486,48 @@ ok  	a	0.000s
 FAIL
 FAIL	a	0.000s
 FAIL
+`,
+		},
+		"FAIL debug": {
+			f: func(t *testing.T, r *reporter) {
+				t.Helper()
+				r.context.verbosity = VerbosityDebug
+				r.Run("a", func(r Reporter) {
+					rptr := pr(t, r)
+					SetStepDetails(rptr, &StepDetails{
+						Diffs: []Diff{
+							{
+								Path:     ".body.message",
+								Matcher:  "Equal",
+								Expected: "bye",
+								Actual:   "hello",
+							},
+							{
+								Path:     ".body.items",
+								Expected: []interface{}{1, 2},
+								Actual:   []interface{}{1},
+							},
+						},
+					})
+					rptr.Error("error!")
+				})
+			},
+			expect: `
+=== RUN   a
+--- FAIL: a (0.00s)
+        error!
+        diffs:
+          .body.message (Equal)
+            - expected: bye
+            + actual:   hello
+          .body.items
+            - expected:
+              - 1
+              - 2
+            + actual:   - 1
+FAIL
+FAIL	a	0.000s
+FAIL
 `,
 		},
 		"multi line log": {
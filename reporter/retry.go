@@ -7,8 +7,9 @@ import (
 )
 
 // RunWithRetry runs f as a subtest of r called name with retry.
+// It stops retrying when ctx is canceled.
 func RunWithRetry(ctx context.Context, r Reporter, name string, f func(Reporter), policy RetryPolicy) bool {
-	return r.runWithRetry(name, f, policy)
+	return r.runWithRetry(ctx, name, f, policy)
 }
 
 // RetryPolicy is an interface for the retry backoff policies.
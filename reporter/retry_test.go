@@ -34,6 +34,36 @@ func TestRunWithRetry(t *testing.T) {
 	}
 }
 
+func TestRunWithRetry_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	policy := &constantRetryPolicy{
+		interval:   time.Hour,
+		maxRetries: 5,
+	}
+	var i int
+	done := make(chan bool)
+	r := run(func(r Reporter) {
+		RunWithRetry(ctx, r, "a", func(r Reporter) {
+			i++
+			cancel()
+			r.Error("fail")
+		}, policy)
+		close(done)
+	})
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("retry is not canceled")
+	}
+	if got, expect := i, 1; got != expect {
+		t.Errorf("expect %d but got %d", expect, got)
+	}
+	if !r.Failed() {
+		t.Error("should be failed")
+	}
+}
+
 func TestRunWithRetry_Parallel(t *testing.T) {
 	ctx := context.Background()
 	retryPolicy := &constantRetryPolicy{
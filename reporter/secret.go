@@ -0,0 +1,100 @@
+package reporter
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// secretMask is the replacement of the secret values in the outputs.
+const secretMask = "****"
+
+// secrets holds the secret values which must not appear in the outputs.
+type secrets struct {
+	m        sync.RWMutex
+	values   map[string]struct{}
+	replacer *strings.Replacer
+}
+
+func (s *secrets) add(v string) {
+	if v == "" {
+		return
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	if _, ok := s.values[v]; ok {
+		return
+	}
+	if s.values == nil {
+		s.values = map[string]struct{}{}
+	}
+	s.values[v] = struct{}{}
+
+	// replace longer values first not to leave a part of the value which contains another one
+	vs := make([]string, 0, len(s.values))
+	for v := range s.values {
+		vs = append(vs, v)
+	}
+	sort.Slice(vs, func(i, j int) bool {
+		if len(vs[i]) != len(vs[j]) {
+			return len(vs[i]) > len(vs[j])
+		}
+		return vs[i] < vs[j]
+	})
+	oldnew := make([]string, 0, len(vs)*2)
+	for _, v := range vs {
+		oldnew = append(oldnew, v, secretMask)
+	}
+	s.replacer = strings.NewReplacer(oldnew...)
+}
+
+func (s *secrets) mask(str string) string {
+	if s == nil {
+		return str
+	}
+	s.m.RLock()
+	defer s.m.RUnlock()
+	if s.replacer == nil {
+		return str
+	}
+	return s.replacer.Replace(str)
+}
+
+// maskValue replaces the secret values in the strings of v recursively.
+func (s *secrets) maskValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return s.mask(v)
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, e := range v {
+			masked[i] = s.maskValue(e)
+		}
+		return masked
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			masked[s.mask(k)] = s.maskValue(e)
+		}
+		return masked
+	default:
+		return v
+	}
+}
+
+func (s *secrets) maskAll(strs []string) []string {
+	if strs == nil {
+		return nil
+	}
+	masked := make([]string, len(strs))
+	for i, str := range strs {
+		masked[i] = s.mask(str)
+	}
+	return masked
+}
+
+// AddSecret registers v as a secret value.
+// The secret values are replaced with "****" in the logs and the test reports.
+func AddSecret(r Reporter, v string) {
+	r.addSecret(v)
+}
@@ -0,0 +1,69 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// ReportSchemaVersion is the schema version of the streamed test report.
+// It is changed if the report has incompatible changes.
+const ReportSchemaVersion = "report/v1"
+
+// Event types of the streamed test report.
+const (
+	ReportEventTypeFile   = "file"
+	ReportEventTypeResult = "result"
+)
+
+// ReportEvent represents a line of the streamed test report.
+// A "file" event is written when each scenario file finishes, and a "result" event is written at the end.
+type ReportEvent struct {
+	SchemaVersion string              `json:"schemaVersion"`
+	Type          string              `json:"type"`
+	File          *ScenarioFileReport `json:"file,omitempty"`
+	Result        *TestResult         `json:"result,omitempty"`
+}
+
+// ReportStream writes the test report incrementally in JSON Lines format.
+// It enables to process the results of long runs without buffering all of them.
+type ReportStream struct {
+	m   sync.Mutex
+	enc *json.Encoder
+}
+
+// NewReportStream returns a new stream which writes the test report to w.
+func NewReportStream(w io.Writer) *ReportStream {
+	return &ReportStream{
+		enc: json.NewEncoder(w),
+	}
+}
+
+// WriteFile writes the result report of the scenario file which r reports.
+func (s *ReportStream) WriteFile(r Reporter) error {
+	report, err := GenerateScenarioFileReport(r)
+	if err != nil {
+		return err
+	}
+	return s.write(&ReportEvent{
+		SchemaVersion: ReportSchemaVersion,
+		Type:          ReportEventTypeFile,
+		File:          report,
+	})
+}
+
+// WriteResult writes the result of all tests which r reports.
+func (s *ReportStream) WriteResult(r Reporter) error {
+	result := testResult(r)
+	return s.write(&ReportEvent{
+		SchemaVersion: ReportSchemaVersion,
+		Type:          ReportEventTypeResult,
+		Result:        &result,
+	})
+}
+
+func (s *ReportStream) write(e *ReportEvent) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.enc.Encode(e)
+}
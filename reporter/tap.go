@@ -0,0 +1,108 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+const tapIndent = "    "
+
+// tapDiagnostic represents the YAML diagnostic block of a failed TAP test line.
+type tapDiagnostic struct {
+	Message    string  `yaml:"message,omitempty"`
+	DurationMS float64 `yaml:"duration_ms"`
+	Diffs      []Diff  `yaml:"diffs,omitempty"`
+}
+
+// WriteTAP writes r in TAP version 13 format.
+// The scenario files, scenarios, and steps are mapped to the nested subtests.
+func (r *TestReport) WriteTAP(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", len(r.Files))
+	for i, file := range r.Files {
+		var scenarios strings.Builder
+		fmt.Fprintf(&scenarios, "1..%d\n", len(file.Scenarios))
+		for j, scenario := range file.Scenarios {
+			var steps strings.Builder
+			fmt.Fprintf(&steps, "1..%d\n", len(scenario.Steps))
+			for k, step := range scenario.Steps {
+				if err := writeTAPStep(&steps, k+1, step); err != nil {
+					return err
+				}
+			}
+			writeTAPSubtest(&scenarios, scenario.Name, steps.String())
+			writeTAPLine(&scenarios, j+1, scenario.Name, scenario.Result, "")
+		}
+		writeTAPSubtest(&b, file.Name, scenarios.String())
+		writeTAPLine(&b, i+1, file.Name, file.Result, "")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeTAPStep(b *strings.Builder, n int, step StepReport) error {
+	var reason string
+	if step.Result == TestResultSkipped && step.Logs.Skip != nil {
+		reason = *step.Logs.Skip
+	}
+	writeTAPLine(b, n, step.Name, step.Result, reason)
+	if step.Result != TestResultFailed {
+		return nil
+	}
+	// YAML doesn't allow the control characters same as XML
+	diag := tapDiagnostic{
+		Message:    validXMLString(strings.Join(step.Logs.Error, "\n")),
+		DurationMS: float64(time.Duration(step.Duration)) / float64(time.Millisecond),
+	}
+	if step.Details != nil {
+		diag.Diffs = step.Details.Diffs
+	}
+	y, err := yaml.MarshalWithOptions(diag, yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return fmt.Errorf("failed to marshal TAP diagnostic: %w", err)
+	}
+	b.WriteString("  ---\n")
+	for _, l := range strings.Split(strings.TrimRight(string(y), "\n"), "\n") {
+		b.WriteString("  ")
+		b.WriteString(l)
+		b.WriteString("\n")
+	}
+	b.WriteString("  ...\n")
+	return nil
+}
+
+func writeTAPSubtest(b *strings.Builder, name, body string) {
+	fmt.Fprintf(b, "%s# Subtest: %s\n", tapIndent, tapName(name))
+	for _, l := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		b.WriteString(tapIndent)
+		b.WriteString(l)
+		b.WriteString("\n")
+	}
+}
+
+func writeTAPLine(b *strings.Builder, n int, name string, result TestResult, reason string) {
+	status := "ok"
+	if result == TestResultFailed {
+		status = "not ok"
+	}
+	fmt.Fprintf(b, "%s %d - %s", status, n, tapName(name))
+	if result == TestResultSkipped {
+		b.WriteString(" # SKIP")
+		if reason != "" {
+			fmt.Fprintf(b, " %s", strings.Join(strings.Fields(reason), " "))
+		}
+	}
+	b.WriteString("\n")
+}
+
+// tapName escapes the characters which have special meanings in the test line.
+func tapName(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "#", `\#`)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -15,7 +15,7 @@ func TestFromT(t *testing.T) {
 		t.Cleanup(func() { os.Args = args })
 		os.Args = []string{}
 		r := FromT(t).(*reporter)
-		if r.context.verbose {
+		if r.context.isVerbose() {
 			t.Error("verbose should be false")
 		}
 		if r.context.matcher != nil {
@@ -27,7 +27,7 @@ func TestFromT(t *testing.T) {
 		t.Cleanup(func() { os.Args = args })
 		os.Args = []string{"-test.v=true"}
 		r := FromT(t).(*reporter)
-		if !r.context.verbose {
+		if !r.context.isVerbose() {
 			t.Error("verbose should be true")
 		}
 	})
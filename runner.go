@@ -6,20 +6,28 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"net/http/cookiejar"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"sync"
+	"time"
 
-	"github.com/fatih/color"
 	"github.com/goccy/go-yaml"
 	"github.com/pkg/errors"
+	"golang.org/x/net/publicsuffix"
 
 	"github.com/zoncoen/scenarigo/context"
 	"github.com/zoncoen/scenarigo/internal/filepathutil"
 	"github.com/zoncoen/scenarigo/plugin"
+	"github.com/zoncoen/scenarigo/protocol/db"
+	"github.com/zoncoen/scenarigo/protocol/graphql"
 	"github.com/zoncoen/scenarigo/protocol/grpc"
 	"github.com/zoncoen/scenarigo/protocol/http"
+	"github.com/zoncoen/scenarigo/protocol/kafka"
+	"github.com/zoncoen/scenarigo/protocol/redis"
+	"github.com/zoncoen/scenarigo/protocol/websocket"
 	"github.com/zoncoen/scenarigo/reporter"
 	"github.com/zoncoen/scenarigo/schema"
 )
@@ -27,6 +35,11 @@ import (
 func init() {
 	http.Register()
 	grpc.Register()
+	websocket.Register()
+	db.Register()
+	redis.Register()
+	kafka.Register()
+	graphql.Register()
 }
 
 // Runner represents a test runner.
@@ -40,12 +53,21 @@ type Runner struct {
 	rootDir         string
 	inputConfig     schema.InputConfig
 	reportConfig    schema.ReportConfig
+	cookieJar       bool
+	httpConfig      schema.HTTPConfig
+	grpcConfig      schema.GRPCConfig
+	parallel        int
+	dryRun          bool
+	contextValues   map[string]any
+	stepTimeout     time.Duration
+	envAllowlist    []string
+	updateGolden    bool
 }
 
 // NewRunner returns a new test runner.
 func NewRunner(opts ...func(*Runner) error) (*Runner, error) {
 	r := &Runner{} //nolint:exhaustruct
-	r.enabledColor = !color.NoColor
+	r.enabledColor = reporter.ColorEnabled()
 	for _, opt := range opts {
 		if err := opt(r); err != nil {
 			return nil, err
@@ -92,6 +114,23 @@ func WithConfig(config *schema.Config) func(*Runner) error {
 		}
 		r.inputConfig = config.Input
 		r.reportConfig = config.Output.Report
+		r.cookieJar = config.HTTP.CookieJar
+		r.httpConfig = config.HTTP
+		r.grpcConfig = config.GRPC
+		r.parallel = config.Parallel
+		if config.Timeout != nil {
+			r.stepTimeout = time.Duration(*config.Timeout)
+		}
+		r.envAllowlist = config.Env.Allowlist
+		return nil
+	}
+}
+
+// WithEnvAllowlist returns a option which restricts the environment variables templates can read by env.
+// Reading other variables results in an error.
+func WithEnvAllowlist(names []string) func(*Runner) error {
+	return func(r *Runner) error {
+		r.envAllowlist = names
 		return nil
 	}
 }
@@ -127,6 +166,74 @@ func WithPluginDir(path string) func(*Runner) error {
 	}
 }
 
+// WithParallel returns a option which sets the number of scenario files to run in parallel.
+// It overrides the value of the configuration.
+func WithParallel(n int) func(*Runner) error {
+	return func(r *Runner) error {
+		if n < 1 {
+			return errors.Errorf("parallel must be greater than 0 but got %d", n)
+		}
+		r.parallel = n
+		return nil
+	}
+}
+
+// WithStepTimeout returns a option which sets the default timeout of the steps which don't set the timeout.
+// It overrides the value of the configuration, and 0 means no timeout.
+func WithStepTimeout(d time.Duration) func(*Runner) error {
+	return func(r *Runner) error {
+		if d < 0 {
+			return errors.Errorf("timeout must not be negative but got %s", d)
+		}
+		r.stepTimeout = d
+		return nil
+	}
+}
+
+// WithUpdateGolden returns a option which sets flag whether the golden files of the expected values are rewritten by the actual values instead of asserting them.
+func WithUpdateGolden(enabled bool) func(*Runner) error {
+	return func(r *Runner) error {
+		r.updateGolden = enabled
+		return nil
+	}
+}
+
+// WithDryRun returns a option which sets flag whether the runner validates the scenarios without sending requests.
+// See DryRunScenario for details.
+func WithDryRun(enabled bool) func(*Runner) error {
+	return func(r *Runner) error {
+		r.dryRun = enabled
+		return nil
+	}
+}
+
+// WithContextValues returns a option which sets the values available as {{ctx.<key>}} in templates and by ctx.Values() in plugins.
+// The values can't be changed during the run.
+func WithContextValues(values map[string]any) func(*Runner) error {
+	return func(r *Runner) error {
+		if r.contextValues == nil {
+			r.contextValues = map[string]any{}
+		}
+		for k, v := range values {
+			r.contextValues[k] = v
+		}
+		return nil
+	}
+}
+
+// WithTAPReport returns a option which sets the file to write the test report in TAP format.
+// It overrides the filename of the configuration.
+func WithTAPReport(path string) func(*Runner) error {
+	return func(r *Runner) error {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return errors.Wrapf(err, `failed to set TAP report file "%s"`, path)
+		}
+		r.reportConfig.TAP.Filename = abs
+		return nil
+	}
+}
+
 // WithScenariosFromReader returns a option which sets readers to read scenario contents.
 func WithScenariosFromReader(readers ...io.Reader) func(*Runner) error {
 	return func(r *Runner) error {
@@ -135,6 +242,15 @@ func WithScenariosFromReader(readers ...io.Reader) func(*Runner) error {
 	}
 }
 
+// WithCookieJar returns a option which sets flag whether each scenario uses a cookie jar.
+// The cookies set by the responses are sent automatically by the following HTTP requests in the same scenario.
+func WithCookieJar(enabled bool) func(*Runner) error {
+	return func(r *Runner) error {
+		r.cookieJar = enabled
+		return nil
+	}
+}
+
 // WithOptionsFromEnv returns a option which sets flag whether accepts configuration from ENV.
 // Currently Available ENV variables are the following.
 //   - SCENARIGO_COLOR=(1|true|TRUE)
@@ -198,6 +314,10 @@ func (r *Runner) ScenarioFiles() []string {
 // Run runs all tests.
 func (r *Runner) Run(ctx *context.Context) {
 	// setup context
+	ctx = ctx.WithValues(r.contextValues)
+	if r.envAllowlist != nil {
+		ctx = ctx.WithEnvAllowlist(r.envAllowlist)
+	}
 	if r.vars != nil {
 		ctx = ctx.WithVars(r.vars)
 	}
@@ -205,6 +325,22 @@ func (r *Runner) Run(ctx *context.Context) {
 		ctx = ctx.WithPluginDir(*r.pluginDir)
 	}
 	ctx = ctx.WithEnabledColor(r.enabledColor)
+	if r.stepTimeout > 0 {
+		ctx = ctx.WithStepTimeout(r.stepTimeout)
+	}
+	if r.updateGolden {
+		ctx = ctx.WithUpdateGolden(true)
+	}
+	httpConfig, err := r.buildHTTPConfig(ctx)
+	if err != nil {
+		ctx.Reporter().Fatal(err)
+	}
+	ctx = http.WithConfig(ctx, httpConfig)
+	grpcConfig, err := r.buildGRPCConfig()
+	if err != nil {
+		ctx.Reporter().Fatal(err)
+	}
+	ctx = grpc.WithConfig(ctx, grpcConfig)
 
 	// open plugins
 	pluginDir := r.rootDir
@@ -224,7 +360,8 @@ func (r *Runner) Run(ctx *context.Context) {
 			})
 			continue
 		}
-		if setup := p.GetSetup(); setup != nil {
+		// the setup functions may send requests
+		if setup := p.GetSetup(); setup != nil && !r.dryRun {
 			setups = append(setups, setupFunc{
 				name: item.Key,
 				f:    setup,
@@ -241,8 +378,45 @@ func (r *Runner) Run(ctx *context.Context) {
 		schema.WithInputConfig(r.rootDir, r.inputConfig),
 	}
 
+	stream, closeStream := r.openReportStream(ctx)
+	defer closeStream()
+	writeFileReport := func(rptr reporter.Reporter) {
+		if stream == nil || rptr == nil {
+			return
+		}
+		if err := stream.WriteFile(rptr); err != nil {
+			ctx.Reporter().Errorf("failed to write JSON Lines test report: %s", err)
+		}
+	}
+
+	// serial scenarios run exclusively while the other scenarios share the lock
+	var serial sync.RWMutex
+	runScenarios := func(ctx *context.Context, scns []*schema.Scenario) {
+		for _, scn := range scns {
+			scn := scn
+			ctx = ctx.WithNode(scn.Node)
+			ctx.Run(scn.Title, func(ctx *context.Context) {
+				ctx.Reporter().Parallel()
+				if scn.Serial {
+					serial.Lock()
+					defer serial.Unlock()
+				} else {
+					serial.RLock()
+					defer serial.RUnlock()
+				}
+				if r.dryRun {
+					_ = DryRunScenario(ctx, scn)
+					return
+				}
+				_ = RunScenario(r.withCookieJar(ctx), scn)
+			})
+		}
+	}
+
+	tests := []reporter.ParallelTest{}
 FILE_LOOP:
 	for _, f := range r.scenarioFiles {
+		f := f
 		testName, err := filepath.Rel(r.rootDir, f)
 		if err != nil {
 			testName = f
@@ -252,43 +426,117 @@ FILE_LOOP:
 				continue FILE_LOOP
 			}
 		}
-		ctx.Run(testName, func(ctx *context.Context) {
-			scns, err := schema.LoadScenarios(f, opts...)
-			if err != nil {
-				ctx.Reporter().Fatalf("failed to load scenarios: %s", err)
-			}
-			for _, scn := range scns {
-				scn := scn
-				ctx = ctx.WithNode(scn.Node)
-				ctx.Run(scn.Title, func(ctx *context.Context) {
-					ctx.Reporter().Parallel()
-					_ = RunScenario(ctx, scn)
-				})
-			}
+		tests = append(tests, reporter.ParallelTest{
+			Name: testName,
+			F: func(rptr reporter.Reporter) {
+				ctx := ctx.WithReporter(rptr)
+				scns, err := schema.LoadScenarios(f, opts...)
+				if err != nil {
+					ctx.Reporter().Fatalf("failed to load scenarios: %s", err)
+				}
+				runScenarios(ctx, scns)
+			},
+			Done: writeFileReport,
 		})
 	}
 	for i, reader := range r.scenarioReaders {
-		ctx.Run(fmt.Sprint(i), func(ctx *context.Context) {
-			scns, err := schema.LoadScenariosFromReader(reader)
-			if err != nil {
-				ctx.Reporter().Fatalf("failed to load scenarios: %s", err)
-			}
-			for _, scn := range scns {
-				scn := scn
-				ctx = ctx.WithNode(scn.Node)
-				ctx.Run(scn.Title, func(ctx *context.Context) {
-					ctx.Reporter().Parallel()
-					_ = RunScenario(ctx, scn)
-				})
-			}
+		reader := reader
+		tests = append(tests, reporter.ParallelTest{
+			Name: fmt.Sprint(i),
+			F: func(rptr reporter.Reporter) {
+				ctx := ctx.WithReporter(rptr)
+				scns, err := schema.LoadScenariosFromReader(reader)
+				if err != nil {
+					ctx.Reporter().Fatalf("failed to load scenarios: %s", err)
+				}
+				runScenarios(ctx, scns)
+			},
+			Done: writeFileReport,
 		})
 	}
+	reporter.RunParallel(ctx.Reporter(), r.parallel, tests)
 	teardown(ctx)
+	if stream != nil {
+		if err := stream.WriteResult(ctx.Reporter()); err != nil {
+			ctx.Reporter().Errorf("failed to write JSON Lines test report: %s", err)
+		}
+	}
+}
+
+// openReportStream opens the JSON Lines test report if it is enabled.
+// The returned function closes the report file.
+func (r *Runner) openReportStream(ctx *context.Context) (*reporter.ReportStream, func()) {
+	if r.reportConfig.JSONLines.Filename == "" {
+		return nil, func() {}
+	}
+	f, err := os.Create(filepathutil.From(r.rootDir, r.reportConfig.JSONLines.Filename))
+	if err != nil {
+		ctx.Reporter().Errorf("failed to write JSON Lines test report: %s", err)
+		return nil, func() {}
+	}
+	return reporter.NewReportStream(f), func() {
+		if err := f.Close(); err != nil {
+			ctx.Reporter().Errorf("failed to write JSON Lines test report: %s", err)
+		}
+	}
+}
+
+// buildHTTPConfig returns the default configuration for HTTP requests.
+// It returns an error if it fails to load the certificates or the proxy configuration is invalid.
+func (r *Runner) buildHTTPConfig(ctx *context.Context) (*http.Config, error) {
+	config := &http.Config{
+		Redirect: r.httpConfig.Redirect,
+	}
+	if r.httpConfig.TLS != nil {
+		tlsConfig, err := r.httpConfig.TLS.Build(ctx, r.rootDir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid http.tls config: %w", err)
+		}
+		config.TLS = tlsConfig
+	}
+	if r.httpConfig.Proxy != nil {
+		proxy, err := r.httpConfig.Proxy.Build(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("invalid http.proxy config: %w", err)
+		}
+		config.Proxy = proxy
+	}
+	return config, nil
+}
+
+// buildGRPCConfig returns the default configuration for gRPC requests.
+// It returns an error if it fails to load the descriptor sets.
+func (r *Runner) buildGRPCConfig() (*grpc.Config, error) {
+	config := &grpc.Config{}
+	if len(r.grpcConfig.DescriptorSets) > 0 {
+		paths := make([]string, len(r.grpcConfig.DescriptorSets))
+		for i, p := range r.grpcConfig.DescriptorSets {
+			paths[i] = filepathutil.From(r.rootDir, p)
+		}
+		files, err := grpc.LoadDescriptorSets(paths...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid grpc.descriptorSets config: %w", err)
+		}
+		config.Files = files
+	}
+	return config, nil
+}
+
+// withCookieJar returns a copy of ctx with a new cookie jar if the cookie jar is enabled.
+func (r *Runner) withCookieJar(ctx *context.Context) *context.Context {
+	if !r.cookieJar {
+		return ctx
+	}
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		ctx.Reporter().Fatalf("failed to create cookie jar: %s", err)
+	}
+	return ctx.WithCookieJar(jar)
 }
 
 // CreateTestReport creates test reports.
 func (r *Runner) CreateTestReport(rptr reporter.Reporter) error {
-	if r.reportConfig.JSON.Filename == "" && r.reportConfig.JUnit.Filename == "" {
+	if r.reportConfig.JSON.Filename == "" && r.reportConfig.JUnit.Filename == "" && r.reportConfig.TAP.Filename == "" && r.reportConfig.HTML.Filename == "" {
 		return nil
 	}
 
@@ -314,12 +562,40 @@ func (r *Runner) CreateTestReport(rptr reporter.Reporter) error {
 			return fmt.Errorf("failed to write JUnit test report: %w", err)
 		}
 		defer f.Close()
+		junit := report
+		switch r.reportConfig.JUnit.TestCase {
+		case "", "scenario":
+		case "step":
+			junit = report.StepTestCases()
+		default:
+			return fmt.Errorf("failed to write JUnit test report: unknown test case type %q", r.reportConfig.JUnit.TestCase)
+		}
 		enc := xml.NewEncoder(f)
 		enc.Indent("", "  ")
-		if err := enc.Encode(report); err != nil {
+		if err := enc.Encode(junit); err != nil {
 			return fmt.Errorf("failed to write JUnit test report: %w", err)
 		}
 	}
+	if r.reportConfig.TAP.Filename != "" {
+		f, err := os.Create(filepathutil.From(r.rootDir, r.reportConfig.TAP.Filename))
+		if err != nil {
+			return fmt.Errorf("failed to write TAP test report: %w", err)
+		}
+		defer f.Close()
+		if err := report.WriteTAP(f); err != nil {
+			return fmt.Errorf("failed to write TAP test report: %w", err)
+		}
+	}
+	if r.reportConfig.HTML.Filename != "" {
+		f, err := os.Create(filepathutil.From(r.rootDir, r.reportConfig.HTML.Filename))
+		if err != nil {
+			return fmt.Errorf("failed to write HTML test report: %w", err)
+		}
+		defer f.Close()
+		if err := report.WriteHTML(f); err != nil {
+			return fmt.Errorf("failed to write HTML test report: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -3,6 +3,7 @@ package scenarigo
 import (
 	"bytes"
 	gocontext "context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,10 +12,14 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/sergi/go-diff/diffmatchpatch"
+	"golang.org/x/net/websocket"
 
 	"github.com/zoncoen/scenarigo/context"
 	"github.com/zoncoen/scenarigo/reporter"
@@ -144,6 +149,99 @@ steps:
 				}
 			},
 		},
+		"cookie jar": {
+			yaml: `
+---
+title: session
+steps:
+- title: login
+  protocol: http
+  request:
+    method: POST
+    url: "{{env.TEST_ADDR}}/login"
+  expect:
+    code: 200
+- title: get user
+  protocol: http
+  request:
+    method: GET
+    url: "{{env.TEST_ADDR}}/me"
+  expect:
+    code: 200
+    body:
+      session: '{{cookies(env.TEST_ADDR).session}}'
+`,
+			config: &schema.Config{
+				HTTP: schema.HTTPConfig{
+					CookieJar: true,
+				},
+			},
+			setup: func(ctx *context.Context) func(*context.Context) {
+				s := httptest.NewServer(cookieTestHandler())
+				if err := os.Setenv("TEST_ADDR", s.URL); err != nil {
+					ctx.Reporter().Fatalf("unexpected error: %s", err)
+				}
+
+				return func(*context.Context) {
+					s.Close()
+					os.Unsetenv("TEST_ADDR")
+				}
+			},
+		},
+		"websocket": {
+			yaml: `
+---
+title: chat
+steps:
+- title: connect
+  protocol: websocket
+  request:
+    action: connect
+    connection: chat
+    url: "{{env.TEST_WS_URL}}"
+- title: send
+  protocol: websocket
+  request:
+    action: send
+    connection: chat
+    message:
+      text: hello
+- title: receive
+  protocol: websocket
+  request:
+    action: receive
+    connection: chat
+    timeout: 1s
+    match:
+      type: echo
+  expect:
+    type: text
+    message:
+      text: hello
+- title: close
+  protocol: websocket
+  request:
+    action: close
+    connection: chat
+`,
+			setup: func(ctx *context.Context) func(*context.Context) {
+				s := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+					var msg map[string]string
+					for websocket.JSON.Receive(ws, &msg) == nil {
+						_ = websocket.JSON.Send(ws, map[string]string{"type": "ack"})
+						_ = websocket.JSON.Send(ws, map[string]string{"type": "echo", "text": msg["text"]})
+					}
+				}))
+				if err := os.Setenv("TEST_WS_URL", "ws"+strings.TrimPrefix(s.URL, "http")); err != nil {
+					ctx.Reporter().Fatalf("unexpected error: %s", err)
+				}
+
+				return func(*context.Context) {
+					s.Close()
+					os.Unsetenv("TEST_WS_URL")
+				}
+			},
+		},
 		"exclude all files": {
 			config: &schema.Config{
 				Scenarios: []string{
@@ -221,6 +319,35 @@ func TestRunnerFail(t *testing.T) {
 				}
 			},
 		},
+		"cookie jar is disabled": {
+			yaml: `
+---
+title: session
+steps:
+- title: login
+  protocol: http
+  request:
+    method: POST
+    url: "{{env.TEST_ADDR}}/login"
+  expect:
+    code: 200
+- title: get user
+  protocol: http
+  request:
+    method: GET
+    url: "{{env.TEST_ADDR}}/me"
+  expect:
+    code: 200
+    body:
+      session: '{{cookies(env.TEST_ADDR).session}}'
+`,
+			setup: func(t *testing.T) func() {
+				t.Helper()
+				s := httptest.NewServer(cookieTestHandler())
+				t.Setenv("TEST_ADDR", s.URL)
+				return s.Close
+			},
+		},
 		"run with yaml": {
 			yaml: `invalid: value`,
 			setup: func(t *testing.T) func() {
@@ -254,6 +381,23 @@ func TestRunnerFail(t *testing.T) {
 	}
 }
 
+func cookieTestHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "xxx", Path: "/"})
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"session":%q}`, c.Value)
+	})
+	return mux
+}
+
 func TestRunner_ScenarioFiles(t *testing.T) {
 	scenariosPath := filepath.Join("test", "e2e", "testdata", "scenarios")
 	runner, err := NewRunner(WithScenarios(scenariosPath))
@@ -445,6 +589,22 @@ func TestWriteTestReport(t *testing.T) {
 			},
 			files: []string{"junit.xml"},
 		},
+		"tap": {
+			config: schema.ReportConfig{
+				TAP: schema.TAPReportConfig{
+					Filename: "report.tap",
+				},
+			},
+			files: []string{"report.tap"},
+		},
+		"html": {
+			config: schema.ReportConfig{
+				HTML: schema.HTMLReportConfig{
+					Filename: "report.html",
+				},
+			},
+			files: []string{"report.html"},
+		},
 		"all": {
 			config: schema.ReportConfig{
 				JSON: schema.JSONReportConfig{
@@ -453,8 +613,14 @@ func TestWriteTestReport(t *testing.T) {
 				JUnit: schema.JUnitReportConfig{
 					Filename: "junit.xml",
 				},
+				TAP: schema.TAPReportConfig{
+					Filename: "report.tap",
+				},
+				HTML: schema.HTMLReportConfig{
+					Filename: "report.html",
+				},
 			},
-			files: []string{"report.json", "junit.xml"},
+			files: []string{"report.json", "junit.xml", "report.tap", "report.html"},
 		},
 		"abs file path": {
 			config: schema.ReportConfig{
@@ -521,6 +687,461 @@ func TestWriteTestReport(t *testing.T) {
 	}
 }
 
+func TestRunner_ReportStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"message":"hello"}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	r, err := NewRunner(
+		WithConfig(&schema.Config{
+			Output: schema.OutputConfig{
+				Report: schema.ReportConfig{
+					JSONLines: schema.JSONLinesReportConfig{
+						Filename: "report.jsonl",
+					},
+				},
+			},
+			Root: dir,
+		}),
+		WithScenariosFromReader(strings.NewReader(fmt.Sprintf(`
+title: stream
+steps:
+- title: GET /
+  protocol: http
+  request:
+    method: GET
+    url: %s
+  expect:
+    body:
+      message: bye
+`, srv.URL))),
+	)
+	if err != nil {
+		t.Fatalf("failed to create a runner: %s", err)
+	}
+	if ok := reporter.Run(func(rptr reporter.Reporter) {
+		r.Run(context.New(rptr))
+	}); ok {
+		t.Fatal("no error")
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "report.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to read report: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if got, expect := len(lines), 2; got != expect {
+		t.Fatalf("expected %d lines but got %d:\n%s", expect, got, b)
+	}
+	var file reporter.ReportEvent
+	if err := json.Unmarshal([]byte(lines[0]), &file); err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if file.SchemaVersion != reporter.ReportSchemaVersion || file.Type != reporter.ReportEventTypeFile || file.File == nil {
+		t.Fatalf("unexpected event: %s", lines[0])
+	}
+	step := file.File.Scenarios[0].Steps[0]
+	if got, expect := step.Result, reporter.TestResultFailed; got != expect {
+		t.Errorf("expected %s but got %s", expect, got)
+	}
+	if step.Details == nil {
+		t.Fatal("no details")
+	}
+	if diff := cmp.Diff([]reporter.Diff{
+		{
+			Path:     ".body.message",
+			Matcher:  "Equal",
+			Expected: "bye",
+			Actual:   "hello",
+		},
+	}, step.Details.Diffs); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]interface{}{"message": "hello"}, step.Details.Response); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+	if got, expect := lines[1], `{"schemaVersion":"report/v1","type":"result","result":"failed"}`; got != expect {
+		t.Errorf("expected %s but got %s", expect, got)
+	}
+}
+
+func TestRunner_Parallel(t *testing.T) {
+	var (
+		m           sync.Mutex
+		inflight    int
+		maxInflight int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		inflight++
+		if inflight > maxInflight {
+			maxInflight = inflight
+		}
+		// the serial scenario must run exclusively
+		exclusive := inflight == 1
+		m.Unlock()
+		time.Sleep(200 * time.Millisecond)
+		m.Lock()
+		inflight--
+		m.Unlock()
+		if r.URL.Path == "/serial" && !exclusive {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	scenario := func(path string, serial bool) io.Reader {
+		return strings.NewReader(fmt.Sprintf(`
+title: %s
+serial: %t
+steps:
+- protocol: http
+  request:
+    method: GET
+    url: %s%s
+  expect:
+    code: OK
+`, path, serial, srv.URL, path))
+	}
+	r, err := NewRunner(
+		WithParallel(2),
+		WithScenariosFromReader(
+			scenario("/parallel", false),
+			scenario("/parallel", false),
+			scenario("/serial", true),
+		),
+	)
+	if err != nil {
+		t.Fatalf("failed to create a runner: %s", err)
+	}
+	var b bytes.Buffer
+	if ok := reporter.Run(func(rptr reporter.Reporter) {
+		r.Run(context.New(rptr))
+	}, reporter.WithWriter(&b), reporter.WithMaxParallel(2), reporter.WithNoColor()); !ok {
+		t.Fatalf("test failed:\n%s", b.String())
+	}
+	var names []string
+	for _, l := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		names = append(names, strings.Split(l, "\t")[1])
+	}
+	if diff := cmp.Diff([]string{"0", "1", "2"}, names); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	if got, expect := maxInflight, 2; got != expect {
+		t.Errorf("expected %d requests in parallel but got %d", expect, got)
+	}
+}
+
+func TestRunner_DryRun(t *testing.T) {
+	var requested int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requested, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		yaml   string
+		ok     bool
+		expect []string
+	}{
+		"valid": {
+			yaml: fmt.Sprintf(`
+title: valid
+vars:
+  url: %s
+steps:
+- title: create
+  protocol: http
+  request:
+    method: POST
+    url: '{{vars.url}}/items'
+  expect:
+    code: OK
+    body:
+      id: '{{assert.notZero}}'
+  bind:
+    vars:
+      id: '{{response.body.id}}'
+- title: get
+  protocol: http
+  request:
+    method: GET
+    url: '{{vars.url}}/items/{{vars.id}}'
+  expect:
+    code: OK
+    body:
+      id: '{{request.body.id}}'
+`, srv.URL),
+			ok: true,
+		},
+		"invalid": {
+			yaml: fmt.Sprintf(`
+title: invalid
+steps:
+- title: invalid template
+  protocol: http
+  request:
+    method: GET
+    url: '{{%s'
+- title: unknown assertion
+  protocol: http
+  request:
+    method: GET
+    url: %s
+  expect:
+    body:
+      id: '{{assert.unknown}}'
+- title: unknown function
+  protocol: http
+  request:
+    method: GET
+    url: '{{unknownFunc()}}'
+`, srv.URL, srv.URL),
+			expect: []string{
+				"--- FAIL: 0/invalid/invalid_template",
+				`failed to parse "{{http`,
+				"--- FAIL: 0/invalid/unknown_assertion",
+				`failed to execute: {{assert.unknown}}: ".assert.unknown" not found`,
+				"--- FAIL: 0/invalid/unknown_function",
+				`failed to execute: {{unknownFunc()}}: ".unknownFunc" not found`,
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r, err := NewRunner(
+				WithDryRun(true),
+				WithScenariosFromReader(strings.NewReader(test.yaml)),
+			)
+			if err != nil {
+				t.Fatalf("failed to create a runner: %s", err)
+			}
+			var b bytes.Buffer
+			ok := reporter.Run(func(rptr reporter.Reporter) {
+				r.Run(context.New(rptr))
+			}, reporter.WithWriter(&b), reporter.WithVerboseLog(), reporter.WithNoColor())
+			if ok != test.ok {
+				t.Fatalf("expect %t but got %t:\n%s", test.ok, ok, b.String())
+			}
+			for _, expect := range test.expect {
+				if !strings.Contains(b.String(), expect) {
+					t.Errorf("%q not found in the log:\n%s", expect, b.String())
+				}
+			}
+		})
+	}
+	if requested != 0 {
+		t.Errorf("expected no requests but got %d", requested)
+	}
+}
+
+func TestRunner_ContextValues(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"tenant": %q}`, r.Header.Get("Tenant-Id"))
+	}))
+	defer srv.Close()
+
+	r, err := NewRunner(
+		WithContextValues(map[string]any{"tenantID": "foo"}),
+		WithScenariosFromReader(strings.NewReader(fmt.Sprintf(`
+title: context values
+steps:
+- protocol: http
+  request:
+    method: GET
+    url: %s
+    header:
+      Tenant-Id: '{{ctx.tenantID}}'
+  expect:
+    body:
+      tenant: foo
+`, srv.URL))),
+	)
+	if err != nil {
+		t.Fatalf("failed to create a runner: %s", err)
+	}
+	var b bytes.Buffer
+	if ok := reporter.Run(func(rptr reporter.Reporter) {
+		r.Run(context.New(rptr))
+	}, reporter.WithWriter(&b), reporter.WithNoColor()); !ok {
+		t.Fatalf("test failed:\n%s", b.String())
+	}
+}
+
+func TestRunner_StepTimeout(t *testing.T) {
+	canceled := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow-body" {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush() //nolint:forcetypeassert
+		}
+		select {
+		case <-r.Context().Done():
+			canceled <- r.URL.Path
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		opts   []func(*Runner) error
+		path   string
+		step   string
+		expect string
+	}{
+		"default timeout": {
+			opts:   []func(*Runner) error{WithStepTimeout(100 * time.Millisecond)},
+			path:   "/slow",
+			expect: "timeout exceeded (default timeout 100ms)",
+		},
+		"default timeout from config": {
+			opts: []func(*Runner) error{WithConfig(&schema.Config{
+				Timeout: func() *schema.Duration {
+					d := schema.Duration(100 * time.Millisecond)
+					return &d
+				}(),
+			})},
+			path:   "/slow",
+			expect: "timeout exceeded (default timeout 100ms)",
+		},
+		"step timeout overrides default": {
+			opts:   []func(*Runner) error{WithStepTimeout(time.Hour)},
+			path:   "/slow",
+			step:   "timeout: 100ms",
+			expect: "timeout exceeded\n",
+		},
+		"reading response body": {
+			opts:   []func(*Runner) error{WithStepTimeout(100 * time.Millisecond)},
+			path:   "/slow-body",
+			expect: "timeout exceeded (default timeout 100ms)",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r, err := NewRunner(append(test.opts, WithScenariosFromReader(strings.NewReader(fmt.Sprintf(`
+title: timeout
+steps:
+- protocol: http
+  request:
+    method: GET
+    url: %s%s
+  expect:
+    code: OK
+  %s
+`, srv.URL, test.path, test.step))))...)
+			if err != nil {
+				t.Fatalf("failed to create a runner: %s", err)
+			}
+			var b bytes.Buffer
+			start := time.Now()
+			if ok := reporter.Run(func(rptr reporter.Reporter) {
+				r.Run(context.New(rptr))
+			}, reporter.WithWriter(&b), reporter.WithNoColor()); ok {
+				t.Fatalf("test passed:\n%s", b.String())
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("took too long to stop the request: %s", elapsed)
+			}
+			if !strings.Contains(b.String(), test.expect) {
+				t.Errorf("%q not found in the log:\n%s", test.expect, b.String())
+			}
+			select {
+			case path := <-canceled:
+				if path != test.path {
+					t.Errorf("expect %s is canceled but got %s", test.path, path)
+				}
+			case <-time.After(5 * time.Second):
+				t.Error("the request is not canceled")
+			}
+		})
+	}
+}
+
+func TestRunner_EnvAllowlist(t *testing.T) {
+	t.Setenv("TEST_ALLOWED", "allowed")
+	t.Setenv("TEST_DENIED", "denied")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-Env")))
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		opts   []func(*Runner) error
+		value  string
+		ok     bool
+		expect string
+	}{
+		"no allowlist": {
+			value: "{{env.TEST_DENIED}}",
+			ok:    true,
+		},
+		"allowed": {
+			opts:  []func(*Runner) error{WithEnvAllowlist([]string{"TEST_ALLOWED"})},
+			value: "{{env.TEST_ALLOWED}}",
+			ok:    true,
+		},
+		"not allowed": {
+			opts:   []func(*Runner) error{WithEnvAllowlist([]string{"TEST_ALLOWED"})},
+			value:  "{{env.TEST_DENIED}}",
+			expect: `".env.TEST_DENIED" not found`,
+		},
+		"not allowed by function": {
+			opts:   []func(*Runner) error{WithEnvAllowlist([]string{"TEST_ALLOWED"})},
+			value:  `{{env("TEST_DENIED")}}`,
+			expect: `env: environment variable "TEST_DENIED" is not allowed to read`,
+		},
+		"not allowed from config": {
+			opts: []func(*Runner) error{WithConfig(&schema.Config{
+				Env: schema.EnvConfig{Allowlist: []string{"TEST_ALLOWED"}},
+			})},
+			value:  "{{env.TEST_DENIED}}",
+			expect: `".env.TEST_DENIED" not found`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r, err := NewRunner(append(test.opts, WithScenariosFromReader(strings.NewReader(fmt.Sprintf(`
+title: env
+vars:
+  value: '%s'
+steps:
+- protocol: http
+  request:
+    method: GET
+    url: %s
+    header:
+      X-Env: '{{vars.value}}'
+  expect:
+    code: OK
+    body: '{{vars.value}}'
+`, test.value, srv.URL))))...)
+			if err != nil {
+				t.Fatalf("failed to create a runner: %s", err)
+			}
+			var b bytes.Buffer
+			ok := reporter.Run(func(rptr reporter.Reporter) {
+				r.Run(context.New(rptr))
+			}, reporter.WithWriter(&b), reporter.WithNoColor())
+			if ok != test.ok {
+				t.Fatalf("expect ok %t but got %t:\n%s", test.ok, ok, b.String())
+			}
+			if !strings.Contains(b.String(), test.expect) {
+				t.Errorf("%q not found in the log:\n%s", test.expect, b.String())
+			}
+		})
+	}
+}
+
 func TestRunner_Dump(t *testing.T) {
 	wd, err := os.Getwd()
 	if err != nil {
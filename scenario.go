@@ -9,15 +9,65 @@ import (
 	"github.com/zoncoen/scenarigo/context"
 	"github.com/zoncoen/scenarigo/errors"
 	"github.com/zoncoen/scenarigo/plugin"
+	"github.com/zoncoen/scenarigo/protocol"
+	"github.com/zoncoen/scenarigo/protocol/http"
 	"github.com/zoncoen/scenarigo/reporter"
 	"github.com/zoncoen/scenarigo/schema"
 )
 
 // RunScenario runs a test scenario s.
+// If s has cases, the scenario runs once per case as a subtest.
 func RunScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
+	if s.Cases == nil {
+		return runScenario(ctx, s)
+	}
+	cases, err := s.Cases.Load(filepath.Dir(s.Filepath()))
+	if err != nil {
+		ctx.Reporter().Fatalf("invalid cases: %s", err)
+	}
+	for i, c := range cases {
+		c := c
+		x, err := ctx.ExecuteTemplate(c)
+		if err != nil {
+			ctx.Reporter().Errorf("invalid cases[%d]: %s", i, err)
+			continue
+		}
+		ctx.Run(caseName(i, x), func(ctx *context.Context) {
+			s, err := s.Clone()
+			if err != nil {
+				ctx.Reporter().Fatalf("failed to copy scenario: %s", err)
+			}
+			_ = runScenario(ctx.WithCase(x), s)
+		})
+	}
+	return ctx
+}
+
+// caseName returns the test name of the case.
+// The name field of the case is used if it exists.
+func caseName(i int, c interface{}) string {
+	if m, ok := c.(map[string]interface{}); ok {
+		if name, ok := m["name"].(string); ok && name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("cases[%d]", i)
+}
+
+func runScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
 	ctx = ctx.WithScenarioFilepath(s.Filepath())
-	steps := context.NewSteps()
-	ctx = ctx.WithSteps(steps)
+	ctx = ctx.WithSteps(context.NewSteps())
+
+	// the connections are shared with the included scenarios and closed by the outermost scenario
+	if ctx.Connections() == nil {
+		conns := context.NewConnections()
+		ctx = ctx.WithConnections(conns)
+		defer func() {
+			if err := conns.Close(); err != nil {
+				ctx.Reporter().Log(err)
+			}
+		}()
+	}
 
 	var setups setupFuncList
 	if s.Plugins != nil {
@@ -50,6 +100,20 @@ func RunScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
 		ctx = ctx.WithVars(vars)
 	}
 
+	// register the secrets to mask them in the outputs
+	for i, secret := range s.Secrets {
+		v, err := ctx.ExecuteTemplate(secret)
+		if err != nil {
+			ctx.Reporter().Fatalf("invalid secrets[%d]: %s", i, err)
+		}
+		if v != nil {
+			reporter.AddSecret(ctx.Reporter(), fmt.Sprint(v))
+		}
+	}
+
+	// the auth of each request overrides the scenario-level one
+	ctx = http.WithAuth(ctx, s.Auth)
+
 	ctx, teardown := setups.setup(ctx)
 	if ctx.Reporter().Failed() {
 		if teardown != nil {
@@ -58,9 +122,79 @@ func RunScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
 		return ctx
 	}
 
+	// decode the steps of the protocols registered by the plugins and
+	// load resources such as certificates before running steps to find errors early
+	for _, list := range []struct {
+		name  string
+		steps []*schema.Step
+	}{
+		{name: "setup", steps: s.Setup},
+		{name: "steps", steps: s.Steps},
+		{name: "teardown", steps: s.Teardown},
+	} {
+		for idx, step := range list.steps {
+			if err := step.ResolveProtocol(); err != nil {
+				ctx.Reporter().Error(
+					errors.WithNodeAndColored(
+						errors.WithPath(err, fmt.Sprintf("%s[%d].protocol", list.name, idx)),
+						ctx.Node(),
+						ctx.EnabledColor(),
+					),
+				)
+				continue
+			}
+			p, ok := step.Request.(protocol.Preparer)
+			if !ok {
+				continue
+			}
+			if err := p.Prepare(ctx); err != nil {
+				ctx.Reporter().Error(
+					errors.WithNodeAndColored(
+						errors.WithPath(err, fmt.Sprintf("%s[%d].request", list.name, idx)),
+						ctx.Node(),
+						ctx.EnabledColor(),
+					),
+				)
+			}
+		}
+	}
+	if ctx.Reporter().Failed() {
+		if teardown != nil {
+			teardown(ctx)
+		}
+		return ctx
+	}
+
+	scnCtx := ctx
+	setupOK := true
+	if len(s.Setup) > 0 {
+		setupOK = ctx.Run("setup", func(ctx *context.Context) {
+			scnCtx = runSteps(ctx, s, "setup", s.Setup, false).WithReporter(scnCtx.Reporter())
+		})
+	}
+	if setupOK {
+		scnCtx = runSteps(scnCtx, s, "steps", s.Steps, false)
+	}
+	// like defer, all teardown steps run even if the preceding steps failed to clean up the resources
+	if len(s.Teardown) > 0 {
+		scnCtx.Run("teardown", func(ctx *context.Context) {
+			runSteps(ctx, s, "teardown", s.Teardown, true)
+		})
+	}
+
+	if teardown != nil {
+		teardown(scnCtx)
+	}
+
+	return scnCtx
+}
+
+// runSteps runs the steps sequentially and returns the context which has the bound variables.
+// The following steps are skipped if a step fails unless all is true.
+func runSteps(ctx *context.Context, s *schema.Scenario, section string, stepList []*schema.Step, all bool) *context.Context {
 	scnCtx := ctx
 	var failed bool
-	for idx, step := range s.Steps {
+	for idx, step := range stepList {
 		step := step
 		var stepCtx *context.Context
 		ok := context.RunWithRetry(scnCtx, step.Title, func(ctx *context.Context) {
@@ -75,7 +209,7 @@ func RunScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
 					errors.WithNodeAndColored(
 						errors.WithPath(
 							err,
-							fmt.Sprintf("steps[%d].if", idx),
+							fmt.Sprintf("%s[%d].if", section, idx),
 						),
 						stepCtx.Node(),
 						stepCtx.EnabledColor(),
@@ -89,13 +223,17 @@ func RunScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
 				reporter.NoFailurePropagation(stepCtx.Reporter())
 			}
 
-			if step.Timeout != nil && *step.Timeout > 0 {
-				reqCtx, cancel := gocontext.WithTimeout(stepCtx.RequestContext(), time.Duration(*step.Timeout))
+			timeout := stepCtx.StepTimeout()
+			if step.Timeout != nil {
+				timeout = time.Duration(*step.Timeout)
+			}
+			if timeout > 0 {
+				reqCtx, cancel := gocontext.WithTimeout(stepCtx.RequestContext(), timeout)
 				defer cancel()
 				stepCtx = stepCtx.WithRequestContext(reqCtx)
 			}
 
-			stepCtx = runStepWithTimeout(stepCtx, s, step, idx)
+			stepCtx = runStepWithTimeout(stepCtx, s, step, section, idx)
 
 			// bind values to the scenario context for enable to access from following steps
 			if step.Bind.Vars != nil {
@@ -105,7 +243,7 @@ func RunScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
 						errors.WithNodeAndColored(
 							errors.WrapPath(
 								err,
-								fmt.Sprintf("steps[%d].bind.vars", idx),
+								fmt.Sprintf("%s[%d].bind.vars", section, idx),
 								"invalid bind",
 							),
 							stepCtx.Node(),
@@ -116,23 +254,23 @@ func RunScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
 				scnCtx = scnCtx.WithVars(vars)
 			}
 		}, step.Retry)
-		if !ok && !step.ContinueOnError {
+		if !ok && !step.ContinueOnError && !all {
 			failed = true
 		}
 		if stepCtx == nil {
 			continue
 		}
 		if step.ID != "" {
-			steps.Add(step.ID, &context.Step{ //nolint:exhaustruct
-				Result: reporter.TestResultString(stepCtx.Reporter()),
+			elapsed, _ := stepCtx.Elapsed()
+			ctx.Steps().Add(step.ID, &context.Step{ //nolint:exhaustruct
+				Result:   reporter.TestResultString(stepCtx.Reporter()),
+				Elapsed:  elapsed,
+				Request:  stepCtx.Request(),
+				Response: stepCtx.InvokeResult(),
 			})
 		}
 	}
 
-	if teardown != nil {
-		teardown(scnCtx)
-	}
-
 	return scnCtx
 }
 
@@ -151,7 +289,7 @@ func executeIf(ctx *context.Context, expr string) (bool, error) {
 	return run, nil
 }
 
-func runStepWithTimeout(ctx *context.Context, scenario *schema.Scenario, step *schema.Step, idx int) *context.Context {
+func runStepWithTimeout(ctx *context.Context, scenario *schema.Scenario, step *schema.Step, section string, idx int) *context.Context {
 	done := make(chan *context.Context)
 	go func() {
 		var finished bool
@@ -160,18 +298,19 @@ func runStepWithTimeout(ctx *context.Context, scenario *schema.Scenario, step *s
 				done <- ctx
 			}
 		}()
-		done <- runStep(ctx, scenario, step, idx)
+		done <- runStep(ctx, scenario, step, section, idx)
 		finished = true
 	}()
 	select {
 	case ctx = <-done:
 	case <-ctx.RequestContext().Done():
+		err := errors.ErrorPath(fmt.Sprintf("%s[%d].timeout", section, idx), "timeout exceeded")
+		if step.Timeout == nil && ctx.StepTimeout() > 0 {
+			err = errors.ErrorPathf(fmt.Sprintf("%s[%d]", section, idx), "timeout exceeded (default timeout %s)", ctx.StepTimeout())
+		}
 		ctx.Reporter().Error(
 			errors.WithNodeAndColored(
-				errors.ErrorPath(
-					fmt.Sprintf("steps[%d].timeout", idx),
-					"timeout exceeded",
-				),
+				err,
 				ctx.Node(),
 				ctx.EnabledColor(),
 			),
@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// Cases represents the parameters of the data-driven scenario.
+// It is a list of maps or a path of CSV, JSON, or YAML file which contains the list.
+type Cases struct {
+	Values []interface{}
+	File   string
+}
+
+// MarshalYAML implements yaml.InterfaceMarshaler interface.
+func (c *Cases) MarshalYAML() (interface{}, error) {
+	if c.File != "" {
+		return c.File, nil
+	}
+	return c.Values, nil
+}
+
+// UnmarshalYAML implements yaml.BytesUnmarshaler interface.
+func (c *Cases) UnmarshalYAML(b []byte) error {
+	var s string
+	if err := yaml.Unmarshal(b, &s); err == nil {
+		c.File = s
+		return nil
+	}
+	var vs []interface{}
+	if err := yaml.Unmarshal(b, &vs); err != nil {
+		return errors.New("cases must be a list or a file path")
+	}
+	c.Values = vs
+	return nil
+}
+
+// Load returns the cases.
+// The file path is resolved from baseDir.
+func (c *Cases) Load(baseDir string) ([]interface{}, error) {
+	if c.File == "" {
+		return c.Values, nil
+	}
+	path := c.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read cases")
+	}
+	var vs []interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		vs, err = decodeCSVCases(b)
+	case ".json":
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		err = d.Decode(&vs)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &vs)
+	default:
+		return nil, errors.Errorf("failed to read cases: unsupported file type %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode cases %s", c.File)
+	}
+	return vs, nil
+}
+
+// decodeCSVCases decodes CSV whose first record is the header.
+// Each of the following records is converted to a map from the header to the field.
+func decodeCSVCases(b []byte) ([]interface{}, error) {
+	records, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	vs := make([]interface{}, 0, len(records)-1)
+	for _, record := range records[1:] {
+		m := make(map[string]interface{}, len(header))
+		for i, k := range header {
+			m[k] = record[i]
+		}
+		vs = append(vs, m)
+	}
+	return vs, nil
+}
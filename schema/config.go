@@ -14,6 +14,7 @@ import (
 
 	"github.com/zoncoen/scenarigo/errors"
 	"github.com/zoncoen/scenarigo/internal/filepathutil"
+	"github.com/zoncoen/scenarigo/protocol/http"
 )
 
 // Config represents a configuration.
@@ -23,8 +24,13 @@ type Config struct {
 	Scenarios       []string                         `yaml:"scenarios,omitempty"`
 	PluginDirectory string                           `yaml:"pluginDirectory,omitempty"`
 	Plugins         OrderedMap[string, PluginConfig] `yaml:"plugins,omitempty"`
+	Parallel        int                              `yaml:"parallel,omitempty"` // default value is 1, the scenario files run sequentially
+	Timeout         *Duration                        `yaml:"timeout,omitempty"`  // default timeout of the steps, 0 means no timeout
+	Env             EnvConfig                        `yaml:"env,omitempty"`
 	Input           InputConfig                      `yaml:"input,omitempty"`
 	Output          OutputConfig                     `yaml:"output,omitempty"`
+	HTTP            HTTPConfig                       `yaml:"http,omitempty"`
+	GRPC            GRPCConfig                       `yaml:"grpc,omitempty"`
 
 	// absolute path to the configuration file
 	Root     string          `yaml:"-"`
@@ -53,6 +59,32 @@ type YTTConfig struct {
 	DefaultFiles []string `yaml:"defaultFiles,omitempty"`
 }
 
+// EnvConfig represents a configuration for the environment variables.
+type EnvConfig struct {
+	// Allowlist restricts the environment variables templates can read by env.
+	// If it is nil, all variables can be read.
+	Allowlist []string `yaml:"allowlist,omitempty"`
+}
+
+// HTTPConfig represents a configuration for HTTP requests.
+type HTTPConfig struct {
+	// CookieJar enables the cookie jar for each scenario.
+	CookieJar bool `yaml:"cookieJar,omitempty"`
+	// Redirect is the default redirect policy of HTTP requests.
+	Redirect *http.RedirectPolicy `yaml:"redirect,omitempty"`
+	// TLS is the default TLS configuration of HTTP requests.
+	TLS *http.TLSConfig `yaml:"tls,omitempty"`
+	// Proxy is the default proxy configuration of HTTP requests.
+	Proxy *http.ProxyConfig `yaml:"proxy,omitempty"`
+}
+
+// GRPCConfig represents a configuration for gRPC requests.
+type GRPCConfig struct {
+	// DescriptorSets are the files of serialized google.protobuf.FileDescriptorSet.
+	// They are used to find the methods when the server reflection is unavailable.
+	DescriptorSets []string `yaml:"descriptorSets,omitempty"`
+}
+
 // OutputConfig represents an output configuration.
 type OutputConfig struct {
 	Verbose bool         `yaml:"verbose,omitempty"`
@@ -62,8 +94,11 @@ type OutputConfig struct {
 
 // ReportConfig represents a report configuration.
 type ReportConfig struct {
-	JSON  JSONReportConfig  `yaml:"json,omitempty"`
-	JUnit JUnitReportConfig `yaml:"junit,omitempty"`
+	JSON      JSONReportConfig      `yaml:"json,omitempty"`
+	JSONLines JSONLinesReportConfig `yaml:"jsonLines,omitempty"`
+	JUnit     JUnitReportConfig     `yaml:"junit,omitempty"`
+	TAP       TAPReportConfig       `yaml:"tap,omitempty"`
+	HTML      HTMLReportConfig      `yaml:"html,omitempty"`
 }
 
 // JSONReportConfig represents a JSON report configuration.
@@ -71,9 +106,26 @@ type JSONReportConfig struct {
 	Filename string `yaml:"filename,omitempty"`
 }
 
+// JSONLinesReportConfig represents a JSON Lines report configuration.
+// The report is written incrementally for each scenario file.
+type JSONLinesReportConfig struct {
+	Filename string `yaml:"filename,omitempty"`
+}
+
 // JUnitReportConfig represents a JUnit report configuration.
 type JUnitReportConfig struct {
 	Filename string `yaml:"filename,omitempty"`
+	TestCase string `yaml:"testCase,omitempty"` // "scenario" or "step", default value is "scenario"
+}
+
+// TAPReportConfig represents a TAP report configuration.
+type TAPReportConfig struct {
+	Filename string `yaml:"filename,omitempty"`
+}
+
+// HTMLReportConfig represents an HTML report configuration.
+type HTMLReportConfig struct {
+	Filename string `yaml:"filename,omitempty"`
 }
 
 // LoadConfig loads a configuration from path.
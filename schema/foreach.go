@@ -0,0 +1,41 @@
+package schema
+
+import (
+	"github.com/goccy/go-yaml"
+)
+
+// ForEach represents a loop which runs the steps for each item of the list.
+// The current item and its index can be referred by {{loop.item}} and {{loop.index}} in the steps.
+type ForEach struct {
+	Items    interface{} `yaml:"items"`
+	Parallel int         `yaml:"parallel,omitempty"` // default value is 1, the items are processed sequentially
+	Steps    []*Step     `yaml:"steps"`
+
+	raw []byte
+}
+
+type forEach ForEach
+
+// UnmarshalYAML implements yaml.BytesUnmarshaler interface.
+func (f *ForEach) UnmarshalYAML(b []byte) error {
+	var v forEach
+	if err := yaml.UnmarshalWithOptions(b, &v, yaml.UseOrderedMap(), yaml.Strict()); err != nil {
+		return err
+	}
+	*f = ForEach(v)
+	f.raw = b
+	return nil
+}
+
+// NewSteps returns the steps which are decoded again for each iteration.
+// Executing templates modifies the steps in place, so every iteration requires the new ones.
+func (f *ForEach) NewSteps() ([]*Step, error) {
+	if f.raw == nil {
+		return f.Steps, nil
+	}
+	var v forEach
+	if err := yaml.UnmarshalWithOptions(f.raw, &v, yaml.UseOrderedMap(), yaml.Strict()); err != nil {
+		return nil, err
+	}
+	return v.Steps, nil
+}
@@ -190,6 +190,9 @@ func loadScenariosFromFileAST(f *ast.File) ([]*Scenario, error) {
 		}
 		s.filepath = f.Name
 		s.Node = doc.Body
+		if p, ok := s.unknownProtocol(); ok && len(s.Plugins) == 0 {
+			return nil, fmt.Errorf("failed to decode YAML: unknown protocol: %s", p)
+		}
 		if err := s.Validate(); err != nil {
 			return nil, fmt.Errorf("validation error: %s: %w", s.filepath, err)
 		}
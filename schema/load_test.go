@@ -483,6 +483,36 @@ func TestLoadScenarios(t *testing.T) {
 	})
 }
 
+func TestLoadScenarios_PluginProtocol(t *testing.T) {
+	// the protocol may be registered by the plugins of the scenario
+	scenarios, err := LoadScenarios("testdata/plugin-protocol.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, expect := len(scenarios), 1; got != expect {
+		t.Fatalf("expect %d scenarios but got %d", expect, got)
+	}
+	step := scenarios[0].Steps[0]
+	if err := step.ResolveProtocol(); err == nil {
+		t.Fatal("no error")
+	} else if got, expect := err.Error(), "unknown protocol: pluginProtocol"; got != expect {
+		t.Fatalf("expect %q but got %q", expect, got)
+	}
+
+	p := &testProtocol{name: "pluginProtocol"}
+	protocol.Register(p)
+	defer protocol.Unregister(p.Name())
+	if err := step.ResolveProtocol(); err != nil {
+		t.Fatalf("failed to resolve protocol: %s", err)
+	}
+	if diff := cmp.Diff(&request{"body": "hello"}, step.Request); diff != "" {
+		t.Errorf("request differs (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(&expect{"body": "hello"}, step.Expect); diff != "" {
+		t.Errorf("expect differs (-want +got):\n%s", diff)
+	}
+}
+
 func TestLoadScenariosFromReader(t *testing.T) {
 	wd, err := os.Getwd()
 	if err != nil {
@@ -3,22 +3,36 @@ package schema
 import (
 	"context"
 	"errors"
+	"math"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 )
 
 // RetryPolicy represents a retry policy.
+// The backoff can be specified by either one of constant, exponential,
+// or the shorthand fields (maxAttempts, interval, maxInterval, and multiplier).
 type RetryPolicy struct {
 	Constant    *RetryPolicyConstant    `yaml:"constant,omitempty"`
 	Exponential *RetryPolicyExponential `yaml:"exponential,omitempty"`
+
+	MaxAttempts *int      `yaml:"maxAttempts,omitempty"` // default value is 3, includes the first attempt
+	Interval    *Duration `yaml:"interval,omitempty"`    // default value is 1s
+	MaxInterval *Duration `yaml:"maxInterval,omitempty"` // default value is 0, 0 means no limit
+	Multiplier  *float64  `yaml:"multiplier,omitempty"`  // default value is 2
 }
 
 // Build returns p as backoff.BackOff.
 // If p is nil, Build returns the policy which never retry.
 func (p *RetryPolicy) Build(ctx context.Context) (context.Context, func(), backoff.BackOff, error) {
 	if p != nil {
-		if p.Constant != nil && p.Exponential != nil {
+		var n int
+		for _, ok := range []bool{p.Constant != nil, p.Exponential != nil, p.hasBackoffFields()} {
+			if ok {
+				n++
+			}
+		}
+		if n > 1 {
 			return nil, nil, nil, errors.New("ambiguous retry policy")
 		}
 		if p.Constant != nil {
@@ -27,10 +41,53 @@ func (p *RetryPolicy) Build(ctx context.Context) (context.Context, func(), backo
 		if p.Exponential != nil {
 			return p.Exponential.Build(ctx)
 		}
+		if p.hasBackoffFields() {
+			return p.buildBackoff(ctx)
+		}
 	}
 	return ctx, func() {}, &backoff.StopBackOff{}, nil
 }
 
+func (p *RetryPolicy) hasBackoffFields() bool {
+	return p.MaxAttempts != nil || p.Interval != nil || p.MaxInterval != nil || p.Multiplier != nil
+}
+
+// buildBackoff returns the exponential backoff policy specified by the shorthand fields.
+// The intervals are not randomized unlike RetryPolicyExponential.
+func (p *RetryPolicy) buildBackoff(ctx context.Context) (context.Context, func(), backoff.BackOff, error) {
+	maxAttempts := 3
+	if p.MaxAttempts != nil {
+		if *p.MaxAttempts < 1 {
+			return nil, nil, nil, errors.New("maxAttempts must be greater than 0")
+		}
+		maxAttempts = *p.MaxAttempts
+	}
+	eb := backoff.NewExponentialBackOff()
+	eb.InitialInterval = time.Second
+	if p.Interval != nil {
+		if *p.Interval < 0 {
+			return nil, nil, nil, errors.New("interval must not be negative")
+		}
+		eb.InitialInterval = time.Duration(*p.Interval)
+	}
+	eb.Multiplier = 2
+	if p.Multiplier != nil {
+		if *p.Multiplier < 1 {
+			return nil, nil, nil, errors.New("multiplier must be greater than or equal to 1")
+		}
+		eb.Multiplier = *p.Multiplier
+	}
+	eb.MaxInterval = time.Duration(math.MaxInt64)
+	if p.MaxInterval != nil && *p.MaxInterval > 0 {
+		eb.MaxInterval = time.Duration(*p.MaxInterval)
+	}
+	eb.RandomizationFactor = 0
+	eb.MaxElapsedTime = 0
+	eb.Reset()
+	b := backoff.WithMaxRetries(eb, uint64(maxAttempts-1))
+	return ctx, func() {}, backoff.WithContext(b, ctx), nil
+}
+
 func maxElapsedTimeContextFunc(ctx context.Context, t *Duration, b backoff.BackOff) (context.Context, func(), backoff.BackOff) {
 	if t == nil || *t == 0 {
 		return ctx, func() {}, b
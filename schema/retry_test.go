@@ -210,6 +210,107 @@ func TestRetryPolicyExponential(t *testing.T) {
 	})
 }
 
+func TestRetryPolicyBackoff(t *testing.T) {
+	t.Run("max attempts", func(t *testing.T) {
+		maxAttempts := 3
+		interval := time.Millisecond
+		p := &RetryPolicy{
+			MaxAttempts: &maxAttempts,
+			Interval:    (*Duration)(&interval),
+		}
+		_, cancel, b, err := p.Build(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cancel()
+
+		var i int
+		_ = backoff.Retry(func() error {
+			i++
+			return errors.New("retry")
+		}, b)
+		if got, expect := i, 3; got != expect {
+			t.Errorf("expect %d but got %d", expect, got)
+		}
+	})
+	t.Run("intervals", func(t *testing.T) {
+		maxAttempts := 5
+		interval := 100 * time.Millisecond
+		maxInterval := 300 * time.Millisecond
+		multiplier := 2.0
+		p := &RetryPolicy{
+			MaxAttempts: &maxAttempts,
+			Interval:    (*Duration)(&interval),
+			MaxInterval: (*Duration)(&maxInterval),
+			Multiplier:  &multiplier,
+		}
+		_, cancel, b, err := p.Build(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cancel()
+
+		var got []time.Duration
+		for d := b.NextBackOff(); d != backoff.Stop; d = b.NextBackOff() {
+			got = append(got, d)
+		}
+		expect := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+		if len(got) != len(expect) {
+			t.Fatalf("expect %v but got %v", expect, got)
+		}
+		for i := range expect {
+			if got[i] != expect[i] {
+				t.Fatalf("expect %v but got %v", expect, got)
+			}
+		}
+	})
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		interval := time.Hour
+		p := &RetryPolicy{
+			Interval: (*Duration)(&interval),
+		}
+		_, cleanup, b, err := p.Build(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+
+		var i int
+		err = backoff.Retry(func() error {
+			i++
+			return errors.New("retry")
+		}, b)
+		if got, expect := i, 1; got != expect {
+			t.Errorf("expect %d but got %d", expect, got)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expect context.Canceled but got %v", err)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		maxAttempts := 0
+		multiplier := 0.5
+		tests := map[string]*RetryPolicy{
+			"max attempts": {MaxAttempts: &maxAttempts},
+			"multiplier":   {Multiplier: &multiplier},
+			"ambiguous": {
+				Constant:    &RetryPolicyConstant{},
+				MaxAttempts: &maxAttempts,
+			},
+		}
+		for name, p := range tests {
+			p := p
+			t.Run(name, func(t *testing.T) {
+				if _, _, _, err := p.Build(context.Background()); err == nil {
+					t.Fatal("no error")
+				}
+			})
+		}
+	})
+}
+
 func TestRetryNoPolicy(t *testing.T) {
 	p := &RetryPolicy{}
 	_, cancel, b, err := p.Build(context.Background())
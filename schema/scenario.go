@@ -2,13 +2,18 @@
 package schema
 
 import (
+	"bytes"
 	"fmt"
 	"regexp"
 
+	"github.com/goccy/go-yaml"
 	"github.com/goccy/go-yaml/ast"
 
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
 	"github.com/zoncoen/scenarigo/errors"
 	"github.com/zoncoen/scenarigo/protocol"
+	"github.com/zoncoen/scenarigo/protocol/http"
 )
 
 const stepIDPattern = `^[a-zA-Z0-9\-_]+$`
@@ -20,9 +25,15 @@ type Scenario struct {
 	SchemaVersion string                 `yaml:"schemaVersion,omitempty"`
 	Title         string                 `yaml:"title,omitempty"`
 	Description   string                 `yaml:"description,omitempty"`
+	Serial        bool                   `yaml:"serial,omitempty"` // run exclusively while the other scenarios run in parallel
 	Plugins       map[string]string      `yaml:"plugins,omitempty"`
 	Vars          map[string]interface{} `yaml:"vars,omitempty"`
+	Secrets       []string               `yaml:"secrets,omitempty"`
+	Auth          *http.AuthConfig       `yaml:"auth,omitempty"` // default auth of the HTTP requests
+	Cases         *Cases                 `yaml:"cases,omitempty"`
+	Setup         []*Step                `yaml:"setup,omitempty"`
 	Steps         []*Step                `yaml:"steps,omitempty"`
+	Teardown      []*Step                `yaml:"teardown,omitempty"`
 
 	// The strict YAML decoder fails to decode if finds an unknown field.
 	// Anchors is the field for enabling to define YAML anchors by avoiding the error.
@@ -38,35 +49,71 @@ func (s *Scenario) Filepath() string {
 	return s.filepath
 }
 
+// Clone returns a new scenario which is decoded from the YAML node of s again.
+// Executing templates modifies the scenario in place, so running it multiple times requires the clone.
+// It returns s itself if s doesn't have the node.
+func (s *Scenario) Clone() (*Scenario, error) {
+	if s.Node == nil {
+		return s, nil
+	}
+	var buf bytes.Buffer
+	dec := yaml.NewDecoder(&buf, yaml.UseOrderedMap(), yaml.Strict())
+	var clone Scenario
+	if err := dec.DecodeFromNode(s.Node, &clone); err != nil {
+		return nil, fmt.Errorf("failed to decode YAML: %w", err)
+	}
+	clone.filepath = s.filepath
+	clone.Node = s.Node
+	return &clone, nil
+}
+
 // Validate validates a scenario.
+// Unknown protocols are allowed if the scenario has plugins because they may register the protocols.
 func (s *Scenario) Validate() error {
 	ids := map[string]struct{}{}
-	for i, stp := range s.Steps {
+	if err := s.validateSteps("setup", s.Setup, ids); err != nil {
+		return err
+	}
+	if err := s.validateSteps("steps", s.Steps, ids); err != nil {
+		return err
+	}
+	return s.validateSteps("teardown", s.Teardown, ids)
+}
+
+func (s *Scenario) validateSteps(path string, steps []*Step, ids map[string]struct{}) error {
+	for i, stp := range steps {
 		if stp.ID != "" {
 			if !stepIDRegexp.MatchString(stp.ID) {
 				return errors.WithNode(
-					errors.ErrorPath(fmt.Sprintf("steps[%d].id", i), "step id must contain only alphanumeric characters, -, or _"),
+					errors.ErrorPath(fmt.Sprintf("%s[%d].id", path, i), "step id must contain only alphanumeric characters, -, or _"),
 					s.Node,
 				)
 			}
 			if _, ok := ids[stp.ID]; ok {
 				return errors.WithNode(
-					errors.ErrorPathf(fmt.Sprintf("steps[%d].id", i), "step id %q is duplicated", stp.ID),
+					errors.ErrorPathf(fmt.Sprintf("%s[%d].id", path, i), "step id %q is duplicated", stp.ID),
 					s.Node,
 				)
 			}
 			ids[stp.ID] = struct{}{}
 		}
 
+		if stp.ForEach != nil {
+			if err := s.validateSteps(fmt.Sprintf("%s[%d].foreach.steps", path, i), stp.ForEach.Steps, ids); err != nil {
+				return err
+			}
+			continue
+		}
+
 		if stp.Include == "" && stp.Ref == nil {
 			if stp.Protocol == "" {
 				return errors.WithNode(
-					errors.ErrorPath(fmt.Sprintf("steps[%d]", i), "no protocol"),
+					errors.ErrorPath(fmt.Sprintf("%s[%d]", path, i), "no protocol"),
 					s.Node,
 				)
-			} else if protocol.Get(stp.Protocol) == nil {
+			} else if protocol.Get(stp.Protocol) == nil && len(s.Plugins) == 0 {
 				return errors.WithNode(
-					errors.ErrorPathf(fmt.Sprintf("steps[%d].protocol", i), "protocol %q not found", stp.Protocol),
+					errors.ErrorPathf(fmt.Sprintf("%s[%d].protocol", path, i), "protocol %q not found", stp.Protocol),
 					s.Node,
 				)
 			}
@@ -75,6 +122,30 @@ func (s *Scenario) Validate() error {
 	return nil
 }
 
+// unknownProtocol returns the unknown protocol of the steps which has the request or expect.
+func (s *Scenario) unknownProtocol() (string, bool) {
+	var find func(steps []*Step) (string, bool)
+	find = func(steps []*Step) (string, bool) {
+		for _, stp := range steps {
+			if stp.hasUnresolvedRequestOrExpect() {
+				return stp.Protocol, true
+			}
+			if stp.ForEach != nil {
+				if p, ok := find(stp.ForEach.Steps); ok {
+					return p, true
+				}
+			}
+		}
+		return "", false
+	}
+	for _, steps := range [][]*Step{s.Setup, s.Steps, s.Teardown} {
+		if p, ok := find(steps); ok {
+			return p, true
+		}
+	}
+	return "", false
+}
+
 // Step represents a step of scenario.
 type Step struct {
 	ID                      string                    `yaml:"id,omitempty" validate:"alphanum"`
@@ -87,11 +158,13 @@ type Step struct {
 	Request                 protocol.Invoker          `yaml:"request,omitempty"`
 	Expect                  protocol.AssertionBuilder `yaml:"expect,omitempty"`
 	Include                 string                    `yaml:"include,omitempty"`
+	ForEach                 *ForEach                  `yaml:"foreach,omitempty"`
 	Ref                     interface{}               `yaml:"ref,omitempty"`
 	Bind                    Bind                      `yaml:"bind,omitempty"`
 	Timeout                 *Duration                 `yaml:"timeout,omitempty"`
 	PostTimeoutWaitingLimit *Duration                 `yaml:"postTimeoutWaitingLimit,omitempty"`
 	Retry                   *RetryPolicy              `yaml:"retry,omitempty"`
+	Until                   *Until                    `yaml:"until,omitempty"`
 }
 
 type rawMessage []byte
@@ -111,11 +184,13 @@ type stepUnmarshaller struct {
 	Vars                    map[string]interface{} `yaml:"vars,omitempty"`
 	Protocol                string                 `yaml:"protocol,omitempty"`
 	Include                 string                 `yaml:"include,omitempty"`
+	ForEach                 *ForEach               `yaml:"foreach,omitempty"`
 	Ref                     interface{}            `yaml:"ref,omitempty"`
 	Bind                    Bind                   `yaml:"bind,omitempty"`
 	Timeout                 *Duration              `yaml:"timeout,omitempty"`
 	PostTimeoutWaitingLimit *Duration              `yaml:"postTimeoutWaitingLimit,omitempty"`
 	Retry                   *RetryPolicy           `yaml:"retry,omitempty"`
+	Until                   *Until                 `yaml:"until,omitempty"`
 
 	Request rawMessage `yaml:"request,omitempty"`
 	Expect  rawMessage `yaml:"expect,omitempty"`
@@ -137,27 +212,88 @@ func (s *Step) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	s.Vars = unmarshaled.Vars
 	s.Protocol = unmarshaled.Protocol
 	s.Include = unmarshaled.Include
+	s.ForEach = unmarshaled.ForEach
 	s.Ref = unmarshaled.Ref
 	s.Bind = unmarshaled.Bind
 	s.Timeout = unmarshaled.Timeout
 	s.PostTimeoutWaitingLimit = unmarshaled.PostTimeoutWaitingLimit
 	s.Retry = unmarshaled.Retry
+	s.Until = unmarshaled.Until
 
 	p := protocol.Get(s.Protocol)
 	if p == nil {
-		if unmarshaled.Request != nil || unmarshaled.Expect != nil {
-			return errors.Errorf("unknown protocol: %s", s.Protocol)
+		if s.Protocol != "" {
+			// The protocol may be registered later by the plugins of the scenario.
+			if unmarshaled.Request != nil {
+				s.Request = &unresolvedRequest{protocol: s.Protocol, raw: unmarshaled.Request}
+			}
+			s.Expect = &unresolvedExpect{protocol: s.Protocol, raw: unmarshaled.Expect}
 		}
 		return nil
 	}
-	if unmarshaled.Request != nil {
-		invoker, err := p.UnmarshalRequest(unmarshaled.Request)
+	return s.unmarshalRequestAndExpect(p, unmarshaled.Request, unmarshaled.Expect)
+}
+
+// ResolveProtocol decodes the request and expect of s by the protocol which is registered after decoding s, such as by the plugins of the scenario.
+// It does nothing if they are already decoded.
+func (s *Step) ResolveProtocol() error {
+	expect, ok := s.Expect.(*unresolvedExpect)
+	if !ok {
+		return nil
+	}
+	p := protocol.Get(s.Protocol)
+	if p == nil {
+		return errors.Errorf("unknown protocol: %s", s.Protocol)
+	}
+	var req rawMessage
+	if r, ok := s.Request.(*unresolvedRequest); ok {
+		req = r.raw
+	}
+	return s.unmarshalRequestAndExpect(p, req, expect.raw)
+}
+
+// hasUnresolvedRequestOrExpect reports whether s has the request or expect which is not decoded yet.
+func (s *Step) hasUnresolvedRequestOrExpect() bool {
+	if _, ok := s.Request.(*unresolvedRequest); ok {
+		return true
+	}
+	if e, ok := s.Expect.(*unresolvedExpect); ok && e.raw != nil {
+		return true
+	}
+	return false
+}
+
+// unresolvedRequest is the request whose protocol is not registered when decoding.
+type unresolvedRequest struct {
+	protocol string
+	raw      rawMessage
+}
+
+// Invoke implements protocol.Invoker interface.
+func (r *unresolvedRequest) Invoke(ctx *context.Context) (*context.Context, interface{}, error) {
+	return ctx, nil, errors.Errorf("unknown protocol: %s", r.protocol)
+}
+
+// unresolvedExpect is the expect whose protocol is not registered when decoding.
+type unresolvedExpect struct {
+	protocol string
+	raw      rawMessage
+}
+
+// Build implements protocol.AssertionBuilder interface.
+func (e *unresolvedExpect) Build(_ *context.Context) (assert.Assertion, error) {
+	return nil, errors.Errorf("unknown protocol: %s", e.protocol)
+}
+
+func (s *Step) unmarshalRequestAndExpect(p protocol.Protocol, req, expect rawMessage) error {
+	if req != nil {
+		invoker, err := p.UnmarshalRequest(req)
 		if err != nil {
 			return err
 		}
 		s.Request = invoker
 	}
-	builder, err := p.UnmarshalExpect(unmarshaled.Expect)
+	builder, err := p.UnmarshalExpect(expect)
 	if err != nil {
 		return err
 	}
@@ -171,6 +307,14 @@ type Bind struct {
 	Vars map[string]interface{} `yaml:"vars"`
 }
 
+// Until represents a polling condition of a step.
+// The request is sent repeatedly until the condition is satisfied or the timeout elapses.
+type Until struct {
+	Condition string    `yaml:"condition"`
+	Interval  *Duration `yaml:"interval,omitempty"` // default value is 1s
+	Timeout   *Duration `yaml:"timeout,omitempty"`  // default value is 1m
+}
+
 type anchors struct{}
 
 // UnmarshalYAML implements yaml.Unmarshaler interface.
@@ -3,17 +3,23 @@ package scenarigo
 import (
 	"fmt"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/zoncoen/scenarigo/assert"
 	"github.com/zoncoen/scenarigo/context"
 	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/internal/reflectutil"
 	"github.com/zoncoen/scenarigo/plugin"
 	"github.com/zoncoen/scenarigo/reporter"
 	"github.com/zoncoen/scenarigo/schema"
+	"github.com/zoncoen/scenarigo/template"
 )
 
-func runStep(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, stepIdx int) *context.Context {
+func runStep(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, section string, stepIdx int) *context.Context {
+	// the templates of a step share the generated values such as uuid()
+	ctx = ctx.WithTemplateState(template.NewState())
 	if s.Vars != nil {
 		vars, err := ctx.ExecuteTemplate(s.Vars)
 		if err != nil {
@@ -21,7 +27,7 @@ func runStep(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, st
 				errors.WithNodeAndColored(
 					errors.WrapPath(
 						err,
-						fmt.Sprintf("steps[%d].vars", stepIdx),
+						fmt.Sprintf("%s[%d].vars", section, stepIdx),
 						"invalid vars",
 					),
 					ctx.Node(),
@@ -32,6 +38,9 @@ func runStep(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, st
 		ctx = ctx.WithVars(vars)
 	}
 
+	if s.ForEach != nil {
+		return runForEach(ctx, scenario, s, section, stepIdx)
+	}
 	if s.Include != "" {
 		baseDir := filepath.Dir(scenario.Filepath())
 		include := filepath.Join(baseDir, s.Include)
@@ -46,16 +55,20 @@ func runStep(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, st
 		if err != nil {
 			ctx.Reporter().Fatalf(`failed to include "%s" as step: %s`, s.Include, err)
 		}
-		currentNode := ctx.Node()
+		includes, err := appendInclude(ctx, scenario, include)
+		if err != nil {
+			ctx.Reporter().Fatalf(`failed to include "%s" as step: %s`, s.Include, err)
+		}
+		currentNode, currentIncludes := ctx.Node(), ctx.Includes()
 		ctx.Reporter().Run(testName, func(rptr reporter.Reporter) {
-			ctx = RunScenario(ctx.WithReporter(rptr).WithNode(scenarios[0].Node), scenarios[0])
+			ctx = RunScenario(ctx.WithReporter(rptr).WithNode(scenarios[0].Node).WithIncludes(includes), scenarios[0])
 		})
 		if ctx.Reporter().Failed() {
 			ctx.Reporter().FailNow()
 		}
 
-		// back node to current node
-		ctx = ctx.WithNode(currentNode)
+		// back node and includes to current ones
+		ctx = ctx.WithNode(currentNode).WithIncludes(currentIncludes)
 		return ctx
 	}
 	if s.Ref != nil {
@@ -65,7 +78,7 @@ func runStep(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, st
 				errors.WithNodeAndColored(
 					errors.WrapPathf(
 						err,
-						fmt.Sprintf("steps[%d].ref", stepIdx),
+						fmt.Sprintf("%s[%d].ref", section, stepIdx),
 						`failed to reference "%s" as step`, s.Ref,
 					),
 					ctx.Node(),
@@ -78,7 +91,7 @@ func runStep(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, st
 			ctx.Reporter().Fatal(
 				errors.WithNodeAndColored(
 					errors.ErrorPathf(
-						fmt.Sprintf("steps[%d].ref", stepIdx),
+						fmt.Sprintf("%s[%d].ref", section, stepIdx),
 						`failed to reference "%s" as step: not implement plugin.Step interface`, s.Ref,
 					),
 					ctx.Node(),
@@ -92,36 +105,145 @@ func runStep(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, st
 		return ctx
 	}
 
-	return invokeAndAssert(ctx, s, stepIdx)
+	// the steps in loops and included scenarios are decoded after the plugins are opened
+	if err := s.ResolveProtocol(); err != nil {
+		ctx.Reporter().Fatal(
+			errors.WithNodeAndColored(
+				errors.WithPath(err, fmt.Sprintf("%s[%d].protocol", section, stepIdx)),
+				ctx.Node(),
+				ctx.EnabledColor(),
+			),
+		)
+	}
+	return invokeAndAssert(ctx, s, section, stepIdx)
 }
 
-func invokeAndAssert(ctx *context.Context, s *schema.Step, stepIdx int) *context.Context {
-	reqTime := time.Now()
-	newCtx, resp, err := s.Request.Invoke(ctx)
-	ctx.Reporter().Logf("elapsed time: %f sec", time.Since(reqTime).Seconds())
+// appendInclude returns the filepaths of the including scenarios with the included one.
+// It returns an error if the included scenario is already being included.
+func appendInclude(ctx *context.Context, scenario *schema.Scenario, include string) ([]string, error) {
+	includes := ctx.Includes()
+	if len(includes) == 0 {
+		path, err := filepath.Abs(scenario.Filepath())
+		if err != nil {
+			return nil, err
+		}
+		includes = []string{path}
+	}
+	path, err := filepath.Abs(include)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range includes {
+		if p == path {
+			return nil, fmt.Errorf("cyclic include: %s -> %s", strings.Join(includes, " -> "), path)
+		}
+	}
+	return append(append([]string{}, includes...), path), nil
+}
 
+// runForEach runs the steps of the foreach loop for each item as subtests named by the index.
+// The items are processed concurrently up to the parallel limit.
+func runForEach(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, section string, stepIdx int) *context.Context {
+	x, err := ctx.ExecuteTemplate(s.ForEach.Items)
 	if err != nil {
 		ctx.Reporter().Fatal(
 			errors.WithNodeAndColored(
-				errors.WithPath(err, fmt.Sprintf("steps[%d].request", stepIdx)),
+				errors.WrapPath(err, fmt.Sprintf("%s[%d].foreach.items", section, stepIdx), "invalid items"),
+				ctx.Node(),
+				ctx.EnabledColor(),
+			),
+		)
+	}
+	items := reflectutil.Elem(reflect.ValueOf(x))
+	if !items.IsValid() {
+		return ctx
+	}
+	if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+		ctx.Reporter().Fatal(
+			errors.WithNodeAndColored(
+				errors.ErrorPathf(fmt.Sprintf("%s[%d].foreach.items", section, stepIdx), "items must be a list but got %T", x),
 				ctx.Node(),
 				ctx.EnabledColor(),
 			),
 		)
 	}
+
+	run := func(ctx *context.Context, loop *context.Loop) {
+		steps, err := s.ForEach.NewSteps()
+		if err != nil {
+			ctx.Reporter().Fatal(
+				errors.WithNodeAndColored(
+					errors.WrapPath(err, fmt.Sprintf("%s[%d].foreach.steps", section, stepIdx), "invalid steps"),
+					ctx.Node(),
+					ctx.EnabledColor(),
+				),
+			)
+		}
+		runSteps(ctx.WithLoop(loop), scenario, "steps", steps, false)
+	}
+	if s.ForEach.Parallel <= 1 {
+		for i := 0; i < items.Len(); i++ {
+			loop := &context.Loop{
+				Item:  items.Index(i).Interface(),
+				Index: i,
+			}
+			ctx.Run(fmt.Sprintf("foreach[%d]", i), func(ctx *context.Context) {
+				run(ctx, loop)
+			})
+		}
+		return ctx
+	}
+	tests := make([]reporter.ParallelTest, items.Len())
+	for i := range tests {
+		loop := &context.Loop{
+			Item:  items.Index(i).Interface(),
+			Index: i,
+		}
+		tests[i] = reporter.ParallelTest{
+			Name: fmt.Sprintf("foreach[%d]", i),
+			F: func(rptr reporter.Reporter) {
+				run(ctx.WithReporter(rptr), loop)
+			},
+		}
+	}
+	reporter.RunParallel(ctx.Reporter(), s.ForEach.Parallel, tests)
+	return ctx
+}
+
+func invokeAndAssert(ctx *context.Context, s *schema.Step, section string, stepIdx int) *context.Context {
+	var (
+		newCtx *context.Context
+		resp   interface{}
+	)
+	if s.Until != nil {
+		var ok bool
+		newCtx, resp, ok = invokeUntil(ctx, s, section, stepIdx)
+		if !ok {
+			return newCtx
+		}
+	} else {
+		newCtx, resp = invoke(ctx, s, section, stepIdx)
+	}
+	newCtx = newCtx.WithInvokeResult(resp)
 	assertion, err := s.Expect.Build(newCtx)
 	if err != nil {
 		ctx.Reporter().Fatal(
 			errors.WithNodeAndColored(
-				errors.WithPath(err, fmt.Sprintf("steps[%d].expect", stepIdx)),
+				errors.WithPath(err, fmt.Sprintf("%s[%d].expect", section, stepIdx)),
 				ctx.Node(),
 				ctx.EnabledColor(),
 			),
 		)
 	}
+	details := &reporter.StepDetails{
+		Request:  newCtx.Request(),
+		Response: newCtx.Response(),
+	}
+	defer reporter.SetStepDetails(ctx.Reporter(), details)
 	if err := assertion.Assert(resp); err != nil {
+		details.Diffs = assertionDiffs(err)
 		err = errors.WithNodeAndColored(
-			errors.WithPath(err, fmt.Sprintf("steps[%d].expect", stepIdx)),
+			errors.WithPath(err, fmt.Sprintf("%s[%d].expect", section, stepIdx)),
 			ctx.Node(),
 			ctx.EnabledColor(),
 		)
@@ -137,3 +259,94 @@ func invokeAndAssert(ctx *context.Context, s *schema.Step, stepIdx int) *context
 	}
 	return newCtx
 }
+
+// assertionDiffs returns the differences found by the assertion for the test reports.
+func assertionDiffs(err error) []reporter.Diff {
+	errs := []error{err}
+	var assertErr *assert.Error
+	if errors.As(err, &assertErr) {
+		errs = assertErr.Errors
+	}
+	var diffs []reporter.Diff
+	for _, err := range errs {
+		for _, diff := range errors.Diffs(err) {
+			diffs = append(diffs, reporter.Diff{
+				Path:     diff.Path,
+				Matcher:  diff.Matcher,
+				Expected: diff.Expected,
+				Actual:   diff.Actual,
+			})
+		}
+	}
+	return diffs
+}
+
+func invoke(ctx *context.Context, s *schema.Step, section string, stepIdx int) (*context.Context, interface{}) {
+	reqTime := time.Now()
+	newCtx, resp, err := s.Request.Invoke(ctx)
+	ctx.Reporter().Logf("elapsed time: %f sec", time.Since(reqTime).Seconds())
+
+	if err != nil {
+		ctx.Reporter().Fatal(
+			errors.WithNodeAndColored(
+				errors.WithPath(err, fmt.Sprintf("%s[%d].request", section, stepIdx)),
+				ctx.Node(),
+				ctx.EnabledColor(),
+			),
+		)
+	}
+	return newCtx, resp
+}
+
+// invokeUntil invokes the request repeatedly until the condition of the step is satisfied.
+// It reports false with the last result if the condition isn't satisfied before the timeout.
+func invokeUntil(ctx *context.Context, s *schema.Step, section string, stepIdx int) (*context.Context, interface{}, bool) {
+	interval := time.Second
+	if s.Until.Interval != nil {
+		interval = time.Duration(*s.Until.Interval)
+	}
+	timeout := time.Minute
+	if s.Until.Timeout != nil {
+		timeout = time.Duration(*s.Until.Timeout)
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for attempts := 1; ; attempts++ {
+		newCtx, resp := invoke(ctx, s, section, stepIdx)
+		ok, err := executeIf(newCtx, s.Until.Condition)
+		if err != nil {
+			ctx.Reporter().Fatal(
+				errors.WithNodeAndColored(
+					errors.WithPath(err, fmt.Sprintf("%s[%d].until.condition", section, stepIdx)),
+					ctx.Node(),
+					ctx.EnabledColor(),
+				),
+			)
+		}
+		if ok {
+			return newCtx, resp, true
+		}
+
+		var reason string
+		select {
+		case <-time.After(interval):
+			continue
+		case <-timer.C:
+			reason = fmt.Sprintf("timeout %s exceeded", timeout)
+		case <-ctx.RequestContext().Done():
+			reason = ctx.RequestContext().Err().Error()
+		}
+		ctx.Reporter().Error(
+			errors.WithNodeAndColored(
+				errors.ErrorPathf(
+					fmt.Sprintf("%s[%d].until", section, stepIdx),
+					"condition is not satisfied after %d attempts: %s", attempts, reason,
+				),
+				ctx.Node(),
+				ctx.EnabledColor(),
+			),
+		)
+		return newCtx, resp, false
+	}
+}
@@ -0,0 +1,45 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zoncoen/scenarigo/template/ast"
+)
+
+// defaultFunc is a function whose value argument may refer to an undefined path.
+// The template evaluates a call of it by executeDefaultFunc instead of calling it with the evaluated arguments.
+type defaultFunc func(fallback, v any) any
+
+// defaultValue returns v, or fallback if v is nil.
+func defaultValue(fallback, v any) any {
+	if v == nil {
+		return fallback
+	}
+	return v
+}
+
+// executeDefaultFunc evaluates default(fallback, v).
+// It returns the fallback if v is undefined or nil, and the fallback is evaluated only in that case.
+// Present zero values such as "" and 0 are returned as they are.
+func (t *Template) executeDefaultFunc(f defaultFunc, call *ast.CallExpr, data any) (any, error) {
+	if len(call.Args) != 2 {
+		return nil, fmt.Errorf("default: expected 2 arguments but got %d", len(call.Args))
+	}
+	v, err := t.executeExpr(call.Args[1], data)
+	if err != nil {
+		var notDefined errNotDefined
+		if !errors.As(err, &notDefined) {
+			return nil, err
+		}
+		v = nil
+	}
+	if v != nil {
+		return v, nil
+	}
+	fallback, err := t.executeExpr(call.Args[0], data)
+	if err != nil {
+		return nil, err
+	}
+	return f(fallback, v), nil
+}
@@ -0,0 +1,58 @@
+package template
+
+import (
+	"fmt"
+	"os"
+)
+
+// Env reads environment variables in templates.
+// It can be called as a function such as {{env("PORT", "8080")}} and refers to a variable by the key such as {{env.PORT}}.
+type Env struct {
+	allowlist []string
+}
+
+// NewEnv returns a new Env that reads only the variables of the allowlist.
+// If the allowlist is nil, it can read all variables.
+func NewEnv(allowlist []string) *Env {
+	return &Env{
+		allowlist: allowlist,
+	}
+}
+
+// Call returns the value of the environment variable.
+// If the variable is not set, it returns the default value if specified; otherwise, an error.
+func (e *Env) Call(name string, defaultValue ...string) (any, error) {
+	if len(defaultValue) > 1 {
+		return nil, fmt.Errorf("env: too many arguments")
+	}
+	if !e.allowed(name) {
+		return nil, fmt.Errorf("env: environment variable %q is not allowed to read", name)
+	}
+	if v, ok := os.LookupEnv(name); ok {
+		return v, nil
+	}
+	if len(defaultValue) == 1 {
+		return defaultValue[0], nil
+	}
+	return nil, fmt.Errorf("env: environment variable %q is not set", name)
+}
+
+// ExtractByKey implements query.KeyExtractor interface.
+func (e *Env) ExtractByKey(key string) (any, bool) {
+	if !e.allowed(key) {
+		return nil, false
+	}
+	return os.LookupEnv(key)
+}
+
+func (e *Env) allowed(name string) bool {
+	if e.allowlist == nil {
+		return true
+	}
+	for _, n := range e.allowlist {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package template
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnv(t *testing.T) {
+	t.Setenv("SCENARIGO_TEST_ENV", "foo")
+	tests := map[string]struct {
+		str         string
+		data        any
+		expect      any
+		expectError string
+	}{
+		"call": {
+			str:    `{{env("SCENARIGO_TEST_ENV")}}`,
+			expect: "foo",
+		},
+		"call with default": {
+			str:    `{{env("SCENARIGO_TEST_ENV", "bar")}}`,
+			expect: "foo",
+		},
+		"default": {
+			str:    `{{env("SCENARIGO_TEST_UNDEFINED", "bar")}}`,
+			expect: "bar",
+		},
+		"key": {
+			str:    `{{env.SCENARIGO_TEST_ENV}}`,
+			expect: "foo",
+		},
+		"not set": {
+			str:         `{{env("SCENARIGO_TEST_UNDEFINED")}}`,
+			expectError: `env: environment variable "SCENARIGO_TEST_UNDEFINED" is not set`,
+		},
+		"allowlist": {
+			str:    `{{env("SCENARIGO_TEST_ENV")}}`,
+			data:   map[string]any{"env": NewEnv([]string{"SCENARIGO_TEST_ENV"})},
+			expect: "foo",
+		},
+		"not allowed": {
+			str:         `{{env("SCENARIGO_TEST_ENV", "bar")}}`,
+			data:        map[string]any{"env": NewEnv([]string{})},
+			expectError: `env: environment variable "SCENARIGO_TEST_ENV" is not allowed to read`,
+		},
+		"not allowed (key)": {
+			str:         `{{env.SCENARIGO_TEST_ENV}}`,
+			data:        map[string]any{"env": NewEnv([]string{})},
+			expectError: `".env.SCENARIGO_TEST_ENV" not found`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			v, err := Execute(test.str, test.data)
+			if test.expectError != "" {
+				if err == nil {
+					t.Fatal("expected error but got no error")
+				}
+				if got, expected := err.Error(), test.expectError; !strings.Contains(got, expected) {
+					t.Errorf("expected error %q but got %q", expected, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if v != test.expect {
+				t.Errorf("expected %v but got %v", test.expect, v)
+			}
+		})
+	}
+}
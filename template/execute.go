@@ -21,8 +21,13 @@ var (
 )
 
 // Execute executes templates of i with data.
+// The executions share the state such as generated UUIDs if data implements StateHolder interface.
 func Execute(i, data interface{}) (interface{}, error) {
-	v, err := execute(reflect.ValueOf(i), data)
+	return executeWithState(i, data, stateFrom(data))
+}
+
+func executeWithState(i, data interface{}, st *State) (interface{}, error) {
+	v, err := execute(reflect.ValueOf(i), data, st)
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +52,7 @@ func structFieldName(field reflect.StructField) string {
 }
 
 //nolint:gocyclo,cyclop,maintidx
-func execute(in reflect.Value, data interface{}) (reflect.Value, error) {
+func execute(in reflect.Value, data interface{}, st *State) (reflect.Value, error) {
 	v := reflectutil.Elem(in)
 	switch v.Kind() {
 	case reflect.Invalid:
@@ -57,7 +62,7 @@ func execute(in reflect.Value, data interface{}) (reflect.Value, error) {
 			e := v.MapIndex(k)
 			if !isNil(e) {
 				keyStr := fmt.Sprint(k.Interface())
-				key, err := execute(k, data)
+				key, err := execute(k, data, st)
 				if err != nil {
 					return reflect.Value{}, errors.WithPath(err, keyStr)
 				}
@@ -67,7 +72,7 @@ func execute(in reflect.Value, data interface{}) (reflect.Value, error) {
 						return reflect.Value{}, errors.New("invalid left arrow function call")
 					}
 					if !isNil(e) {
-						x, err := execute(e, data)
+						x, err := execute(e, data, st)
 						if err != nil {
 							return reflect.Value{}, errors.WithPath(err, keyStr)
 						}
@@ -81,7 +86,7 @@ func execute(in reflect.Value, data interface{}) (reflect.Value, error) {
 					v = res
 					break
 				}
-				x, err := convert(e.Type())(execute(e, data))
+				x, err := convert(e.Type())(execute(e, data, st))
 				if err != nil {
 					return reflect.Value{}, errors.WithPath(err, keyStr)
 				}
@@ -101,7 +106,7 @@ func execute(in reflect.Value, data interface{}) (reflect.Value, error) {
 					keyStr := fmt.Sprint(key.Interface())
 					value := e.FieldByName("Value")
 					if !isNil(key) {
-						k, err := execute(key, data)
+						k, err := execute(key, data, st)
 						if err != nil {
 							return reflect.Value{}, errors.WithPath(err, keyStr)
 						}
@@ -113,7 +118,7 @@ func execute(in reflect.Value, data interface{}) (reflect.Value, error) {
 							return reflect.Value{}, errors.New("invalid left arrow function call")
 						}
 						if !isNil(value) {
-							x, err := execute(value, data)
+							x, err := execute(value, data, st)
 							if err != nil {
 								return reflect.Value{}, errors.WithPath(err, keyStr)
 							}
@@ -128,7 +133,7 @@ func execute(in reflect.Value, data interface{}) (reflect.Value, error) {
 						break
 					}
 				}
-				x, err := convert(e.Type())(execute(e, data))
+				x, err := convert(e.Type())(execute(e, data, st))
 				if err != nil {
 					return reflect.Value{}, errors.WithQuery(err, query.New(
 						query.ExtractByStructTag("yaml", "json"),
@@ -147,7 +152,7 @@ func execute(in reflect.Value, data interface{}) (reflect.Value, error) {
 				continue // skip unexported field
 			}
 			field := v.Field(i)
-			x, err := convert(field.Type())(execute(field, data))
+			x, err := convert(field.Type())(execute(field, data, st))
 			if err != nil {
 				fieldName := structFieldName(v.Type().Field(i))
 				return reflect.Value{}, errors.WithPath(err, fieldName)
@@ -162,6 +167,7 @@ func execute(in reflect.Value, data interface{}) (reflect.Value, error) {
 		if err != nil {
 			return reflect.Value{}, err
 		}
+		tmpl.state = st
 		x, err := tmpl.Execute(data)
 		if err != nil {
 			return reflect.Value{}, err
@@ -1,7 +1,18 @@
 package template
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"  //nolint:gosec
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"hash"
+
+	"github.com/goccy/go-yaml"
 
 	"github.com/zoncoen/scenarigo/template/val"
 )
@@ -10,6 +21,37 @@ var functions = map[string]any{
 	"size": size,
 }
 
+// libFunctions are the functions that data can override.
+// Templates refer to a value of the data instead of the function if the data has the same key.
+var libFunctions = map[string]any{
+	"base64encode": base64Encode,
+	"base64decode": base64Decode,
+	"fromJSON":     fromJSON,
+	"toJSON":       toJSON,
+	"md5":          hashFunc("md5", md5.New),
+	"sha1":         hashFunc("sha1", sha1.New),
+	"sha256":       hashFunc("sha256", sha256.New),
+	"hmacSHA256":   hmacSHA256,
+	"uuid":         statefulFunc(func(s *State, _ any) any { return s.uuid }),
+	"now":          statefulFunc(func(_ *State, data any) any { return nowFunc(clockFrom(data)) }),
+	"addDuration":  addDuration,
+	"formatTime":   formatTime,
+	"upper":        upper,
+	"lower":        lower,
+	"trim":         trim,
+	"trimPrefix":   trimPrefix,
+	"trimSuffix":   trimSuffix,
+	"split":        split,
+	"join":         join,
+	"replace":      replace,
+	"atoi":         atoi,
+	"itoa":         itoa,
+	"parseFloat":   parseFloat,
+	"ternary":      ternary,
+	"default":      defaultFunc(defaultValue),
+	"env":          &Env{},
+}
+
 func size(in any) (any, error) {
 	v := val.NewValue(in)
 	if s, ok := v.(val.Sizer); ok {
@@ -23,3 +65,216 @@ func size(in any) (any, error) {
 	}
 	return nil, fmt.Errorf("size(%s) is not defined", v.Type().Name())
 }
+
+// ternary returns a if cond is true; otherwise, b.
+// The condition is evaluated in the same way as the conditional expression cond ? a : b, but both a and b are always evaluated.
+func ternary(cond, a, b any) (any, error) {
+	c, ok := truthy(cond)
+	if !ok {
+		return nil, fmt.Errorf("ternary: condition must be a boolean but got %s", typeValue(val.NewValue(cond)))
+	}
+	if c {
+		return a, nil
+	}
+	return b, nil
+}
+
+func base64Encode(in any, encoding ...string) (any, error) {
+	enc, err := base64Encoding("base64encode", encoding)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toBytes("base64encode", in)
+	if err != nil {
+		return nil, err
+	}
+	return enc.EncodeToString(b), nil
+}
+
+func base64Decode(in any, encoding ...string) (any, error) {
+	enc, err := base64Encoding("base64decode", encoding)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toBytes("base64decode", in)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := enc.DecodeString(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("base64decode: failed to decode: %w", err)
+	}
+	return decoded, nil
+}
+
+// base64Encoding returns the encoding specified by the optional argument.
+// It supports "std" (standard alphabet) and "url" (URL-safe alphabet); the default is "std".
+func base64Encoding(name string, encoding []string) (*base64.Encoding, error) {
+	if len(encoding) == 0 {
+		return base64.StdEncoding, nil
+	}
+	if len(encoding) > 1 {
+		return nil, fmt.Errorf("%s: too many arguments", name)
+	}
+	switch encoding[0] {
+	case "std":
+		return base64.StdEncoding, nil
+	case "url":
+		return base64.URLEncoding, nil
+	default:
+		return nil, fmt.Errorf(`%s: unknown encoding %q: encoding must be "std" or "url"`, name, encoding[0])
+	}
+}
+
+// fromJSON decodes a JSON string or bytes.
+// Numbers are decoded into int64 if they are integers; otherwise, float64.
+func fromJSON(in any) (any, error) {
+	b, err := toBytes("fromJSON", in)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("fromJSON: failed to decode: %w", err)
+	}
+	if d.More() {
+		return nil, fmt.Errorf("fromJSON: failed to decode: invalid character after top-level value")
+	}
+	return convertJSONNumbers(v), nil
+}
+
+func convertJSONNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]any:
+		for k, e := range v {
+			v[k] = convertJSONNumbers(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = convertJSONNumbers(e)
+		}
+	}
+	return v
+}
+
+// toJSON encodes a value into a JSON string.
+func toJSON(in any) (any, error) {
+	v, err := jsonCompatible(in)
+	if err != nil {
+		return nil, fmt.Errorf("toJSON: failed to encode: %w", err)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("toJSON: failed to encode: %w", err)
+	}
+	return string(b), nil
+}
+
+// jsonCompatible converts YAML values such as yaml.MapSlice into values that encoding/json can encode.
+func jsonCompatible(in any) (any, error) {
+	switch v := in.(type) {
+	case yaml.MapSlice:
+		m := make(map[string]any, len(v))
+		for _, item := range v {
+			k, ok := item.Key.(string)
+			if !ok {
+				return nil, fmt.Errorf("object key must be a string but got %T", item.Key)
+			}
+			e, err := jsonCompatible(item.Value)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = e
+		}
+		return m, nil
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("object key must be a string but got %T", key)
+			}
+			e, err := jsonCompatible(value)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = e
+		}
+		return m, nil
+	case []any:
+		s := make([]any, len(v))
+		for i, value := range v {
+			e, err := jsonCompatible(value)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = e
+		}
+		return s, nil
+	}
+	return in, nil
+}
+
+// hashFunc returns a function that computes the hex digest of a string or bytes.
+// Strings are hashed as UTF-8 bytes.
+func hashFunc(name string, h func() hash.Hash) func(any) (any, error) {
+	return func(in any) (any, error) {
+		b, err := toBytes(name, in)
+		if err != nil {
+			return nil, err
+		}
+		hh := h()
+		hh.Write(b)
+		return hex.EncodeToString(hh.Sum(nil)), nil
+	}
+}
+
+// hmacSHA256 computes the hex-encoded HMAC-SHA256 of msg with key.
+// Strings are hashed as UTF-8 bytes.
+func hmacSHA256(key, msg any) (any, error) {
+	k, err := toBytes("hmacSHA256", key)
+	if err != nil {
+		return nil, err
+	}
+	m, err := toBytes("hmacSHA256", msg)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(sha256.New, k)
+	h.Write(m)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// toBytes converts a string or bytes value into bytes.
+func toBytes(name string, in any) ([]byte, error) {
+	switch v := in.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("%s(%s) is not defined", name, val.NewValue(in).Type().Name())
+	}
+}
+
+// IsBuiltinFunc reports whether name is a function provided by templates, such as size and int.
+func IsBuiltinFunc(name string) bool {
+	if _, ok := functions[name]; ok {
+		return true
+	}
+	if _, ok := libFunctions[name]; ok {
+		return true
+	}
+	_, ok := typeFunctions.ExtractByKey(name)
+	return ok
+}
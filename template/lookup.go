@@ -13,14 +13,24 @@ import (
 
 type errNotDefined struct {
 	error
+	root string // the name of the root such as a of a.b[0], empty if the root isn't an identifier
 }
 
-func lookup(node ast.Node, data interface{}) (interface{}, error) {
+// UndefinedRoot returns the name of the root such as "vars" of "vars.foo" if err is caused by referring to an undefined value.
+func UndefinedRoot(err error) (string, bool) {
+	var notDefined errNotDefined
+	if !errors.As(err, &notDefined) {
+		return "", false
+	}
+	return notDefined.root, true
+}
+
+func lookup(node ast.Node, data interface{}, st *State) (interface{}, error) {
 	v, err := extract(node, data)
 	if err != nil {
 		return nil, err
 	}
-	return Execute(v, data)
+	return executeWithState(v, data, st)
 }
 
 func extract(node ast.Node, data interface{}) (interface{}, error) {
@@ -42,24 +52,81 @@ func extract(node ast.Node, data interface{}) (interface{}, error) {
 	}
 	v, err = q.Extract(data)
 	if err != nil {
-		return nil, errNotDefined{err}
+		var rootName string
+		// use the library function only if the data doesn't have the same key
+		if root, ok := pathRoot(node).(*ast.Ident); ok {
+			rootName = root.Name
+			if _, rerr := newQuery().Key(root.Name).Extract(data); rerr != nil {
+				if f, ferr := q.Extract(libFunctions); ferr == nil {
+					return f, nil
+				}
+			}
+		}
+		return nil, errNotDefined{error: err, root: rootName}
 	}
 	return v, nil
 }
 
+func newQuery() *query.Query {
+	return query.New(
+		query.ExtractByStructTag("yaml", "json"),
+		query.CustomExtractFunc(yamlextractor.MapSliceExtractFunc(false)),
+	)
+}
+
+// lookupFrom looks up the value by the path from v.
+// The root is the root expression of the path that is evaluated into v, such as f() of f().a.b.
+func lookupFrom(node, root ast.Node, v interface{}) (interface{}, error) {
+	q, err := buildQueryFrom(newQuery(), node, root)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create query from AST")
+	}
+	x, err := q.Extract(v)
+	if err != nil {
+		return nil, errNotDefined{error: err}
+	}
+	return x, nil
+}
+
+// pathRoot returns the root expression of the path such as a of a.b[0].
+func pathRoot(node ast.Node) ast.Node {
+	switch n := node.(type) {
+	case *ast.SelectorExpr:
+		return pathRoot(n.X)
+	case *ast.IndexExpr:
+		return pathRoot(n.X)
+	}
+	return node
+}
+
 func buildQuery(q *query.Query, node ast.Node) (*query.Query, error) {
+	return buildQueryFrom(q, node, nil)
+}
+
+func buildQueryFrom(q *query.Query, node, root ast.Node) (*query.Query, error) {
+	if root != nil && node == root {
+		return q, nil
+	}
 	var err error
 	switch n := node.(type) {
 	case *ast.Ident:
 		return q.Key(n.Name), nil
 	case *ast.SelectorExpr:
-		q, err = buildQuery(q, n.X)
+		q, err = buildQueryFrom(q, n.X, root)
 		if err != nil {
 			return nil, err
 		}
 		return q.Key(n.Sel.Name), nil
 	case *ast.IndexExpr:
 		i, ok := n.Index.(*ast.BasicLit)
+		if ok && i.Kind == token.STRING {
+			// the key which can't be written as a selector such as header["x-request-id"]
+			q, err = buildQueryFrom(q, n.X, root)
+			if err != nil {
+				return nil, err
+			}
+			return q.Key(i.Value), nil
+		}
 		if !ok || i.Kind != token.INT {
 			return nil, errors.Errorf(`expected int but "%s"`, i.Kind.String())
 		}
@@ -67,7 +134,7 @@ func buildQuery(q *query.Query, node ast.Node) (*query.Query, error) {
 		if err != nil {
 			return nil, errors.Errorf(`expected int but "%s"`, i.Value)
 		}
-		q, err = buildQuery(q, n.X)
+		q, err = buildQueryFrom(q, n.X, root)
 		if err != nil {
 			return nil, err
 		}
@@ -75,3 +142,8 @@ func buildQuery(q *query.Query, node ast.Node) (*query.Query, error) {
 	}
 	return nil, errors.Errorf(`unknown node "%T"`, node)
 }
+
+func isIdent(node ast.Node) bool {
+	_, ok := node.(*ast.Ident)
+	return ok
+}
@@ -0,0 +1,60 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/zoncoen/scenarigo/template/val"
+)
+
+// The numeric conversion functions convert strings such as the ones of response bodies into numbers and vice versa.
+
+// atoi parses a decimal string into int64.
+func atoi(in any) (any, error) {
+	s, ok := in.(string)
+	if !ok {
+		return nil, fmt.Errorf("atoi(%s) is not defined", val.NewValue(in).Type().Name())
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("atoi: failed to parse %q as an integer: %w", s, numError(err))
+	}
+	return i, nil
+}
+
+// parseFloat parses a string into float64.
+func parseFloat(in any) (any, error) {
+	s, ok := in.(string)
+	if !ok {
+		return nil, fmt.Errorf("parseFloat(%s) is not defined", val.NewValue(in).Type().Name())
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parseFloat: failed to parse %q as a float: %w", s, numError(err))
+	}
+	return f, nil
+}
+
+// itoa formats an integer as a decimal string.
+func itoa(in any) (any, error) {
+	v := reflect.ValueOf(in)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	default:
+		return nil, fmt.Errorf("itoa(%s) is not defined", val.NewValue(in).Type().Name())
+	}
+}
+
+// numError returns the cause of err to avoid repeating the function name and the input of strconv.NumError.
+func numError(err error) error {
+	var nerr *strconv.NumError
+	if errors.As(err, &nerr) {
+		return nerr.Err
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package template
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNumberFunctions(t *testing.T) {
+	tests := map[string]struct {
+		str         string
+		data        any
+		expect      any
+		expectError string
+	}{
+		"atoi": {
+			str:    `{{atoi(v)}}`,
+			data:   map[string]any{"v": "9007199254740993"},
+			expect: int64(9007199254740993),
+		},
+		"atoi (negative)": {
+			str:    `{{atoi("-42")}}`,
+			expect: int64(-42),
+		},
+		"atoi and compare": {
+			str:    `{{atoi(v) > 2}}`,
+			data:   map[string]any{"v": "3"},
+			expect: true,
+		},
+		"atoi (invalid)": {
+			str:         `{{atoi("1.5")}}`,
+			expectError: `atoi: failed to parse "1.5" as an integer: invalid syntax`,
+		},
+		"atoi (out of range)": {
+			str:         `{{atoi("9223372036854775808")}}`,
+			expectError: `atoi: failed to parse "9223372036854775808" as an integer: value out of range`,
+		},
+		"atoi (not string)": {
+			str:         `{{atoi(1)}}`,
+			expectError: "atoi(int) is not defined",
+		},
+		"parseFloat": {
+			str:    `{{parseFloat(v)}}`,
+			data:   map[string]any{"v": "12.50"},
+			expect: 12.5,
+		},
+		"parseFloat (exponent)": {
+			str:    `{{parseFloat("1e3")}}`,
+			expect: 1000.0,
+		},
+		"parseFloat (invalid)": {
+			str:         `{{parseFloat("1.5.0")}}`,
+			expectError: `parseFloat: failed to parse "1.5.0" as a float: invalid syntax`,
+		},
+		"parseFloat (not string)": {
+			str:         `{{parseFloat(1.5)}}`,
+			expectError: "parseFloat(float) is not defined",
+		},
+		"itoa": {
+			str:    `{{itoa(42)}}`,
+			expect: "42",
+		},
+		"itoa (uint)": {
+			str:    `{{itoa(v)}}`,
+			data:   map[string]any{"v": uint64(18446744073709551615)},
+			expect: "18446744073709551615",
+		},
+		"itoa (atoi)": {
+			str:    `{{itoa(atoi("-7"))}}`,
+			expect: "-7",
+		},
+		"itoa (not integer)": {
+			str:         `{{itoa("42")}}`,
+			expectError: "itoa(string) is not defined",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			v, err := Execute(test.str, test.data)
+			if test.expectError != "" {
+				if err == nil {
+					t.Fatal("expected error but got no error")
+				}
+				if got, expected := err.Error(), test.expectError; !strings.Contains(got, expected) {
+					t.Errorf("expected error %q but got %q", expected, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(test.expect, v); diff != "" {
+				t.Errorf("diff: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// The string functions are pure functions that don't depend on the data.
+
+func upper(s string) string {
+	return strings.ToUpper(s)
+}
+
+func lower(s string) string {
+	return strings.ToLower(s)
+}
+
+// trim returns s with all leading and trailing white space removed.
+// If the cutset is specified, it removes the characters contained in the cutset instead.
+func trim(s string, cutset ...string) (any, error) {
+	switch len(cutset) {
+	case 0:
+		return strings.TrimSpace(s), nil
+	case 1:
+		return strings.Trim(s, cutset[0]), nil
+	default:
+		return nil, fmt.Errorf("trim: too many arguments")
+	}
+}
+
+func trimPrefix(s, prefix string) string {
+	return strings.TrimPrefix(s, prefix)
+}
+
+func trimSuffix(s, suffix string) string {
+	return strings.TrimSuffix(s, suffix)
+}
+
+func split(s, sep string) []string {
+	return strings.Split(s, sep)
+}
+
+// join concatenates the string elements of the list with sep.
+func join(list any, sep string) (any, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("join: expected a list but got %T", list)
+	}
+	elems := make([]string, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		e := v.Index(i)
+		for e.Kind() == reflect.Interface && !e.IsNil() {
+			e = e.Elem()
+		}
+		if e.Kind() != reflect.String {
+			return nil, fmt.Errorf("join: expected a list of strings but the element at index %d is %s", i, e.Type())
+		}
+		elems[i] = e.String()
+	}
+	return strings.Join(elems, sep), nil
+}
+
+func replace(s, old, new string) string { //nolint:predeclared
+	return strings.ReplaceAll(s, old, new)
+}
@@ -0,0 +1,103 @@
+package template
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStringFunctions(t *testing.T) {
+	tests := map[string]struct {
+		str         string
+		data        any
+		expect      any
+		expectError string
+	}{
+		"upper": {
+			str:    `{{upper("Scenarigo")}}`,
+			expect: "SCENARIGO",
+		},
+		"lower": {
+			str:    `{{lower("Scenarigo")}}`,
+			expect: "scenarigo",
+		},
+		"trim": {
+			str:    `{{trim(v)}}`,
+			data:   map[string]any{"v": " \tscenarigo\n"},
+			expect: "scenarigo",
+		},
+		"trim (cutset)": {
+			str:    `{{trim("--scenarigo-", "-")}}`,
+			expect: "scenarigo",
+		},
+		"trimPrefix": {
+			str:    `{{trimPrefix("Bearer token", "Bearer ")}}`,
+			expect: "token",
+		},
+		"trimSuffix": {
+			str:    `{{trimSuffix("scenario.yaml", ".yaml")}}`,
+			expect: "scenario",
+		},
+		"split": {
+			str:    `{{split("a,b,c", ",")}}`,
+			expect: []string{"a", "b", "c"},
+		},
+		"split and index": {
+			str:    `{{split("a,b,c", ",")[1]}}`,
+			expect: "b",
+		},
+		"split and size": {
+			str:    `{{size(split("a,b,c", ","))}}`,
+			expect: int64(3),
+		},
+		"join": {
+			str:    `{{join(v, "-")}}`,
+			data:   map[string]any{"v": []any{"a", "b", "c"}},
+			expect: "a-b-c",
+		},
+		"join (split)": {
+			str:    `{{join(split("a,b,c", ","), "")}}`,
+			expect: "abc",
+		},
+		"join (not list)": {
+			str:         `{{join("abc", "-")}}`,
+			expectError: "join: expected a list but got string",
+		},
+		"join (not string)": {
+			str:         `{{join(v, "-")}}`,
+			data:        map[string]any{"v": []any{"a", 1}},
+			expectError: "join: expected a list of strings but the element at index 1 is int",
+		},
+		"data takes precedence": {
+			str:    `{{upper("a")}}`,
+			data:   map[string]any{"upper": func(s string) string { return s + "!" }},
+			expect: "a!",
+		},
+		"replace": {
+			str:    `{{replace("a-b-c", "-", "_")}}`,
+			expect: "a_b_c",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			v, err := Execute(test.str, test.data)
+			if test.expectError != "" {
+				if err == nil {
+					t.Fatal("expected error but got no error")
+				}
+				if got, expected := err.Error(), test.expectError; !strings.Contains(got, expected) {
+					t.Errorf("expected error %q but got %q", expected, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(test.expect, v); diff != "" {
+				t.Errorf("diff: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ type Template struct {
 
 	executingLeftArrowExprArg bool
 	argFuncs                  *funcStash
+	state                     *State
 }
 
 // New parses text as a template and returns it.
@@ -40,6 +41,7 @@ func New(str string) (*Template, error) {
 		str:      str,
 		expr:     expr,
 		argFuncs: &funcStash{},
+		state:    NewState(),
 	}, nil
 }
 
@@ -83,11 +85,17 @@ func (t *Template) executeExpr(expr ast.Expr, data interface{}) (interface{}, er
 	case *ast.ConditionalExpr:
 		return t.executeConditionalExpr(e, data)
 	case *ast.Ident:
-		return lookup(e, data)
-	case *ast.SelectorExpr:
-		return lookup(e, data)
-	case *ast.IndexExpr:
-		return lookup(e, data)
+		return lookup(e, data, t.state)
+	case *ast.SelectorExpr, *ast.IndexExpr:
+		if root := pathRoot(e); !isIdent(root) {
+			// the path from the result of an expression such as f().a
+			v, err := t.executeExpr(root.(ast.Expr), data) //nolint:forcetypeassert
+			if err != nil {
+				return nil, err
+			}
+			return lookupFrom(e, root, v)
+		}
+		return lookup(e, data, t.state)
 	case *ast.CallExpr:
 		return t.executeFuncCall(e, data)
 	case *ast.LeftArrowExpr:
@@ -335,17 +343,26 @@ func (t *Template) executeConditionalExpr(e *ast.ConditionalExpr, data interface
 	if err != nil {
 		return nil, err
 	}
-	cv := val.NewValue(c)
-	cond, ok := cv.(val.LogicalValue)
+	cond, ok := truthy(c)
 	if !ok {
-		return nil, fmt.Errorf("invalid operation: operator ? not defined on %s", typeValue(cv))
+		return nil, fmt.Errorf("invalid operation: operator ? not defined on %s", typeValue(val.NewValue(c)))
 	}
-	if cond.IsTruthy() {
+	if cond {
 		return t.executeExpr(e.X, data)
 	}
 	return t.executeExpr(e.Y, data)
 }
 
+// truthy evaluates v as the condition of the conditional expression.
+// It reports false as the second value if v isn't a logical value.
+func truthy(v interface{}) (bool, bool) {
+	cond, ok := val.NewValue(v).(val.LogicalValue)
+	if !ok {
+		return false, false
+	}
+	return cond.IsTruthy(), true
+}
+
 // align indents of marshaled texts
 //
 //	example: addIndent("a: 1\nb:2", "- ")
@@ -406,7 +423,7 @@ func (t *Template) executeFuncCall(call *ast.CallExpr, data interface{}) (interf
 		if err != nil {
 			return nil, err
 		}
-		v, err := lookup(selector.Sel, x)
+		v, err := lookup(selector.Sel, x, t.state)
 		if err == nil {
 			fn = reflect.ValueOf(v)
 		} else {
@@ -423,6 +440,12 @@ func (t *Template) executeFuncCall(call *ast.CallExpr, data interface{}) (interf
 		if err != nil {
 			return nil, err
 		}
+		if sf, ok := f.(statefulFunc); ok {
+			f = sf(t.state, data)
+		}
+		if df, ok := f.(defaultFunc); ok {
+			return t.executeDefaultFunc(df, call, data)
+		}
 		fn = reflect.ValueOf(f)
 		if id, ok := call.Fun.(*ast.Ident); ok {
 			fnName = id.Name
@@ -536,7 +559,10 @@ func (t *Template) executeLeftArrowExpr(e *ast.LeftArrowExpr, data interface{})
 	}
 	f, ok := v.(Func)
 	if !ok {
-		return nil, errors.Errorf(`expect template function but got %T`, e)
+		f, ok = newGoFunc(v)
+		if !ok {
+			return nil, errors.Errorf(`expect template function but got %T`, v)
+		}
 	}
 
 	// without arg in map key
@@ -597,6 +623,7 @@ func (t *Template) executeLeftArrowExprArg(arg ast.Expr, data interface{}) (inte
 		expr:                      arg,
 		executingLeftArrowExprArg: true,
 		argFuncs:                  t.argFuncs,
+		state:                     t.state,
 	}
 	v, err := tt.Execute(data)
 	return v, err
@@ -622,3 +649,52 @@ type Func interface {
 type lazyFunc struct {
 	f Func
 }
+
+// goFunc is an adaptor to allow the use of ordinary functions that have only one argument as left arrow functions.
+// The argument is unmarshaled into the type of the function parameter.
+type goFunc struct {
+	fn reflect.Value
+}
+
+func newGoFunc(v interface{}) (*goFunc, bool) {
+	fn := reflect.ValueOf(v)
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		return nil, false
+	}
+	typ := fn.Type()
+	if typ.NumIn() != 1 || typ.IsVariadic() {
+		return nil, false
+	}
+	switch typ.NumOut() {
+	case 1:
+	case 2:
+		if typ.Out(1) != reflectutil.TypeError {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	return &goFunc{fn: fn}, true
+}
+
+// Exec implements Func interface.
+func (f *goFunc) Exec(arg interface{}) (interface{}, error) {
+	in := reflect.ValueOf(arg)
+	if !in.IsValid() {
+		in = reflect.Zero(f.fn.Type().In(0))
+	}
+	vs := f.fn.Call([]reflect.Value{in})
+	if len(vs) == 2 && !vs[1].IsNil() {
+		return nil, vs[1].Interface().(error) //nolint:forcetypeassert
+	}
+	return vs[0].Interface(), nil
+}
+
+// UnmarshalArg implements Func interface.
+func (f *goFunc) UnmarshalArg(unmarshal func(interface{}) error) (interface{}, error) {
+	arg := reflect.New(f.fn.Type().In(0))
+	if err := unmarshal(arg.Interface()); err != nil {
+		return nil, err
+	}
+	return arg.Elem().Interface(), nil
+}
@@ -88,6 +88,15 @@ func TestTemplate_Execute(t *testing.T) {
 			},
 			expect: "ok",
 		},
+		"query by string key": {
+			str: `{{a["x-b"][1]}}`,
+			data: map[string]map[string][]string{
+				"a": {
+					"x-b": {"ng", "ok"},
+				},
+			},
+			expect: "ok",
+		},
 
 		"function call": {
 			str: `{{f("ok")}}`,
@@ -242,6 +251,49 @@ func TestTemplate_Execute(t *testing.T) {
 			},
 			expect: "preout-prein-test-sufin-sufout",
 		},
+		"left arrow func (Go function)": {
+			str: strings.Trim(`
+{{greet <-}}:
+  name: '{{name}}'
+  address:
+    city: Tokyo
+`, "\n"),
+			data: map[string]interface{}{
+				"greet": func(arg struct {
+					Name    string `yaml:"name"`
+					Address struct {
+						City string `yaml:"city"`
+					} `yaml:"address"`
+				},
+				) string {
+					return fmt.Sprintf("Hello, %s from %s!", arg.Name, arg.Address.City)
+				},
+				"name": "Alice",
+			},
+			expect: "Hello, Alice from Tokyo!",
+		},
+		"left arrow func (Go function returns an error)": {
+			str: strings.Trim(`
+{{f <-}}:
+  ids: [1, 2]
+`, "\n"),
+			data: map[string]interface{}{
+				"f": func(arg map[string][]int) (int, error) {
+					return 0, errors.Errorf("%d ids", len(arg["ids"]))
+				},
+			},
+			expectError: "2 ids",
+		},
+		"left arrow func (invalid function)": {
+			str: strings.Trim(`
+{{f <-}}:
+  ids: [1, 2]
+`, "\n"),
+			data: map[string]interface{}{
+				"f": func(a, b int) int { return a + b },
+			},
+			expectError: "expect template function but got func(int, int) int",
+		},
 		"left arrow func with the non-string argument": {
 			str: strings.Trim(`
 {{join <-}}: '{{arg}}'
@@ -312,6 +364,180 @@ func TestTemplate_Execute(t *testing.T) {
 			},
 			expectError: "failed to execute: {{size(v)}}: size(nil) is not defined",
 		},
+		"base64encode": {
+			str:    `{{base64encode("scenarigo?")}}`,
+			expect: "c2NlbmFyaWdvPw==",
+		},
+		"base64encode (url)": {
+			str:    `{{base64encode(bytes("scenarigo?"), "url")}}`,
+			expect: "c2NlbmFyaWdvPw==",
+		},
+		"base64encode (url-safe alphabet)": {
+			str:    `{{base64encode(v, "url")}}`,
+			data:   map[string]any{"v": []byte{0xfb, 0xff}},
+			expect: "-_8=",
+		},
+		"base64decode": {
+			str:    `{{base64decode("c2NlbmFyaWdvPw==")}}`,
+			expect: []byte("scenarigo?"),
+		},
+		"base64decode (url)": {
+			str:    `{{base64decode("-_8=", "url")}}`,
+			expect: []byte{0xfb, 0xff},
+		},
+		"base64decode and convert to string": {
+			str:    `{{string(base64decode(v))}}`,
+			data:   map[string]any{"v": []byte("dGVzdA==")},
+			expect: "test",
+		},
+		"base64decode (invalid)": {
+			str:         `{{base64decode("-_8=")}}`,
+			expectError: "failed to execute: {{base64decode(\"-_8=\")}}: base64decode: failed to decode: illegal base64 data at input byte 0",
+		},
+		"base64decode (unknown encoding)": {
+			str:         `{{base64decode("dGVzdA==", "raw")}}`,
+			expectError: `failed to execute: {{base64decode("dGVzdA==", "raw")}}: base64decode: unknown encoding "raw": encoding must be "std" or "url"`,
+		},
+		"base64decode (int)": {
+			str:         `{{base64decode(1)}}`,
+			expectError: "failed to execute: {{base64decode(1)}}: base64decode(int) is not defined",
+		},
+		"fromJSON": {
+			str:    `{{fromJSON(v)}}`,
+			data:   map[string]any{"v": `{"id": 1, "tags": ["a", "b"], "score": 0.5}`},
+			expect: map[string]any{"id": int64(1), "tags": []any{"a", "b"}, "score": 0.5},
+		},
+		"fromJSON (bytes)": {
+			str:    `{{fromJSON(bytes("[1, 2]"))}}`,
+			expect: []any{int64(1), int64(2)},
+		},
+		"fromJSON with path": {
+			str:    `{{fromJSON(v).tags[1]}}`,
+			data:   map[string]any{"v": `{"id": 1, "tags": ["a", "b"]}`},
+			expect: "b",
+		},
+		"fromJSON with path in expression": {
+			str:    `{{fromJSON(v).id == 1}}`,
+			data:   map[string]any{"v": `{"id": 1}`},
+			expect: true,
+		},
+		"fromJSON with path (not found)": {
+			str:         `{{fromJSON(v).name}}`,
+			data:        map[string]any{"v": `{"id": 1}`},
+			expectError: `failed to execute: {{fromJSON(v).name}}: ".name" not found`,
+		},
+		"fromJSON (invalid)": {
+			str:         `{{fromJSON("{")}}`,
+			expectError: `failed to execute: {{fromJSON("{")}}: fromJSON: failed to decode: unexpected EOF`,
+		},
+		"fromJSON (trailing data)": {
+			str:         `{{fromJSON("{} {}")}}`,
+			expectError: `failed to execute: {{fromJSON("{} {}")}}: fromJSON: failed to decode: invalid character after top-level value`,
+		},
+		"toJSON": {
+			str: `{{toJSON(v)}}`,
+			data: map[string]any{
+				"v": yaml.MapSlice{
+					{Key: "name", Value: "test"},
+					{Key: "ids", Value: []any{1, 2}},
+				},
+			},
+			expect: `{"ids":[1,2],"name":"test"}`,
+		},
+		"toJSON (invalid)": {
+			str:         `{{toJSON(v)}}`,
+			data:        map[string]any{"v": func() {}},
+			expectError: `failed to execute: {{toJSON(v)}}: toJSON: failed to encode: json: unsupported type: func()`,
+		},
+		"md5": {
+			str:    `{{md5("test")}}`,
+			expect: "098f6bcd4621d373cade4e832627b4f6",
+		},
+		"sha1": {
+			str:    `{{sha1("test")}}`,
+			expect: "a94a8fe5ccb19ba61c4c0873d391e987982fbbd3",
+		},
+		"sha256": {
+			str:    `{{sha256("test")}}`,
+			expect: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		},
+		"sha256 (bytes)": {
+			str:    `{{sha256(bytes("test"))}}`,
+			expect: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		},
+		"sha256 (UTF-8)": {
+			str:    `{{sha256("é")}}`,
+			expect: "4a99557e4033c3539de2eb65472017cad5f9557f7a0625a09f1c3f6e2ba69c4c",
+		},
+		"sha256 (int)": {
+			str:         `{{sha256(1)}}`,
+			expectError: "failed to execute: {{sha256(1)}}: sha256(int) is not defined",
+		},
+		"hmacSHA256": {
+			str:    `{{hmacSHA256("key", "The quick brown fox jumps over the lazy dog")}}`,
+			expect: "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		},
+		"ternary": {
+			str:    `{{ternary(count > 0, "has", "none")}}`,
+			data:   map[string]any{"count": 3},
+			expect: "has",
+		},
+		"ternary (false)": {
+			str:    `{{ternary(count > 0, "has", "none")}}`,
+			data:   map[string]any{"count": 0},
+			expect: "none",
+		},
+		"ternary (not bool)": {
+			str:         `{{ternary(1, "has", "none")}}`,
+			expectError: `failed to execute: {{ternary(1, "has", "none")}}: ternary: condition must be a boolean but got int(1)`,
+		},
+		"default": {
+			str:    `{{default("fallback", v.name)}}`,
+			data:   map[string]any{"v": map[string]any{"name": "test"}},
+			expect: "test",
+		},
+		"default (missing key)": {
+			str:    `{{default("fallback", v.name)}}`,
+			data:   map[string]any{"v": map[string]any{}},
+			expect: "fallback",
+		},
+		"default (undefined root)": {
+			str:    `{{default(1, v[0])}}`,
+			expect: int64(1),
+		},
+		"default (nil)": {
+			str:    `{{default("fallback", v.name)}}`,
+			data:   map[string]any{"v": map[string]any{"name": nil}},
+			expect: "fallback",
+		},
+		"default (empty string)": {
+			str:    `{{default("fallback", v.name)}}`,
+			data:   map[string]any{"v": map[string]any{"name": ""}},
+			expect: "",
+		},
+		"default (zero)": {
+			str:    `{{default(1, v.count)}}`,
+			data:   map[string]any{"v": map[string]any{"count": 0}},
+			expect: 0,
+		},
+		"default (fallback is evaluated lazily)": {
+			str:    `{{default(undefined.value, v)}}`,
+			data:   map[string]any{"v": "test"},
+			expect: "test",
+		},
+		"default (other error)": {
+			str:         `{{default("fallback", 1 + "a")}}`,
+			expectError: `failed to execute: {{default("fallback", 1 + "a")}}: invalid operation: int(1) + string(a) not defined`,
+		},
+		"default (too few arguments)": {
+			str:         `{{default("fallback")}}`,
+			expectError: "default: expected 2 arguments but got 1",
+		},
+		"default (data takes precedence)": {
+			str:    `{{default}}`,
+			data:   map[string]any{"default": "value"},
+			expect: "value",
+		},
 		"not found": {
 			str:         "{{a.b[1]}}",
 			expectError: `".a.b[1]" not found`,
@@ -1566,6 +1792,56 @@ func (*callFunc) UnmarshalArg(unmarshal func(interface{}) error) (interface{}, e
 	return &arg, nil
 }
 
+func TestUndefinedRoot(t *testing.T) {
+	tests := map[string]struct {
+		str       string
+		data      interface{}
+		expect    string
+		undefined bool
+	}{
+		"undefined key": {
+			str:       "{{vars.foo}}",
+			data:      map[string]interface{}{"vars": map[string]interface{}{}},
+			expect:    "vars",
+			undefined: true,
+		},
+		"undefined root": {
+			str:       "{{response.body}}",
+			expect:    "response",
+			undefined: true,
+		},
+		"undefined in function argument": {
+			str:       "{{size(response.body)}}",
+			expect:    "response",
+			undefined: true,
+		},
+		"other error": {
+			str:  "{{1 + \"a\"}}",
+			data: map[string]interface{}{},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := New(test.str)
+			if err != nil {
+				t.Fatalf("failed to parse: %s", err)
+			}
+			_, err = tmpl.Execute(test.data)
+			if err == nil {
+				t.Fatal("no error")
+			}
+			root, ok := UndefinedRoot(err)
+			if ok != test.undefined {
+				t.Fatalf("expect %t but got %t: %s", test.undefined, ok, err)
+			}
+			if root != test.expect {
+				t.Errorf("expect %q but got %q", test.expect, root)
+			}
+		})
+	}
+}
+
 func TestFuncStash(t *testing.T) {
 	var s funcStash
 	name := s.save("value")
@@ -0,0 +1,139 @@
+package template
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock provides the current time for time functions such as now().
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is an adaptor to allow the use of ordinary functions as Clock.
+type ClockFunc func() time.Time
+
+// Now implements Clock interface.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// WithClock returns the data that makes time functions such as now() use the clock.
+// Templates can refer to the original data as it is.
+func WithClock(data any, clock Clock) any {
+	return &clockData{
+		data:  data,
+		clock: clock,
+	}
+}
+
+type clockData struct {
+	data  any
+	clock Clock
+}
+
+// ExtractByKey implements query.KeyExtractor interface.
+func (d *clockData) ExtractByKey(key string) (any, bool) {
+	v, err := newQuery().Key(key).Extract(d.data)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// Now implements Clock interface.
+func (d *clockData) Now() time.Time {
+	return d.clock.Now()
+}
+
+// clockFrom returns the clock of the data if it implements Clock interface.
+func clockFrom(data any) Clock {
+	if c, ok := data.(Clock); ok {
+		return c
+	}
+	return ClockFunc(time.Now)
+}
+
+// timeLayouts are the named layouts that formatTime accepts.
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// nowFunc returns a function that returns the current time of the clock in RFC3339 format.
+func nowFunc(clock Clock) func() any {
+	return func() any {
+		return clock.Now().Format(time.RFC3339)
+	}
+}
+
+// addDuration adds the duration to the time and returns the result in RFC3339 format.
+// The time can be a time.Time or an RFC3339 string, and the duration can be a time.Duration or a string such as "1h30m".
+func addDuration(t, d any) (any, error) {
+	tm, err := toTime("addDuration", t)
+	if err != nil {
+		return nil, err
+	}
+	var dur time.Duration
+	switch d := d.(type) {
+	case time.Duration:
+		dur = d
+	case string:
+		dur, err = time.ParseDuration(d)
+		if err != nil {
+			return nil, fmt.Errorf("addDuration: invalid duration: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("addDuration: duration must be a string or a duration but got %T", d)
+	}
+	return tm.Add(dur).Format(time.RFC3339), nil
+}
+
+// formatTime formats the time with the layout.
+// The layout can be a Go layout such as "2006-01-02" or a name of predefined layouts such as "RFC1123", and the default is RFC3339.
+func formatTime(t any, layout ...string) (any, error) {
+	tm, err := toTime("formatTime", t)
+	if err != nil {
+		return nil, err
+	}
+	l := time.RFC3339
+	switch len(layout) {
+	case 0:
+	case 1:
+		l = layout[0]
+		if named, ok := timeLayouts[l]; ok {
+			l = named
+		} else if time.Unix(0, 0).UTC().Format(l) == l {
+			return nil, fmt.Errorf("formatTime: invalid layout %q: layout must contain elements of the reference time", l)
+		}
+	default:
+		return nil, fmt.Errorf("formatTime: too many arguments")
+	}
+	return tm.Format(l), nil
+}
+
+func toTime(name string, in any) (time.Time, error) {
+	switch v := in.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: invalid time: %w", name, err)
+		}
+		return t, nil
+	default:
+		return time.Time{}, fmt.Errorf("%s: time must be an RFC3339 string or a time but got %T", name, in)
+	}
+}
@@ -0,0 +1,114 @@
+package template
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTimeFunctions(t *testing.T) {
+	clock := ClockFunc(func() time.Time {
+		return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	})
+	tests := map[string]struct {
+		str         string
+		data        any
+		expect      any
+		expectError string
+	}{
+		"now": {
+			str:    "{{now()}}",
+			data:   WithClock(nil, clock),
+			expect: "2024-01-02T03:04:05Z",
+		},
+		"now with data": {
+			str:    `{{now() + vars.suffix}}`,
+			data:   WithClock(map[string]any{"vars": map[string]any{"suffix": "!"}}, clock),
+			expect: "2024-01-02T03:04:05Z!",
+		},
+		"addDuration": {
+			str:    `{{addDuration(now(), "1h30m")}}`,
+			data:   WithClock(nil, clock),
+			expect: "2024-01-02T04:34:05Z",
+		},
+		"addDuration (negative)": {
+			str:    `{{addDuration("2024-01-02T03:04:05+09:00", "-24h")}}`,
+			expect: "2024-01-01T03:04:05+09:00",
+		},
+		"addDuration (time.Duration)": {
+			str:    `{{addDuration(t, d)}}`,
+			data:   map[string]any{"t": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), "d": time.Minute},
+			expect: "2024-01-02T03:05:05Z",
+		},
+		"addDuration (invalid duration)": {
+			str:         `{{addDuration("2024-01-02T03:04:05Z", "1 hour")}}`,
+			expectError: `addDuration: invalid duration: time: unknown unit " hour" in duration "1 hour"`,
+		},
+		"addDuration (invalid time)": {
+			str:         `{{addDuration("2024-01-02", "1h")}}`,
+			expectError: `addDuration: invalid time: parsing time "2024-01-02" as "2006-01-02T15:04:05Z07:00": cannot parse "" as "T"`,
+		},
+		"formatTime": {
+			str:    `{{formatTime(now())}}`,
+			data:   WithClock(nil, clock),
+			expect: "2024-01-02T03:04:05Z",
+		},
+		"formatTime (layout)": {
+			str:    `{{formatTime(now(), "2006/01/02")}}`,
+			data:   WithClock(nil, clock),
+			expect: "2024/01/02",
+		},
+		"formatTime (named layout)": {
+			str:    `{{formatTime(now(), "RFC1123")}}`,
+			data:   WithClock(nil, clock),
+			expect: "Tue, 02 Jan 2024 03:04:05 UTC",
+		},
+		"formatTime (invalid layout)": {
+			str:         `{{formatTime(now(), "yyyy-mm-dd")}}`,
+			data:        WithClock(nil, clock),
+			expectError: `formatTime: invalid layout "yyyy-mm-dd": layout must contain elements of the reference time`,
+		},
+		"formatTime (invalid time)": {
+			str:         `{{formatTime(1)}}`,
+			expectError: `formatTime: time must be an RFC3339 string or a time but got int64`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			v, err := Execute(test.str, test.data)
+			if test.expectError != "" {
+				if err == nil {
+					t.Fatal("expected error but got no error")
+				}
+				if got, expected := err.Error(), test.expectError; !strings.Contains(got, expected) {
+					t.Errorf("expected error %q but got %q", expected, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(test.expect, v); diff != "" {
+				t.Errorf("diff: (-want +got)\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("default clock", func(t *testing.T) {
+		before := time.Now().Add(-time.Second)
+		v, err := Execute("{{now()}}", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		got, err := time.Parse(time.RFC3339, v.(string))
+		if err != nil {
+			t.Fatalf("failed to parse: %s", err)
+		}
+		if got.Before(before.Truncate(time.Second)) || got.After(time.Now()) {
+			t.Errorf("unexpected time %s", got)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package template
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// State holds the state shared by the executions of templates, such as generated UUIDs.
+type State struct {
+	m     sync.Mutex
+	uuids map[string]string
+}
+
+// NewState returns a new state.
+func NewState() *State {
+	return &State{
+		uuids: map[string]string{},
+	}
+}
+
+// StateHolder is the interface of the data which provides the state shared by the executions.
+// Execute uses a new state for each call if the data doesn't implement it.
+type StateHolder interface {
+	TemplateState() *State
+}
+
+// stateFrom returns the state of the data if it implements StateHolder interface.
+func stateFrom(data any) *State {
+	if h, ok := data.(StateHolder); ok {
+		if st := h.TemplateState(); st != nil {
+			return st
+		}
+	}
+	return NewState()
+}
+
+// statefulFunc is a function that depends on the execution state or the data.
+type statefulFunc func(st *State, data any) any
+
+// uuid generates a UUID string.
+// The first optional argument specifies the version, "v4" (random, default) or "v7" (time-ordered).
+// The second optional argument is a name to generate another UUID of the same version.
+// The generated UUID is memoized by the version and the name in the state;
+// calls with the same version and name, such as uuid() and uuid("v4"), return the same UUID while the executions share the state.
+func (s *State) uuid(args ...string) (any, error) {
+	version := "v4"
+	name := ""
+	switch len(args) {
+	case 0:
+	case 1:
+		version = args[0]
+	case 2:
+		version, name = args[0], args[1]
+	default:
+		return nil, fmt.Errorf("uuid: too many arguments")
+	}
+	var gen func() ([16]byte, error)
+	switch version {
+	case "v4":
+		gen = newUUIDv4
+	case "v7":
+		gen = newUUIDv7
+	default:
+		return nil, fmt.Errorf(`uuid: unknown version %q: version must be "v4" or "v7"`, version)
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	key := version + "/" + name
+	if id, ok := s.uuids[key]; ok {
+		return id, nil
+	}
+	b, err := gen()
+	if err != nil {
+		return nil, fmt.Errorf("uuid: failed to generate: %w", err)
+	}
+	id := formatUUID(b)
+	s.uuids[key] = id
+	return id, nil
+}
+
+func newUUIDv4() ([16]byte, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return b, err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+	return b, nil
+}
+
+func newUUIDv7() ([16]byte, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return b, err
+	}
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(time.Now().UnixMilli()))
+	copy(b[:6], ts[2:])         // 48-bit Unix timestamp in milliseconds
+	b[6] = (b[6] & 0x0f) | 0x70 // version 7
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+	return b, nil
+}
+
+func formatUUID(b [16]byte) string {
+	s := hex.EncodeToString(b[:])
+	return strings.Join([]string{s[0:8], s[8:12], s[12:16], s[16:20], s[20:32]}, "-")
+}
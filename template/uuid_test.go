@@ -0,0 +1,133 @@
+package template
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestUUID(t *testing.T) {
+	tests := map[string]struct {
+		str     string
+		pattern string
+	}{
+		"default": {
+			str:     "{{uuid()}}",
+			pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		},
+		"v4": {
+			str:     `{{uuid("v4")}}`,
+			pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		},
+		"v7": {
+			str:     `{{uuid("v7")}}`,
+			pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			v, err := Execute(test.str, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			s, ok := v.(string)
+			if !ok {
+				t.Fatalf("expected string but got %T", v)
+			}
+			if !regexp.MustCompile(test.pattern).MatchString(s) {
+				t.Errorf("%q doesn't match %q", s, test.pattern)
+			}
+		})
+	}
+
+	t.Run("v7 is time-ordered", func(t *testing.T) {
+		x, err := Execute(`{{uuid("v7")}}`, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		time.Sleep(2 * time.Millisecond)
+		y, err := Execute(`{{uuid("v7")}}`, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if x.(string) >= y.(string) {
+			t.Errorf("expected %q < %q", x, y)
+		}
+	})
+
+	t.Run("memoized in a single execution", func(t *testing.T) {
+		v, err := Execute(yaml.MapSlice{
+			{Key: "a", Value: "{{uuid()}}"},
+			{Key: "b", Value: "{{vars.id}}"},
+			{Key: "c", Value: `{{uuid("v4", "other")}}`},
+			{Key: "d", Value: `{{uuid() == uuid("v4")}}`},
+		}, map[string]any{
+			"vars": map[string]any{
+				"id": "{{uuid()}}",
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		m := v.(yaml.MapSlice)
+		if m[0].Value != m[1].Value {
+			t.Errorf("expected the same UUID but got %q and %q", m[0].Value, m[1].Value)
+		}
+		if m[0].Value == m[2].Value {
+			t.Errorf("expected different UUIDs but got %q", m[0].Value)
+		}
+		if m[3].Value != true {
+			t.Errorf("expected true but got %v", m[3].Value)
+		}
+
+		w, err := Execute("{{uuid()}}", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if m[0].Value == w {
+			t.Errorf("expected a new UUID in another execution but got %q", w)
+		}
+	})
+
+	t.Run("shared by the executions with the state", func(t *testing.T) {
+		data := &stateData{state: NewState()}
+		x, err := Execute("{{uuid()}}", data)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		y, err := Execute("{{uuid()}}", data)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if x != y {
+			t.Errorf("expected the same UUID but got %q and %q", x, y)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for str, expect := range map[string]string{
+			`{{uuid("v1")}}`:           `uuid: unknown version "v1": version must be "v4" or "v7"`,
+			`{{uuid("v4", "a", "b")}}`: "uuid: too many arguments",
+		} {
+			_, err := Execute(str, nil)
+			if err == nil {
+				t.Fatalf("%s: no error", str)
+			}
+			if !strings.Contains(err.Error(), expect) {
+				t.Errorf("%s: expected %q to contain %q", str, err, expect)
+			}
+		}
+	})
+}
+
+type stateData struct {
+	state *State
+}
+
+func (d *stateData) TemplateState() *State {
+	return d.state
+}